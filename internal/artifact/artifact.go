@@ -0,0 +1,134 @@
+package artifact
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+// ResolvePath resolves a caller-supplied relative path under baseDir.
+// Absolute paths and ".." components are rejected so callers cannot escape baseDir, and
+// symlinks in the existing part of the path are resolved before the containment check, so a
+// link inside baseDir cannot point the write elsewhere either.
+func ResolvePath(baseDir, relative string) (string, error) {
+	if baseDir == "" {
+		return "", fmt.Errorf("artifact directory is not configured")
+	}
+
+	relative = strings.TrimSpace(relative)
+	if filepath.IsAbs(relative) || strings.HasPrefix(relative, "/") || strings.HasPrefix(relative, `\`) || filepath.VolumeName(relative) != "" {
+		return "", fmt.Errorf("path %q must be relative to the artifact directory", relative)
+	}
+
+	for _, part := range strings.FieldsFunc(relative, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if part == ".." {
+			return "", fmt.Errorf("path %q must not contain '..' components", relative)
+		}
+	}
+
+	base, err := filepath.Abs(baseDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve artifact directory: %w", err)
+	}
+	if base, err = evalExisting(base); err != nil {
+		return "", fmt.Errorf("failed to resolve artifact directory: %w", err)
+	}
+	resolved, err := evalExisting(filepath.Join(base, filepath.FromSlash(relative)))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %q: %w", relative, err)
+	}
+
+	// The joined path, with its symlinks followed, must still live under the base directory
+	rel, err := filepath.Rel(base, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q escapes the artifact directory", relative)
+	}
+
+	return resolved, nil
+}
+
+// evalExisting resolves the symlinks of the longest existing prefix of path and appends the
+// components that do not exist yet, which a later write will create
+func evalExisting(path string) (string, error) {
+	var missing []string
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
+
+// FileStem returns a unique file name stem for an artifact written at now: the UTC timestamp,
+// which keeps listings in time order, and a random suffix, so concurrent scans finishing in the
+// same millisecond do not overwrite each other's files
+func FileStem(prefix string, now time.Time) string {
+	var suffix [4]byte
+	rand.Read(suffix[:])
+	return prefix + "-" + now.UTC().Format("20060102T150405.000Z") + "-" + hex.EncodeToString(suffix[:])
+}
+
+// WriteScan writes the raw KRR output and the structured scan result to uniquely named files
+// in dir, creating it if needed. Scan results describe the cluster, so the directory and files
+// are only accessible to the server's user. It returns the paths of the written files.
+func WriteScan(dir string, result *krr.ScanResult, format krr.OutputFormat, now time.Time) ([]string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	base := filepath.Join(dir, FileStem("krr-scan", now))
+
+	rawExt := ".txt"
+	if format == krr.OutputJSON {
+		rawExt = ".raw.json"
+	} else if format == krr.OutputYAML {
+		rawExt = ".yaml"
+	}
+	rawPath := base + rawExt
+	if err := WriteFile(rawPath, []byte(result.RawOutput)); err != nil {
+		return nil, fmt.Errorf("failed to write raw output: %w", err)
+	}
+
+	structured, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scan result: %w", err)
+	}
+	jsonPath := base + ".json"
+	if err := WriteFile(jsonPath, structured); err != nil {
+		return nil, fmt.Errorf("failed to write structured result: %w", err)
+	}
+
+	return []string{rawPath, jsonPath}, nil
+}
+
+// WriteFile creates path with owner-only permissions and writes data to it. It fails rather
+// than follow a symlink or replace a file already at path.
+func WriteFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
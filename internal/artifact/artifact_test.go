@@ -0,0 +1,131 @@
+package artifact
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+func TestResolvePath(t *testing.T) {
+	base := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Mkdir(filepath.Join(base, "reports"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(base, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(base, "reports"), filepath.Join(base, "latest")); err != nil {
+		t.Fatal(err)
+	}
+	resolvedBase, err := filepath.EvalSymlinks(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		relative string
+		want     string // relative to the resolved base; empty when an error is expected
+	}{
+		{"existing directory", "reports", "reports"},
+		{"directory to be created", "reports/2024/may", "reports/2024/may"},
+		{"base itself", "", "."},
+		{"symlink within the base", "latest/daily", "reports/daily"},
+		{"absolute path", "/etc/passwd", ""},
+		{"backslash root", `\windows`, ""},
+		{"parent directory", "../other", ""},
+		{"nested parent directory", "reports/../../other", ""},
+		{"backslash parent directory", `reports\..\..\other`, ""},
+		{"symlink out of the base", "escape", ""},
+		{"path below a symlink out of the base", "escape/reports", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolvePath(base, tt.relative)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("ResolvePath(%q) = %q, want an error", tt.relative, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolvePath(%q) error = %v", tt.relative, err)
+			}
+			if want := filepath.Join(resolvedBase, filepath.FromSlash(tt.want)); got != want {
+				t.Errorf("ResolvePath(%q) = %q, want %q", tt.relative, got, want)
+			}
+		})
+	}
+
+	if _, err := ResolvePath("", "reports"); err == nil {
+		t.Error("ResolvePath() without an artifact directory succeeded")
+	}
+}
+
+func TestWriteScan(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	result := &krr.ScanResult{RawOutput: `{"scans": []}`}
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	first, err := WriteScan(dir, result, krr.OutputJSON, now)
+	if err != nil {
+		t.Fatalf("WriteScan() error = %v", err)
+	}
+	// A second scan finishing in the same millisecond must not overwrite the first
+	second, err := WriteScan(dir, result, krr.OutputJSON, now)
+	if err != nil {
+		t.Fatalf("WriteScan() error = %v", err)
+	}
+	if first[0] == second[0] || first[1] == second[1] {
+		t.Fatalf("WriteScan() reused paths %v", first)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0700 {
+		t.Errorf("directory mode = %o, want 700", mode)
+	}
+	for _, path := range append(first, second...) {
+		if !strings.HasPrefix(filepath.Base(path), "krr-scan-20240501T100000.000Z-") {
+			t.Errorf("file %s lacks the timestamp prefix", path)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("%s mode = %o, want 600", filepath.Base(path), mode)
+		}
+	}
+	if !strings.HasSuffix(first[0], ".raw.json") || !strings.HasSuffix(first[1], ".json") {
+		t.Errorf("WriteScan() = %v, want the raw JSON and the structured result", first)
+	}
+}
+
+func TestWriteFileRefusesExistingPaths(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(t.TempDir(), "target")
+	if err := os.WriteFile(target, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{target, link} {
+		if err := WriteFile(path, []byte("overwritten")); err == nil {
+			t.Errorf("WriteFile(%s) succeeded, want an error", path)
+		}
+	}
+	if data, _ := os.ReadFile(target); string(data) != "keep" {
+		t.Errorf("target = %q, want it untouched", data)
+	}
+}
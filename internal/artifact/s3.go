@@ -0,0 +1,173 @@
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Uploader stores scan reports in remote object storage
+type Uploader interface {
+	// Upload stores body under the given object key
+	Upload(ctx context.Context, key string, body []byte, contentType string) error
+
+	// URL returns the location of the object stored under key
+	URL(key string) string
+}
+
+// S3Config holds the settings for an S3-compatible bucket
+type S3Config struct {
+	Bucket          string
+	Endpoint        string // e.g. https://s3.eu-west-1.amazonaws.com or http://minio:9000
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UsePathStyle    bool // required by most S3-compatible servers such as MinIO
+}
+
+// S3Uploader uploads objects to an S3-compatible bucket using AWS Signature Version 4
+type S3Uploader struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Uploader creates an uploader for the configured bucket
+func NewS3Uploader(config S3Config) (*S3Uploader, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket cannot be empty")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	if _, err := url.Parse(config.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+
+	return &S3Uploader{
+		config: config,
+		client: &http.Client{Timeout: 2 * time.Minute},
+		now:    time.Now,
+	}, nil
+}
+
+// URL returns the object URL for key
+func (u *S3Uploader) URL(key string) string {
+	endpoint, _ := url.Parse(u.config.Endpoint)
+	path := "/" + encodePath(key)
+	if u.config.UsePathStyle {
+		path = "/" + u.config.Bucket + path
+	} else {
+		endpoint.Host = u.config.Bucket + "." + endpoint.Host
+	}
+	endpoint.Path = ""
+	return strings.TrimSuffix(endpoint.String(), "/") + path
+}
+
+// Upload stores body under key with a signed PUT request
+func (u *S3Uploader) Upload(ctx context.Context, key string, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.URL(key), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	u.sign(req, body)
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload of %s failed with status %d: %s", key, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// sign adds AWS Signature Version 4 headers to the request
+func (u *S3Uploader) sign(req *http.Request, body []byte) {
+	now := u.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Canonical headers must be lower-case, sorted, and include host
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + u.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+u.config.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, u.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		u.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// encodePath URI-encodes each segment of an object key, keeping the '/' separators
+func encodePath(key string) string {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -1,11 +1,79 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/manifest"
+	"greenops-mcp/internal/schedule"
+)
+
+// MCP transports the server can speak
+const (
+	TransportHTTP  = "http"
+	TransportStdio = "stdio"
+)
+
+// ScheduleEntry is a scan the server runs on a cron schedule
+type ScheduleEntry struct {
+	Name        string          `json:"name"`
+	Cron        string          `json:"cron"`
+	Options     krr.ScanOptions `json:"options"`
+	NotifySlack bool            `json:"notify_slack"`
+}
+
+// TenantConfig pins the tool calls of one tenant to a fixed cluster scope. Context, Namespace
+// and PrometheusURL override whatever the client asks for; Namespaces is the list a client may
+// choose from instead of a single fixed namespace. Empty fields leave that dimension open.
+type TenantConfig struct {
+	Name           string   `json:"name"`
+	Context        string   `json:"context"`
+	KubeconfigData string   `json:"kubeconfig_data"`
+	Namespace      string   `json:"namespace"`
+	Namespaces     []string `json:"namespaces"`
+	PrometheusURL  string   `json:"prometheus_url"`
+}
+
+// ClusterConfig is a named cluster in the cluster registry, which the cluster argument of
+// krr_scan selects. Empty fields fall back to the server's kubeconfig, current context and
+// Prometheus discovery; labels only describe the cluster to clients.
+type ClusterConfig struct {
+	Context       string            `json:"context"`
+	Kubeconfig    string            `json:"kubeconfig"`
+	PrometheusURL string            `json:"prometheus_url"`
+	Labels        map[string]string `json:"labels"`
+
+	// Pricing names the entry of pricing that krr_cost_estimate uses for scans of the cluster
+	Pricing string `json:"pricing"`
+
+	// GridIntensity is the carbon intensity of the cluster's electricity in gCO2e/kWh, which
+	// krr_carbon_estimate uses instead of carbon_grid_intensity (0 keeps the server's)
+	GridIntensity float64 `json:"grid_intensity"`
+}
+
+// PriceSet is a named set of unit prices, e.g. of a cloud provider, region or node pool
+type PriceSet struct {
+	CPUCostPerCoreHour   float64 `json:"cpu_cost_per_core_hour"`
+	MemoryCostPerGiBHour float64 `json:"memory_cost_per_gib_hour"`
+	Currency             string  `json:"currency"`
+}
+
+// Tenant routing modes: how a request's tenant is found, and so what the tenants map is keyed by
+const (
+	TenantRoutingToken  = "token"  // the request's bearer token
+	TenantRoutingHeader = "header" // the value of tenant_header, set by a trusted caller or gateway
+	TenantRoutingClaim  = "claim"  // the value(s) of the OIDC claim tenant_claim
 )
 
 // Config represents the configuration for the KRR MCP server
@@ -13,35 +81,280 @@ type Config struct {
 	// KRR CLI configuration
 	KRRPath         string        `json:"krr_path"`
 	DefaultTimeout  time.Duration `json:"default_timeout"`
+	MaxTimeout      time.Duration `json:"max_timeout"`
 	DefaultStrategy string        `json:"default_strategy"`
-	
+
+	// Upper bound on KRR processes running at once across all tool calls
+	MaxConcurrentScans int `json:"max_concurrent_scans"`
+
+	// Per-client limits, a client being a tenant, an OIDC user or else an MCP session: tool
+	// calls per minute and scanning tool calls in flight at once (0 disables either)
+	ClientRateLimit          int `json:"client_rate_limit"`
+	ClientMaxConcurrentScans int `json:"client_max_concurrent_scans"`
+
+	// KRR's internal parallelism (--max_workers) when a call doesn't set krr_workers (0 keeps
+	// KRR's default), and the most a call may ask for
+	DefaultKRRWorkers int `json:"default_krr_workers"`
+	MaxKRRWorkers     int `json:"max_krr_workers"`
+
+	// Retries for scans rate limited by Prometheus (HTTP 429), honouring Retry-After hints and
+	// otherwise backing off exponentially from rate_limit_backoff (0 retries disables)
+	RateLimitRetries int           `json:"rate_limit_retries"`
+	RateLimitBackoff time.Duration `json:"rate_limit_backoff"`
+
+	// Directory holding custom KRR strategy files that the strategy_path argument may select
+	// (disabled if empty), and the Python interpreter that runs them
+	StrategyDir string `json:"strategy_dir"`
+	PythonPath  string `json:"python_path"`
+
+	// Arguments appended verbatim to every KRR scan after the generated flags (unvalidated)
+	ExtraArgs []string `json:"extra_args"`
+
+	// KRR flags a krr_scan call may pass through its extra_flags argument (none if empty)
+	AllowedExtraFlags []string `json:"allowed_extra_flags"`
+
+	// User-Agent KRR sends with its Prometheus queries, to attribute them in shared Prometheus logs
+	PrometheusUserAgent string `json:"prometheus_user_agent"`
+
+	// PEM bundle of the CA certificates KRR trusts for an HTTPS Prometheus with a private CA
+	PrometheusCACertFile string `json:"prometheus_ca_cert_file"`
+
+	// kubectl CLI used for cluster lookups KRR doesn't cover (e.g. node placement)
+	KubectlPath string `json:"kubectl_path"`
+
+	// Inline kubeconfig (raw or base64 YAML) for environments without a mounted kubeconfig file
+	KubeconfigData string `json:"kubeconfig_data"`
+
+	// Named clusters a scan can target by name instead of by context
+	Clusters map[string]ClusterConfig `json:"clusters"`
+
+	// Static API keys the MCP endpoint requires, as a bearer token or X-API-Key header: inline,
+	// and/or one per line in a secret file (disabled if both are empty)
+	AuthToken     string `json:"auth_token"`
+	AuthTokenFile string `json:"auth_token_file"`
+
+	// OpenID Connect: when an issuer is set, MCP requests must carry a bearer JWT signed by it
+	// for the audience, and the claim named by oidc_username_claim identifies the caller in
+	// logs and scan records
+	OIDCIssuerURL     string `json:"oidc_issuer_url"`
+	OIDCAudience      string `json:"oidc_audience"`
+	OIDCUsernameClaim string `json:"oidc_username_claim"`
+
+	// Multi-tenant mode: when set, MCP requests must resolve to a tenant and every scan is
+	// confined to that tenant's scope. The map is keyed by bearer token, tenant_header value or
+	// tenant_claim value, depending on tenant_routing.
+	Tenants       map[string]TenantConfig `json:"tenants"`
+	TenantRouting string                  `json:"tenant_routing"`
+	TenantHeader  string                  `json:"tenant_header"`
+	TenantClaim   string                  `json:"tenant_claim"`
+
 	// Server configuration
-	ServerName        string `json:"server_name"`
-	ServerVersion     string `json:"server_version"`
-	
+	ServerName    string `json:"server_name"`
+	ServerVersion string `json:"server_version"`
+
+	// MCP transport: streamable HTTP, or stdio for clients that launch the server as a subprocess
+	Transport string `json:"transport"`
+
+	// Address the HTTP server listens on, e.g. ":8080" or "127.0.0.1:9000", or a Unix socket
+	// as "unix:///var/run/greenops-mcp.sock"
+	ListenAddr string `json:"listen_addr"`
+
+	// Serve HTTPS with this PEM certificate and key (plain HTTP if empty). With tls_auto_reload
+	// the files are re-read when they change, for rotated certificates.
+	TLSCertFile   string `json:"tls_cert_file"`
+	TLSKeyFile    string `json:"tls_key_file"`
+	TLSAutoReload bool   `json:"tls_auto_reload"`
+
+	// PEM bundle of the CAs whose client certificates may call the MCP endpoint (mutual TLS);
+	// health checks stay reachable without one
+	TLSClientCAFile string `json:"tls_client_ca_file"`
+
+	// HTTP path the streamable MCP handler is mounted at
+	MCPPath string `json:"mcp_path"`
+
+	// Path prefix every HTTP route is served under, e.g. "/greenops" when an ingress mounts the
+	// server there without rewriting paths (none if empty)
+	BasePath string `json:"base_path"`
+
+	// Serve streamable HTTP statelessly: no MCP sessions are kept between requests, so any
+	// replica behind a load balancer can answer any request without session affinity
+	Stateless bool `json:"stateless"`
+
+	// Also serve the legacy HTTP+SSE transport, for clients that predate streamable HTTP, at
+	// sse_path (its message endpoint is the same path with a session query parameter)
+	EnableSSE bool   `json:"enable_sse"`
+	SSEPath   string `json:"sse_path"`
+
+	// CORS for browser-based MCP clients: origins allowed to call the MCP endpoint ("*" for
+	// any; none if empty, so browsers are denied), and the request headers and methods they
+	// may use (defaults cover the MCP protocol and this server's auth headers)
+	CORSAllowedOrigins []string `json:"cors_allowed_origins"`
+	CORSAllowedHeaders []string `json:"cors_allowed_headers"`
+	CORSAllowedMethods []string `json:"cors_allowed_methods"`
+
+	// HTTP server timeouts (0 disables). The MCP endpoint extends its write deadline to
+	// max_timeout plus write_timeout, so long scans are not cut off.
+	ReadTimeout  time.Duration `json:"read_timeout"`
+	WriteTimeout time.Duration `json:"write_timeout"`
+	IdleTimeout  time.Duration `json:"idle_timeout"`
+
+	// How long shutdown waits for in-flight scans to finish before canceling them
+	DrainTimeout time.Duration `json:"drain_timeout"`
+
 	// Default scan options
-	DefaultNamespace  string `json:"default_namespace"`
+	DefaultNamespace    string `json:"default_namespace"`
 	DefaultOutputFormat string `json:"default_output_format"`
-	DefaultNoColor    bool   `json:"default_no_color"`
-	
+	DefaultNoColor      bool   `json:"default_no_color"`
+
+	// Named scan profiles that krr_scan's profile argument loads as its base options
+	Profiles map[string]krr.ScanOptions `json:"profiles"`
+
+	// Scan scope guardrails protecting a shared Prometheus: the longest history window a scan may
+	// analyse (0 disables) and whether every scan must target a single namespace
+	MaxHistoryDuration time.Duration `json:"max_history_duration"`
+	RequireNamespace   bool          `json:"require_namespace"`
+
+	// Directory krr_scan_async jobs are persisted in, so their results survive a restart (in
+	// memory only if empty), and how long finished jobs are kept
+	JobDir       string        `json:"job_dir"`
+	JobRetention time.Duration `json:"job_retention"`
+
+	// Number of finished scans whose outcome krr_recent reports (0 disables)
+	RecentScans int `json:"recent_scans"`
+
+	// Directory every parsed scan is persisted in for krr_scan_history (disabled if empty), how
+	// long stored scans are kept (0 keeps them forever) and whether their results are gzipped
+	HistoryDir       string        `json:"history_dir"`
+	HistoryRetention time.Duration `json:"history_retention"`
+	CompressStored   bool          `json:"compress_stored"`
+
+	// Scans run periodically by the server itself; results are published like krr_scan reports
+	ScheduledScans []ScheduleEntry `json:"scheduled_scans"`
+
+	// File the schedules created with krr_schedule_scan are persisted in, so they survive a
+	// restart (in memory only if empty), and how many such schedules may exist (0 disables it)
+	ScheduleFile string `json:"schedule_file"`
+	MaxSchedules int    `json:"max_schedules"`
+
+	// Maximum number of table rows returned by krr_scan, keeping the header (0 disables)
+	MaxOutputRows int `json:"max_output_rows"`
+
+	// Cost model used by the cost output mode (prices per hour, in any currency)
+	CPUCostPerCoreHour   float64 `json:"cpu_cost_per_core_hour"`
+	MemoryCostPerGiBHour float64 `json:"memory_cost_per_gib_hour"`
+
+	// Named price sets krr_cost_estimate can price a scan with instead of the cost model above
+	Pricing map[string]PriceSet `json:"pricing"`
+
+	// Carbon model of krr_carbon_estimate: average watts drawn per vCPU and per GiB of memory,
+	// the data center's power usage effectiveness and the grid's carbon intensity in gCO2e/kWh
+	CarbonWattsPerVCPU  float64 `json:"carbon_watts_per_vcpu"`
+	CarbonWattsPerGiB   float64 `json:"carbon_watts_per_gib"`
+	CarbonPUE           float64 `json:"carbon_pue"`
+	CarbonGridIntensity float64 `json:"carbon_grid_intensity"`
+
+	// Severity thresholds, as percentages of the recommended request that current requests may
+	// fall below (under) or exceed (over) before a container is classified WARNING or CRITICAL
+	SeverityUnderCriticalPercent float64 `json:"severity_under_critical_percent"`
+	SeverityUnderWarningPercent  float64 `json:"severity_under_warning_percent"`
+	SeverityOverCriticalPercent  float64 `json:"severity_over_critical_percent"`
+	SeverityOverWarningPercent   float64 `json:"severity_over_warning_percent"`
+
+	// Whether krr_apply_recommendations may patch workloads in the cluster; it is disabled by
+	// default so the server stays read-only
+	EnableApply bool `json:"enable_apply"`
+
+	// Where krr_generate_helm_values puts container resources in the values of a chart, as
+	// dot-separated paths by chart name ("*" for any other chart); "resources" if unset
+	HelmValuePaths map[string]string `json:"helm_value_paths"`
+
+	// Directory under which scan reports may be saved via the save_to_path argument (disabled if empty)
+	ArtifactDir string `json:"artifact_dir"`
+
+	// Optional upload of scan reports to an S3-compatible bucket (disabled if s3_bucket is empty).
+	// Credentials fall back to AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY.
+	S3Bucket          string `json:"s3_bucket"`
+	S3Endpoint        string `json:"s3_endpoint"`
+	S3Region          string `json:"s3_region"`
+	S3Prefix          string `json:"s3_prefix"`
+	S3UsePathStyle    bool   `json:"s3_use_path_style"`
+	S3AccessKeyID     string `json:"s3_access_key_id"`
+	S3SecretAccessKey string `json:"s3_secret_access_key"`
+
+	// Slack notifications posted after successful scans
+	SlackWebhookURL    string `json:"slack_webhook_url"`
+	DefaultNotifySlack bool   `json:"default_notify_slack"`
+
+	// Optional Prometheus Pushgateway that receives savings metrics after every successful
+	// scan (disabled if pushgateway_url is empty)
+	PushgatewayURL string `json:"pushgateway_url"`
+	PushgatewayJob string `json:"pushgateway_job"`
+
 	// Logging
 	LogLevel string `json:"log_level"`
 	LogFile  string `json:"log_file"`
+
+	// Access log of the MCP endpoints. Requests that fail (status 400 and up) are always logged;
+	// the others with probability access_log_sample_rate.
+	AccessLog           bool    `json:"access_log"`
+	AccessLogSampleRate float64 `json:"access_log_sample_rate"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
-		KRRPath:           "krr", // Assumes krr is in PATH
-		DefaultTimeout:    5 * time.Minute,
-		DefaultStrategy:   "simple",
-		ServerName:        "krr-mcp-server",
-		ServerVersion:     "1.0.0",
-		DefaultNamespace:  "",
+		KRRPath:             "krr", // Assumes krr is in PATH
+		DefaultTimeout:      5 * time.Minute,
+		MaxTimeout:          30 * time.Minute,
+		DefaultStrategy:     "simple",
+		MaxConcurrentScans:  4,
+		MaxKRRWorkers:       32,
+		RateLimitRetries:    2,
+		RateLimitBackoff:    10 * time.Second,
+		PythonPath:          "python3",
+		KubectlPath:         "kubectl",
+		ServerName:          "krr-mcp-server",
+		ServerVersion:       "1.0.0",
+		Transport:           TransportHTTP,
+		ListenAddr:          ":8080",
+		OIDCUsernameClaim:   "email",
+		MCPPath:             "/mcp",
+		TenantRouting:       TenantRoutingToken,
+		TenantHeader:        "X-Tenant",
+		TenantClaim:         "tenant",
+		SSEPath:             "/sse",
+		CORSAllowedHeaders:  DefaultCORSAllowedHeaders(),
+		CORSAllowedMethods:  DefaultCORSAllowedMethods(),
+		ReadTimeout:         30 * time.Second,
+		WriteTimeout:        30 * time.Second,
+		IdleTimeout:         2 * time.Minute,
+		DrainTimeout:        30 * time.Second,
+		JobRetention:        time.Hour,
+		DefaultNamespace:    "",
 		DefaultOutputFormat: "table",
-		DefaultNoColor:    true,
-		LogLevel:          "info",
-		LogFile:           "",
+		DefaultNoColor:      true,
+
+		SeverityUnderCriticalPercent: 50,
+		SeverityUnderWarningPercent:  20,
+		SeverityOverCriticalPercent:  100,
+		SeverityOverWarningPercent:   50,
+
+		// Cloud Carbon Footprint's average coefficients and the world average grid intensity
+		CarbonWattsPerVCPU:  2.12,
+		CarbonWattsPerGiB:   0.392,
+		CarbonPUE:           1.135,
+		CarbonGridIntensity: 475,
+
+		RecentScans:      50,
+		HistoryRetention: 90 * 24 * time.Hour,
+		CompressStored:   true,
+		MaxSchedules:     20,
+		PushgatewayJob:   "greenops-mcp",
+
+		LogLevel: "info",
+		LogFile:  "",
+
+		AccessLogSampleRate: 1,
 	}
 }
 
@@ -51,24 +364,24 @@ func LoadConfig(configPath string) (*Config, error) {
 	if configPath == "" {
 		return DefaultConfig(), nil
 	}
-	
+
 	// Check if config file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return DefaultConfig(), nil
 	}
-	
+
 	// Read config file
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
-	
+
 	// Parse JSON config
 	config := DefaultConfig()
 	if err := json.Unmarshal(data, config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
-	
+
 	// Validate and set defaults for missing fields
 	if config.KRRPath == "" {
 		config.KRRPath = "krr"
@@ -76,22 +389,55 @@ func LoadConfig(configPath string) (*Config, error) {
 	if config.DefaultTimeout == 0 {
 		config.DefaultTimeout = 5 * time.Minute
 	}
+	if config.MaxTimeout == 0 {
+		config.MaxTimeout = 30 * time.Minute
+	}
 	if config.DefaultStrategy == "" {
 		config.DefaultStrategy = "simple"
 	}
+	if config.KubectlPath == "" {
+		config.KubectlPath = "kubectl"
+	}
 	if config.ServerName == "" {
 		config.ServerName = "krr-mcp-server"
 	}
 	if config.ServerVersion == "" {
 		config.ServerVersion = "1.0.0"
 	}
+	if config.Transport == "" {
+		config.Transport = TransportHTTP
+	}
+	if config.ListenAddr == "" {
+		config.ListenAddr = ":8080"
+	}
+	if config.OIDCUsernameClaim == "" {
+		config.OIDCUsernameClaim = "email"
+	}
+	if config.MCPPath == "" {
+		config.MCPPath = "/mcp"
+	}
+	if config.TenantRouting == "" {
+		config.TenantRouting = TenantRoutingToken
+	}
+	if config.SSEPath == "" {
+		config.SSEPath = "/sse"
+	}
+	if len(config.CORSAllowedHeaders) == 0 {
+		config.CORSAllowedHeaders = DefaultCORSAllowedHeaders()
+	}
+	if len(config.CORSAllowedMethods) == 0 {
+		config.CORSAllowedMethods = DefaultCORSAllowedMethods()
+	}
 	if config.DefaultOutputFormat == "" {
 		config.DefaultOutputFormat = "json"
 	}
+	if config.PushgatewayJob == "" {
+		config.PushgatewayJob = "greenops-mcp"
+	}
 	if config.LogLevel == "" {
 		config.LogLevel = "info"
 	}
-	
+
 	return config, nil
 }
 
@@ -102,18 +448,18 @@ func (c *Config) SaveConfig(configPath string) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
-	
+
 	// Marshal config to JSON
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
-	
+
 	// Write config file
 	if err := os.WriteFile(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
-	
+
 	return nil
 }
 
@@ -122,24 +468,370 @@ func (c *Config) Validate() error {
 	if c.KRRPath == "" {
 		return fmt.Errorf("krr_path cannot be empty")
 	}
-	
+
 	if c.DefaultTimeout <= 0 {
 		return fmt.Errorf("default_timeout must be positive")
 	}
-	
+
+	if c.MaxTimeout <= 0 {
+		return fmt.Errorf("max_timeout must be positive")
+	}
+
+	if c.ReadTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 {
+		return fmt.Errorf("read_timeout, write_timeout and idle_timeout cannot be negative")
+	}
+
+	if c.DrainTimeout < 0 {
+		return fmt.Errorf("drain_timeout cannot be negative")
+	}
+
+	if c.JobRetention <= 0 {
+		return fmt.Errorf("job_retention must be positive")
+	}
+
+	if c.RateLimitRetries < 0 {
+		return fmt.Errorf("rate_limit_retries cannot be negative")
+	}
+
+	if c.RateLimitRetries > 0 && c.RateLimitBackoff <= 0 {
+		return fmt.Errorf("rate_limit_backoff must be positive when rate_limit_retries is set")
+	}
+
+	if c.PrometheusCACertFile != "" {
+		data, err := os.ReadFile(c.PrometheusCACertFile)
+		if err != nil {
+			return fmt.Errorf("prometheus_ca_cert_file: %w", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(data) {
+			return fmt.Errorf("prometheus_ca_cert_file %s contains no PEM certificates", c.PrometheusCACertFile)
+		}
+	}
+
+	if c.MaxConcurrentScans <= 0 {
+		return fmt.Errorf("max_concurrent_scans must be positive")
+	}
+
+	if c.ClientRateLimit < 0 {
+		return fmt.Errorf("client_rate_limit cannot be negative")
+	}
+
+	if c.ClientMaxConcurrentScans < 0 {
+		return fmt.Errorf("client_max_concurrent_scans cannot be negative")
+	}
+
+	for _, flag := range c.AllowedExtraFlags {
+		if _, err := krr.NormalizeFlagName(flag); err != nil {
+			return fmt.Errorf("allowed_extra_flags: %w", err)
+		}
+	}
+
+	if c.MaxKRRWorkers <= 0 {
+		return fmt.Errorf("max_krr_workers must be positive")
+	}
+
+	if c.DefaultKRRWorkers < 0 || c.DefaultKRRWorkers > c.MaxKRRWorkers {
+		return fmt.Errorf("default_krr_workers must be between 0 and max_krr_workers (%d)", c.MaxKRRWorkers)
+	}
+
+	if c.MaxHistoryDuration < 0 {
+		return fmt.Errorf("max_history_duration cannot be negative")
+	}
+
+	if c.RecentScans < 0 {
+		return fmt.Errorf("recent_scans cannot be negative")
+	}
+
+	if c.HistoryRetention < 0 {
+		return fmt.Errorf("history_retention cannot be negative")
+	}
+
+	if c.MaxOutputRows < 0 {
+		return fmt.Errorf("max_output_rows cannot be negative")
+	}
+
+	for name, profile := range c.Profiles {
+		if err := validateProfile(profile); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+
+	names := make(map[string]bool, len(c.ScheduledScans))
+	for _, entry := range c.ScheduledScans {
+		if entry.Name == "" {
+			return fmt.Errorf("scheduled_scans entries need a name")
+		}
+		if names[entry.Name] {
+			return fmt.Errorf("duplicate scheduled scan name: %s", entry.Name)
+		}
+		names[entry.Name] = true
+		if _, err := schedule.Parse(entry.Cron); err != nil {
+			return fmt.Errorf("scheduled scan %q: %w", entry.Name, err)
+		}
+		if err := validateProfile(entry.Options); err != nil {
+			return fmt.Errorf("scheduled scan %q: %w", entry.Name, err)
+		}
+		if entry.NotifySlack && c.SlackWebhookURL == "" {
+			return fmt.Errorf("scheduled scan %q: notify_slack requires slack_webhook_url", entry.Name)
+		}
+	}
+
+	if c.MaxSchedules < 0 {
+		return fmt.Errorf("max_schedules cannot be negative")
+	}
+
+	for chart, path := range c.HelmValuePaths {
+		if err := manifest.ValidateValuePath(path); err != nil {
+			return fmt.Errorf("helm_value_paths %q: %w", chart, err)
+		}
+	}
+
+	if c.Transport != TransportHTTP && c.Transport != TransportStdio {
+		return fmt.Errorf("transport must be %q or %q", TransportHTTP, TransportStdio)
+	}
+
+	if c.Transport == TransportHTTP {
+		if path, ok := c.UnixSocketPath(); ok {
+			if path == "" {
+				return fmt.Errorf("listen_addr %q has no socket path", c.ListenAddr)
+			}
+		} else if _, _, err := net.SplitHostPort(c.ListenAddr); err != nil {
+			return fmt.Errorf("listen_addr must be host:port, :port or unix:///path: %w", err)
+		}
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("tls_cert_file and tls_key_file must be set together")
+	}
+	if c.TLSCertFile != "" {
+		if c.Transport != TransportHTTP {
+			return fmt.Errorf("tls_cert_file requires the %q transport", TransportHTTP)
+		}
+		if _, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile); err != nil {
+			return fmt.Errorf("tls_cert_file/tls_key_file: %w", err)
+		}
+	}
+	if c.TLSClientCAFile != "" {
+		if c.TLSCertFile == "" {
+			return fmt.Errorf("tls_client_ca_file requires tls_cert_file and tls_key_file")
+		}
+		data, err := os.ReadFile(c.TLSClientCAFile)
+		if err != nil {
+			return fmt.Errorf("tls_client_ca_file: %w", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(data) {
+			return fmt.Errorf("tls_client_ca_file %s contains no PEM certificates", c.TLSClientCAFile)
+		}
+	}
+
+	// Tenants are identified by HTTP request details, which stdio has no equivalent of
+	if len(c.Tenants) > 0 && c.Transport != TransportHTTP {
+		return fmt.Errorf("tenants require the %q transport", TransportHTTP)
+	}
+	switch c.TenantRouting {
+	case TenantRoutingToken, TenantRoutingHeader, TenantRoutingClaim:
+	default:
+		return fmt.Errorf("tenant_routing must be %q, %q or %q", TenantRoutingToken, TenantRoutingHeader, TenantRoutingClaim)
+	}
+	tokenTenants := len(c.Tenants) > 0 && c.TenantRouting == TenantRoutingToken
+
+	tokens, err := c.AuthTokens()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if len(token) < 16 {
+			return fmt.Errorf("auth tokens must be at least 16 characters")
+		}
+	}
+	if len(tokens) > 0 && tokenTenants {
+		return fmt.Errorf("auth_token and token-routed tenants cannot be combined; tenant tokens already authenticate requests")
+	}
+	if len(tokens) > 0 && c.Transport != TransportHTTP {
+		return fmt.Errorf("auth_token requires the %q transport", TransportHTTP)
+	}
+
+	if c.OIDCIssuerURL != "" {
+		issuer, err := url.Parse(c.OIDCIssuerURL)
+		if err != nil || issuer.Host == "" || (issuer.Scheme != "https" && issuer.Scheme != "http") {
+			return fmt.Errorf("oidc_issuer_url must be an absolute http(s) URL")
+		}
+		if c.OIDCAudience == "" {
+			return fmt.Errorf("oidc_issuer_url requires oidc_audience")
+		}
+		if len(tokens) > 0 || tokenTenants {
+			return fmt.Errorf("oidc_issuer_url cannot be combined with auth_token or token-routed tenants")
+		}
+		if c.Transport != TransportHTTP {
+			return fmt.Errorf("oidc_issuer_url requires the %q transport", TransportHTTP)
+		}
+	}
+
+	if len(c.Tenants) > 0 {
+		switch c.TenantRouting {
+		case TenantRoutingHeader:
+			// The header alone proves nothing, so callers must be authenticated some other way
+			if len(tokens) == 0 && c.TLSClientCAFile == "" && c.OIDCIssuerURL == "" {
+				return fmt.Errorf("tenant_routing %q requires auth_token, tls_client_ca_file or oidc_issuer_url", TenantRoutingHeader)
+			}
+			if c.TenantHeader == "" {
+				return fmt.Errorf("tenant_routing %q requires tenant_header", TenantRoutingHeader)
+			}
+		case TenantRoutingClaim:
+			if c.OIDCIssuerURL == "" {
+				return fmt.Errorf("tenant_routing %q requires oidc_issuer_url", TenantRoutingClaim)
+			}
+			if c.TenantClaim == "" {
+				return fmt.Errorf("tenant_routing %q requires tenant_claim", TenantRoutingClaim)
+			}
+		}
+	}
+
+	tenantNames := make(map[string]bool, len(c.Tenants))
+	for key, tenant := range c.Tenants {
+		if tokenTenants && len(key) < 16 {
+			return fmt.Errorf("tenant %q: tokens must be at least 16 characters", tenant.Name)
+		}
+		if key == "" {
+			return fmt.Errorf("tenant %q: empty key", tenant.Name)
+		}
+		if tenant.Name == "" {
+			return fmt.Errorf("tenants entries need a name")
+		}
+		if tenantNames[tenant.Name] {
+			return fmt.Errorf("duplicate tenant name: %s", tenant.Name)
+		}
+		// Names become directory names for the tenant's stored reports
+		if strings.ContainsAny(tenant.Name, `/\`) || tenant.Name == "." || tenant.Name == ".." {
+			return fmt.Errorf("tenant %q: names cannot contain path separators", tenant.Name)
+		}
+		tenantNames[tenant.Name] = true
+		if tenant.Namespace != "" && len(tenant.Namespaces) > 0 {
+			return fmt.Errorf("tenant %q: namespace and namespaces cannot be combined", tenant.Name)
+		}
+		if tenant.PrometheusURL != "" {
+			if u, err := url.Parse(tenant.PrometheusURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+				return fmt.Errorf("tenant %q: prometheus_url must be an absolute http(s) URL", tenant.Name)
+			}
+		}
+	}
+
+	for name, cluster := range c.Clusters {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("clusters entries need a name")
+		}
+		if cluster.Kubeconfig != "" {
+			if info, err := os.Stat(cluster.Kubeconfig); err != nil {
+				return fmt.Errorf("cluster %q: kubeconfig: %w", name, err)
+			} else if info.IsDir() {
+				return fmt.Errorf("cluster %q: kubeconfig %s is a directory", name, cluster.Kubeconfig)
+			}
+		}
+		if cluster.PrometheusURL != "" {
+			if u, err := url.Parse(cluster.PrometheusURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+				return fmt.Errorf("cluster %q: prometheus_url must be an absolute http(s) URL", name)
+			}
+		}
+		if _, ok := c.Pricing[cluster.Pricing]; cluster.Pricing != "" && !ok {
+			return fmt.Errorf("cluster %q: pricing %q is not defined in pricing", name, cluster.Pricing)
+		}
+		if cluster.GridIntensity < 0 {
+			return fmt.Errorf("cluster %q: grid_intensity cannot be negative", name)
+		}
+	}
+
 	if c.ServerName == "" {
 		return fmt.Errorf("server_name cannot be empty")
 	}
-	
+
 	if c.ServerVersion == "" {
 		return fmt.Errorf("server_version cannot be empty")
 	}
-	
+
+	if !strings.HasPrefix(c.MCPPath, "/") {
+		return fmt.Errorf("mcp_path must start with '/'")
+	}
+
+	if slices.Contains(reservedPaths, c.MCPPath) {
+		return fmt.Errorf("mcp_path cannot be %s, which the server already serves", c.MCPPath)
+	}
+
+	if c.BasePath != "" && (!strings.HasPrefix(c.BasePath, "/") || strings.HasSuffix(c.BasePath, "/")) {
+		return fmt.Errorf("base_path must start with '/' and must not end with '/'")
+	}
+
+	if c.Stateless && c.Transport != TransportHTTP {
+		return fmt.Errorf("stateless requires the %q transport", TransportHTTP)
+	}
+
+	if c.EnableSSE {
+		if !strings.HasPrefix(c.SSEPath, "/") {
+			return fmt.Errorf("sse_path must start with '/'")
+		}
+		if c.SSEPath == c.MCPPath || slices.Contains(reservedPaths, c.SSEPath) {
+			return fmt.Errorf("sse_path cannot be %s, which the server already serves", c.SSEPath)
+		}
+		if c.Transport != TransportHTTP {
+			return fmt.Errorf("enable_sse requires the %q transport", TransportHTTP)
+		}
+		// The SSE transport does not hand request headers to tool calls, so tenants could
+		// not be told apart
+		if len(c.Tenants) > 0 {
+			return fmt.Errorf("enable_sse cannot be combined with tenants")
+		}
+	}
+
+	for _, origin := range c.CORSAllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return fmt.Errorf("cors_allowed_origins: %q is not an origin like https://app.example.com", origin)
+		}
+	}
+
 	// Validate output format
 	if c.DefaultOutputFormat != "json" && c.DefaultOutputFormat != "yaml" && c.DefaultOutputFormat != "table" {
 		return fmt.Errorf("default_output_format must be 'json', 'yaml', or 'table'")
 	}
-	
+
+	if c.CPUCostPerCoreHour < 0 || c.MemoryCostPerGiBHour < 0 {
+		return fmt.Errorf("cpu_cost_per_core_hour and memory_cost_per_gib_hour cannot be negative")
+	}
+
+	if c.CarbonWattsPerVCPU < 0 || c.CarbonWattsPerGiB < 0 || c.CarbonGridIntensity < 0 {
+		return fmt.Errorf("carbon_watts_per_vcpu, carbon_watts_per_gib and carbon_grid_intensity cannot be negative")
+	}
+
+	if c.CarbonPUE < 1 {
+		return fmt.Errorf("carbon_pue must be at least 1")
+	}
+
+	for name, prices := range c.Pricing {
+		if prices.CPUCostPerCoreHour < 0 || prices.MemoryCostPerGiBHour < 0 {
+			return fmt.Errorf("pricing %q: prices cannot be negative", name)
+		}
+		if prices.CPUCostPerCoreHour == 0 && prices.MemoryCostPerGiBHour == 0 {
+			return fmt.Errorf("pricing %q: needs cpu_cost_per_core_hour or memory_cost_per_gib_hour", name)
+		}
+	}
+
+	if c.SeverityUnderCriticalPercent < 0 || c.SeverityUnderWarningPercent < 0 || c.SeverityOverCriticalPercent < 0 || c.SeverityOverWarningPercent < 0 {
+		return fmt.Errorf("severity thresholds cannot be negative")
+	}
+
+	if c.SeverityUnderWarningPercent > c.SeverityUnderCriticalPercent || c.SeverityOverWarningPercent > c.SeverityOverCriticalPercent {
+		return fmt.Errorf("severity warning thresholds cannot exceed the matching critical thresholds")
+	}
+
+	if c.S3Bucket != "" && (c.S3AccessKeyID == "" || c.S3SecretAccessKey == "") {
+		return fmt.Errorf("s3_bucket requires s3_access_key_id and s3_secret_access_key (or AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY)")
+	}
+
+	if c.DefaultNotifySlack && c.SlackWebhookURL == "" {
+		return fmt.Errorf("default_notify_slack requires slack_webhook_url")
+	}
+
 	// Validate log level
 	validLogLevels := map[string]bool{
 		"debug": true,
@@ -150,7 +842,98 @@ func (c *Config) Validate() error {
 	if !validLogLevels[c.LogLevel] {
 		return fmt.Errorf("log_level must be one of: debug, info, warn, error")
 	}
-	
+
+	if c.AccessLogSampleRate < 0 || c.AccessLogSampleRate > 1 {
+		return fmt.Errorf("access_log_sample_rate must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// AuthTokens returns the static API keys: auth_token plus every non-empty line of
+// auth_token_file, so keys can be rotated by listing old and new ones together
+func (c *Config) AuthTokens() ([]string, error) {
+	var tokens []string
+	if token := strings.TrimSpace(c.AuthToken); token != "" {
+		tokens = append(tokens, token)
+	}
+	if c.AuthTokenFile != "" {
+		data, err := os.ReadFile(c.AuthTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth_token_file: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if token := strings.TrimSpace(line); token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// reservedPaths are the HTTP paths the server serves besides the MCP endpoints
+var reservedPaths = []string{"/healthz", "/readyz", "/capabilities", "/metrics"}
+
+// RoutePath returns the path a route is served at, under base_path
+func (c *Config) RoutePath(path string) string {
+	return c.BasePath + path
+}
+
+// unixSocketScheme prefixes a listen_addr that is a Unix socket path
+const unixSocketScheme = "unix://"
+
+// UnixSocketPath returns the socket path of a "unix:///path" listen_addr, and whether
+// listen_addr is one
+func (c *Config) UnixSocketPath() (string, bool) {
+	path, ok := strings.CutPrefix(c.ListenAddr, unixSocketScheme)
+	return path, ok
+}
+
+// DefaultCORSAllowedHeaders returns the request headers browsers may send by default: those of
+// the MCP streamable HTTP protocol and this server's auth and correlation headers
+func DefaultCORSAllowedHeaders() []string {
+	return []string{"Authorization", "Content-Type", "Accept", "Mcp-Session-Id", "Mcp-Protocol-Version", "Last-Event-ID", "X-API-Key", "X-Request-ID"}
+}
+
+// DefaultCORSAllowedMethods returns the methods of the MCP streamable HTTP protocol
+func DefaultCORSAllowedMethods() []string {
+	return []string{"GET", "POST", "DELETE"}
+}
+
+// validateProfile checks the values of scan options from the config file that KRR would
+// otherwise reject mid-scan
+func validateProfile(profile krr.ScanOptions) error {
+	quantities := []struct {
+		field string
+		value string
+		parse func(string) (float64, error)
+	}{
+		{"cpu_min", profile.CPUMin, krr.ParseCPU},
+		{"cpu_max", profile.CPUMax, krr.ParseCPU},
+		{"memory_min", profile.MemoryMin, krr.ParseMemory},
+		{"memory_max", profile.MemoryMax, krr.ParseMemory},
+	}
+	for _, quantity := range quantities {
+		if quantity.value == "" {
+			continue
+		}
+		if _, err := quantity.parse(quantity.value); err != nil {
+			return fmt.Errorf("%s %q is not a valid Kubernetes quantity", quantity.field, quantity.value)
+		}
+	}
+
+	if profile.ClusterLabelValue != "" && profile.PrometheusLabel == "" {
+		return fmt.Errorf("cluster_label_value requires prometheus_label")
+	}
+
+	if profile.HistoryDuration < 0 {
+		return fmt.Errorf("history_duration cannot be negative")
+	}
+
+	if profile.MaxWorkers < 0 {
+		return fmt.Errorf("max_workers cannot be negative")
+	}
+
 	return nil
 }
 
@@ -160,7 +943,7 @@ func GetConfigPath() string {
 	if err != nil {
 		return "./krr-mcp-config.json"
 	}
-	
+
 	return filepath.Join(homeDir, ".config", "krr-mcp", "config.json")
 }
 
@@ -169,30 +952,286 @@ func (c *Config) LoadFromEnvironment() {
 	if krrPath := os.Getenv("KRR_PATH"); krrPath != "" {
 		c.KRRPath = krrPath
 	}
-	
+
+	if userAgent := os.Getenv("KRR_PROMETHEUS_USER_AGENT"); userAgent != "" {
+		c.PrometheusUserAgent = userAgent
+	}
+
+	if caCertFile := os.Getenv("KRR_PROMETHEUS_CA_CERT_FILE"); caCertFile != "" {
+		c.PrometheusCACertFile = caCertFile
+	}
+
+	if kubectlPath := os.Getenv("KUBECTL_PATH"); kubectlPath != "" {
+		c.KubectlPath = kubectlPath
+	}
+
+	if strategyDir := os.Getenv("KRR_STRATEGY_DIR"); strategyDir != "" {
+		c.StrategyDir = strategyDir
+	}
+
+	if pythonPath := os.Getenv("KRR_PYTHON_PATH"); pythonPath != "" {
+		c.PythonPath = pythonPath
+	}
+
+	if kubeconfigData := os.Getenv("KRR_KUBECONFIG_DATA"); kubeconfigData != "" {
+		c.KubeconfigData = kubeconfigData
+	}
+
 	if timeout := os.Getenv("KRR_TIMEOUT"); timeout != "" {
 		if duration, err := time.ParseDuration(timeout); err == nil {
 			c.DefaultTimeout = duration
 		}
 	}
-	
+
+	if maxTimeout := os.Getenv("KRR_MAX_TIMEOUT"); maxTimeout != "" {
+		if duration, err := time.ParseDuration(maxTimeout); err == nil {
+			c.MaxTimeout = duration
+		}
+	}
+
+	httpTimeouts := []struct {
+		env  string
+		dest *time.Duration
+	}{
+		{"KRR_HTTP_READ_TIMEOUT", &c.ReadTimeout},
+		{"KRR_HTTP_WRITE_TIMEOUT", &c.WriteTimeout},
+		{"KRR_HTTP_IDLE_TIMEOUT", &c.IdleTimeout},
+		{"KRR_DRAIN_TIMEOUT", &c.DrainTimeout},
+	}
+	for _, timeout := range httpTimeouts {
+		if value := os.Getenv(timeout.env); value != "" {
+			if duration, err := time.ParseDuration(value); err == nil {
+				*timeout.dest = duration
+			}
+		}
+	}
+
+	if jobDir := os.Getenv("KRR_JOB_DIR"); jobDir != "" {
+		c.JobDir = jobDir
+	}
+
+	if jobRetention := os.Getenv("KRR_JOB_RETENTION"); jobRetention != "" {
+		if duration, err := time.ParseDuration(jobRetention); err == nil {
+			c.JobRetention = duration
+		}
+	}
+
+	if historyDir := os.Getenv("KRR_HISTORY_DIR"); historyDir != "" {
+		c.HistoryDir = historyDir
+	}
+
+	if historyRetention := os.Getenv("KRR_HISTORY_RETENTION"); historyRetention != "" {
+		if duration, err := time.ParseDuration(historyRetention); err == nil {
+			c.HistoryRetention = duration
+		}
+	}
+
+	if compressStored := os.Getenv("KRR_COMPRESS_STORED"); compressStored != "" {
+		if value, err := strconv.ParseBool(compressStored); err == nil {
+			c.CompressStored = value
+		}
+	}
+
+	if enableApply := os.Getenv("KRR_ENABLE_APPLY"); enableApply != "" {
+		if value, err := strconv.ParseBool(enableApply); err == nil {
+			c.EnableApply = value
+		}
+	}
+
+	if scheduleFile := os.Getenv("KRR_SCHEDULE_FILE"); scheduleFile != "" {
+		c.ScheduleFile = scheduleFile
+	}
+
+	if maxSchedules := os.Getenv("KRR_MAX_SCHEDULES"); maxSchedules != "" {
+		if value, err := strconv.Atoi(maxSchedules); err == nil {
+			c.MaxSchedules = value
+		}
+	}
+
+	if maxScans := os.Getenv("KRR_MAX_CONCURRENT_SCANS"); maxScans != "" {
+		if value, err := strconv.Atoi(maxScans); err == nil {
+			c.MaxConcurrentScans = value
+		}
+	}
+
+	if rateLimit := os.Getenv("KRR_CLIENT_RATE_LIMIT"); rateLimit != "" {
+		if value, err := strconv.Atoi(rateLimit); err == nil {
+			c.ClientRateLimit = value
+		}
+	}
+
+	if clientScans := os.Getenv("KRR_CLIENT_MAX_CONCURRENT_SCANS"); clientScans != "" {
+		if value, err := strconv.Atoi(clientScans); err == nil {
+			c.ClientMaxConcurrentScans = value
+		}
+	}
+
+	if maxHistory := os.Getenv("KRR_MAX_HISTORY_DURATION"); maxHistory != "" {
+		if duration, err := time.ParseDuration(maxHistory); err == nil {
+			c.MaxHistoryDuration = duration
+		}
+	}
+
+	if requireNamespace := os.Getenv("KRR_REQUIRE_NAMESPACE"); requireNamespace != "" {
+		if value, err := strconv.ParseBool(requireNamespace); err == nil {
+			c.RequireNamespace = value
+		}
+	}
+
+	if maxRows := os.Getenv("KRR_MAX_OUTPUT_ROWS"); maxRows != "" {
+		if value, err := strconv.Atoi(maxRows); err == nil {
+			c.MaxOutputRows = value
+		}
+	}
+
+	if transport := os.Getenv("KRR_TRANSPORT"); transport != "" {
+		c.Transport = transport
+	}
+
+	if listenAddr := os.Getenv("KRR_LISTEN_ADDR"); listenAddr != "" {
+		c.ListenAddr = listenAddr
+	}
+
+	if origins := os.Getenv("KRR_CORS_ALLOWED_ORIGINS"); origins != "" {
+		c.CORSAllowedOrigins = nil
+		for _, origin := range strings.Split(origins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				c.CORSAllowedOrigins = append(c.CORSAllowedOrigins, origin)
+			}
+		}
+	}
+
+	if certFile := os.Getenv("KRR_TLS_CERT_FILE"); certFile != "" {
+		c.TLSCertFile = certFile
+	}
+
+	if keyFile := os.Getenv("KRR_TLS_KEY_FILE"); keyFile != "" {
+		c.TLSKeyFile = keyFile
+	}
+
+	if clientCAFile := os.Getenv("KRR_TLS_CLIENT_CA_FILE"); clientCAFile != "" {
+		c.TLSClientCAFile = clientCAFile
+	}
+
+	if authToken := os.Getenv("KRR_AUTH_TOKEN"); authToken != "" {
+		c.AuthToken = authToken
+	}
+
+	if authTokenFile := os.Getenv("KRR_AUTH_TOKEN_FILE"); authTokenFile != "" {
+		c.AuthTokenFile = authTokenFile
+	}
+
+	if oidcIssuerURL := os.Getenv("KRR_OIDC_ISSUER_URL"); oidcIssuerURL != "" {
+		c.OIDCIssuerURL = oidcIssuerURL
+	}
+
+	if oidcAudience := os.Getenv("KRR_OIDC_AUDIENCE"); oidcAudience != "" {
+		c.OIDCAudience = oidcAudience
+	}
+
+	if mcpPath := os.Getenv("KRR_MCP_PATH"); mcpPath != "" {
+		c.MCPPath = mcpPath
+	}
+
+	if basePath := os.Getenv("KRR_BASE_PATH"); basePath != "" {
+		c.BasePath = basePath
+	}
+
+	if stateless := os.Getenv("KRR_STATELESS"); stateless != "" {
+		if value, err := strconv.ParseBool(stateless); err == nil {
+			c.Stateless = value
+		}
+	}
+
+	if enableSSE := os.Getenv("KRR_ENABLE_SSE"); enableSSE != "" {
+		if value, err := strconv.ParseBool(enableSSE); err == nil {
+			c.EnableSSE = value
+		}
+	}
+
 	if strategy := os.Getenv("KRR_STRATEGY"); strategy != "" {
 		c.DefaultStrategy = strategy
 	}
-	
+
 	if namespace := os.Getenv("KRR_NAMESPACE"); namespace != "" {
 		c.DefaultNamespace = namespace
 	}
-	
+
 	if outputFormat := os.Getenv("KRR_OUTPUT_FORMAT"); outputFormat != "" {
 		c.DefaultOutputFormat = outputFormat
 	}
-	
+
+	if cpuCost := os.Getenv("KRR_CPU_COST_PER_CORE_HOUR"); cpuCost != "" {
+		if value, err := strconv.ParseFloat(cpuCost, 64); err == nil {
+			c.CPUCostPerCoreHour = value
+		}
+	}
+
+	if memoryCost := os.Getenv("KRR_MEMORY_COST_PER_GIB_HOUR"); memoryCost != "" {
+		if value, err := strconv.ParseFloat(memoryCost, 64); err == nil {
+			c.MemoryCostPerGiBHour = value
+		}
+	}
+
+	if gridIntensity := os.Getenv("KRR_CARBON_GRID_INTENSITY"); gridIntensity != "" {
+		if value, err := strconv.ParseFloat(gridIntensity, 64); err == nil {
+			c.CarbonGridIntensity = value
+		}
+	}
+
+	if artifactDir := os.Getenv("KRR_ARTIFACT_DIR"); artifactDir != "" {
+		c.ArtifactDir = artifactDir
+	}
+
+	if bucket := os.Getenv("KRR_S3_BUCKET"); bucket != "" {
+		c.S3Bucket = bucket
+	}
+
+	if endpoint := os.Getenv("KRR_S3_ENDPOINT"); endpoint != "" {
+		c.S3Endpoint = endpoint
+	}
+
+	if region := os.Getenv("KRR_S3_REGION"); region != "" {
+		c.S3Region = region
+	}
+
+	if prefix := os.Getenv("KRR_S3_PREFIX"); prefix != "" {
+		c.S3Prefix = prefix
+	}
+
+	if accessKey := os.Getenv("AWS_ACCESS_KEY_ID"); accessKey != "" && c.S3AccessKeyID == "" {
+		c.S3AccessKeyID = accessKey
+	}
+
+	if secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY"); secretKey != "" && c.S3SecretAccessKey == "" {
+		c.S3SecretAccessKey = secretKey
+	}
+
+	if webhook := os.Getenv("KRR_SLACK_WEBHOOK_URL"); webhook != "" {
+		c.SlackWebhookURL = webhook
+	}
+
+	if pushgateway := os.Getenv("KRR_PUSHGATEWAY_URL"); pushgateway != "" {
+		c.PushgatewayURL = pushgateway
+	}
+
+	if notifySlack := os.Getenv("KRR_NOTIFY_SLACK"); notifySlack != "" {
+		if value, err := strconv.ParseBool(notifySlack); err == nil {
+			c.DefaultNotifySlack = value
+		}
+	}
+
 	if logLevel := os.Getenv("KRR_LOG_LEVEL"); logLevel != "" {
 		c.LogLevel = logLevel
 	}
-	
+
 	if logFile := os.Getenv("KRR_LOG_FILE"); logFile != "" {
 		c.LogFile = logFile
 	}
-}
\ No newline at end of file
+
+	if accessLog := os.Getenv("KRR_ACCESS_LOG"); accessLog != "" {
+		if value, err := strconv.ParseBool(accessLog); err == nil {
+			c.AccessLog = value
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package krr
+
+import "regexp"
+
+// ansiPattern matches ANSI CSI sequences (colors, cursor movement) and OSC sequences (e.g. hyperlinks)
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)")
+
+// StripANSI removes ANSI escape sequences from text
+func StripANSI(text string) string {
+	return ansiPattern.ReplaceAllString(text, "")
+}
@@ -0,0 +1,52 @@
+package krr
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CacheKey returns a stable string identifying the scan the options describe. Only options
+// that change KRR's results are included; cosmetic ones such as NoColor and Verbose are not,
+// so scans differing only in those share a key. Defaults are normalized the same way
+// buildScanArgs applies them.
+func (o ScanOptions) CacheKey() string {
+	strategy := strings.TrimSpace(o.Strategy)
+	if strategy == "" {
+		strategy = "simple"
+	}
+	output := o.Output
+	if output == "" {
+		output = OutputJSON
+	}
+	resources := slices.Clone(o.Resources)
+	slices.Sort(resources)
+	namespaces := slices.Clone(o.Namespaces)
+	slices.Sort(namespaces)
+
+	values := url.Values{}
+	values.Set("namespace", strings.TrimSpace(o.Namespace))
+	values.Set("context", strings.TrimSpace(o.Context))
+	values.Set("cluster", strings.TrimSpace(o.ClusterName))
+	values.Set("strategy", strategy)
+	values.Set("strategy_path", o.StrategyPath)
+	values.Set("cpu_min", strings.TrimSpace(o.CPUMin))
+	values.Set("cpu_max", strings.TrimSpace(o.CPUMax))
+	values.Set("memory_min", strings.TrimSpace(o.MemoryMin))
+	values.Set("memory_max", strings.TrimSpace(o.MemoryMax))
+	values.Set("history_duration", o.HistoryDuration.String())
+	values.Set("prometheus_url", strings.TrimSpace(o.PrometheusURL))
+	values.Set("prometheus_label", strings.TrimSpace(o.PrometheusLabel))
+	values.Set("cluster_label_value", strings.TrimSpace(o.ClusterLabelValue))
+	values.Set("output", string(output))
+	values.Set("recommend_only", strconv.FormatBool(o.RecommendOnly))
+	values["resource"] = resources
+	if o.Namespace == "" {
+		values["namespaces"] = namespaces
+	}
+	values["extra_flag"] = extraFlagArgs(o.ExtraFlags)
+
+	// Encode sorts by key, which makes the key independent of field order
+	return values.Encode()
+}
@@ -0,0 +1,45 @@
+package krr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	base := ScanOptions{Namespace: "shop", Strategy: "simple", HistoryDuration: 48 * time.Hour}
+	tests := []struct {
+		name     string
+		other    ScanOptions
+		wantSame bool
+	}{
+		{"identical", base, true},
+		{"NoColor", ScanOptions{Namespace: "shop", Strategy: "simple", HistoryDuration: 48 * time.Hour, NoColor: true}, true},
+		{"Verbose", ScanOptions{Namespace: "shop", Strategy: "simple", HistoryDuration: 48 * time.Hour, Verbose: true}, true},
+		{"default strategy", ScanOptions{Namespace: "shop", HistoryDuration: 48 * time.Hour}, true},
+		{"default output", ScanOptions{Namespace: "shop", Strategy: "simple", HistoryDuration: 48 * time.Hour, Output: OutputJSON}, true},
+		{"surrounding spaces", ScanOptions{Namespace: " shop ", Strategy: "simple ", HistoryDuration: 48 * time.Hour}, true},
+		{"namespace", ScanOptions{Namespace: "billing", Strategy: "simple", HistoryDuration: 48 * time.Hour}, false},
+		{"strategy", ScanOptions{Namespace: "shop", Strategy: "simple-limit", HistoryDuration: 48 * time.Hour}, false},
+		{"history", ScanOptions{Namespace: "shop", Strategy: "simple", HistoryDuration: 24 * time.Hour}, false},
+		{"threshold", ScanOptions{Namespace: "shop", Strategy: "simple", HistoryDuration: 48 * time.Hour, CPUMin: "100m"}, false},
+		{"output", ScanOptions{Namespace: "shop", Strategy: "simple", HistoryDuration: 48 * time.Hour, Output: OutputTable}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := base.CacheKey() == tt.other.CacheKey(); same != tt.wantSame {
+				t.Errorf("CacheKey() equal = %v, want %v\n%s\n%s", same, tt.wantSame, base.CacheKey(), tt.other.CacheKey())
+			}
+		})
+	}
+}
+
+func TestCacheKeyIgnoresListOrder(t *testing.T) {
+	a := ScanOptions{Namespaces: []string{"shop", "billing"}, Resources: []string{"Deployment", "StatefulSet"}}
+	b := ScanOptions{Namespaces: []string{"billing", "shop"}, Resources: []string{"StatefulSet", "Deployment"}}
+	if a.CacheKey() != b.CacheKey() {
+		t.Errorf("CacheKey() differs by list order:\n%s\n%s", a.CacheKey(), b.CacheKey())
+	}
+	if c := (ScanOptions{Namespaces: []string{"shop"}}); c.CacheKey() == a.CacheKey() {
+		t.Error("CacheKey() ignores the namespace list")
+	}
+}
@@ -0,0 +1,69 @@
+package krr
+
+import (
+	"fmt"
+	"math"
+)
+
+// CarbonModel holds the coefficients used to turn resource amounts into energy and emissions
+type CarbonModel struct {
+	// Average power drawn per vCPU and per GiB of memory, in watts
+	WattsPerVCPU float64 `json:"watts_per_vcpu"`
+	WattsPerGiB  float64 `json:"watts_per_gib"`
+
+	// PUE is the power usage effectiveness of the data center (total power / IT power)
+	PUE float64 `json:"pue"`
+
+	// GridIntensity is the carbon intensity of the electricity, in gCO2e per kWh
+	GridIntensity float64 `json:"grid_intensity"`
+	HoursPerMonth float64 `json:"hours_per_month"`
+}
+
+// CarbonEstimate is the estimated monthly energy and emissions of requested vs recommended resources
+type CarbonEstimate struct {
+	ReclaimableCPUCores   float64 `json:"reclaimable_cpu_cores"`
+	ReclaimableMemoryGiB  float64 `json:"reclaimable_memory_gib"`
+	CurrentMonthlyKWh     float64 `json:"current_monthly_kwh"`
+	RecommendedMonthlyKWh float64 `json:"recommended_monthly_kwh"`
+	MonthlyKWhSavings     float64 `json:"monthly_kwh_savings"`
+
+	// Emissions in kilograms of CO2 equivalent
+	CurrentMonthlyCO2eKg     float64  `json:"current_monthly_co2e_kg"`
+	RecommendedMonthlyCO2eKg float64  `json:"recommended_monthly_co2e_kg"`
+	MonthlyCO2eSavingsKg     float64  `json:"monthly_co2e_savings_kg"`
+	Assumptions              []string `json:"assumptions"`
+}
+
+// EstimateCarbon computes the monthly energy and emissions of the resources in a summary, and
+// what the reclaimable resources would save
+func EstimateCarbon(summary Summary, model CarbonModel) CarbonEstimate {
+	hours := model.HoursPerMonth
+	if hours <= 0 {
+		hours = HoursPerMonth
+	}
+	kwh := func(cores, bytes float64) float64 {
+		return (cores*model.WattsPerVCPU + bytes/bytesPerGiB*model.WattsPerGiB) * model.PUE * hours / 1000
+	}
+	current := kwh(summary.CurrentCPUCores, summary.CurrentMemoryBytes)
+	recommended := kwh(summary.RecommendedCPUCores, summary.RecommendedMemoryBytes)
+	savings := kwh(summary.ReclaimableCPUCores, summary.ReclaimableMemoryBytes)
+
+	return CarbonEstimate{
+		ReclaimableCPUCores:      round(summary.ReclaimableCPUCores, 3),
+		ReclaimableMemoryGiB:     round(summary.ReclaimableMemoryBytes/bytesPerGiB, 3),
+		CurrentMonthlyKWh:        round(current, 2),
+		RecommendedMonthlyKWh:    round(recommended, 2),
+		MonthlyKWhSavings:        round(savings, 2),
+		CurrentMonthlyCO2eKg:     round(current*model.GridIntensity/1000, 2),
+		RecommendedMonthlyCO2eKg: round(recommended*model.GridIntensity/1000, 2),
+		MonthlyCO2eSavingsKg:     round(savings*model.GridIntensity/1000, 2),
+		Assumptions: []string{
+			fmt.Sprintf("%g W per vCPU and %g W per GiB of memory", model.WattsPerVCPU, model.WattsPerGiB),
+			fmt.Sprintf("PUE of %g", model.PUE),
+			fmt.Sprintf("Grid carbon intensity of %g gCO2e/kWh", model.GridIntensity),
+			fmt.Sprintf("%g hours per month", math.Round(hours)),
+			"Energy is based on resource requests, as if reserved capacity drew average power; embodied emissions are not included",
+			"Savings only count containers that are over-provisioned and have both current and recommended requests",
+		},
+	}
+}
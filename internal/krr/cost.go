@@ -0,0 +1,66 @@
+package krr
+
+import (
+	"fmt"
+	"math"
+)
+
+// HoursPerMonth is the average number of hours in a month (365 * 24 / 12)
+const HoursPerMonth = 730
+
+// bytesPerGiB is the number of bytes in a GiB
+const bytesPerGiB = 1 << 30
+
+// CostModel holds the unit prices used to turn resource amounts into money
+type CostModel struct {
+	CPUCostPerCoreHour   float64 `json:"cpu_cost_per_core_hour"`
+	MemoryCostPerGiBHour float64 `json:"memory_cost_per_gib_hour"`
+	HoursPerMonth        float64 `json:"hours_per_month"`
+}
+
+// Enabled reports whether at least one unit price has been configured
+func (m CostModel) Enabled() bool {
+	return m.CPUCostPerCoreHour > 0 || m.MemoryCostPerGiBHour > 0
+}
+
+// CostEstimate is the estimated monthly cost of requested vs recommended resources
+type CostEstimate struct {
+	ReclaimableCPUCores     float64  `json:"reclaimable_cpu_cores"`
+	ReclaimableMemoryGiB    float64  `json:"reclaimable_memory_gib"`
+	CurrentMonthlyCost      float64  `json:"current_monthly_cost"`
+	RecommendedMonthlyCost  float64  `json:"recommended_monthly_cost"`
+	MonthlyCPUSavings       float64  `json:"monthly_cpu_savings"`
+	MonthlyMemorySavings    float64  `json:"monthly_memory_savings"`
+	EstimatedMonthlySavings float64  `json:"estimated_monthly_savings"`
+	Assumptions             []string `json:"assumptions"`
+}
+
+// EstimateCost computes estimated monthly savings from the reclaimable resources in a summary
+func EstimateCost(summary Summary, model CostModel) CostEstimate {
+	hours := model.HoursPerMonth
+	if hours <= 0 {
+		hours = HoursPerMonth
+	}
+
+	cpuMonthly := model.CPUCostPerCoreHour * hours
+	memoryMonthly := model.MemoryCostPerGiBHour * hours
+
+	estimate := CostEstimate{
+		ReclaimableCPUCores:    round(summary.ReclaimableCPUCores, 3),
+		ReclaimableMemoryGiB:   round(summary.ReclaimableMemoryBytes/bytesPerGiB, 3),
+		CurrentMonthlyCost:     round(summary.CurrentCPUCores*cpuMonthly+summary.CurrentMemoryBytes/bytesPerGiB*memoryMonthly, 2),
+		RecommendedMonthlyCost: round(summary.RecommendedCPUCores*cpuMonthly+summary.RecommendedMemoryBytes/bytesPerGiB*memoryMonthly, 2),
+		MonthlyCPUSavings:      round(summary.ReclaimableCPUCores*cpuMonthly, 2),
+		MonthlyMemorySavings:   round(summary.ReclaimableMemoryBytes/bytesPerGiB*memoryMonthly, 2),
+	}
+	estimate.EstimatedMonthlySavings = round(estimate.MonthlyCPUSavings+estimate.MonthlyMemorySavings, 2)
+	estimate.Assumptions = []string{
+		fmt.Sprintf("CPU priced at %g per core-hour", model.CPUCostPerCoreHour),
+		fmt.Sprintf("Memory priced at %g per GiB-hour", model.MemoryCostPerGiBHour),
+		fmt.Sprintf("%g hours per month", math.Round(hours)),
+		"Costs are based on resource requests, not limits or node pricing",
+		"Savings only count containers that are over-provisioned and have both current and recommended requests",
+	}
+
+	return estimate
+}
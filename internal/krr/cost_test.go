@@ -0,0 +1,94 @@
+package krr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEstimateCost(t *testing.T) {
+	summary := Summary{
+		CurrentCPUCores:        4,
+		RecommendedCPUCores:    2,
+		ReclaimableCPUCores:    2,
+		CurrentMemoryBytes:     8 * bytesPerGiB,
+		RecommendedMemoryBytes: 4 * bytesPerGiB,
+		ReclaimableMemoryBytes: 4 * bytesPerGiB,
+	}
+	tests := []struct {
+		name  string
+		model CostModel
+		want  CostEstimate
+	}{
+		{
+			name:  "default hours per month",
+			model: CostModel{CPUCostPerCoreHour: 0.05, MemoryCostPerGiBHour: 0.01},
+			want: CostEstimate{
+				ReclaimableCPUCores:     2,
+				ReclaimableMemoryGiB:    4,
+				CurrentMonthlyCost:      204.4,
+				RecommendedMonthlyCost:  102.2,
+				MonthlyCPUSavings:       73,
+				MonthlyMemorySavings:    29.2,
+				EstimatedMonthlySavings: 102.2,
+			},
+		},
+		{
+			name:  "custom hours per month",
+			model: CostModel{CPUCostPerCoreHour: 0.05, MemoryCostPerGiBHour: 0.01, HoursPerMonth: 100},
+			want: CostEstimate{
+				ReclaimableCPUCores:     2,
+				ReclaimableMemoryGiB:    4,
+				CurrentMonthlyCost:      28,
+				RecommendedMonthlyCost:  14,
+				MonthlyCPUSavings:       10,
+				MonthlyMemorySavings:    4,
+				EstimatedMonthlySavings: 14,
+			},
+		},
+		{
+			name:  "CPU price only",
+			model: CostModel{CPUCostPerCoreHour: 0.05},
+			want: CostEstimate{
+				ReclaimableCPUCores:     2,
+				ReclaimableMemoryGiB:    4,
+				CurrentMonthlyCost:      146,
+				RecommendedMonthlyCost:  73,
+				MonthlyCPUSavings:       73,
+				EstimatedMonthlySavings: 73,
+			},
+		},
+		{
+			name: "no prices",
+			want: CostEstimate{ReclaimableCPUCores: 2, ReclaimableMemoryGiB: 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateCost(summary, tt.model)
+			if len(got.Assumptions) == 0 {
+				t.Error("EstimateCost() reported no assumptions")
+			}
+			got.Assumptions = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EstimateCost() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCostModelEnabled(t *testing.T) {
+	tests := []struct {
+		model CostModel
+		want  bool
+	}{
+		{CostModel{}, false},
+		{CostModel{HoursPerMonth: 730}, false},
+		{CostModel{CPUCostPerCoreHour: 0.05}, true},
+		{CostModel{MemoryCostPerGiBHour: 0.01}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.model.Enabled(); got != tt.want {
+			t.Errorf("%+v.Enabled() = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+}
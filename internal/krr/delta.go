@@ -0,0 +1,101 @@
+package krr
+
+// Directions of a recommended field change
+const (
+	DeltaIncrease = "increase"
+	DeltaDecrease = "decrease"
+	DeltaSet      = "set"
+)
+
+// FieldChange is a single resource field whose recommendation differs from its current value
+type FieldChange struct {
+	// Field is the container resource field, e.g. "requests.cpu" or "limits.memory"
+	Field       string `json:"field"`
+	Current     string `json:"current,omitempty"`
+	Recommended string `json:"recommended"`
+	Direction   string `json:"direction"`
+}
+
+// ContainerDelta lists the fields KRR wants to change for one container
+type ContainerDelta struct {
+	Namespace string        `json:"namespace"`
+	Kind      string        `json:"kind"`
+	Name      string        `json:"name"`
+	Container string        `json:"container,omitempty"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// Delta is the projection of a scan onto the fields that would change
+type Delta struct {
+	Containers []ContainerDelta `json:"containers"`
+
+	// Unchanged counts the containers whose recommendations all match their current values
+	Unchanged int `json:"unchanged"`
+}
+
+// ComputeDelta returns, per container, only the fields whose recommendation differs from the
+// current value. Quantities are compared numerically, so "1" and "1000m" are equal, and fields
+// without a recommendation are left out.
+func ComputeDelta(result *ScanResult) Delta {
+	delta := Delta{Containers: []ContainerDelta{}}
+	for _, resource := range result.Resources {
+		var changes []FieldChange
+		fields := []struct {
+			name                 string
+			current, recommended string
+			parse                func(string) (float64, error)
+		}{
+			{"requests.cpu", resource.Current.CPU, resource.Recommended.CPU, ParseCPU},
+			{"requests.memory", resource.Current.Memory, resource.Recommended.Memory, ParseMemory},
+			{"limits.cpu", resource.CurrentLimits.CPU, resource.RecommendedLimits.CPU, ParseCPU},
+			{"limits.memory", resource.CurrentLimits.Memory, resource.RecommendedLimits.Memory, ParseMemory},
+		}
+		for _, field := range fields {
+			if change, ok := fieldChange(field.name, field.current, field.recommended, field.parse); ok {
+				changes = append(changes, change)
+			}
+		}
+
+		if len(changes) == 0 {
+			delta.Unchanged++
+			continue
+		}
+		delta.Containers = append(delta.Containers, ContainerDelta{
+			Namespace: resource.Namespace,
+			Kind:      resource.Kind,
+			Name:      resource.Name,
+			Container: resource.Container,
+			Changes:   changes,
+		})
+	}
+	return delta
+}
+
+// fieldChange compares one field, reporting whether the recommendation changes it
+func fieldChange(name, current, recommended string, parse func(string) (float64, error)) (FieldChange, bool) {
+	if recommended == "" {
+		return FieldChange{}, false
+	}
+	want, err := parse(recommended)
+	if err != nil {
+		return FieldChange{}, false
+	}
+
+	change := FieldChange{Field: name, Current: current, Recommended: recommended}
+	if current == "" {
+		change.Direction = DeltaSet
+		return change, true
+	}
+	have, err := parse(current)
+	switch {
+	case err != nil:
+		change.Direction = DeltaSet
+	case want > have:
+		change.Direction = DeltaIncrease
+	case want < have:
+		change.Direction = DeltaDecrease
+	default:
+		return FieldChange{}, false
+	}
+	return change, true
+}
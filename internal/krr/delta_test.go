@@ -0,0 +1,71 @@
+package krr
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestComputeDeltaEmpty(t *testing.T) {
+	tests := []struct {
+		name          string
+		resources     []Resource
+		wantUnchanged int
+	}{
+		{name: "no resources"},
+		{
+			name: "recommendations match current values",
+			resources: []Resource{
+				{Name: "web", Current: ResourceRequirements{CPU: "1", Memory: "1Gi"}, Recommended: ResourceRequirements{CPU: "1000m", Memory: "1024Mi"}},
+				{Name: "api", CurrentLimits: ResourceRequirements{Memory: "512Mi"}, RecommendedLimits: ResourceRequirements{Memory: "512Mi"}},
+			},
+			wantUnchanged: 2,
+		},
+		{
+			name:          "no recommendation",
+			resources:     []Resource{{Name: "batch", Current: ResourceRequirements{CPU: "250m"}}},
+			wantUnchanged: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta := ComputeDelta(&ScanResult{Resources: tt.resources})
+			if len(delta.Containers) != 0 || delta.Unchanged != tt.wantUnchanged {
+				t.Errorf("ComputeDelta() = %+v, want no changes and %d unchanged", delta, tt.wantUnchanged)
+			}
+			// An empty delta is an empty list, not null, so clients can iterate it
+			data, err := json.Marshal(delta)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded map[string]any
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if containers, ok := decoded["containers"].([]any); !ok || len(containers) != 0 {
+				t.Errorf("containers = %s, want []", data)
+			}
+		})
+	}
+}
+
+func TestComputeDeltaDirections(t *testing.T) {
+	resource := Resource{
+		Namespace: "shop", Kind: "Deployment", Name: "web", Container: "app",
+		Current:           ResourceRequirements{CPU: "500m", Memory: "256Mi"},
+		Recommended:       ResourceRequirements{CPU: "100m", Memory: "512Mi"},
+		RecommendedLimits: ResourceRequirements{Memory: "1Gi"},
+	}
+	want := []FieldChange{
+		{Field: "requests.cpu", Current: "500m", Recommended: "100m", Direction: DeltaDecrease},
+		{Field: "requests.memory", Current: "256Mi", Recommended: "512Mi", Direction: DeltaIncrease},
+		{Field: "limits.memory", Recommended: "1Gi", Direction: DeltaSet},
+	}
+	delta := ComputeDelta(&ScanResult{Resources: []Resource{resource}})
+	if len(delta.Containers) != 1 {
+		t.Fatalf("ComputeDelta() = %+v, want one container", delta)
+	}
+	if got := delta.Containers[0].Changes; !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package krr
+
+import "slices"
+
+// RecommendationChange is a container whose recommendation or severity differs between two scans
+type RecommendationChange struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container,omitempty"`
+
+	Before         ResourceRequirements `json:"before"`
+	After          ResourceRequirements `json:"after"`
+	SeverityBefore string               `json:"severity_before,omitempty"`
+	SeverityAfter  string               `json:"severity_after,omitempty"`
+}
+
+// ResultDiff is how the recommendations of a scope changed from one scan to the next
+type ResultDiff struct {
+	// Added and Removed are containers present in only the newer or only the older scan
+	Added   []Resource             `json:"added,omitempty"`
+	Removed []Resource             `json:"removed,omitempty"`
+	Changed []RecommendationChange `json:"changed,omitempty"`
+}
+
+// Empty reports whether the two scans recommended the same thing
+func (d ResultDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffResults compares two scans of the same scope container by container. Recommended
+// requests are compared numerically, so "1" and "1000m" are equal; a changed severity alone
+// also counts as a change.
+func DiffResults(before, after *ScanResult) ResultDiff {
+	previous := make(map[string]Resource, len(before.Resources))
+	for _, resource := range before.Resources {
+		previous[resourceKey(resource)] = resource
+	}
+
+	var diff ResultDiff
+	seen := make(map[string]bool, len(after.Resources))
+	for _, resource := range after.Resources {
+		key := resourceKey(resource)
+		seen[key] = true
+		old, ok := previous[key]
+		if !ok {
+			diff.Added = append(diff.Added, resource)
+			continue
+		}
+		if sameQuantity(old.Recommended.CPU, resource.Recommended.CPU, ParseCPU) &&
+			sameQuantity(old.Recommended.Memory, resource.Recommended.Memory, ParseMemory) &&
+			old.Severity == resource.Severity {
+			continue
+		}
+		diff.Changed = append(diff.Changed, RecommendationChange{
+			Namespace:      resource.Namespace,
+			Kind:           resource.Kind,
+			Name:           resource.Name,
+			Container:      resource.Container,
+			Before:         old.Recommended,
+			After:          resource.Recommended,
+			SeverityBefore: old.Severity,
+			SeverityAfter:  resource.Severity,
+		})
+	}
+
+	// Removed containers keep the older scan's order
+	for _, resource := range before.Resources {
+		if !seen[resourceKey(resource)] {
+			diff.Removed = append(diff.Removed, resource)
+		}
+	}
+	return diff
+}
+
+// resourceKey identifies a container across scans
+func resourceKey(resource Resource) string {
+	return resource.Namespace + "/" + resource.Kind + "/" + resource.Name + "/" + resource.Container
+}
+
+// sameQuantity compares two quantities numerically, falling back to the strings when either
+// does not parse
+func sameQuantity(a, b string, parse func(string) (float64, error)) bool {
+	x, errA := parse(a)
+	y, errB := parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return x == y
+}
+
+// Containers returns the keys of every container the diff mentions, sorted
+func (d ResultDiff) Containers() []string {
+	var keys []string
+	for _, resource := range d.Added {
+		keys = append(keys, resourceKey(resource))
+	}
+	for _, resource := range d.Removed {
+		keys = append(keys, resourceKey(resource))
+	}
+	for _, change := range d.Changed {
+		keys = append(keys, change.Namespace+"/"+change.Kind+"/"+change.Name+"/"+change.Container)
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
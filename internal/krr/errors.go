@@ -0,0 +1,127 @@
+package krr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+)
+
+// ErrorKind classifies why a scan failed, so callers can react without parsing messages
+type ErrorKind string
+
+const (
+	ErrorKindNotInstalled ErrorKind = "krr_not_installed"
+	ErrorKindTimeout      ErrorKind = "timeout"
+	ErrorKindCanceled     ErrorKind = "canceled"
+	ErrorKindPrometheus   ErrorKind = "prometheus_unavailable"
+	ErrorKindRateLimited  ErrorKind = "rate_limited"
+	ErrorKindKubernetes   ErrorKind = "kubernetes_access"
+	ErrorKindFailed       ErrorKind = "krr_failed"
+)
+
+// ErrorKinds returns every error kind ClassifyError can return. It is the fixed label set for
+// anything that counts failures by kind, which keeps label cardinality bounded.
+func ErrorKinds() []ErrorKind {
+	return []ErrorKind{
+		ErrorKindNotInstalled,
+		ErrorKindTimeout,
+		ErrorKindCanceled,
+		ErrorKindPrometheus,
+		ErrorKindRateLimited,
+		ErrorKindKubernetes,
+		ErrorKindFailed,
+	}
+}
+
+// maxErrorStderr is the amount of trailing stderr kept on a ScanError
+const maxErrorStderr = 4096
+
+// ScanError is returned by CLIExecutor.Scan when KRR could not be run or exited with an error
+type ScanError struct {
+	Kind     ErrorKind
+	ExitCode int    // KRR's exit code, or -1 if it did not exit normally
+	Stderr   string // trailing part of KRR's stderr, trimmed
+	Err      error
+}
+
+// Error implements the error interface
+func (e *ScanError) Error() string {
+	if e.Kind == ErrorKindCanceled {
+		return "krr command canceled"
+	}
+	if e.ExitCode >= 0 {
+		return fmt.Sprintf("krr command failed with exit code %d: %s", e.ExitCode, e.Stderr)
+	}
+	return fmt.Sprintf("failed to execute krr command: %v", e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// newScanError classifies a failed KRR execution
+func newScanError(ctx context.Context, err error, stderr string) *ScanError {
+	scanErr := &ScanError{ExitCode: -1, Stderr: trimStderr(stderr), Err: err}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		scanErr.ExitCode = exitErr.ExitCode()
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		scanErr.Kind = ErrorKindTimeout
+	case errors.Is(ctx.Err(), context.Canceled):
+		scanErr.Kind = ErrorKindCanceled
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		scanErr.Kind = ErrorKindNotInstalled
+	default:
+		scanErr.Kind = classifyStderr(stderr)
+	}
+	return scanErr
+}
+
+// classifyStderr guesses the failure kind from KRR's log output
+func classifyStderr(stderr string) ErrorKind {
+	lower := strings.ToLower(stderr)
+	switch {
+	case isRateLimited(lower):
+		// Checked first: managed Prometheus throttling also mentions prometheus
+		return ErrorKindRateLimited
+	case strings.Contains(lower, "prometheus"):
+		return ErrorKindPrometheus
+	case strings.Contains(lower, "unauthorized"), strings.Contains(lower, "forbidden"),
+		strings.Contains(lower, "kubeconfig"), strings.Contains(lower, "context") && strings.Contains(lower, "not found"):
+		return ErrorKindKubernetes
+	default:
+		return ErrorKindFailed
+	}
+}
+
+// trimStderr keeps the end of stderr, where the actual error usually is
+func trimStderr(stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if len(stderr) > maxErrorStderr {
+		stderr = "..." + stderr[len(stderr)-maxErrorStderr:]
+	}
+	return stderr
+}
+
+// ClassifyError returns the kind of a scan error, or ErrorKindFailed for errors that
+// did not come from running KRR
+func ClassifyError(err error) ErrorKind {
+	var scanErr *ScanError
+	if errors.As(err, &scanErr) {
+		return scanErr.Kind
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorKindCanceled
+	}
+	return ErrorKindFailed
+}
@@ -0,0 +1,74 @@
+package krr
+
+import "testing"
+
+func TestClassifyStderr(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   ErrorKind
+	}{
+		{
+			name:   "HTTP 429 from Prometheus",
+			stderr: "ERROR    Prometheus query failed: HTTP 429 Too Many Requests",
+			want:   ErrorKindRateLimited,
+		},
+		{
+			name:   "status line",
+			stderr: "prometheus_api_client.exceptions.PrometheusApiClientException: HTTP/1.1 429",
+			want:   ErrorKindRateLimited,
+		},
+		{
+			name:   "status code field",
+			stderr: "requests.exceptions.HTTPError: status_code=429 for url: http://prometheus:9090/api/v1/query_range",
+			want:   ErrorKindRateLimited,
+		},
+		{
+			name:   "status code with a separator",
+			stderr: "Query failed with status code: 429",
+			want:   ErrorKindRateLimited,
+		},
+		{
+			name:   "reason phrase only",
+			stderr: "Too many requests, please slow down",
+			want:   ErrorKindRateLimited,
+		},
+		{
+			name:   "CPU throttling of a workload",
+			stderr: "WARNING  shop/web is CPU throttled 35% of the time; the Prometheus query for throttling returned no data",
+			want:   ErrorKindPrometheus,
+		},
+		{
+			name:   "throttling without Prometheus",
+			stderr: "container app is being throttled",
+			want:   ErrorKindFailed,
+		},
+		{
+			name:   "429 that is not a status code",
+			stderr: "Calculated recommendations for 429 pods before the scan crashed",
+			want:   ErrorKindFailed,
+		},
+		{
+			name:   "429 in a memory value",
+			stderr: "memory request 429Mi exceeds the limit",
+			want:   ErrorKindFailed,
+		},
+		{
+			name:   "Prometheus unreachable",
+			stderr: "Couldn't connect to Prometheus found under http://prometheus:9090",
+			want:   ErrorKindPrometheus,
+		},
+		{
+			name:   "Kubernetes forbidden",
+			stderr: "pods is forbidden: User \"system:anonymous\" cannot list resource",
+			want:   ErrorKindKubernetes,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyStderr(tt.stderr); got != tt.want {
+				t.Errorf("classifyStderr(%q) = %q, want %q", tt.stderr, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,30 +1,205 @@
 package krr
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
+
+	"greenops-mcp/internal/kube"
 )
 
+// cancelWaitDelay is how long a canceled scan waits for KRR's output pipes to close after the
+// kill before it returns anyway
+const cancelWaitDelay = 5 * time.Second
+
 // CLIExecutor implements the Executor interface using the KRR CLI
 type CLIExecutor struct {
-	krrPath string
-	timeout time.Duration
+	krrPath        string
+	timeout        time.Duration
+	kubeconfigData string
+	kubeconfigPath string
+	severity       *SeverityThresholds
+	pythonPath     string
+	extraArgs      []string
+	caCertFile     string
+}
+
+// ExecutorOption configures optional CLIExecutor behaviour
+type ExecutorOption func(*CLIExecutor)
+
+// WithKubeconfigData makes KRR use inline kubeconfig data (raw or base64 YAML). The data is
+// written to a private temporary file for the duration of each scan and passed via KUBECONFIG.
+func WithKubeconfigData(data string) ExecutorOption {
+	return func(e *CLIExecutor) {
+		e.kubeconfigData = data
+	}
+}
+
+// WithKubeconfigPath makes KRR use the kubeconfig file at path, passed via KUBECONFIG. Inline
+// kubeconfig data takes precedence.
+func WithKubeconfigPath(path string) ExecutorOption {
+	return func(e *CLIExecutor) {
+		e.kubeconfigPath = path
+	}
+}
+
+// WithSeverityThresholds classifies the severity of parsed recommendations with the given
+// thresholds instead of keeping the severity reported by KRR
+func WithSeverityThresholds(thresholds SeverityThresholds) ExecutorOption {
+	return func(e *CLIExecutor) {
+		e.severity = &thresholds
+	}
+}
+
+// WithPythonPath sets the Python interpreter that runs custom strategy files (default python3)
+func WithPythonPath(path string) ExecutorOption {
+	return func(e *CLIExecutor) {
+		e.pythonPath = path
+	}
+}
+
+// WithExtraArgs appends arguments verbatim to every scan, after the generated flags. They are
+// not validated; this is an escape hatch for KRR builds with nonstandard flags.
+func WithExtraArgs(args []string) ExecutorOption {
+	return func(e *CLIExecutor) {
+		e.extraArgs = args
+	}
+}
+
+// WithPrometheusCACertFile makes KRR trust the CA certificates in a PEM bundle for its HTTPS
+// Prometheus connections. KRR has no flag for this; the bundle is passed through the
+// environment variables its Python HTTP client honours, and replaces the default trust store.
+func WithPrometheusCACertFile(path string) ExecutorOption {
+	return func(e *CLIExecutor) {
+		e.caCertFile = path
+	}
 }
 
 // NewCLIExecutor creates a new CLI executor with the specified KRR path and timeout
-func NewCLIExecutor(krrPath string, timeout time.Duration) Executor {
-	return &CLIExecutor{
-		krrPath: krrPath,
-		timeout: timeout,
+func NewCLIExecutor(krrPath string, timeout time.Duration, opts ...ExecutorOption) Executor {
+	executor := &CLIExecutor{
+		krrPath:    krrPath,
+		timeout:    timeout,
+		pythonPath: "python3",
 	}
+	for _, opt := range opts {
+		opt(executor)
+	}
+	return executor
 }
 
 // Scan executes a KRR scan with the provided options
 func (e *CLIExecutor) Scan(ctx context.Context, options ScanOptions) (*ScanResult, error) {
+	args := e.buildArgs(options)
+
+	// Execute the command with timeout context, unless the caller already set a deadline
+	timeoutCtx := ctx
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline && e.timeout > 0 {
+		var cancel context.CancelFunc
+		timeoutCtx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	// A custom strategy is a Python file that registers the strategy and runs KRR itself
+	command := e.krrPath
+	if options.StrategyPath != "" {
+		if info, err := os.Stat(options.StrategyPath); err != nil {
+			return nil, fmt.Errorf("custom strategy file %s: %w", options.StrategyPath, err)
+		} else if info.IsDir() {
+			return nil, fmt.Errorf("custom strategy file %s is a directory", options.StrategyPath)
+		}
+		command = e.pythonPath
+		args = append([]string{options.StrategyPath}, args...)
+	}
+
+	// Capture stderr separately so it can be surfaced on failure or in verbose mode
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(timeoutCtx, command, args...)
+	cmd.Stderr = &stderr
+	killProcessGroupOnCancel(cmd)
+	cmd.WaitDelay = cancelWaitDelay
+	if hasProgress(ctx) {
+		cmd.Stderr = io.MultiWriter(&stderr, &stageWriter{ctx: ctx})
+	}
+	var env []string
+	if e.kubeconfigData != "" {
+		path, cleanup, err := kube.MaterializeKubeconfig(e.kubeconfigData)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		env = append(env, "KUBECONFIG="+path)
+	} else if e.kubeconfigPath != "" {
+		env = append(env, "KUBECONFIG="+e.kubeconfigPath)
+	}
+	if e.caCertFile != "" {
+		env = append(env, "REQUESTS_CA_BUNDLE="+e.caCertFile, "SSL_CERT_FILE="+e.caCertFile)
+	}
+	if options.NoColor {
+		// KRR renders through Rich, which honours NO_COLOR
+		env = append(env, "NO_COLOR=1")
+	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	ReportProgress(ctx, StageDiscovering)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, newScanError(timeoutCtx, err, stderr.String())
+	}
+
+	// Parse the output based on format
+	result := &ScanResult{
+		Timestamp: time.Now().Format(time.RFC3339),
+		Cluster:   options.ClusterName,
+		RawOutput: string(output),
+	}
+	if options.Verbose {
+		result.VerboseOutput = stderr.String()
+	}
+	result.Prometheus = ParsePrometheusDiscovery(stderr.String() + "\n" + string(output))
+	if options.NoColor {
+		// Strip any escape codes KRR emitted anyway, so cached and saved output stays plain
+		result.RawOutput = StripANSI(result.RawOutput)
+		result.VerboseOutput = StripANSI(result.VerboseOutput)
+	}
+
+	// Try to parse JSON output if format is JSON; a failure keeps the raw output and is reported
+	// on the result instead of failing the scan
+	if options.Output == OutputJSON || options.Output == "" {
+		if parsed, err := ParseJSON(output); err != nil {
+			result.ParseError = err.Error()
+		} else {
+			result.SchemaVersion = parsed.SchemaVersion
+			result.Strategy = parsed.Strategy
+			result.Resources = parsed.Resources
+			result.Summary = parsed.Summary
+			if e.severity != nil {
+				classified := ClassifySeverity(result, *e.severity)
+				result.Resources = classified.Resources
+				result.Summary = classified.Summary
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// buildArgs is the full KRR argv for a scan: the generated flags, then the configured extra
+// arguments, so they can override or extend anything generated
+func (e *CLIExecutor) buildArgs(options ScanOptions) []string {
+	return append(buildScanArgs(options), e.extraArgs...)
+}
+
+// buildScanArgs converts scan options into KRR CLI arguments
+func buildScanArgs(options ScanOptions) []string {
 	// Set the base strategy command
 	strategy := "simple"
 	if options.Strategy != "" {
@@ -32,9 +207,13 @@ func (e *CLIExecutor) Scan(ctx context.Context, options ScanOptions) (*ScanResul
 	}
 	args := []string{strategy}
 
-	// Add namespace if specified
+	// Add namespace if specified; KRR accepts --namespace once per namespace
 	if options.Namespace != "" {
 		args = append(args, "--namespace", options.Namespace)
+	} else {
+		for _, namespace := range options.Namespaces {
+			args = append(args, "--namespace", namespace)
+		}
 	}
 
 	// Add context if specified
@@ -47,6 +226,39 @@ func (e *CLIExecutor) Scan(ctx context.Context, options ScanOptions) (*ScanResul
 		args = append(args, "--context", options.ClusterName)
 	}
 
+	// Restrict the scan to specific workload kinds
+	for _, resource := range options.Resources {
+		args = append(args, "--resource", resource)
+	}
+
+	// KRR takes the history window in hours
+	if options.HistoryDuration > 0 {
+		args = append(args, "--history_duration", strconv.FormatFloat(options.HistoryDuration.Hours(), 'f', -1, 64))
+	}
+
+	if options.PrometheusURL != "" {
+		args = append(args, "--prometheus-url", options.PrometheusURL)
+	}
+
+	// Scope a centralized Prometheus to one cluster's metrics; KRR calls the label name
+	// --prometheus-label and its value --prometheus-cluster-label
+	if options.PrometheusLabel != "" {
+		args = append(args, "--prometheus-label", options.PrometheusLabel)
+		if options.ClusterLabelValue != "" {
+			args = append(args, "--prometheus-cluster-label", options.ClusterLabelValue)
+		}
+	}
+
+	// KRR adds --prometheus-headers to every Prometheus request, which overrides its User-Agent
+	if options.PrometheusUserAgent != "" {
+		args = append(args, "--prometheus-headers", "User-Agent: "+options.PrometheusUserAgent)
+	}
+
+	// KRR parallelizes its requests with an async worker pool
+	if options.MaxWorkers > 0 {
+		args = append(args, "--max_workers", strconv.Itoa(options.MaxWorkers))
+	}
+
 	// Add CPU limits if specified
 	if options.CPUMin != "" {
 		args = append(args, "--cpu-min", options.CPUMin)
@@ -65,6 +277,9 @@ func (e *CLIExecutor) Scan(ctx context.Context, options ScanOptions) (*ScanResul
 		args = append(args, "--mem-min", options.MemoryMax)
 	}
 
+	// Allow-listed extra flags come after the generated ones
+	args = append(args, extraFlagArgs(options.ExtraFlags)...)
+
 	// Add output format (using correct flag name)
 	if options.Output != "" {
 		args = append(args, "--formatter", string(options.Output))
@@ -73,48 +288,14 @@ func (e *CLIExecutor) Scan(ctx context.Context, options ScanOptions) (*ScanResul
 		args = append(args, "--formatter", "json")
 	}
 
-	// Add verbose flag
+	// Verbose mode replaces quiet mode; KRR's debug logs go to stderr
 	if options.Verbose {
 		args = append(args, "--verbose")
+	} else {
+		args = append(args, "--quiet")
 	}
 
-	// Always add quiet mode by default
-	args = append(args, "--quiet")
-
-	// Execute the command with timeout context
-	timeoutCtx := ctx
-	if e.timeout > 0 {
-		var cancel context.CancelFunc
-		timeoutCtx, cancel = context.WithTimeout(ctx, e.timeout)
-		defer cancel()
-	}
-
-	cmd := exec.CommandContext(timeoutCtx, e.krrPath, args...)
-	output, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("krr command failed with exit code %d: %s", exitErr.ExitCode(), string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("failed to execute krr command: %w", err)
-	}
-
-	// Parse the output based on format
-	result := &ScanResult{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Cluster:   options.ClusterName,
-		RawOutput: string(output),
-	}
-
-	// Try to parse JSON output if format is JSON
-	if options.Output == OutputJSON || options.Output == "" {
-		var resources []Resource
-		if err := json.Unmarshal(output, &resources); err == nil {
-			result.Resources = resources
-			result.Summary = calculateSummary(resources)
-		}
-	}
-
-	return result, nil
+	return args
 }
 
 // ValidateInstallation checks if KRR CLI is properly installed and accessible
@@ -146,7 +327,7 @@ func (e *CLIExecutor) ListStrategies(ctx context.Context) ([]string, error) {
 	}
 
 	// Return the actual strategies available in KRR CLI
-	strategies := []string{"simple", "simple-limit"}
+	strategies := BuiltinStrategies
 
 	// Verify the strategies exist in the help output
 	helpText := string(output)
@@ -158,6 +339,41 @@ func (e *CLIExecutor) ListStrategies(ctx context.Context) ([]string, error) {
 	return []string{"simple"}, nil
 }
 
+// DescribeStrategies reads the strategies from `krr --help` and the settings of each from
+// `krr <strategy> --help`. Help is rendered without colors and wide enough that Rich rarely
+// wraps it. When the command list cannot be parsed, the builtin strategies are described.
+func (e *CLIExecutor) DescribeStrategies(ctx context.Context) ([]Strategy, error) {
+	help := func(args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, e.krrPath, args...)
+		cmd.Env = append(os.Environ(), "NO_COLOR=1", "COLUMNS=200", "TERM=dumb")
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to get krr %s: %w", strings.Join(args, " "), err)
+		}
+		return string(output), nil
+	}
+
+	output, err := help("--help")
+	if err != nil {
+		return nil, err
+	}
+	strategies := ParseStrategyCommands(output)
+	if len(strategies) == 0 {
+		for _, name := range BuiltinStrategies {
+			strategies = append(strategies, Strategy{Name: name})
+		}
+	}
+
+	for i := range strategies {
+		output, err := help(strategies[i].Name, "--help")
+		if err != nil {
+			return nil, err
+		}
+		strategies[i].Settings = ParseStrategySettings(output)
+	}
+	return strategies, nil
+}
+
 // calculateSummary generates a summary from the scan results
 func calculateSummary(resources []Resource) Summary {
 	summary := Summary{
@@ -172,6 +388,10 @@ func calculateSummary(resources []Resource) Summary {
 		switch strings.ToLower(resource.Severity) {
 		case "critical":
 			summary.CriticalSeverity++
+		case "warning":
+			summary.WarningSeverity++
+		case "ok", "good":
+			summary.OKSeverity++
 		case "high":
 			summary.HighSeverity++
 		case "medium":
@@ -179,7 +399,53 @@ func calculateSummary(resources []Resource) Summary {
 		case "low":
 			summary.LowSeverity++
 		}
+
+		if current, recommended, ok := quantityPair(resource.Current.CPU, resource.Recommended.CPU, ParseCPU); ok {
+			summary.CurrentCPUCores += current
+			summary.RecommendedCPUCores += recommended
+			summary.ReclaimableCPUCores += math.Max(0, current-recommended)
+		}
+		if current, recommended, ok := quantityPair(resource.Current.Memory, resource.Recommended.Memory, ParseMemory); ok {
+			summary.CurrentMemoryBytes += current
+			summary.RecommendedMemoryBytes += recommended
+			summary.ReclaimableMemoryBytes += math.Max(0, current-recommended)
+		}
 	}
 
+	if summary.CurrentCPUCores > 0 {
+		summary.CPUUtilization = round(summary.RecommendedCPUCores/summary.CurrentCPUCores, 3)
+	}
+	if summary.CurrentMemoryBytes > 0 {
+		summary.MemoryUtilization = round(summary.RecommendedMemoryBytes/summary.CurrentMemoryBytes, 3)
+	}
+	summary.CurrentCPUCores = round(summary.CurrentCPUCores, 3)
+	summary.RecommendedCPUCores = round(summary.RecommendedCPUCores, 3)
+	summary.ReclaimableCPUCores = round(summary.ReclaimableCPUCores, 3)
+	summary.CurrentMemoryBytes = math.Round(summary.CurrentMemoryBytes)
+	summary.RecommendedMemoryBytes = math.Round(summary.RecommendedMemoryBytes)
+	summary.ReclaimableMemoryBytes = math.Round(summary.ReclaimableMemoryBytes)
+
 	return summary
 }
+
+// quantityPair parses a current/recommended pair, reporting false unless both are known
+func quantityPair(current, recommended string, parse func(string) (float64, error)) (float64, float64, bool) {
+	if current == "" || recommended == "" {
+		return 0, 0, false
+	}
+	currentValue, err := parse(current)
+	if err != nil {
+		return 0, 0, false
+	}
+	recommendedValue, err := parse(recommended)
+	if err != nil {
+		return 0, 0, false
+	}
+	return currentValue, recommendedValue, true
+}
+
+// round rounds a value to the given number of decimal places
+func round(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
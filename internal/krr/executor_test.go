@@ -0,0 +1,306 @@
+package krr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeKRR writes a shell script standing in for the KRR binary and returns its path
+func fakeKRR(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake KRR binary is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "krr")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// kubeconfigFiles lists the materialized kubeconfig files left in dir
+func kubeconfigFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "greenops-kubeconfig-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestScanKubeconfigDataIsPrivatePerScan(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	// Each scan prints the kubeconfig it was given, after a pause that lets the others overlap
+	krrPath := fakeKRR(t, `sleep 0.1; cat "$KUBECONFIG"`)
+
+	const scans = 8
+	var wg sync.WaitGroup
+	outputs := make([]string, scans)
+	errs := make([]error, scans)
+	for i := range scans {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data := fmt.Sprintf("apiVersion: v1\nkind: Config\ncurrent-context: cluster-%d\n", i)
+			executor := NewCLIExecutor(krrPath, 0, WithKubeconfigData(data))
+			result, err := executor.Scan(context.Background(), ScanOptions{Output: OutputTable})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			outputs[i] = result.RawOutput
+		}()
+	}
+	wg.Wait()
+
+	for i := range scans {
+		if errs[i] != nil {
+			t.Errorf("scan %d: Scan() error = %v", i, errs[i])
+			continue
+		}
+		if want := fmt.Sprintf("current-context: cluster-%d\n", i); !strings.Contains(outputs[i], want) {
+			t.Errorf("scan %d read kubeconfig %q, want its own cluster-%d", i, outputs[i], i)
+		}
+	}
+	if files := kubeconfigFiles(t, tmp); len(files) > 0 {
+		t.Errorf("kubeconfig files left behind: %v", files)
+	}
+}
+
+func TestScanKubeconfigDataIsRemovedOnError(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	// The script reports the file's mode before failing, so the test sees it while it exists
+	krrPath := fakeKRR(t, `stat -c %a "$KUBECONFIG" >&2; exit 3`)
+
+	executor := NewCLIExecutor(krrPath, 0, WithKubeconfigData("apiVersion: v1\nkind: Config\n"))
+	_, err := executor.Scan(context.Background(), ScanOptions{Output: OutputTable})
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("Scan() error = %v, want a *ScanError", err)
+	}
+	if scanErr.ExitCode != 3 {
+		t.Errorf("exit code = %d, want 3", scanErr.ExitCode)
+	}
+	if mode := strings.TrimSpace(scanErr.Stderr); mode != "600" {
+		t.Errorf("kubeconfig mode = %s, want 600", mode)
+	}
+	if files := kubeconfigFiles(t, tmp); len(files) > 0 {
+		t.Errorf("kubeconfig files left behind after a failed scan: %v", files)
+	}
+}
+
+func TestBuildArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		extraArgs []string
+		options   ScanOptions
+		want      []string
+	}{
+		{
+			name: "defaults",
+			want: []string{"simple", "--formatter", "json", "--quiet"},
+		},
+		{
+			name:      "extra args after the generated flags",
+			extraArgs: []string{"--fork-flag", "value"},
+			options:   ScanOptions{Strategy: "simple_limit", Namespace: "shop", Verbose: true},
+			want:      []string{"simple_limit", "--namespace", "shop", "--formatter", "json", "--verbose", "--fork-flag", "value"},
+		},
+		{
+			name:      "extra args after allow-listed extra flags",
+			extraArgs: []string{"--formatter", "yaml"},
+			options:   ScanOptions{Output: OutputTable, ExtraFlags: map[string]string{"--use_oomkill_data": ""}},
+			want:      []string{"simple", "--use_oomkill_data", "--formatter", "table", "--quiet", "--formatter", "yaml"},
+		},
+		{
+			name:      "extra args are passed verbatim",
+			extraArgs: []string{"--label=a b", ""},
+			options:   ScanOptions{MaxWorkers: 4},
+			want:      []string{"simple", "--max_workers", "4", "--formatter", "json", "--quiet", "--label=a b", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewCLIExecutor("krr", 0, WithExtraArgs(tt.extraArgs)).(*CLIExecutor)
+			if got := executor.buildArgs(tt.options); !slices.Equal(got, tt.want) {
+				t.Errorf("buildArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanVerbose(t *testing.T) {
+	// The fake KRR echoes its arguments and logs to stderr
+	krrPath := fakeKRR(t, `echo "$@"; echo "debug: querying prometheus" >&2`)
+	executor := NewCLIExecutor(krrPath, 0)
+
+	for _, verbose := range []bool{true, false} {
+		t.Run(fmt.Sprintf("verbose=%v", verbose), func(t *testing.T) {
+			result, err := executor.Scan(context.Background(), ScanOptions{Output: OutputTable, Verbose: verbose})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			args := strings.Fields(result.RawOutput)
+			if got := slices.Contains(args, "--verbose"); got != verbose {
+				t.Errorf("--verbose passed = %v, want %v (args %q)", got, verbose, args)
+			}
+			if got := slices.Contains(args, "--quiet"); got == verbose {
+				t.Errorf("--quiet passed = %v, want %v (args %q)", got, !verbose, args)
+			}
+			if got := strings.Contains(result.VerboseOutput, "querying prometheus"); got != verbose {
+				t.Errorf("VerboseOutput = %q, want stderr captured only in verbose mode", result.VerboseOutput)
+			}
+		})
+	}
+}
+
+func TestScanNoColor(t *testing.T) {
+	// The fake KRR colors its output regardless, and reports whether NO_COLOR was set
+	krrPath := fakeKRR(t, `printf '\033[1;32mshop\033[0m NO_COLOR=%s\n' "$NO_COLOR"`)
+	executor := NewCLIExecutor(krrPath, 0)
+
+	tests := []struct {
+		noColor bool
+		want    string
+	}{
+		{noColor: true, want: "shop NO_COLOR=1\n"},
+		{noColor: false, want: "\x1b[1;32mshop\x1b[0m NO_COLOR=\n"},
+	}
+	for _, tt := range tests {
+		t.Setenv("NO_COLOR", "")
+		result, err := executor.Scan(context.Background(), ScanOptions{Output: OutputTable, NoColor: tt.noColor})
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if result.RawOutput != tt.want {
+			t.Errorf("Scan(NoColor %v) output = %q, want %q", tt.noColor, result.RawOutput, tt.want)
+		}
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"\x1b[1;31mCRITICAL\x1b[0m", "CRITICAL"},
+		{"\x1b[2K\x1b[1Gscanning", "scanning"},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"plain │ table", "plain │ table"},
+	}
+	for _, tt := range tests {
+		if got := StripANSI(tt.text); got != tt.want {
+			t.Errorf("StripANSI(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBuildScanArgsClusterLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ScanOptions
+		want    []string
+	}{
+		{
+			name:    "label and value",
+			options: ScanOptions{PrometheusLabel: "cluster", ClusterLabelValue: "prod-eu"},
+			want:    []string{"--prometheus-label", "cluster", "--prometheus-cluster-label", "prod-eu"},
+		},
+		{
+			name:    "label only",
+			options: ScanOptions{PrometheusLabel: "cluster"},
+			want:    []string{"--prometheus-label", "cluster"},
+		},
+		{
+			name:    "value without a label",
+			options: ScanOptions{ClusterLabelValue: "prod-eu"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := buildScanArgs(tt.options)
+			// The flags sit between the strategy and the output flags
+			got := args[1 : len(args)-3]
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("buildScanArgs() = %q, want the label flags %q", args, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanMalformedJSONKeepsRawOutput(t *testing.T) {
+	krrPath := fakeKRR(t, `echo '{"scans": [{"object": '`)
+	executor := NewCLIExecutor(krrPath, 0)
+
+	result, err := executor.Scan(context.Background(), ScanOptions{Output: OutputJSON})
+	if err != nil {
+		t.Fatalf("Scan() error = %v, want the raw output instead", err)
+	}
+	if result.ParseError == "" {
+		t.Error("ParseError is empty for malformed JSON")
+	}
+	if want := "{\"scans\": [{\"object\": \n"; result.RawOutput != want {
+		t.Errorf("RawOutput = %q, want %q", result.RawOutput, want)
+	}
+	if len(result.Resources) != 0 {
+		t.Errorf("Resources = %+v, want none", result.Resources)
+	}
+}
+
+func TestScanPrometheusCACertEnv(t *testing.T) {
+	t.Setenv("REQUESTS_CA_BUNDLE", "")
+	t.Setenv("SSL_CERT_FILE", "")
+	krrPath := fakeKRR(t, `echo "REQUESTS_CA_BUNDLE=$REQUESTS_CA_BUNDLE SSL_CERT_FILE=$SSL_CERT_FILE"; echo "$@"`)
+
+	tests := []struct {
+		name string
+		opts []ExecutorOption
+		want string
+	}{
+		{name: "CA bundle", opts: []ExecutorOption{WithPrometheusCACertFile("/etc/greenops/ca.pem")}, want: "REQUESTS_CA_BUNDLE=/etc/greenops/ca.pem SSL_CERT_FILE=/etc/greenops/ca.pem"},
+		{name: "system trust store", want: "REQUESTS_CA_BUNDLE= SSL_CERT_FILE="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewCLIExecutor(krrPath, 0, tt.opts...).Scan(context.Background(), ScanOptions{Output: OutputTable})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			env, args, _ := strings.Cut(result.RawOutput, "\n")
+			if env != tt.want {
+				t.Errorf("environment = %q, want %q", env, tt.want)
+			}
+			// The bundle only travels through the environment; KRR has no flag for it
+			if strings.Contains(args, "ca.pem") {
+				t.Errorf("arguments = %q, want the CA bundle kept out of argv", args)
+			}
+		})
+	}
+}
+
+func TestBuildScanArgsMaxWorkers(t *testing.T) {
+	tests := []struct {
+		workers int
+		want    []string
+	}{
+		{workers: 8, want: []string{"--max_workers", "8"}},
+		{workers: 1, want: []string{"--max_workers", "1"}},
+		{workers: 0},
+	}
+	for _, tt := range tests {
+		args := buildScanArgs(ScanOptions{MaxWorkers: tt.workers})
+		if got := args[1 : len(args)-3]; !slices.Equal(got, tt.want) {
+			t.Errorf("buildScanArgs(MaxWorkers %d) = %q, want the worker flags %q", tt.workers, args, tt.want)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package krr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Setting returns a numeric strategy setting (e.g. "history_duration") if KRR reported it
+func (s StrategyInfo) Setting(name string) (float64, bool) {
+	value, ok := s.Settings[name]
+	if !ok {
+		return 0, false
+	}
+	number, ok := value.(float64)
+	return number, ok
+}
+
+// HistoryDuration returns the history window used by the strategy, or zero if unknown
+func (s StrategyInfo) HistoryDuration() time.Duration {
+	hours, ok := s.Setting("history_duration")
+	if !ok {
+		return 0
+	}
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// FindResources returns the resources matching the given workload. Empty kind or
+// container match any value; name and namespace comparisons are exact.
+func FindResources(resources []Resource, kind, name, namespace, container string) []Resource {
+	var matches []Resource
+	for _, resource := range resources {
+		if resource.Name != name || resource.Namespace != namespace {
+			continue
+		}
+		if kind != "" && !strings.EqualFold(resource.Kind, kind) {
+			continue
+		}
+		if container != "" && resource.Container != container {
+			continue
+		}
+		matches = append(matches, resource)
+	}
+	return matches
+}
+
+// Explain returns a human-readable rationale for a single recommendation
+func Explain(resource Resource, strategy StrategyInfo) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s %s/%s", resource.Kind, resource.Namespace, resource.Name)
+	if resource.Container != "" {
+		fmt.Fprintf(&b, " (container %s)", resource.Container)
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(describeStrategy(strategy))
+	b.WriteString("\n")
+	if resource.Severity != "" {
+		fmt.Fprintf(&b, "Severity: %s\n", resource.Severity)
+	}
+	b.WriteString("\n")
+
+	b.WriteString(explainCPU(resource, strategy))
+	b.WriteString("\n")
+	b.WriteString(explainMemory(resource, strategy))
+
+	if limits := explainLimits(resource); limits != "" {
+		b.WriteString("\n")
+		b.WriteString(limits)
+	}
+
+	if resource.Reason != "" {
+		fmt.Fprintf(&b, "\nNotes from KRR: %s\n", resource.Reason)
+	}
+
+	return b.String()
+}
+
+// describeStrategy summarizes the strategy and the history window it looked at
+func describeStrategy(strategy StrategyInfo) string {
+	name := strategy.Name
+	if name == "" {
+		name = "unknown"
+	}
+
+	var details []string
+	if window := strategy.HistoryDuration(); window > 0 {
+		details = append(details, fmt.Sprintf("history window %s", formatWindow(window)))
+	}
+	if percentile, ok := strategy.Setting("cpu_percentile"); ok {
+		details = append(details, fmt.Sprintf("CPU percentile p%g", percentile))
+	}
+	if buffer, ok := strategy.Setting("memory_buffer_percentage"); ok {
+		details = append(details, fmt.Sprintf("memory buffer %g%%", buffer))
+	}
+
+	if len(details) == 0 {
+		return fmt.Sprintf("Strategy: %s", name)
+	}
+	return fmt.Sprintf("Strategy: %s (%s)", name, strings.Join(details, ", "))
+}
+
+// explainCPU explains the CPU request recommendation
+func explainCPU(resource Resource, strategy StrategyInfo) string {
+	current, recommended := resource.Current.CPU, resource.Recommended.CPU
+	if recommended == "" {
+		return "CPU request: KRR could not compute a recommendation (usually not enough usage history in Prometheus).\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CPU request: current %s -> recommended %s%s\n", displayQuantity(current), recommended, changeSuffix(current, recommended, ParseCPU))
+	percentile, ok := strategy.Setting("cpu_percentile")
+	if !ok {
+		percentile = 95
+	}
+	fmt.Fprintf(&b, "  The p%g of observed CPU usage%s is about %s, which is what KRR suggests reserving.\n", percentile, windowSuffix(strategy), recommended)
+	b.WriteString(implication(current, recommended, ParseCPU, "CPU"))
+	return b.String()
+}
+
+// explainMemory explains the memory request recommendation
+func explainMemory(resource Resource, strategy StrategyInfo) string {
+	current, recommended := resource.Current.Memory, resource.Recommended.Memory
+	if recommended == "" {
+		return "Memory request: KRR could not compute a recommendation (usually not enough usage history in Prometheus).\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Memory request: current %s -> recommended %s%s\n", displayQuantity(current), recommended, changeSuffix(current, recommended, ParseMemory))
+	if buffer, ok := strategy.Setting("memory_buffer_percentage"); ok {
+		if bytes, err := ParseMemory(recommended); err == nil {
+			peak := bytes / (1 + buffer/100)
+			fmt.Fprintf(&b, "  Peak memory usage%s was about %s; KRR adds a %g%% buffer on top of the peak.\n", windowSuffix(strategy), FormatMemory(peak), buffer)
+		}
+	} else {
+		fmt.Fprintf(&b, "  The recommendation is derived from peak memory usage%s plus a safety buffer.\n", windowSuffix(strategy))
+	}
+	b.WriteString(implication(current, recommended, ParseMemory, "memory"))
+	return b.String()
+}
+
+// explainLimits describes limit changes, if KRR recommended any
+func explainLimits(resource Resource) string {
+	var lines []string
+	if resource.RecommendedLimits.CPU != "" || resource.CurrentLimits.CPU != "" {
+		lines = append(lines, fmt.Sprintf("CPU limit: current %s -> recommended %s", displayQuantity(resource.CurrentLimits.CPU), displayQuantity(resource.RecommendedLimits.CPU)))
+	}
+	if resource.RecommendedLimits.Memory != "" || resource.CurrentLimits.Memory != "" {
+		lines = append(lines, fmt.Sprintf("Memory limit: current %s -> recommended %s", displayQuantity(resource.CurrentLimits.Memory), displayQuantity(resource.RecommendedLimits.Memory)))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// implication explains what the gap between current and recommended means in practice
+func implication(current, recommended string, parse func(string) (float64, error), name string) string {
+	if current == "" {
+		return fmt.Sprintf("  No %s request is set, so the scheduler cannot reserve capacity for this container; setting one improves bin-packing and protects it from noisy neighbours.\n", name)
+	}
+
+	currentValue, err := parse(current)
+	if err != nil {
+		return ""
+	}
+	recommendedValue, err := parse(recommended)
+	if err != nil || recommendedValue == 0 {
+		return ""
+	}
+
+	ratio := currentValue / recommendedValue
+	switch {
+	case ratio >= 2:
+		return fmt.Sprintf("  The workload reserves about %.1fx the %s it actually needs; the excess is capacity other workloads cannot use.\n", ratio, name)
+	case ratio > 1.1:
+		return fmt.Sprintf("  The workload is moderately over-provisioned on %s; lowering the request frees capacity without affecting typical usage.\n", name)
+	case ratio < 0.9:
+		return fmt.Sprintf("  The workload regularly uses more %s than it requests, which risks throttling or eviction under node pressure.\n", name)
+	default:
+		return fmt.Sprintf("  The current %s request already matches observed usage closely.\n", name)
+	}
+}
+
+// changeSuffix formats the relative change from current to recommended, e.g. " (-90%)"
+func changeSuffix(current, recommended string, parse func(string) (float64, error)) string {
+	currentValue, err := parse(current)
+	if err != nil || currentValue == 0 {
+		return ""
+	}
+	recommendedValue, err := parse(recommended)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.0f%%)", (recommendedValue-currentValue)/currentValue*100)
+}
+
+// windowSuffix returns " over the last <window>" when the history window is known
+func windowSuffix(strategy StrategyInfo) string {
+	if window := strategy.HistoryDuration(); window > 0 {
+		return " over the last " + formatWindow(window)
+	}
+	return ""
+}
+
+// formatWindow renders a duration in days when it is a whole number of days
+func formatWindow(window time.Duration) string {
+	if window >= 24*time.Hour && window%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", int(window/(24*time.Hour)))
+	}
+	return window.String()
+}
+
+// displayQuantity renders an empty quantity as "unset"
+func displayQuantity(quantity string) string {
+	if quantity == "" {
+		return "unset"
+	}
+	return quantity
+}
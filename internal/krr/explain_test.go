@@ -0,0 +1,220 @@
+package krr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadFixture parses testdata/<name> into a ScanResult
+func loadFixture(t *testing.T, name string) *ScanResult {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	return result
+}
+
+func TestFindResources(t *testing.T) {
+	resources := loadFixture(t, "v3-simple.json").Resources
+	tests := []struct {
+		name                                 string
+		kind, workload, namespace, container string
+		want                                 []string
+	}{
+		{name: "any kind and container", workload: "web", namespace: "shop", want: []string{"web/app"}},
+		{name: "kind is case-insensitive", kind: "deployment", workload: "web", namespace: "shop", want: []string{"web/app"}},
+		{name: "container filter", workload: "postgres", namespace: "shop", container: "postgres", want: []string{"postgres/postgres"}},
+		{name: "other namespace", workload: "web", namespace: "batch"},
+		{name: "other kind", kind: "StatefulSet", workload: "web", namespace: "shop"},
+		{name: "other container", workload: "web", namespace: "shop", container: "sidecar"},
+		{name: "unknown workload", workload: "checkout", namespace: "shop"},
+		{name: "name is exact", workload: "we", namespace: "shop"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, resource := range FindResources(resources, tt.kind, tt.workload, tt.namespace, tt.container) {
+				got = append(got, resource.Name+"/"+resource.Container)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FindResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy StrategyInfo
+		want     string
+	}{
+		{
+			name:     "simple",
+			strategy: loadFixture(t, "v3-simple.json").Strategy,
+			want:     "Strategy: simple (history window 14d, CPU percentile p95, memory buffer 15%)",
+		},
+		{
+			name:     "simple-limit has no cpu_percentile",
+			strategy: loadFixture(t, "v2-simple-limit.json").Strategy,
+			want:     "Strategy: simple-limit (history window 7d, memory buffer 15%)",
+		},
+		{
+			name:     "partial day window",
+			strategy: StrategyInfo{Name: "simple", Settings: map[string]any{"history_duration": 1.5}},
+			want:     "Strategy: simple (history window 1h30m0s)",
+		},
+		{
+			name:     "non-numeric settings are ignored",
+			strategy: StrategyInfo{Name: "custom", Settings: map[string]any{"history_duration": "336"}},
+			want:     "Strategy: custom",
+		},
+		{name: "unknown", want: "Strategy: unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeStrategy(tt.strategy); got != tt.want {
+				t.Errorf("describeStrategy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name      string
+		fixture   string
+		workload  string
+		strategy  *StrategyInfo // overrides the fixture's strategy when set
+		wants     []string
+		doNotWant []string
+	}{
+		{
+			name:     "over-provisioned with limits",
+			fixture:  "v3-simple.json",
+			workload: "web",
+			wants: []string{
+				"Deployment shop/web (container app)",
+				"Strategy: simple (history window 14d, CPU percentile p95, memory buffer 15%)",
+				"Severity: CRITICAL",
+				"CPU request: current 500m -> recommended 12m (-98%)",
+				"The p95 of observed CPU usage over the last 14d is about 12m",
+				"reserves about 41.7x the CPU it actually needs",
+				"Memory request: current 512Mi -> recommended 100Mi (-80%)",
+				"Peak memory usage over the last 14d was about",
+				"KRR adds a 15% buffer on top of the peak",
+				"CPU limit: current 1 -> recommended unset",
+				"Memory limit: current 512Mi -> recommended 100Mi",
+			},
+			doNotWant: []string{"Notes from KRR"},
+		},
+		{
+			name:     "close to usage without a CPU limit",
+			fixture:  "v3-simple.json",
+			workload: "postgres",
+			wants: []string{
+				"StatefulSet shop/postgres (container postgres)",
+				"CPU request: current 250m -> recommended 200m (-20%)",
+				"moderately over-provisioned on CPU",
+				"Memory request: current 1Gi -> recommended 1Gi (+0%)",
+				"current memory request already matches observed usage closely",
+				"Memory limit: current 1Gi -> recommended 1Gi",
+			},
+			doNotWant: []string{"CPU limit:"},
+		},
+		{
+			name:     "no recommendation",
+			fixture:  "v3-simple.json",
+			workload: "nightly-report",
+			wants: []string{
+				"CronJob batch/nightly-report (container report)",
+				"Severity: UNKNOWN",
+				"CPU request: KRR could not compute a recommendation",
+				"Memory request: KRR could not compute a recommendation",
+				"Notes from KRR: cpu: Not enough data; memory: Not enough data",
+			},
+			doNotWant: []string{"->"},
+		},
+		{
+			name:     "under-provisioned with the default percentile",
+			fixture:  "v2-simple-limit.json",
+			workload: "api",
+			wants: []string{
+				"Strategy: simple-limit (history window 7d, memory buffer 15%)",
+				"CPU request: current 100m -> recommended 350m (+250%)",
+				"The p95 of observed CPU usage over the last 7d is about 350m",
+				"regularly uses more CPU than it requests",
+				"CPU limit: current 200m -> recommended 700m",
+				"Memory limit: current 128Mi -> recommended 192Mi",
+				"Notes from KRR: memory: HPA detected",
+			},
+		},
+		{
+			name:     "unknown strategy",
+			fixture:  "v3-simple.json",
+			workload: "web",
+			strategy: &StrategyInfo{},
+			wants: []string{
+				"Strategy: unknown\n",
+				"The p95 of observed CPU usage is about 12m",
+				"derived from peak memory usage plus a safety buffer",
+			},
+			doNotWant: []string{"over the last"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scan := loadFixture(t, tt.fixture)
+			strategy := scan.Strategy
+			if tt.strategy != nil {
+				strategy = *tt.strategy
+			}
+			var resource *Resource
+			for i := range scan.Resources {
+				if scan.Resources[i].Name == tt.workload {
+					resource = &scan.Resources[i]
+				}
+			}
+			if resource == nil {
+				t.Fatalf("fixture %s has no %s", tt.fixture, tt.workload)
+			}
+
+			got := Explain(*resource, strategy)
+			for _, want := range tt.wants {
+				if !strings.Contains(got, want) {
+					t.Errorf("Explain() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, unwanted := range tt.doNotWant {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("Explain() = %q, want no %q", got, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestExplainWithoutCurrentRequests(t *testing.T) {
+	resource := Resource{Kind: "Deployment", Namespace: "shop", Name: "worker", Recommended: ResourceRequirements{CPU: "50m", Memory: "64Mi"}}
+	got := Explain(resource, StrategyInfo{Name: "simple"})
+	for _, want := range []string{
+		"Deployment shop/worker\n",
+		"CPU request: current unset -> recommended 50m\n",
+		"No CPU request is set",
+		"No memory request is set",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Explain() = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "limit:") {
+		t.Errorf("Explain() = %q, want no limit lines", got)
+	}
+}
@@ -0,0 +1,15 @@
+package krr
+
+// FilterResources returns a copy of the result keeping only the resources for which keep
+// returns true, with the summary recalculated over the remaining resources
+func FilterResources(result *ScanResult, keep func(Resource) bool) *ScanResult {
+	filtered := *result
+	filtered.Resources = nil
+	for _, resource := range result.Resources {
+		if keep(resource) {
+			filtered.Resources = append(filtered.Resources, resource)
+		}
+	}
+	filtered.Summary = calculateSummary(filtered.Resources)
+	return &filtered
+}
@@ -0,0 +1,37 @@
+package krr
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// flagNamePattern matches a long KRR flag name without its leading dashes
+var flagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// NormalizeFlagName validates a long flag name, given with or without its leading "--", and
+// returns it in "--name" form
+func NormalizeFlagName(name string) (string, error) {
+	bare := strings.TrimPrefix(strings.TrimSpace(name), "--")
+	if !flagNamePattern.MatchString(bare) {
+		return "", fmt.Errorf("invalid flag name %q (expected a long flag such as --max_workers)", name)
+	}
+	return "--" + bare, nil
+}
+
+// extraFlagArgs renders extra flags in sorted order. Each flag is a single "--name=value"
+// argument, so a value can never be read as a flag of its own; an empty value renders the bare
+// flag, for boolean switches. Names must already be normalized.
+func extraFlagArgs(flags map[string]string) []string {
+	args := make([]string, 0, len(flags))
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		if value := flags[name]; value != "" {
+			args = append(args, name+"="+value)
+		} else {
+			args = append(args, name)
+		}
+	}
+	return args
+}
@@ -0,0 +1,47 @@
+package krr
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeFlagName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "max_workers", want: "--max_workers"},
+		{name: "--use_oomkill_data", want: "--use_oomkill_data"},
+		{name: " --cpu-min ", want: "--cpu-min"},
+		{name: "--cpu_percentile=95", wantErr: true},
+		{name: "-v", wantErr: true},
+		{name: "---x", wantErr: true},
+		{name: "--Kubeconfig", wantErr: true},
+		{name: "--a b", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeFlagName(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeFlagName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeFlagName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExtraFlagArgs(t *testing.T) {
+	flags := map[string]string{
+		"--use_oomkill_data": "",
+		"--cpu_percentile":   "95 --kubeconfig=/etc/shadow",
+		"--allow_hpa":        "true",
+	}
+	// Each flag is one argument, so a value with spaces or dashes cannot start a flag of its own
+	want := []string{"--allow_hpa=true", "--cpu_percentile=95 --kubeconfig=/etc/shadow", "--use_oomkill_data"}
+	if got := extraFlagArgs(flags); !slices.Equal(got, want) {
+		t.Errorf("extraFlagArgs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,34 @@
+package krr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHistoryDuration is the history window KRR analyses when --history_duration is not set
+const DefaultHistoryDuration = 14 * 24 * time.Hour
+
+// ParseHistoryDuration parses a history window given as a Go duration ("36h"), a number of
+// days ("7d") or a bare number of hours ("168")
+func ParseHistoryDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	var duration time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid history duration %q", value)
+		}
+		duration = time.Duration(n * float64(24*time.Hour))
+	} else if hours, err := strconv.ParseFloat(value, 64); err == nil {
+		duration = time.Duration(hours * float64(time.Hour))
+	} else if duration, err = time.ParseDuration(value); err != nil {
+		return 0, fmt.Errorf("invalid history duration %q", value)
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("history duration %q must be positive", value)
+	}
+	return duration, nil
+}
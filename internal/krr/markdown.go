@@ -0,0 +1,104 @@
+package krr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markdownTopWorkloads is the number of workloads listed in the Markdown report
+const markdownTopWorkloads = 10
+
+// RenderMarkdown renders a scan result as a Markdown rightsizing report with a summary,
+// a table of the workloads with the largest potential savings and instructions to apply them
+func RenderMarkdown(result *ScanResult) string {
+	var b strings.Builder
+	summary := result.Summary
+
+	b.WriteString("# KRR Rightsizing Report\n\n")
+	if result.Cluster != "" {
+		fmt.Fprintf(&b, "- **Cluster:** %s\n", result.Cluster)
+	}
+	if result.Timestamp != "" {
+		fmt.Fprintf(&b, "- **Generated:** %s\n", result.Timestamp)
+	}
+	if result.Strategy.Name != "" {
+		fmt.Fprintf(&b, "- %s\n", strings.Replace(describeStrategy(result.Strategy), "Strategy:", "**Strategy:**", 1))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Summary\n\n")
+	b.WriteString("| Metric | Value |\n")
+	b.WriteString("|--------|-------|\n")
+	fmt.Fprintf(&b, "| Containers scanned | %d |\n", summary.TotalResources)
+	fmt.Fprintf(&b, "| Containers with recommendations | %d |\n", summary.ResourcesWithRecommendations)
+	fmt.Fprintf(&b, "| Critical / warning containers | %d / %d |\n", summary.CriticalSeverity, summary.WarningSeverity)
+	fmt.Fprintf(&b, "| CPU requested → recommended | %s → %s cores |\n", formatCores(summary.CurrentCPUCores), formatCores(summary.RecommendedCPUCores))
+	fmt.Fprintf(&b, "| Memory requested → recommended | %s → %s |\n", FormatMemory(summary.CurrentMemoryBytes), FormatMemory(summary.RecommendedMemoryBytes))
+	fmt.Fprintf(&b, "| Reclaimable CPU | %s cores |\n", formatCores(summary.ReclaimableCPUCores))
+	fmt.Fprintf(&b, "| Reclaimable memory | %s |\n", FormatMemory(summary.ReclaimableMemoryBytes))
+	b.WriteString("\n")
+
+	top := TopRecommendations(result.Resources, CostModel{}, markdownTopWorkloads)
+	b.WriteString("## Top Workloads\n\n")
+	if len(top) == 0 {
+		b.WriteString("No over-provisioned workloads were found.\n")
+		return b.String()
+	}
+
+	b.WriteString("| Namespace | Workload | Container | CPU request | Memory request | Severity |\n")
+	b.WriteString("|-----------|----------|-----------|-------------|----------------|----------|\n")
+	for _, resource := range top {
+		fmt.Fprintf(&b, "| %s | %s/%s | %s | %s | %s | %s |\n",
+			markdownEscape(resource.Namespace),
+			markdownEscape(resource.Kind), markdownEscape(resource.Name),
+			markdownEscape(resource.Container),
+			formatChange(resource.Current.CPU, resource.Recommended.CPU),
+			formatChange(resource.Current.Memory, resource.Recommended.Memory),
+			markdownEscape(resource.Severity),
+		)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## How to Apply\n\n")
+	b.WriteString("Review each change before applying it. For workloads managed by GitOps or Helm, ")
+	b.WriteString("commit the new requests to the source manifests instead, otherwise the next sync reverts them.\n\n")
+	b.WriteString("```sh\n")
+	for _, resource := range top {
+		if command := setResourcesCommand(resource); command != "" {
+			b.WriteString(command + "\n")
+		}
+	}
+	b.WriteString("```\n")
+
+	return b.String()
+}
+
+// setResourcesCommand returns the kubectl command applying a resource's recommended requests
+func setResourcesCommand(resource Resource) string {
+	var requests []string
+	if resource.Recommended.CPU != "" {
+		requests = append(requests, "cpu="+resource.Recommended.CPU)
+	}
+	if resource.Recommended.Memory != "" {
+		requests = append(requests, "memory="+resource.Recommended.Memory)
+	}
+	if len(requests) == 0 {
+		return ""
+	}
+
+	command := fmt.Sprintf("kubectl set resources %s/%s -n %s", strings.ToLower(resource.Kind), resource.Name, resource.Namespace)
+	if resource.Container != "" {
+		command += " -c " + resource.Container
+	}
+	return command + " --requests=" + strings.Join(requests, ",")
+}
+
+// formatCores renders a number of cores with at most three decimals
+func formatCores(cores float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", cores), "0"), ".")
+}
+
+// markdownEscape escapes characters that would break a Markdown table cell
+func markdownEscape(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
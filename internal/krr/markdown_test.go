@@ -0,0 +1,51 @@
+package krr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownGolden(t *testing.T) {
+	for _, fixture := range []string{"v3-simple", "v2-simple-limit"} {
+		t.Run(fixture, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", fixture+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseJSON(data)
+			if err != nil {
+				t.Fatalf("ParseJSON() error = %v", err)
+			}
+			result := &ScanResult{
+				Timestamp: "2024-05-01T10:00:00Z",
+				Cluster:   "prod",
+				Strategy:  parsed.Strategy,
+				Resources: parsed.Resources,
+				Summary:   parsed.Summary,
+			}
+			assertGolden(t, fixture+".md", []byte(RenderMarkdown(result)))
+		})
+	}
+}
+
+func TestRenderMarkdownWithoutRecommendations(t *testing.T) {
+	got := RenderMarkdown(&ScanResult{})
+	if !strings.Contains(got, "## Summary") || !strings.Contains(got, "No over-provisioned workloads were found.") {
+		t.Errorf("RenderMarkdown() = %q, want the summary and an empty top workloads section", got)
+	}
+	if strings.Contains(got, "| Namespace |") {
+		t.Errorf("RenderMarkdown() rendered an empty workloads table:\n%s", got)
+	}
+}
+
+func TestRenderMarkdownEscapesCells(t *testing.T) {
+	result := &ScanResult{Resources: []Resource{{
+		Name: "web|api", Namespace: "shop", Kind: "Deployment", Container: "app",
+		Current: ResourceRequirements{CPU: "1"}, Recommended: ResourceRequirements{CPU: "100m"},
+	}}}
+	if got := RenderMarkdown(result); !strings.Contains(got, `| Deployment/web\|api |`) {
+		t.Errorf("RenderMarkdown() left a pipe unescaped in a table cell:\n%s", got)
+	}
+}
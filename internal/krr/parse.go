@@ -0,0 +1,255 @@
+package krr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema versions recognised by ParseJSON. KRR does not version its JSON output, so these
+// labels are ours: each names a document shape, inferred from the fields present, rather than
+// a KRR release. testdata/ holds a sample of each shape.
+const (
+	// SchemaLegacyArray is a bare array of scans instead of a {"scans": [...]} document
+	SchemaLegacyArray = "legacy-array"
+
+	// SchemaV1 has scans with bare numeric values and no strategy block
+	SchemaV1 = "v1"
+
+	// SchemaV2 wraps values in {"value", "severity"} objects and reports the strategy
+	SchemaV2 = "v2"
+
+	// SchemaV3 is the v2 shape plus the top-level score or clusterSummary
+	SchemaV3 = "v3"
+)
+
+// krrOutput mirrors the top-level document produced by `krr <strategy> --formatter json`.
+// Fields that only exist in some KRR versions are optional; unknown fields are ignored.
+type krrOutput struct {
+	Scans          []krrScan       `json:"scans"`
+	Description    string          `json:"description"`
+	Strategy       *krrStrategy    `json:"strategy"`
+	Score          json.RawMessage `json:"score"`
+	ClusterSummary json.RawMessage `json:"clusterSummary"`
+}
+
+type krrStrategy struct {
+	Name     string         `json:"name"`
+	Settings map[string]any `json:"settings"`
+}
+
+type krrScan struct {
+	Object      krrObject      `json:"object"`
+	Recommended krrRecommended `json:"recommended"`
+	Severity    string         `json:"severity"`
+}
+
+type krrObject struct {
+	Name        string         `json:"name"`
+	Namespace   string         `json:"namespace"`
+	Kind        string         `json:"kind"`
+	Container   string         `json:"container"`
+	Pods        []krrPod       `json:"pods"`
+	Allocations krrAllocations `json:"allocations"`
+
+	// Allocated is the name some older releases used for allocations
+	Allocated *krrAllocations `json:"allocated"`
+}
+
+type krrPod struct {
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted"`
+}
+
+type krrAllocations struct {
+	Requests map[string]json.RawMessage `json:"requests"`
+	Limits   map[string]json.RawMessage `json:"limits"`
+}
+
+type krrRecommended struct {
+	Requests map[string]json.RawMessage `json:"requests"`
+	Limits   map[string]json.RawMessage `json:"limits"`
+	Info     map[string]*string         `json:"info"`
+}
+
+// ParseJSON converts KRR's JSON formatter output into a ScanResult. It accepts the document
+// shapes written by the KRR releases we know of and records the detected shape in SchemaVersion.
+func ParseJSON(data []byte) (*ScanResult, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("failed to parse krr JSON output: empty document")
+	}
+
+	var doc krrOutput
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &doc.Scans); err != nil {
+			return nil, fmt.Errorf("failed to parse krr JSON output: %w", err)
+		}
+	} else if err := json.Unmarshal(trimmed, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse krr JSON output: %w", err)
+	}
+
+	result := &ScanResult{SchemaVersion: detectSchemaVersion(trimmed[0] == '[', &doc)}
+	if doc.Strategy != nil {
+		result.Strategy = StrategyInfo{
+			Name:        doc.Strategy.Name,
+			Description: doc.Description,
+			Settings:    doc.Strategy.Settings,
+		}
+	}
+
+	for _, scan := range doc.Scans {
+		allocations := scan.Object.Allocations
+		if allocations.Requests == nil && allocations.Limits == nil && scan.Object.Allocated != nil {
+			allocations = *scan.Object.Allocated
+		}
+
+		resource := Resource{
+			Name:      scan.Object.Name,
+			Namespace: scan.Object.Namespace,
+			Kind:      scan.Object.Kind,
+			Container: scan.Object.Container,
+			Severity:  scan.Severity,
+			Current: ResourceRequirements{
+				CPU:    cpuValue(allocations.Requests["cpu"]),
+				Memory: memoryValue(allocations.Requests["memory"]),
+			},
+			CurrentLimits: ResourceRequirements{
+				CPU:    cpuValue(allocations.Limits["cpu"]),
+				Memory: memoryValue(allocations.Limits["memory"]),
+			},
+			Recommended: ResourceRequirements{
+				CPU:    cpuValue(scan.Recommended.Requests["cpu"]),
+				Memory: memoryValue(scan.Recommended.Requests["memory"]),
+			},
+			RecommendedLimits: ResourceRequirements{
+				CPU:    cpuValue(scan.Recommended.Limits["cpu"]),
+				Memory: memoryValue(scan.Recommended.Limits["memory"]),
+			},
+			Reason: infoReason(scan.Recommended.Info),
+		}
+		if resource.Severity == "" {
+			resource.Severity = valueSeverity(scan.Recommended)
+		}
+		for _, pod := range scan.Object.Pods {
+			if !pod.Deleted {
+				resource.Pods = append(resource.Pods, pod.Name)
+			}
+		}
+		result.Resources = append(result.Resources, resource)
+	}
+
+	result.Summary = calculateSummary(result.Resources)
+	return result, nil
+}
+
+// detectSchemaVersion infers which KRR output shape a decoded document was written in
+func detectSchemaVersion(bareArray bool, doc *krrOutput) string {
+	switch {
+	case bareArray:
+		return SchemaLegacyArray
+	case len(doc.Score) > 0 || len(doc.ClusterSummary) > 0:
+		return SchemaV3
+	case doc.Strategy != nil || hasWrappedValues(doc.Scans):
+		return SchemaV2
+	default:
+		return SchemaV1
+	}
+}
+
+// hasWrappedValues reports whether any recommendation uses the {"value", "severity"} form
+func hasWrappedValues(scans []krrScan) bool {
+	for _, scan := range scans {
+		for _, values := range []map[string]json.RawMessage{scan.Recommended.Requests, scan.Recommended.Limits} {
+			for _, raw := range values {
+				if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// severityRank orders KRR severities from least to most severe
+var severityRank = map[string]int{
+	"unknown":  0,
+	"good":     1,
+	"ok":       2,
+	"warning":  3,
+	"critical": 4,
+}
+
+// valueSeverity derives a scan severity from the per-value severities, for KRR versions
+// that only report severity alongside each recommended value
+func valueSeverity(recommended krrRecommended) string {
+	worst := ""
+	for _, values := range []map[string]json.RawMessage{recommended.Requests, recommended.Limits} {
+		for _, raw := range values {
+			var wrapped struct {
+				Severity string `json:"severity"`
+			}
+			if err := json.Unmarshal(raw, &wrapped); err != nil || wrapped.Severity == "" {
+				continue
+			}
+			if worst == "" || severityRank[strings.ToLower(wrapped.Severity)] > severityRank[strings.ToLower(worst)] {
+				worst = wrapped.Severity
+			}
+		}
+	}
+	return worst
+}
+
+// numericValue extracts a number from a KRR value, which is either a bare number,
+// a {"value": ..., "severity": ...} object, null, or "?" when unknown
+func numericValue(raw json.RawMessage) (float64, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, false
+	}
+
+	var number float64
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number, true
+	}
+
+	var wrapped struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err == nil && len(wrapped.Value) > 0 && string(wrapped.Value) != "null" {
+		if err := json.Unmarshal(wrapped.Value, &number); err == nil {
+			return number, true
+		}
+	}
+
+	return 0, false
+}
+
+// cpuValue converts a KRR CPU value (in cores) into a Kubernetes quantity string
+func cpuValue(raw json.RawMessage) string {
+	if cores, ok := numericValue(raw); ok {
+		return FormatCPU(cores)
+	}
+	return ""
+}
+
+// memoryValue converts a KRR memory value (in bytes) into a Kubernetes quantity string
+func memoryValue(raw json.RawMessage) string {
+	if bytes, ok := numericValue(raw); ok {
+		return FormatMemory(bytes)
+	}
+	return ""
+}
+
+// infoReason flattens KRR's per-resource info messages into a single reason string
+func infoReason(info map[string]*string) string {
+	var parts []string
+	for resource, message := range info {
+		if message != nil && *message != "" {
+			parts = append(parts, fmt.Sprintf("%s: %s", resource, *message))
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "; ")
+}
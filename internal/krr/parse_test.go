@@ -0,0 +1,74 @@
+package krr
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites the golden files from the current output: go test ./internal/krr -update
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// assertGolden compares got with testdata/<name>, or rewrites the file when -update is set
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run go test -update to accept it):\n%s", path, got)
+	}
+}
+
+func TestParseJSONGolden(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		wantSchema string
+	}{
+		{"v3-simple.json", SchemaV3},
+		{"v2-simple-limit.json", SchemaV2},
+		{"v1-bare-values.json", SchemaV1},
+		{"legacy-array.json", SchemaLegacyArray},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := ParseJSON(data)
+			if err != nil {
+				t.Fatalf("ParseJSON() error = %v", err)
+			}
+			if result.SchemaVersion != tt.wantSchema {
+				t.Errorf("SchemaVersion = %q, want %q", result.SchemaVersion, tt.wantSchema)
+			}
+
+			got, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, strings.TrimSuffix(tt.fixture, ".json")+".golden", append(got, '\n'))
+		})
+	}
+}
+
+func TestParseJSONRejectsInvalidDocuments(t *testing.T) {
+	for _, data := range []string{"", "   ", "{not json", `{"scans": {}}`, "Traceback (most recent call last):"} {
+		if _, err := ParseJSON([]byte(data)); err == nil {
+			t.Errorf("ParseJSON(%q) succeeded, want an error", data)
+		}
+	}
+}
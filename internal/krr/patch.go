@@ -0,0 +1,140 @@
+package krr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// podTemplatePaths locates the pod template of the workload kinds whose containers can be
+// patched in place. Jobs are left out since their pod template is immutable, and custom
+// resources such as Argo Rollouts since they do not support strategic merge patches.
+var podTemplatePaths = map[string][]string{
+	"deployment":  {"spec", "template", "spec"},
+	"statefulset": {"spec", "template", "spec"},
+	"daemonset":   {"spec", "template", "spec"},
+	"cronjob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// PodSpecPath returns the path of the pod spec within a workload of the given kind, and whether
+// the kind can be patched
+func PodSpecPath(kind string) ([]string, bool) {
+	path, ok := podTemplatePaths[strings.ToLower(kind)]
+	return path, ok
+}
+
+// ContainerResources are the resources to set on one container, as Kubernetes quantities by
+// "cpu" and "memory"; empty maps are left out of patches
+type ContainerResources struct {
+	Name     string            `json:"name"`
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+// WorkloadPatch sets the recommended resources of the containers of one workload
+type WorkloadPatch struct {
+	Namespace  string               `json:"namespace"`
+	Kind       string               `json:"kind"`
+	Name       string               `json:"name"`
+	Containers []ContainerResources `json:"containers"`
+
+	// Changes are the fields the patch changes per container, with their current values
+	Changes []ContainerDelta `json:"changes"`
+}
+
+// StrategicMergePatch returns the patch as a strategic merge patch document. Containers are
+// merged by name, so other containers and fields are left alone.
+func (p WorkloadPatch) StrategicMergePatch() map[string]any {
+	containers := make([]any, 0, len(p.Containers))
+	for _, container := range p.Containers {
+		resources := map[string]any{}
+		if len(container.Requests) > 0 {
+			resources["requests"] = container.Requests
+		}
+		if len(container.Limits) > 0 {
+			resources["limits"] = container.Limits
+		}
+		containers = append(containers, map[string]any{"name": container.Name, "resources": resources})
+	}
+
+	path, _ := PodSpecPath(p.Kind)
+	var patch any = map[string]any{"containers": containers}
+	for i := len(path) - 1; i >= 0; i-- {
+		patch = map[string]any{path[i]: patch}
+	}
+	return patch.(map[string]any)
+}
+
+// BuildPatches turns the containers of a delta into one patch per workload, sorted by
+// namespace, kind and name. Limits are only patched with includeLimits. Containers of kinds
+// that cannot be patched, and containers without a name, are returned as skipped with the
+// reason.
+func BuildPatches(delta Delta, includeLimits bool) (patches []WorkloadPatch, skipped map[string]string) {
+	skipped = map[string]string{}
+	byWorkload := map[string]*WorkloadPatch{}
+	for _, container := range delta.Containers {
+		key := container.Namespace + "/" + container.Kind + "/" + container.Name
+		if _, ok := PodSpecPath(container.Kind); !ok {
+			skipped[key] = fmt.Sprintf("%s workloads cannot be patched in place", container.Kind)
+			continue
+		}
+		if container.Container == "" {
+			skipped[key+"/"] = "KRR did not report the container name"
+			continue
+		}
+
+		resources := ContainerResources{Name: container.Container}
+		var changes []FieldChange
+		for _, change := range container.Changes {
+			section, resource, _ := strings.Cut(change.Field, ".")
+			switch {
+			case section == "requests":
+				if resources.Requests == nil {
+					resources.Requests = map[string]string{}
+				}
+				resources.Requests[resource] = change.Recommended
+			case section == "limits" && includeLimits:
+				if resources.Limits == nil {
+					resources.Limits = map[string]string{}
+				}
+				resources.Limits[resource] = change.Recommended
+			default:
+				continue
+			}
+			changes = append(changes, change)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		patch, ok := byWorkload[key]
+		if !ok {
+			patch = &WorkloadPatch{Namespace: container.Namespace, Kind: container.Kind, Name: container.Name}
+			byWorkload[key] = patch
+		}
+		patch.Containers = append(patch.Containers, resources)
+		patch.Changes = append(patch.Changes, ContainerDelta{
+			Namespace: container.Namespace,
+			Kind:      container.Kind,
+			Name:      container.Name,
+			Container: container.Container,
+			Changes:   changes,
+		})
+	}
+
+	patches = make([]WorkloadPatch, 0, len(byWorkload))
+	for _, patch := range byWorkload {
+		patches = append(patches, *patch)
+	}
+	sort.Slice(patches, func(i, j int) bool {
+		a, b := patches[i], patches[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return patches, skipped
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package krr
+
+import "os/exec"
+
+// killProcessGroupOnCancel keeps exec's default of killing only KRR itself on platforms
+// without process groups; cancelWaitDelay still bounds the wait for its output
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package krr
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroupOnCancel runs cmd in its own process group and makes cancellation of its
+// context kill the whole group, so helpers KRR or a custom strategy started die with it
+// instead of holding Prometheus queries and KRR's output pipe open
+func killProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,65 @@
+package krr
+
+import (
+	"context"
+	"regexp"
+)
+
+// Scan stages reported through a ProgressFunc while KRR runs
+const (
+	StageDiscovering = "discovering workloads"
+	StageQuerying    = "querying Prometheus"
+)
+
+// prometheusActivityPattern matches KRR log lines showing it has moved on from listing
+// workloads to the metrics service
+var prometheusActivityPattern = regexp.MustCompile(`(?i)prometheus|thanos|victoria ?metrics|mimir|calculat`)
+
+// ProgressFunc receives human-readable scan stages as a scan advances
+type ProgressFunc func(stage string)
+
+// progressKey is the context key under which WithProgress stores a ProgressFunc
+type progressKey struct{}
+
+// WithProgress returns a context whose scans report their stages to fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress reports a stage to the ProgressFunc of ctx, if it has one
+func ReportProgress(ctx context.Context, stage string) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		fn(stage)
+	}
+}
+
+// hasProgress reports whether ctx carries a ProgressFunc
+func hasProgress(ctx context.Context) bool {
+	_, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	return ok
+}
+
+// stageWriter watches KRR's log output for the point where it starts querying Prometheus and
+// reports it once. It keeps the tail of the previous write, so a keyword split across writes
+// still matches.
+type stageWriter struct {
+	ctx      context.Context
+	tail     []byte
+	reported bool
+}
+
+// Write implements io.Writer; it never fails, so KRR's stderr capture is unaffected
+func (w *stageWriter) Write(p []byte) (int, error) {
+	if w.reported {
+		return len(p), nil
+	}
+	data := append(w.tail, p...)
+	if prometheusActivityPattern.Match(data) {
+		w.reported = true
+		w.tail = nil
+		ReportProgress(w.ctx, StageQuerying)
+		return len(p), nil
+	}
+	w.tail = append([]byte(nil), data[max(len(data)-32, 0):]...)
+	return len(p), nil
+}
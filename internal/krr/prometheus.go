@@ -0,0 +1,54 @@
+package krr
+
+import (
+	"regexp"
+	"strings"
+)
+
+// metricsServices are the metrics service names KRR logs during discovery
+const metricsServices = `Prometheus|Thanos|Victoria ?Metrics|Mimir|Amazon Managed Prometheus|Google Managed Prometheus`
+
+var (
+	// prometheusUsingPattern matches KRR's log line naming the metrics service it connected to,
+	// e.g. "Using Prometheus found at http://prometheus-operated.monitoring:9090 for cluster default"
+	prometheusUsingPattern = regexp.MustCompile(`Using (` + metricsServices + `)\b.*? at (\S+)`)
+
+	// prometheusMissingPattern matches KRR's error for a failed auto-discovery,
+	// e.g. "Prometheus instance could not be found while scanning in default cluster."
+	prometheusMissingPattern = regexp.MustCompile(`\b(` + metricsServices + `) instance could not be found while scanning in \S+ cluster`)
+)
+
+// PrometheusDiscovery describes the metrics service KRR reported using for a scan
+type PrometheusDiscovery struct {
+	// Service is the kind of metrics service, e.g. "Prometheus" or "Thanos"
+	Service string `json:"service,omitempty"`
+	URL     string `json:"url,omitempty"`
+
+	// Found is false when KRR logged that auto-discovery failed
+	Found bool `json:"found"`
+}
+
+// ParsePrometheusDiscovery extracts the Prometheus endpoint KRR used from its log output
+// (stderr, or stdout for older versions that logged there). It returns nil when KRR logged
+// nothing about discovery, which is the common case without --verbose on some versions.
+func ParsePrometheusDiscovery(output string) *PrometheusDiscovery {
+	output = StripANSI(output)
+
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		if match := prometheusUsingPattern.FindStringSubmatch(line); match != nil {
+			return &PrometheusDiscovery{
+				Service: match[1],
+				URL:     strings.TrimRight(match[2], ".,;"),
+				Found:   true,
+			}
+		}
+	}
+
+	for _, line := range lines {
+		if match := prometheusMissingPattern.FindStringSubmatch(line); match != nil {
+			return &PrometheusDiscovery{Service: match[1], Found: false}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package krr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePrometheusDiscovery(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *PrometheusDiscovery
+	}{
+		{
+			name: "prometheus found",
+			output: "[10:42:07] INFO     Listing scannable objects in default\n" +
+				"           INFO     Connecting to Prometheus for default cluster\n" +
+				"           INFO     Using Prometheus found at http://prometheus-operated.monitoring:9090 for cluster default\n" +
+				"           INFO     Prometheus connected successfully for default cluster\n",
+			want: &PrometheusDiscovery{Service: "Prometheus", URL: "http://prometheus-operated.monitoring:9090", Found: true},
+		},
+		{
+			name:   "victoria metrics found with color codes",
+			output: "\x1b[2m[10:42:07]\x1b[0m \x1b[34mINFO\x1b[0m     Using Victoria Metrics found at http://vmsingle.monitoring:8429. for cluster prod\n",
+			want:   &PrometheusDiscovery{Service: "Victoria Metrics", URL: "http://vmsingle.monitoring:8429", Found: true},
+		},
+		{
+			name: "not found",
+			output: "[10:42:07] INFO     Connecting to Prometheus for default cluster\n" +
+				"           ERROR    Prometheus instance could not be found while scanning in default cluster.\n" +
+				"           ERROR    Skipping cluster default\n",
+			want: &PrometheusDiscovery{Service: "Prometheus", Found: false},
+		},
+		{
+			name:   "thanos not found",
+			output: "           ERROR    Thanos instance could not be found while scanning in kind-greenops cluster.\n",
+			want:   &PrometheusDiscovery{Service: "Thanos", Found: false},
+		},
+		{
+			name: "found wins over an earlier failed service",
+			output: "           ERROR    Thanos instance could not be found while scanning in default cluster.\n" +
+				"           INFO     Using Prometheus found at http://prometheus.monitoring:9090 for cluster default\n",
+			want: &PrometheusDiscovery{Service: "Prometheus", URL: "http://prometheus.monitoring:9090", Found: true},
+		},
+		{
+			name: "unrelated not found noise",
+			output: "           WARNING  prometheus-adapter metrics not found for Deployment shop/web\n" +
+				"           WARNING  Prometheus label cluster not found, scanning without it\n" +
+				"           INFO     HPA not found for shop/web\n",
+		},
+		{
+			name:   "message split across lines",
+			output: "           ERROR    Prometheus instance\ncould not be found while scanning in default cluster.\n",
+		},
+		{name: "no output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParsePrometheusDiscovery(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePrometheusDiscovery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
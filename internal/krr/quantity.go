@@ -0,0 +1,99 @@
+package krr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// memorySuffixes maps Kubernetes quantity suffixes to their byte multipliers
+var memorySuffixes = map[string]float64{
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+	"k":  1e3,
+	"K":  1e3,
+	"M":  1e6,
+	"G":  1e9,
+	"T":  1e12,
+	"P":  1e15,
+}
+
+// ParseCPU parses a Kubernetes CPU quantity (e.g. "100m", "1.5") into cores
+func ParseCPU(quantity string) (float64, error) {
+	q := strings.TrimSpace(quantity)
+	if q == "" {
+		return 0, fmt.Errorf("empty CPU quantity")
+	}
+
+	multiplier := 1.0
+	if strings.HasSuffix(q, "m") {
+		multiplier = 1e-3
+		q = strings.TrimSuffix(q, "m")
+	}
+
+	value, err := strconv.ParseFloat(q, 64)
+	if err != nil || value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("invalid CPU quantity %q", quantity)
+	}
+
+	return value * multiplier, nil
+}
+
+// ParseMemory parses a Kubernetes memory quantity (e.g. "128Mi", "1G") into bytes
+func ParseMemory(quantity string) (float64, error) {
+	q := strings.TrimSpace(quantity)
+	if q == "" {
+		return 0, fmt.Errorf("empty memory quantity")
+	}
+
+	multiplier := 1.0
+	for _, suffixLen := range []int{2, 1} {
+		if len(q) <= suffixLen {
+			continue
+		}
+		if m, ok := memorySuffixes[q[len(q)-suffixLen:]]; ok {
+			multiplier = m
+			q = q[:len(q)-suffixLen]
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(q, 64)
+	if err != nil || value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("invalid memory quantity %q", quantity)
+	}
+
+	return value * multiplier, nil
+}
+
+// FormatCPU formats a number of cores as a Kubernetes CPU quantity
+func FormatCPU(cores float64) string {
+	// Round away float noise (0.1*1000 = 100.00000000000001) before taking the ceiling
+	millis := math.Ceil(math.Round(cores*1e6) / 1e3)
+	if millis >= 1000 && math.Mod(millis, 1000) == 0 {
+		return strconv.FormatFloat(millis/1000, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%dm", int64(millis))
+}
+
+// FormatMemory formats a number of bytes as a Kubernetes memory quantity using binary suffixes.
+// Larger units are only used when they don't lose meaningful precision.
+func FormatMemory(bytes float64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   float64
+	}{
+		{"Gi", 1 << 30},
+		{"Mi", 1 << 20},
+		{"Ki", 1 << 10},
+	} {
+		if bytes >= 10*unit.size || (bytes >= unit.size && math.Mod(bytes, unit.size) == 0) {
+			return fmt.Sprintf("%d%s", int64(math.Ceil(bytes/unit.size)), unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%d", int64(math.Ceil(bytes)))
+}
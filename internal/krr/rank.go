@@ -0,0 +1,52 @@
+package krr
+
+import (
+	"math"
+	"sort"
+)
+
+// gibPerCore weights memory against CPU when ranking without a cost model,
+// using the common 1 vCPU : 4 GiB cloud instance ratio
+const gibPerCore = 4
+
+// Reclaimable returns the CPU cores and memory bytes a resource would free by
+// adopting its recommended requests. Under-provisioned resources reclaim nothing.
+func Reclaimable(resource Resource) (cpuCores, memoryBytes float64) {
+	if current, recommended, ok := quantityPair(resource.Current.CPU, resource.Recommended.CPU, ParseCPU); ok {
+		cpuCores = math.Max(0, current-recommended)
+	}
+	if current, recommended, ok := quantityPair(resource.Current.Memory, resource.Recommended.Memory, ParseMemory); ok {
+		memoryBytes = math.Max(0, current-recommended)
+	}
+	return cpuCores, memoryBytes
+}
+
+// savingsScore ranks a resource by how much it would save, in money when a cost model
+// is configured and in weighted core-equivalents otherwise
+func savingsScore(resource Resource, model CostModel) float64 {
+	cpuCores, memoryBytes := Reclaimable(resource)
+	if model.Enabled() {
+		return cpuCores*model.CPUCostPerCoreHour + memoryBytes/bytesPerGiB*model.MemoryCostPerGiBHour
+	}
+	return cpuCores + memoryBytes/bytesPerGiB/gibPerCore
+}
+
+// TopRecommendations returns up to n resources with the largest potential savings,
+// skipping resources that would not free anything
+func TopRecommendations(resources []Resource, model CostModel, n int) []Resource {
+	ranked := make([]Resource, 0, len(resources))
+	for _, resource := range resources {
+		if savingsScore(resource, model) > 0 {
+			ranked = append(ranked, resource)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return savingsScore(ranked[i], model) > savingsScore(ranked[j], model)
+	})
+
+	if n > 0 && len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
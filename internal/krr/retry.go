@@ -0,0 +1,132 @@
+package krr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// rateLimitPattern matches HTTP 429 responses surfaced in KRR's logs: a 429 status code
+	// ("HTTP 429", "HTTP/1.1 429", "status code: 429") or its reason phrase. Looser words such
+	// as "throttling" are left out, since KRR also logs CPU throttling of the workloads it scans.
+	rateLimitPattern = regexp.MustCompile(`(?:http|status)\S*(?:[\s:='"]+(?:code|error))?[\s:='"]*429\b|too many requests`)
+
+	// retryAfterPattern matches a Retry-After hint, e.g. "Retry-After: 30" or "'retry-after': '2.5'"
+	retryAfterPattern = regexp.MustCompile(`(?i)retry[-_ ]after['"]?\s*[:=]?\s*['"]?([^'"\r\n}]+)`)
+
+	// retrySecondsPattern matches the delay-seconds form at the start of a Retry-After value
+	retrySecondsPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)s?\b`)
+)
+
+// maxRetryAfter caps a Retry-After hint, so a bogus value cannot stall a scan indefinitely
+const maxRetryAfter = 5 * time.Minute
+
+// isRateLimited reports whether lower-cased stderr shows Prometheus rejecting KRR's queries
+// with HTTP 429
+func isRateLimited(lower string) bool {
+	return rateLimitPattern.MatchString(lower)
+}
+
+// ParseRetryAfter extracts a Retry-After hint from KRR's log output, given either as a number
+// of seconds or as an HTTP date relative to now. It reports false when there is no usable hint.
+func ParseRetryAfter(stderr string, now time.Time) (time.Duration, bool) {
+	match := retryAfterPattern.FindStringSubmatch(stderr)
+	if match == nil {
+		return 0, false
+	}
+	value := strings.TrimSpace(match[1])
+
+	var wait time.Duration
+	if seconds := retrySecondsPattern.FindStringSubmatch(value); seconds != nil {
+		n, _ := strconv.ParseFloat(seconds[1], 64)
+		wait = time.Duration(n * float64(time.Second))
+	} else if date, err := http.ParseTime(value[:min(len(value), len(http.TimeFormat))]); err == nil {
+		wait = date.Sub(now)
+	} else {
+		return 0, false
+	}
+
+	if wait < 0 {
+		wait = 0
+	}
+	return min(wait, maxRetryAfter), true
+}
+
+// RetryFunc is told the error kind of each failed attempt a RetryingExecutor retries
+type RetryFunc func(kind ErrorKind)
+
+// retryKey is the context key under which WithRetryObserver stores a RetryFunc
+type retryKey struct{}
+
+// WithRetryObserver returns a context whose scans report every retry to fn
+func WithRetryObserver(ctx context.Context, fn RetryFunc) context.Context {
+	return context.WithValue(ctx, retryKey{}, fn)
+}
+
+// reportRetry reports a retry to the RetryFunc of ctx, if it has one
+func reportRetry(ctx context.Context, kind ErrorKind) {
+	if fn, ok := ctx.Value(retryKey{}).(RetryFunc); ok {
+		fn(kind)
+	}
+}
+
+// RetryingExecutor wraps an Executor and retries scans rejected by a rate-limited Prometheus.
+// It waits for the Retry-After hint when KRR logged one and otherwise backs off exponentially.
+// Other failures are returned immediately.
+type RetryingExecutor struct {
+	Executor
+	retries int
+	backoff time.Duration
+}
+
+// NewRetryingExecutor creates an executor that retries rate-limited scans up to retries times,
+// starting from backoff and doubling it on each attempt
+func NewRetryingExecutor(next Executor, retries int, backoff time.Duration) Executor {
+	return &RetryingExecutor{Executor: next, retries: retries, backoff: backoff}
+}
+
+// Scan runs the scan, retrying while it fails with ErrorKindRateLimited and ctx allows
+func (e *RetryingExecutor) Scan(ctx context.Context, options ScanOptions) (*ScanResult, error) {
+	backoff := e.backoff
+	for attempt := 0; ; attempt++ {
+		result, err := e.Executor.Scan(ctx, options)
+		if err == nil || attempt >= e.retries || ClassifyError(err) != ErrorKindRateLimited {
+			return result, err
+		}
+
+		wait := backoff
+		var scanErr *ScanError
+		if errors.As(err, &scanErr) {
+			if hint, ok := ParseRetryAfter(scanErr.Stderr, time.Now()); ok {
+				wait = hint
+			}
+		}
+		backoff *= 2
+
+		// Give up early rather than sleep past the scan's deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return result, err
+		}
+
+		log.Printf("KRR scan rate limited by Prometheus, retrying in %s (attempt %d of %d)", wait, attempt+1, e.retries)
+		reportRetry(ctx, ErrorKindRateLimited)
+		ReportProgress(ctx, fmt.Sprintf("rate limited by Prometheus, retrying in %s", wait))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil, ctx.Err()
+			}
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
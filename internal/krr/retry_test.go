@@ -0,0 +1,110 @@
+package krr
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// scriptedExecutor fails with the errors of its script in turn, then succeeds
+type scriptedExecutor struct {
+	Executor
+	script []error
+	calls  int
+}
+
+func (e *scriptedExecutor) Scan(ctx context.Context, options ScanOptions) (*ScanResult, error) {
+	e.calls++
+	if e.calls <= len(e.script) {
+		return nil, e.script[e.calls-1]
+	}
+	return &ScanResult{}, nil
+}
+
+func TestRetryingExecutorReportsRetries(t *testing.T) {
+	rateLimited := &ScanError{Kind: ErrorKindRateLimited, ExitCode: 1, Stderr: "HTTP 429 Too Many Requests"}
+
+	tests := []struct {
+		name        string
+		script      []error
+		retries     int
+		wantCalls   int
+		wantRetries []ErrorKind
+		wantErr     bool
+	}{
+		{
+			name:      "success needs no retry",
+			retries:   2,
+			wantCalls: 1,
+		},
+		{
+			name:        "rate limit is retried until it succeeds",
+			script:      []error{rateLimited, rateLimited},
+			retries:     2,
+			wantCalls:   3,
+			wantRetries: []ErrorKind{ErrorKindRateLimited, ErrorKindRateLimited},
+		},
+		{
+			name:        "retries are bounded",
+			script:      []error{rateLimited, rateLimited, rateLimited},
+			retries:     1,
+			wantCalls:   2,
+			wantRetries: []ErrorKind{ErrorKindRateLimited},
+			wantErr:     true,
+		},
+		{
+			name:      "prometheus failures are not retried",
+			script:    []error{&ScanError{Kind: ErrorKindPrometheus, ExitCode: 1}},
+			retries:   2,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "timeouts are not retried",
+			script:    []error{&ScanError{Kind: ErrorKindTimeout, ExitCode: -1}},
+			retries:   2,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &scriptedExecutor{script: tt.script}
+			executor := NewRetryingExecutor(next, tt.retries, time.Millisecond)
+
+			var retries []ErrorKind
+			ctx := WithRetryObserver(context.Background(), func(kind ErrorKind) {
+				retries = append(retries, kind)
+			})
+			_, err := executor.Scan(ctx, ScanOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan() error = %v, want error %v", err, tt.wantErr)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("KRR ran %d times, want %d", next.calls, tt.wantCalls)
+			}
+			if !slices.Equal(retries, tt.wantRetries) {
+				t.Errorf("reported retries %v, want %v", retries, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestRetryingExecutorWithoutObserver(t *testing.T) {
+	next := &scriptedExecutor{script: []error{&ScanError{Kind: ErrorKindRateLimited, ExitCode: 1}}}
+	if _, err := NewRetryingExecutor(next, 1, time.Millisecond).Scan(context.Background(), ScanOptions{}); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+}
+
+func TestRetryingExecutorStopsWhenCanceled(t *testing.T) {
+	next := &scriptedExecutor{script: []error{&ScanError{Kind: ErrorKindRateLimited, ExitCode: 1}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewRetryingExecutor(next, 3, time.Hour).Scan(ctx, ScanOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Scan() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,149 @@
+package krr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SavingsTotals are the resources, money, energy and emissions that recommendations would save
+// per month
+type SavingsTotals struct {
+	ReclaimableCPUCores  float64 `json:"reclaimable_cpu_cores"`
+	ReclaimableMemoryGiB float64 `json:"reclaimable_memory_gib"`
+	MonthlySavings       float64 `json:"monthly_savings"`
+	MonthlyKWhSavings    float64 `json:"monthly_kwh_savings"`
+	MonthlyCO2eSavingsKg float64 `json:"monthly_co2e_savings_kg"`
+}
+
+// Savings prices the reclaimable resources of a summary with a cost and a carbon model. Money
+// is 0 when the cost model has no prices.
+func Savings(summary Summary, cost CostModel, carbon CarbonModel) SavingsTotals {
+	footprint := EstimateCarbon(summary, carbon)
+	totals := SavingsTotals{
+		ReclaimableCPUCores:  footprint.ReclaimableCPUCores,
+		ReclaimableMemoryGiB: footprint.ReclaimableMemoryGiB,
+		MonthlyKWhSavings:    footprint.MonthlyKWhSavings,
+		MonthlyCO2eSavingsKg: footprint.MonthlyCO2eSavingsKg,
+	}
+	if cost.Enabled() {
+		totals.MonthlySavings = EstimateCost(summary, cost).EstimatedMonthlySavings
+	}
+	return totals
+}
+
+// Add adds other to t
+func (t *SavingsTotals) Add(other SavingsTotals) {
+	t.ReclaimableCPUCores = round(t.ReclaimableCPUCores+other.ReclaimableCPUCores, 3)
+	t.ReclaimableMemoryGiB = round(t.ReclaimableMemoryGiB+other.ReclaimableMemoryGiB, 3)
+	t.MonthlySavings = round(t.MonthlySavings+other.MonthlySavings, 2)
+	t.MonthlyKWhSavings = round(t.MonthlyKWhSavings+other.MonthlyKWhSavings, 2)
+	t.MonthlyCO2eSavingsKg = round(t.MonthlyCO2eSavingsKg+other.MonthlyCO2eSavingsKg, 2)
+}
+
+// Sub returns t minus other
+func (t SavingsTotals) Sub(other SavingsTotals) SavingsTotals {
+	return SavingsTotals{
+		ReclaimableCPUCores:  round(t.ReclaimableCPUCores-other.ReclaimableCPUCores, 3),
+		ReclaimableMemoryGiB: round(t.ReclaimableMemoryGiB-other.ReclaimableMemoryGiB, 3),
+		MonthlySavings:       round(t.MonthlySavings-other.MonthlySavings, 2),
+		MonthlyKWhSavings:    round(t.MonthlyKWhSavings-other.MonthlyKWhSavings, 2),
+		MonthlyCO2eSavingsKg: round(t.MonthlyCO2eSavingsKg-other.MonthlyCO2eSavingsKg, 2),
+	}
+}
+
+// SavingsOffender is a workload with a large potential saving
+type SavingsOffender struct {
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	SavingsTotals
+}
+
+// SavingsTrend compares the savings of the reported scans with those of earlier scans of the
+// same scopes, priced alike, so the change only reflects the workloads
+type SavingsTrend struct {
+	Period        string        `json:"period"`
+	PreviousScans []string      `json:"previous_scans"`
+	Previous      SavingsTotals `json:"previous"`
+	Current       SavingsTotals `json:"current"`
+	Change        SavingsTotals `json:"change"`
+}
+
+// SavingsReport is an executive summary of what the recommendations of one or more scans would save
+type SavingsReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Scans       []string  `json:"scans"`
+	Clusters    []string  `json:"clusters,omitempty"`
+
+	// Currency of the money figures, when the prices name one
+	Currency  string            `json:"currency,omitempty"`
+	Total     SavingsTotals     `json:"total"`
+	Offenders []SavingsOffender `json:"offenders"`
+	Trend     *SavingsTrend     `json:"trend,omitempty"`
+	Notes     []string          `json:"notes,omitempty"`
+}
+
+// RenderSavingsMarkdown renders a savings report for people who do not read scan results: the
+// totals, the top offenders and the trend
+func RenderSavingsMarkdown(report SavingsReport) string {
+	var b strings.Builder
+	money := func(value float64) string {
+		return strings.TrimSpace(fmt.Sprintf("%.2f %s", value, report.Currency))
+	}
+
+	b.WriteString("# Savings Report\n\n")
+	fmt.Fprintf(&b, "- **Scans:** %s\n", strings.Join(report.Scans, ", "))
+	if len(report.Clusters) > 0 {
+		fmt.Fprintf(&b, "- **Clusters:** %s\n", strings.Join(report.Clusters, ", "))
+	}
+	fmt.Fprintf(&b, "- **Generated:** %s\n\n", report.GeneratedAt.UTC().Format(time.RFC3339))
+
+	b.WriteString("## Potential Monthly Savings\n\n")
+	b.WriteString("| Metric | Value |\n")
+	b.WriteString("|--------|-------|\n")
+	fmt.Fprintf(&b, "| CPU | %s cores |\n", formatCores(report.Total.ReclaimableCPUCores))
+	fmt.Fprintf(&b, "| Memory | %s GiB |\n", formatCores(report.Total.ReclaimableMemoryGiB))
+	fmt.Fprintf(&b, "| Cost | %s |\n", money(report.Total.MonthlySavings))
+	fmt.Fprintf(&b, "| Energy | %.2f kWh |\n", report.Total.MonthlyKWhSavings)
+	fmt.Fprintf(&b, "| Emissions | %.2f kg CO2e |\n", report.Total.MonthlyCO2eSavingsKg)
+	b.WriteString("\n")
+
+	b.WriteString("## Top Offenders\n\n")
+	if len(report.Offenders) == 0 {
+		b.WriteString("No over-provisioned workloads were found.\n\n")
+	} else {
+		b.WriteString("| # | Workload | CPU (cores) | Memory (GiB) | Cost | kg CO2e |\n")
+		b.WriteString("|---|----------|-------------|--------------|------|---------|\n")
+		for i, offender := range report.Offenders {
+			workload := offender.Namespace + "/" + offender.Kind + "/" + offender.Name
+			if offender.Cluster != "" {
+				workload = offender.Cluster + ": " + workload
+			}
+			fmt.Fprintf(&b, "| %d | %s | %s | %s | %s | %.2f |\n", i+1, markdownEscape(workload),
+				formatCores(offender.ReclaimableCPUCores), formatCores(offender.ReclaimableMemoryGiB),
+				money(offender.MonthlySavings), offender.MonthlyCO2eSavingsKg)
+		}
+		b.WriteString("\n")
+	}
+
+	if trend := report.Trend; trend != nil {
+		fmt.Fprintf(&b, "## Trend (vs. %s earlier)\n\n", trend.Period)
+		b.WriteString("| Metric | Previous | Current | Change |\n")
+		b.WriteString("|--------|----------|---------|--------|\n")
+		fmt.Fprintf(&b, "| CPU (cores) | %s | %s | %+.3g |\n", formatCores(trend.Previous.ReclaimableCPUCores), formatCores(trend.Current.ReclaimableCPUCores), trend.Change.ReclaimableCPUCores)
+		fmt.Fprintf(&b, "| Memory (GiB) | %s | %s | %+.3g |\n", formatCores(trend.Previous.ReclaimableMemoryGiB), formatCores(trend.Current.ReclaimableMemoryGiB), trend.Change.ReclaimableMemoryGiB)
+		fmt.Fprintf(&b, "| Cost | %s | %s | %+.2f |\n", money(trend.Previous.MonthlySavings), money(trend.Current.MonthlySavings), trend.Change.MonthlySavings)
+		fmt.Fprintf(&b, "| kg CO2e | %.2f | %.2f | %+.2f |\n", trend.Previous.MonthlyCO2eSavingsKg, trend.Current.MonthlyCO2eSavingsKg, trend.Change.MonthlyCO2eSavingsKg)
+		b.WriteString("\nA negative change means less waste than in the previous period.\n\n")
+	}
+
+	if len(report.Notes) > 0 {
+		b.WriteString("## Notes\n\n")
+		for _, note := range report.Notes {
+			fmt.Fprintf(&b, "- %s\n", note)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package krr
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+// Severity levels assigned by ClassifySeverity, matching the names KRR itself uses
+const (
+	SeverityCritical = "CRITICAL"
+	SeverityWarning  = "WARNING"
+	SeverityOK       = "OK"
+)
+
+// SeverityThresholds configures how far current requests may sit from the recommendation
+// before a container is flagged. Values are percentages of the recommended request.
+type SeverityThresholds struct {
+	// Under-provisioning: current below recommended, which risks throttling or OOM kills
+	UnderCritical float64 `json:"under_critical"`
+	UnderWarning  float64 `json:"under_warning"`
+
+	// Over-provisioning: current above recommended, which wastes reserved capacity
+	OverCritical float64 `json:"over_critical"`
+	OverWarning  float64 `json:"over_warning"`
+}
+
+// DefaultSeverityThresholds returns thresholds that treat under-provisioning more strictly
+// than over-provisioning, since the former affects availability and the latter only cost
+func DefaultSeverityThresholds() SeverityThresholds {
+	return SeverityThresholds{
+		UnderCritical: 50,
+		UnderWarning:  20,
+		OverCritical:  100,
+		OverWarning:   50,
+	}
+}
+
+// Classify returns the severity of a resource: the worst of its CPU and memory classifications.
+// It returns "" when neither dimension has a recommendation to compare against.
+func (t SeverityThresholds) Classify(resource Resource) string {
+	cpu := t.classifyQuantity(resource.Current.CPU, resource.Recommended.CPU, ParseCPU)
+	memory := t.classifyQuantity(resource.Current.Memory, resource.Recommended.Memory, ParseMemory)
+	if severityRank[strings.ToLower(memory)] > severityRank[strings.ToLower(cpu)] {
+		return memory
+	}
+	return cpu
+}
+
+// classifyQuantity classifies one current/recommended pair. A missing request counts as
+// fully under-provisioned, since the container can be scheduled without any reservation.
+func (t SeverityThresholds) classifyQuantity(current, recommended string, parse func(string) (float64, error)) string {
+	if recommended == "" {
+		return ""
+	}
+	recommendedValue, err := parse(recommended)
+	if err != nil || recommendedValue <= 0 {
+		return ""
+	}
+
+	currentValue := 0.0
+	if current != "" {
+		if currentValue, err = parse(current); err != nil {
+			return ""
+		}
+	}
+
+	// Rounded to a millionth of a percent, so a deviation exactly at a threshold (80m against
+	// 100m is 20%) is not pushed over it by floating-point error
+	deviation := math.Round(math.Abs(currentValue-recommendedValue)/recommendedValue*100*1e6) / 1e6
+	critical, warning := t.OverCritical, t.OverWarning
+	if currentValue < recommendedValue {
+		critical, warning = t.UnderCritical, t.UnderWarning
+	}
+
+	switch {
+	case deviation > critical:
+		return SeverityCritical
+	case deviation > warning:
+		return SeverityWarning
+	default:
+		return SeverityOK
+	}
+}
+
+// ClassifySeverity returns a copy of the result with every resource's severity derived from
+// the thresholds instead of KRR's own scoring, and the summary counts recalculated
+func ClassifySeverity(result *ScanResult, thresholds SeverityThresholds) *ScanResult {
+	classified := *result
+	classified.Resources = make([]Resource, len(result.Resources))
+	for i, resource := range result.Resources {
+		if severity := thresholds.Classify(resource); severity != "" {
+			resource.Severity = severity
+		}
+		classified.Resources[i] = resource
+	}
+	classified.Summary = calculateSummary(classified.Resources)
+	return &classified
+}
+
+// MeetsSeverity reports whether severity is at least as severe as minimum (e.g. WARNING
+// meets WARNING and OK, but not CRITICAL)
+func MeetsSeverity(severity, minimum string) bool {
+	return severityRank[strings.ToLower(severity)] >= severityRank[strings.ToLower(minimum)]
+}
+
+// MinimumSeverities are the severities a min_severity filter accepts, most severe first: the
+// levels ClassifySeverity assigns
+var MinimumSeverities = []string{SeverityCritical, SeverityWarning, SeverityOK}
+
+// ValidSeverity reports whether name, in any case, is one of MinimumSeverities
+func ValidSeverity(name string) bool {
+	return slices.Contains(MinimumSeverities, strings.ToUpper(name))
+}
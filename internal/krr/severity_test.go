@@ -0,0 +1,85 @@
+package krr
+
+import "testing"
+
+func TestClassifyThresholdBoundaries(t *testing.T) {
+	// Defaults: under-provisioning is WARNING over 20% and CRITICAL over 50%,
+	// over-provisioning WARNING over 50% and CRITICAL over 100%
+	thresholds := DefaultSeverityThresholds()
+	tests := []struct {
+		name        string
+		current     string
+		recommended string
+		want        string
+	}{
+		{"matches the recommendation", "100m", "100m", SeverityOK},
+
+		{"under: at the warning threshold", "80m", "100m", SeverityOK},
+		{"under: just past the warning threshold", "79m", "100m", SeverityWarning},
+		{"under: at the critical threshold", "50m", "100m", SeverityWarning},
+		{"under: just past the critical threshold", "49m", "100m", SeverityCritical},
+		{"under: no request at all", "", "100m", SeverityCritical},
+
+		{"over: at the warning threshold", "150m", "100m", SeverityOK},
+		{"over: just past the warning threshold", "151m", "100m", SeverityWarning},
+		{"over: at the critical threshold", "200m", "100m", SeverityWarning},
+		{"over: just past the critical threshold", "201m", "100m", SeverityCritical},
+
+		{"no recommendation", "100m", "", ""},
+		{"unparsable current", "lots", "100m", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := Resource{
+				Current:     ResourceRequirements{CPU: tt.current},
+				Recommended: ResourceRequirements{CPU: tt.recommended},
+			}
+			if got := thresholds.Classify(resource); got != tt.want {
+				t.Errorf("Classify(current %q, recommended %q) = %q, want %q", tt.current, tt.recommended, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyTakesTheWorstDimension(t *testing.T) {
+	resource := Resource{
+		Current:     ResourceRequirements{CPU: "100m", Memory: "100Mi"},
+		Recommended: ResourceRequirements{CPU: "100m", Memory: "300Mi"},
+	}
+	if got := DefaultSeverityThresholds().Classify(resource); got != SeverityCritical {
+		t.Errorf("Classify() = %q, want the memory dimension's CRITICAL", got)
+	}
+}
+
+func TestValidSeverity(t *testing.T) {
+	for _, name := range []string{"CRITICAL", "WARNING", "OK", "warning", "Ok"} {
+		if !ValidSeverity(name) {
+			t.Errorf("ValidSeverity(%q) = false, want true", name)
+		}
+	}
+	// KRR's own GOOD and UNKNOWN are never assigned by ClassifySeverity
+	for _, name := range []string{"GOOD", "UNKNOWN", "unknown", "", "HIGH"} {
+		if ValidSeverity(name) {
+			t.Errorf("ValidSeverity(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestMeetsSeverity(t *testing.T) {
+	tests := []struct {
+		severity, minimum string
+		want              bool
+	}{
+		{SeverityCritical, SeverityWarning, true},
+		{SeverityWarning, SeverityWarning, true},
+		{SeverityOK, SeverityWarning, false},
+		{SeverityWarning, SeverityCritical, false},
+		{SeverityOK, SeverityOK, true},
+		{"UNKNOWN", SeverityOK, false},
+	}
+	for _, tt := range tests {
+		if got := MeetsSeverity(tt.severity, tt.minimum); got != tt.want {
+			t.Errorf("MeetsSeverity(%q, %q) = %v, want %v", tt.severity, tt.minimum, got, tt.want)
+		}
+	}
+}
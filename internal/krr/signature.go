@@ -0,0 +1,34 @@
+package krr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// Signature returns a stable hash of a scan's recommendations, for telling cheaply whether two
+// scans recommend the same thing. It covers each container's identity, current and recommended
+// requests and limits and severity, independent of order. Timestamps, pod names, reasons and
+// raw output are left out, so rescans of an unchanged cluster have the same signature.
+func Signature(result *ScanResult) string {
+	lines := make([]string, 0, len(result.Resources))
+	for _, resource := range result.Resources {
+		lines = append(lines, strings.Join([]string{
+			resource.Namespace, resource.Kind, resource.Name, resource.Container,
+			resource.Current.CPU, resource.Current.Memory,
+			resource.Recommended.CPU, resource.Recommended.Memory,
+			resource.CurrentLimits.CPU, resource.CurrentLimits.Memory,
+			resource.RecommendedLimits.CPU, resource.RecommendedLimits.Memory,
+			resource.Severity,
+		}, "\x00"))
+	}
+	slices.Sort(lines)
+
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line))
+		hash.Write([]byte{'\n'})
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
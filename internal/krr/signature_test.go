@@ -0,0 +1,51 @@
+package krr
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSignature(t *testing.T) {
+	web := Resource{Namespace: "shop", Kind: "Deployment", Name: "web", Container: "app", Severity: SeverityWarning,
+		Current: ResourceRequirements{CPU: "500m", Memory: "512Mi"}, Recommended: ResourceRequirements{CPU: "100m", Memory: "256Mi"}}
+	db := Resource{Namespace: "shop", Kind: "StatefulSet", Name: "db", Container: "postgres", Severity: SeverityOK,
+		Current: ResourceRequirements{CPU: "1", Memory: "2Gi"}, Recommended: ResourceRequirements{CPU: "1", Memory: "2Gi"}}
+	base := Signature(&ScanResult{Resources: []Resource{web, db}})
+
+	cosmetic := web
+	cosmetic.Pods = []string{"web-7d9f8b6c4d-2xk8p"}
+	changed := web
+	changed.Recommended.CPU = "200m"
+
+	tests := []struct {
+		name     string
+		result   *ScanResult
+		wantSame bool
+	}{
+		{"different order", &ScanResult{Resources: []Resource{db, web}}, true},
+		{"different timestamp and raw output", &ScanResult{Timestamp: "2024-05-01T10:00:00Z", RawOutput: "{}", Resources: []Resource{web, db}}, true},
+		{"different pods", &ScanResult{Resources: []Resource{cosmetic, db}}, true},
+		{"different recommendation", &ScanResult{Resources: []Resource{changed, db}}, false},
+		{"missing container", &ScanResult{Resources: []Resource{web}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := Signature(tt.result) == base; same != tt.wantSame {
+				t.Errorf("Signature() equal = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestSignatureIgnoresOrderOfManyResources(t *testing.T) {
+	var resources []Resource
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		resources = append(resources, Resource{Namespace: "shop", Kind: "Deployment", Name: name, Recommended: ResourceRequirements{CPU: "100m"}})
+	}
+	want := Signature(&ScanResult{Resources: resources})
+	reversed := slices.Clone(resources)
+	slices.Reverse(reversed)
+	if got := Signature(&ScanResult{Resources: reversed}); got != want {
+		t.Errorf("Signature(reversed) = %s, want %s", got, want)
+	}
+}
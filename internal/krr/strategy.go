@@ -0,0 +1,146 @@
+package krr
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// BuiltinStrategies are the recommendation strategies shipped with KRR
+var BuiltinStrategies = []string{"simple", "simple-limit"}
+
+// strategyNamePattern matches strategy names as KRR registers them as subcommands.
+// It also keeps a name from being mistaken for a flag.
+var strategyNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateStrategy checks a strategy name. Without a custom strategy file only the builtin
+// strategies are accepted; with one, any well-formed name is, since the file registers it.
+func ValidateStrategy(name string, custom bool) error {
+	if !strategyNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid strategy name %q", name)
+	}
+	if !custom && !slices.Contains(BuiltinStrategies, name) {
+		return fmt.Errorf("unknown strategy %q (builtin strategies: %s; set strategy_path to use a custom strategy)", name, strings.Join(BuiltinStrategies, ", "))
+	}
+	return nil
+}
+
+// Strategy describes a recommendation strategy of the installed KRR and the settings it takes
+type Strategy struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Settings    []StrategySetting `json:"settings"`
+}
+
+// StrategySetting is one tunable setting of a strategy, passed to KRR as a flag
+type StrategySetting struct {
+	Flag        string `json:"flag"`
+	Type        string `json:"type,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+var (
+	// helpBoxChars are the box-drawing characters Rich frames KRR's help panels with
+	helpBoxChars = "│╭╮╰╯─┃┏┓┗┛━|"
+
+	// helpColumnSeparator splits a help entry into its columns
+	helpColumnSeparator = regexp.MustCompile(`\s{2,}`)
+
+	// helpDefaultPattern matches the default value Typer appends to a setting's description
+	helpDefaultPattern = regexp.MustCompile(`\s*\[default:\s*([^\]]*)\]`)
+
+	// helpTypePattern matches the value type column of a setting, e.g. TEXT or INTEGER
+	helpTypePattern = regexp.MustCompile(`^[A-Z][A-Z_]*$`)
+)
+
+// helpSections splits KRR's help output into its sections by title, each a list of entry
+// lines with the panel framing removed. It understands both Rich panels ("╭─ Commands ─╮")
+// and plain Typer headings ("Commands:").
+func helpSections(help string) map[string][]string {
+	sections := make(map[string][]string)
+	var current string
+	for _, line := range strings.Split(StripANSI(help), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "╭") || strings.HasPrefix(trimmed, "┏"):
+			current = strings.TrimSpace(strings.Trim(trimmed, helpBoxChars+" "))
+		case strings.HasPrefix(trimmed, "╰") || strings.HasPrefix(trimmed, "┗"):
+			current = ""
+		case strings.HasSuffix(trimmed, ":") && !strings.HasPrefix(line, " "):
+			current = strings.TrimSuffix(trimmed, ":")
+		case current != "":
+			entry := strings.TrimSpace(strings.Trim(trimmed, helpBoxChars))
+			if entry != "" {
+				sections[current] = append(sections[current], entry)
+			}
+		}
+	}
+	return sections
+}
+
+// ParseStrategyCommands returns the strategies listed in the Commands section of `krr --help`,
+// with their one-line descriptions. Commands that are not strategies are left out.
+func ParseStrategyCommands(help string) []Strategy {
+	var strategies []Strategy
+	for _, entry := range helpSections(help)["Commands"] {
+		columns := helpColumnSeparator.Split(entry, 2)
+		if !strategyNamePattern.MatchString(columns[0]) {
+			// A wrapped description continues the previous command's
+			if len(strategies) > 0 {
+				last := &strategies[len(strategies)-1]
+				last.Description = strings.TrimSpace(last.Description + " " + entry)
+			}
+			continue
+		}
+		strategy := Strategy{Name: columns[0], Settings: []StrategySetting{}}
+		if len(columns) > 1 {
+			strategy.Description = columns[1]
+		}
+		strategies = append(strategies, strategy)
+	}
+
+	// KRR registers every strategy as a "Run KRR using the `name` strategy" command, next to
+	// utility commands such as version
+	return slices.DeleteFunc(strategies, func(strategy Strategy) bool {
+		return !slices.Contains(BuiltinStrategies, strategy.Name) && !strings.Contains(strings.ToLower(strategy.Description), "strategy")
+	})
+}
+
+// ParseStrategySettings returns the settings listed in the Strategy Settings section of
+// `krr <strategy> --help`
+func ParseStrategySettings(help string) []StrategySetting {
+	settings := []StrategySetting{}
+	for _, entry := range helpSections(help)["Strategy Settings"] {
+		columns := helpColumnSeparator.Split(entry, -1)
+		if !strings.HasPrefix(columns[0], "--") {
+			// A wrapped description continues the previous setting's
+			if len(settings) > 0 {
+				last := &settings[len(settings)-1]
+				last.Description = strings.TrimSpace(last.Description + " " + entry)
+			}
+			continue
+		}
+		setting := StrategySetting{Flag: strings.Fields(columns[0])[0]}
+		rest := columns[1:]
+		// Boolean settings list their negated flag too, e.g. "--allow_hpa  --no-allow_hpa"
+		for len(rest) > 0 && strings.HasPrefix(rest[0], "-") {
+			rest = rest[1:]
+		}
+		if len(rest) > 0 && helpTypePattern.MatchString(rest[0]) {
+			setting.Type, rest = rest[0], rest[1:]
+		}
+		setting.Description = strings.Join(rest, " ")
+		settings = append(settings, setting)
+	}
+
+	// Defaults are read once descriptions are complete, since they often wrap onto the next line
+	for i := range settings {
+		if match := helpDefaultPattern.FindStringSubmatch(settings[i].Description); match != nil {
+			settings[i].Default = strings.TrimSpace(match[1])
+			settings[i].Description = strings.TrimSpace(helpDefaultPattern.ReplaceAllString(settings[i].Description, ""))
+		}
+	}
+	return settings
+}
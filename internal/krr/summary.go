@@ -0,0 +1,82 @@
+package krr
+
+import "sort"
+
+// SummarizeCluster rolls a scan result up into cluster totals and a per-namespace breakdown
+func SummarizeCluster(result *ScanResult) ClusterSummary {
+	byNamespace := make(map[string][]Resource)
+	for _, resource := range result.Resources {
+		byNamespace[resource.Namespace] = append(byNamespace[resource.Namespace], resource)
+	}
+
+	namespaces := make([]string, 0, len(byNamespace))
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	summary := ClusterSummary{
+		Cluster:    result.Cluster,
+		Timestamp:  result.Timestamp,
+		Totals:     calculateSummary(result.Resources),
+		Namespaces: make([]NamespaceSummary, 0, len(namespaces)),
+	}
+	for _, namespace := range namespaces {
+		summary.Namespaces = append(summary.Namespaces, NamespaceSummary{
+			Namespace: namespace,
+			Summary:   calculateSummary(byNamespace[namespace]),
+		})
+	}
+
+	return summary
+}
+
+// WorkloadSummary is the summary of the containers of a single workload
+type WorkloadSummary struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Summary
+}
+
+// SummarizeWorkloads rolls resources up per workload, in the order workloads first appear
+func SummarizeWorkloads(resources []Resource) []WorkloadSummary {
+	var order []string
+	byWorkload := make(map[string][]Resource)
+	for _, resource := range resources {
+		key := resource.Namespace + "/" + resource.Kind + "/" + resource.Name
+		if _, ok := byWorkload[key]; !ok {
+			order = append(order, key)
+		}
+		byWorkload[key] = append(byWorkload[key], resource)
+	}
+
+	summaries := make([]WorkloadSummary, 0, len(order))
+	for _, key := range order {
+		first := byWorkload[key][0]
+		summaries = append(summaries, WorkloadSummary{
+			Namespace: first.Namespace,
+			Kind:      first.Kind,
+			Name:      first.Name,
+			Summary:   calculateSummary(byWorkload[key]),
+		})
+	}
+	return summaries
+}
+
+// MergeResults combines several scan results into one, concatenating their resources and
+// recalculating the summary. Cluster, timestamp and strategy are taken from the first result.
+func MergeResults(results ...*ScanResult) *ScanResult {
+	merged := &ScanResult{}
+	for i, result := range results {
+		if i == 0 {
+			merged.Timestamp = result.Timestamp
+			merged.Cluster = result.Cluster
+			merged.Strategy = result.Strategy
+			merged.SchemaVersion = result.SchemaVersion
+		}
+		merged.Resources = append(merged.Resources, result.Resources...)
+	}
+	merged.Summary = calculateSummary(merged.Resources)
+	return merged
+}
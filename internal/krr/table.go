@@ -0,0 +1,39 @@
+package krr
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// RenderTable renders parsed recommendations as a compact plain-text table.
+// It is used when output had to be produced from KRR's JSON rather than its own table formatter.
+func RenderTable(resources []Resource) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tKIND\tCONTAINER\tCPU REQUEST\tMEMORY REQUEST\tSEVERITY")
+	for _, resource := range resources {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			resource.Namespace,
+			resource.Name,
+			resource.Kind,
+			resource.Container,
+			formatChange(resource.Current.CPU, resource.Recommended.CPU),
+			formatChange(resource.Current.Memory, resource.Recommended.Memory),
+			resource.Severity,
+		)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// formatChange renders a current -> recommended pair, using "?" for unknown values
+func formatChange(current, recommended string) string {
+	if current == "" {
+		current = "unset"
+	}
+	if recommended == "" {
+		recommended = "?"
+	}
+	return current + " -> " + recommended
+}
@@ -2,6 +2,7 @@ package krr
 
 import (
 	"context"
+	"time"
 )
 
 // OutputFormat defines the format for KRR CLI output
@@ -15,30 +16,62 @@ const (
 
 // ScanOptions represents options for KRR scanning
 type ScanOptions struct {
-	Namespace     string       `json:"namespace,omitempty"`
-	Output        OutputFormat `json:"output,omitempty"`
-	Context       string       `json:"context,omitempty"`
-	ClusterName   string       `json:"cluster_name,omitempty"`
-	Strategy      string       `json:"strategy,omitempty"`
-	CPUMin        string       `json:"cpu_min,omitempty"`
-	CPUMax        string       `json:"cpu_max,omitempty"`
-	MemoryMin     string       `json:"memory_min,omitempty"`
-	MemoryMax     string       `json:"memory_max,omitempty"`
-	RecommendOnly bool         `json:"recommend_only,omitempty"`
-	Verbose       bool         `json:"verbose,omitempty"`
-	NoColor       bool         `json:"no_color,omitempty"`
+	Namespace    string       `json:"namespace,omitempty"`
+	Output       OutputFormat `json:"output,omitempty"`
+	Context      string       `json:"context,omitempty"`
+	ClusterName  string       `json:"cluster_name,omitempty"`
+	Strategy     string       `json:"strategy,omitempty"`
+	StrategyPath string       `json:"strategy_path,omitempty"`
+	CPUMin       string       `json:"cpu_min,omitempty"`
+	CPUMax       string       `json:"cpu_max,omitempty"`
+	MemoryMin    string       `json:"memory_min,omitempty"`
+	MemoryMax    string       `json:"memory_max,omitempty"`
+	Resources    []string     `json:"resources,omitempty"`
+
+	// HistoryDuration is how much Prometheus history KRR analyses (KRR's default when zero)
+	HistoryDuration time.Duration `json:"history_duration,omitempty"`
+
+	// Namespaces scans several namespaces in one run; it is ignored when Namespace is set
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// PrometheusURL points KRR at a specific Prometheus instead of auto-discovering one
+	PrometheusURL string `json:"prometheus_url,omitempty"`
+
+	// PrometheusLabel is the metric label that tells clusters apart in a centralized Prometheus
+	// (e.g. Thanos), and ClusterLabelValue the value of that label selecting this cluster
+	PrometheusLabel   string `json:"prometheus_label,omitempty"`
+	ClusterLabelValue string `json:"cluster_label_value,omitempty"`
+
+	// PrometheusUserAgent is sent as the User-Agent of KRR's Prometheus queries, so they can
+	// be attributed in shared Prometheus logs (KRR's default when empty)
+	PrometheusUserAgent string `json:"prometheus_user_agent,omitempty"`
+
+	// ExtraFlags are additional KRR flags by "--name", checked against the server's allow-list
+	// before they get here; an empty value passes a boolean flag
+	ExtraFlags map[string]string `json:"extra_flags,omitempty"`
+
+	// MaxWorkers bounds KRR's concurrent Prometheus and Kubernetes requests (KRR's default when zero)
+	MaxWorkers int `json:"max_workers,omitempty"`
+
+	RecommendOnly bool `json:"recommend_only,omitempty"`
+	Verbose       bool `json:"verbose,omitempty"`
+	NoColor       bool `json:"no_color,omitempty"`
 }
 
-// Resource represents a Kubernetes resource with recommendations
+// Resource represents a Kubernetes resource with recommendations.
+// Current and Recommended describe resource requests; limits are reported separately.
 type Resource struct {
-	Name      string                 `json:"name"`
-	Namespace string                 `json:"namespace"`
-	Kind      string                 `json:"kind"`
-	Container string                 `json:"container,omitempty"`
-	Current   ResourceRequirements   `json:"current"`
-	Recommended ResourceRequirements `json:"recommended"`
-	Severity  string                 `json:"severity"`
-	Reason    string                 `json:"reason"`
+	Name              string               `json:"name"`
+	Namespace         string               `json:"namespace"`
+	Kind              string               `json:"kind"`
+	Container         string               `json:"container,omitempty"`
+	Pods              []string             `json:"pods,omitempty"`
+	Current           ResourceRequirements `json:"current"`
+	Recommended       ResourceRequirements `json:"recommended"`
+	CurrentLimits     ResourceRequirements `json:"current_limits,omitzero"`
+	RecommendedLimits ResourceRequirements `json:"recommended_limits,omitzero"`
+	Severity          string               `json:"severity"`
+	Reason            string               `json:"reason"`
 }
 
 // ResourceRequirements represents CPU and memory requirements
@@ -47,36 +80,88 @@ type ResourceRequirements struct {
 	Memory string `json:"memory,omitempty"`
 }
 
+// StrategyInfo describes the strategy KRR used to compute recommendations
+type StrategyInfo struct {
+	Name        string         `json:"name,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Settings    map[string]any `json:"settings,omitempty"`
+}
+
 // ScanResult represents the result of a KRR scan
 type ScanResult struct {
-	Timestamp   string     `json:"timestamp"`
-	Cluster     string     `json:"cluster"`
-	Resources   []Resource `json:"resources"`
-	Summary     Summary    `json:"summary"`
-	RawOutput   string     `json:"raw_output,omitempty"`
+	Timestamp string       `json:"timestamp"`
+	Cluster   string       `json:"cluster"`
+	Strategy  StrategyInfo `json:"strategy,omitzero"`
+	Resources []Resource   `json:"resources"`
+	Summary   Summary      `json:"summary"`
+	RawOutput string       `json:"raw_output,omitempty"`
+
+	// SchemaVersion is the KRR JSON output shape detected by ParseJSON (e.g. "v2")
+	SchemaVersion string `json:"schema_version,omitempty"`
+
+	// ParseError is why KRR's JSON output could not be parsed; the result then only carries
+	// RawOutput, which usually means KRR changed its output format
+	ParseError string `json:"parse_error,omitempty"`
+
+	// VerboseOutput holds KRR's stderr log output when the scan ran in verbose mode
+	VerboseOutput string `json:"verbose_output,omitempty"`
+
+	// Prometheus is the metrics service KRR reported discovering, if it logged one
+	Prometheus *PrometheusDiscovery `json:"prometheus,omitempty"`
 }
 
-// Summary provides an overview of the scan results
+// Summary provides an overview of the scan results.
+// Resource totals only include containers where both current and recommended requests are known.
 type Summary struct {
-	TotalResources       int `json:"total_resources"`
+	TotalResources               int `json:"total_resources"`
 	ResourcesWithRecommendations int `json:"resources_with_recommendations"`
-	CriticalSeverity     int `json:"critical_severity"`
-	HighSeverity         int `json:"high_severity"`
-	MediumSeverity       int `json:"medium_severity"`
-	LowSeverity          int `json:"low_severity"`
+	CriticalSeverity             int `json:"critical_severity"`
+	HighSeverity                 int `json:"high_severity"`
+	MediumSeverity               int `json:"medium_severity"`
+	LowSeverity                  int `json:"low_severity"`
+	WarningSeverity              int `json:"warning_severity"`
+	OKSeverity                   int `json:"ok_severity"`
+
+	CurrentCPUCores        float64 `json:"current_cpu_cores"`
+	RecommendedCPUCores    float64 `json:"recommended_cpu_cores"`
+	ReclaimableCPUCores    float64 `json:"reclaimable_cpu_cores"`
+	CurrentMemoryBytes     float64 `json:"current_memory_bytes"`
+	RecommendedMemoryBytes float64 `json:"recommended_memory_bytes"`
+	ReclaimableMemoryBytes float64 `json:"reclaimable_memory_bytes"`
+
+	// Estimated utilization is recommended / current requests (1.0 means requests match usage)
+	CPUUtilization    float64 `json:"cpu_utilization"`
+	MemoryUtilization float64 `json:"memory_utilization"`
+}
+
+// NamespaceSummary is the summary of a single namespace within a cluster
+type NamespaceSummary struct {
+	Namespace string `json:"namespace"`
+	Summary
+}
+
+// ClusterSummary rolls scan results up to cluster totals with a per-namespace breakdown
+type ClusterSummary struct {
+	Cluster    string             `json:"cluster,omitempty"`
+	Timestamp  string             `json:"timestamp"`
+	Totals     Summary            `json:"totals"`
+	Namespaces []NamespaceSummary `json:"namespaces"`
 }
 
 // Executor defines the interface for executing KRR CLI commands
 type Executor interface {
 	// Scan executes a KRR scan with the provided options
 	Scan(ctx context.Context, options ScanOptions) (*ScanResult, error)
-	
+
 	// ValidateInstallation checks if KRR CLI is properly installed and accessible
 	ValidateInstallation(ctx context.Context) error
-	
+
 	// GetVersion returns the version of the installed KRR CLI
 	GetVersion(ctx context.Context) (string, error)
-	
+
 	// ListStrategies returns available recommendation strategies
 	ListStrategies(ctx context.Context) ([]string, error)
-}
\ No newline at end of file
+
+	// DescribeStrategies returns the installed KRR's strategies with their tunable settings
+	DescribeStrategies(ctx context.Context) ([]Strategy, error)
+}
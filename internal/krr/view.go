@@ -0,0 +1,49 @@
+package krr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// View selects which resources a scan reports
+type View string
+
+const (
+	// ViewAll reports every scanned container
+	ViewAll View = "all"
+
+	// ViewRecommendOnly reports containers whose recommendation differs from their current requests or limits
+	ViewRecommendOnly View = "recommend-only"
+
+	// ViewProblems is recommend-only plus containers at WARNING or above and containers KRR could
+	// not compute a recommendation for (usually too little history), which may be under-provisioned
+	ViewProblems View = "problems"
+)
+
+// ParseView validates a view name
+func ParseView(name string) (View, error) {
+	switch view := View(strings.ToLower(strings.TrimSpace(name))); view {
+	case ViewAll, ViewRecommendOnly, ViewProblems:
+		return view, nil
+	}
+	return "", fmt.Errorf("must be 'all', 'recommend-only' or 'problems'")
+}
+
+// Keep reports whether the view includes the resource
+func (v View) Keep(resource Resource) bool {
+	switch v {
+	case ViewRecommendOnly:
+		return HasChanges(resource)
+	case ViewProblems:
+		return HasChanges(resource) || MeetsSeverity(resource.Severity, "WARNING") ||
+			(resource.Recommended.CPU == "" && resource.Recommended.Memory == "")
+	default:
+		return true
+	}
+}
+
+// HasChanges reports whether KRR recommends changing any request or limit of the resource
+func HasChanges(resource Resource) bool {
+	delta := ComputeDelta(&ScanResult{Resources: []Resource{resource}})
+	return len(delta.Containers) > 0
+}
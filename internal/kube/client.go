@@ -0,0 +1,291 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Client queries the cluster through the kubectl CLI
+type Client struct {
+	kubectlPath    string
+	kubeconfigData string
+	kubeconfigPath string
+}
+
+// ClientOption configures optional Client behaviour
+type ClientOption func(*Client)
+
+// WithClientKubeconfigData makes kubectl use inline kubeconfig data (raw or base64 YAML)
+func WithClientKubeconfigData(data string) ClientOption {
+	return func(c *Client) {
+		c.kubeconfigData = data
+	}
+}
+
+// WithClientKubeconfigPath makes kubectl use the kubeconfig file at path. Inline kubeconfig
+// data takes precedence.
+func WithClientKubeconfigPath(path string) ClientOption {
+	return func(c *Client) {
+		c.kubeconfigPath = path
+	}
+}
+
+// NewClient creates a client that shells out to the given kubectl executable
+func NewClient(kubectlPath string, opts ...ClientOption) *Client {
+	if kubectlPath == "" {
+		kubectlPath = "kubectl"
+	}
+	client := &Client{kubectlPath: kubectlPath}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
+}
+
+// run executes kubectl against the given context and returns its stdout
+func (c *Client) run(ctx context.Context, kubeContext string, args ...string) ([]byte, error) {
+	if kubeContext != "" {
+		args = append([]string{"--context", kubeContext}, args...)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.kubectlPath, args...)
+	cmd.Stderr = &stderr
+	if c.kubeconfigData != "" {
+		path, cleanup, err := MaterializeKubeconfig(c.kubeconfigData)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		cmd.Env = append(os.Environ(), "KUBECONFIG="+path)
+	} else if c.kubeconfigPath != "" {
+		cmd.Env = append(os.Environ(), "KUBECONFIG="+c.kubeconfigPath)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("kubectl %s failed with exit code %d: %s", args[0], exitErr.ExitCode(), strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("failed to execute kubectl: %w", err)
+	}
+	return output, nil
+}
+
+// CurrentContext returns the kubeconfig's current context, failing when it is unset or names a
+// context the kubeconfig does not define. It only reads the kubeconfig, never the cluster.
+func (c *Client) CurrentContext(ctx context.Context) (string, error) {
+	output, err := c.run(ctx, "", "config", "view", "--minify", "-o", "jsonpath={.current-context}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Context is a kubeconfig context and the API server endpoint of its cluster
+type Context struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	Server    string `json:"server,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Current   bool   `json:"current,omitempty"`
+}
+
+// kubeconfigView is the subset of `kubectl config view -o json` used here
+type kubeconfigView struct {
+	CurrentContext string `json:"current-context"`
+	Contexts       []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster   string `json:"cluster"`
+			Namespace string `json:"namespace"`
+		} `json:"context"`
+	} `json:"contexts"`
+	Clusters []struct {
+		Name    string `json:"name"`
+		Cluster struct {
+			Server string `json:"server"`
+		} `json:"cluster"`
+	} `json:"clusters"`
+}
+
+// Contexts returns the contexts of the kubeconfig, merged across the files KUBECONFIG lists.
+// It only reads the kubeconfig, never the cluster, and kubectl redacts the credentials.
+func (c *Client) Contexts(ctx context.Context) ([]Context, error) {
+	output, err := c.run(ctx, "", "config", "view", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+	var view kubeconfigView
+	if err := json.Unmarshal(output, &view); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	servers := make(map[string]string, len(view.Clusters))
+	for _, cluster := range view.Clusters {
+		servers[cluster.Name] = cluster.Cluster.Server
+	}
+	contexts := make([]Context, 0, len(view.Contexts))
+	for _, entry := range view.Contexts {
+		contexts = append(contexts, Context{
+			Name:      entry.Name,
+			Cluster:   entry.Context.Cluster,
+			Server:    servers[entry.Context.Cluster],
+			Namespace: entry.Context.Namespace,
+			Current:   entry.Name == view.CurrentContext,
+		})
+	}
+	return contexts, nil
+}
+
+// ServerVersion returns the Kubernetes version of the cluster's API server, which proves the
+// cluster is reachable with the kubeconfig's credentials
+func (c *Client) ServerVersion(ctx context.Context, kubeContext string) (string, error) {
+	output, err := c.run(ctx, kubeContext, "version", "-o", "json")
+	if err != nil {
+		return "", err
+	}
+	var version struct {
+		ServerVersion *struct {
+			GitVersion string `json:"gitVersion"`
+		} `json:"serverVersion"`
+	}
+	if err := json.Unmarshal(output, &version); err != nil {
+		return "", fmt.Errorf("failed to parse kubectl version: %w", err)
+	}
+	if version.ServerVersion == nil {
+		return "", fmt.Errorf("kubectl did not report a server version")
+	}
+	return version.ServerVersion.GitVersion, nil
+}
+
+// Get returns a workload object as JSON
+func (c *Client) Get(ctx context.Context, kubeContext, namespace, kind, name string) ([]byte, error) {
+	return c.run(ctx, kubeContext, "get", strings.ToLower(kind), name, "--namespace", namespace, "-o", "json")
+}
+
+// Patch applies a strategic merge patch to a workload. With dryRun the API server validates
+// the patch, admission included, without persisting it.
+func (c *Client) Patch(ctx context.Context, kubeContext, namespace, kind, name string, patch []byte, dryRun bool) error {
+	args := []string{"patch", strings.ToLower(kind), name, "--namespace", namespace, "--type", "strategic", "--patch", string(patch)}
+	if dryRun {
+		args = append(args, "--dry-run=server")
+	}
+	_, err := c.run(ctx, kubeContext, args...)
+	return err
+}
+
+// NodeNames returns the names of the nodes matching a label selector
+func (c *Client) NodeNames(ctx context.Context, kubeContext, selector string) ([]string, error) {
+	output, err := c.run(ctx, kubeContext, "get", "nodes", "-l", selector, "-o", "jsonpath={.items[*].metadata.name}")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// NamespaceNames returns the names of the namespaces matching a label selector
+func (c *Client) NamespaceNames(ctx context.Context, kubeContext, selector string) ([]string, error) {
+	output, err := c.run(ctx, kubeContext, "get", "namespaces", "-l", selector, "-o", "jsonpath={.items[*].metadata.name}")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// Namespace is a namespace as reported by kubectl
+type Namespace struct {
+	Name   string            `json:"name"`
+	Status string            `json:"status"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// namespaceList is the subset of `kubectl get namespaces -o json` used here
+type namespaceList struct {
+	Items []struct {
+		Metadata struct {
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Status struct {
+			Phase string `json:"phase"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// Namespaces returns the namespaces matching a label selector, or every namespace for an
+// empty selector
+func (c *Client) Namespaces(ctx context.Context, kubeContext, selector string) ([]Namespace, error) {
+	args := []string{"get", "namespaces", "-o", "json"}
+	if selector != "" {
+		args = append(args, "-l", selector)
+	}
+	output, err := c.run(ctx, kubeContext, args...)
+	if err != nil {
+		return nil, err
+	}
+	var list namespaceList
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse namespace list: %w", err)
+	}
+
+	namespaces := make([]Namespace, 0, len(list.Items))
+	for _, item := range list.Items {
+		namespaces = append(namespaces, Namespace{
+			Name:   item.Metadata.Name,
+			Status: item.Status.Phase,
+			Labels: item.Metadata.Labels,
+		})
+	}
+	return namespaces, nil
+}
+
+// podList is the subset of `kubectl get pods -o json` used here
+type podList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Spec struct {
+			NodeName string `json:"nodeName"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// PodsOnNodes returns the set of pods, keyed as "namespace/name", scheduled on nodes matching the selector
+func (c *Client) PodsOnNodes(ctx context.Context, kubeContext, nodeSelector string) (map[string]bool, error) {
+	nodes, err := c.NodeNames(ctx, kubeContext, nodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	nodeSet := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		nodeSet[node] = true
+	}
+
+	pods := make(map[string]bool)
+	if len(nodeSet) == 0 {
+		return pods, nil
+	}
+
+	output, err := c.run(ctx, kubeContext, "get", "pods", "--all-namespaces", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+	var list podList
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse pod list: %w", err)
+	}
+
+	for _, pod := range list.Items {
+		if nodeSet[pod.Spec.NodeName] {
+			pods[pod.Metadata.Namespace+"/"+pod.Metadata.Name] = true
+		}
+	}
+	return pods, nil
+}
@@ -0,0 +1,69 @@
+package kube
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DecodeKubeconfig returns kubeconfig YAML from data that is either raw YAML or base64-encoded YAML
+func DecodeKubeconfig(data string) []byte {
+	trimmed := strings.TrimSpace(data)
+	if decoded, err := base64.StdEncoding.DecodeString(trimmed); err == nil && looksLikeKubeconfig(string(decoded)) {
+		return decoded
+	}
+	return []byte(data)
+}
+
+// looksLikeKubeconfig reports whether text plausibly contains a kubeconfig document
+func looksLikeKubeconfig(text string) bool {
+	return strings.Contains(text, "apiVersion") || strings.Contains(text, "clusters:") || strings.Contains(text, "contexts:")
+}
+
+// HasKubeconfig reports whether a kubeconfig is in play: inline data, $KUBECONFIG or
+// ~/.kube/config. Without one, KRR falls back to in-cluster service account credentials.
+func HasKubeconfig(data string) bool {
+	if data != "" || os.Getenv("KUBECONFIG") != "" {
+		return true
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(home, ".kube", "config"))
+	return err == nil
+}
+
+// MaterializeKubeconfig writes kubeconfig data to a private temporary file (mode 0600) and
+// returns its path together with a cleanup function that removes it. Every call creates a
+// distinct file, so concurrent scans never share or delete each other's kubeconfig.
+func MaterializeKubeconfig(data string) (string, func(), error) {
+	file, err := os.CreateTemp("", "greenops-kubeconfig-*.yaml")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create kubeconfig file: %w", err)
+	}
+	path := file.Name()
+	cleanup := func() {
+		os.Remove(path)
+	}
+
+	// CreateTemp already uses 0600, but be explicit in case of an unusual umask or platform
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to secure kubeconfig file: %w", err)
+	}
+	if _, err := file.Write(DecodeKubeconfig(data)); err != nil {
+		file.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write kubeconfig file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write kubeconfig file: %w", err)
+	}
+
+	return path, cleanup, nil
+}
@@ -0,0 +1,99 @@
+package kube
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// labelKeyPattern matches an optional DNS subdomain prefix followed by a label name
+	labelKeyPattern = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+
+	// labelValuePattern matches a label value, which may be empty
+	labelValuePattern = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?)?$`)
+
+	// setRequirementPattern matches "key in (a,b)" and "key notin (a,b)"
+	setRequirementPattern = regexp.MustCompile(`^(\S+)\s+(in|notin)\s+\(([^()]*)\)$`)
+)
+
+// ValidateLabelSelector checks that a selector follows Kubernetes label selector syntax
+// (e.g. "pool=spot,tier!=batch,zone in (a,b),!legacy")
+func ValidateLabelSelector(selector string) error {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return fmt.Errorf("selector cannot be empty")
+	}
+
+	for _, requirement := range splitRequirements(selector) {
+		if err := validateRequirement(strings.TrimSpace(requirement)); err != nil {
+			return fmt.Errorf("invalid selector %q: %w", selector, err)
+		}
+	}
+	return nil
+}
+
+// splitRequirements splits a selector on commas that are not inside a value set
+func splitRequirements(selector string) []string {
+	var requirements []string
+	depth, start := 0, 0
+	for i, r := range selector {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				requirements = append(requirements, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(requirements, selector[start:])
+}
+
+// validateRequirement validates a single selector requirement
+func validateRequirement(requirement string) error {
+	if requirement == "" {
+		return fmt.Errorf("empty requirement")
+	}
+
+	if match := setRequirementPattern.FindStringSubmatch(requirement); match != nil {
+		if err := validateKey(match[1]); err != nil {
+			return err
+		}
+		for _, value := range strings.Split(match[3], ",") {
+			if err := validateValue(strings.TrimSpace(value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, operator := range []string{"!=", "==", "="} {
+		if key, value, found := strings.Cut(requirement, operator); found {
+			if err := validateKey(strings.TrimSpace(key)); err != nil {
+				return err
+			}
+			return validateValue(strings.TrimSpace(value))
+		}
+	}
+
+	// Existence checks: "key" or "!key"
+	return validateKey(strings.TrimPrefix(requirement, "!"))
+}
+
+func validateKey(key string) error {
+	if !labelKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	return nil
+}
+
+func validateValue(value string) error {
+	if !labelValuePattern.MatchString(value) {
+		return fmt.Errorf("invalid label value %q", value)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultValuePath is where charts commonly take the resources of their main container
+const DefaultValuePath = "resources"
+
+// valuePathVariables are the placeholders a value path may use
+var valuePathVariables = []string{"container", "workload", "component", "release"}
+
+// HelmRelease is the Helm release a workload belongs to
+type HelmRelease struct {
+	Name      string
+	Namespace string
+
+	// Chart is the chart name without its version, and Component the workload's
+	// app.kubernetes.io/component label; both may be empty
+	Chart     string
+	Component string
+}
+
+// chartPattern splits the helm.sh/chart label into the chart name and its version
+var chartPattern = regexp.MustCompile(`^(.+?)-v?[0-9]+\.[0-9]+.*$`)
+
+// ReleaseOf returns the Helm release of a live object, from the annotations Helm 3 sets on
+// every resource it installs, or else the labels Helm charts conventionally set
+func ReleaseOf(object map[string]any) (HelmRelease, bool) {
+	metadata, _ := object["metadata"].(map[string]any)
+	annotations, _ := metadata["annotations"].(map[string]any)
+	labels, _ := metadata["labels"].(map[string]any)
+	text := func(values map[string]any, key string) string {
+		value, _ := values[key].(string)
+		return value
+	}
+
+	release := HelmRelease{
+		Name:      text(annotations, "meta.helm.sh/release-name"),
+		Namespace: text(annotations, "meta.helm.sh/release-namespace"),
+		Component: text(labels, "app.kubernetes.io/component"),
+	}
+	if release.Name == "" && strings.EqualFold(text(labels, "app.kubernetes.io/managed-by"), "Helm") {
+		release.Name = text(labels, "app.kubernetes.io/instance")
+	}
+	if release.Name == "" {
+		return HelmRelease{}, false
+	}
+	if release.Namespace == "" {
+		release.Namespace = text(metadata, "namespace")
+	}
+	release.Chart = text(labels, "helm.sh/chart")
+	if match := chartPattern.FindStringSubmatch(release.Chart); match != nil {
+		release.Chart = match[1]
+	}
+	return release, true
+}
+
+// ExpandValuePath fills the placeholders of a dot-separated value path, such as
+// "{component}.resources", and returns its keys
+func ExpandValuePath(template string, values map[string]string) ([]string, error) {
+	if strings.TrimSpace(template) == "" {
+		return nil, fmt.Errorf("value path is empty")
+	}
+	var keys []string
+	for _, segment := range strings.Split(template, ".") {
+		for _, variable := range valuePathVariables {
+			placeholder := "{" + variable + "}"
+			if !strings.Contains(segment, placeholder) {
+				continue
+			}
+			if values[variable] == "" {
+				return nil, fmt.Errorf("value path %q uses %s, which is not known for this workload", template, placeholder)
+			}
+			segment = strings.ReplaceAll(segment, placeholder, values[variable])
+		}
+		if segment == "" {
+			return nil, fmt.Errorf("value path %q has an empty key", template)
+		}
+		if strings.ContainsAny(segment, "{}") {
+			return nil, fmt.Errorf("value path %q has an unknown placeholder; use %s", template, placeholderList())
+		}
+		keys = append(keys, segment)
+	}
+	return keys, nil
+}
+
+// ValidateValuePath checks the syntax of a value path
+func ValidateValuePath(template string) error {
+	values := make(map[string]string, len(valuePathVariables))
+	for _, variable := range valuePathVariables {
+		values[variable] = variable
+	}
+	_, err := ExpandValuePath(template, values)
+	return err
+}
+
+// placeholderList returns the placeholders a value path may use
+func placeholderList() string {
+	placeholders := make([]string, len(valuePathVariables))
+	for i, variable := range valuePathVariables {
+		placeholders[i] = "{" + variable + "}"
+	}
+	return strings.Join(placeholders, ", ")
+}
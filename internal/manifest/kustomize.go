@@ -0,0 +1,55 @@
+package manifest
+
+import (
+	"strings"
+
+	"greenops-mcp/internal/krr"
+)
+
+// Component is a Kustomize component, which overlays include with components: to apply its
+// patches on top of their resources
+type Component struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Patches    []KustomizePatch `json:"patches"`
+}
+
+// KustomizePatch is an entry of the patches field of a kustomization or component
+type KustomizePatch struct {
+	Path   string           `json:"path"`
+	Target *KustomizeTarget `json:"target,omitempty"`
+}
+
+// KustomizeTarget selects the object a JSON 6902 patch applies to
+type KustomizeTarget struct {
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+}
+
+// NewComponent returns a component applying the given patches
+func NewComponent(patches []KustomizePatch) Component {
+	return Component{APIVersion: "kustomize.config.k8s.io/v1alpha1", Kind: "Component", Patches: patches}
+}
+
+// NewTarget returns the target of a JSON 6902 patch of a workload with the given API version.
+// Like StrategicMergeFile, it leaves the namespace out, which bases often do not set either.
+func NewTarget(apiVersion, kind, name string) *KustomizeTarget {
+	group, version, found := strings.Cut(apiVersion, "/")
+	if !found {
+		group, version = "", apiVersion
+	}
+	return &KustomizeTarget{Group: group, Version: version, Kind: kind, Name: name}
+}
+
+// StrategicMergeFile returns a strategic merge patch as Kustomize expects it in a patch file,
+// with the type and name of the object it applies to. The namespace is left out, so the patch
+// also matches bases that leave it to the overlay.
+func StrategicMergeFile(patch krr.WorkloadPatch, apiVersion string) map[string]any {
+	document := patch.StrategicMergePatch()
+	document["apiVersion"] = apiVersion
+	document["kind"] = patch.Kind
+	document["metadata"] = map[string]any{"name": patch.Name}
+	return document
+}
@@ -0,0 +1,89 @@
+package manifest
+
+import "strings"
+
+// VPA update modes
+const (
+	UpdateModeOff     = "Off"     // only compute recommendations
+	UpdateModeInitial = "Initial" // set resources when pods are created
+	UpdateModeAuto    = "Auto"    // also evict pods whose resources drift from the recommendation
+)
+
+// VerticalPodAutoscaler is an autoscaling.k8s.io/v1 VerticalPodAutoscaler, with fields in the
+// order kubectl prints them
+type VerticalPodAutoscaler struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Spec       VPASpec    `json:"spec"`
+}
+
+// ObjectMeta is the metadata of a generated object
+type ObjectMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// VPASpec is the spec of a VerticalPodAutoscaler
+type VPASpec struct {
+	TargetRef      TargetRef         `json:"targetRef"`
+	UpdatePolicy   VPAUpdatePolicy   `json:"updatePolicy"`
+	ResourcePolicy VPAResourcePolicy `json:"resourcePolicy,omitzero"`
+}
+
+// TargetRef references the workload a VerticalPodAutoscaler scales
+type TargetRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// VPAUpdatePolicy sets how a VerticalPodAutoscaler applies its recommendations
+type VPAUpdatePolicy struct {
+	UpdateMode string `json:"updateMode"`
+}
+
+// VPAResourcePolicy bounds the recommendations of a VerticalPodAutoscaler per container
+type VPAResourcePolicy struct {
+	ContainerPolicies []VPAContainerPolicy `json:"containerPolicies,omitempty"`
+}
+
+// VPAContainerPolicy is the policy of one container, or of every other container for "*"
+type VPAContainerPolicy struct {
+	ContainerName       string            `json:"containerName"`
+	Mode                string            `json:"mode,omitempty"`
+	ControlledResources []string          `json:"controlledResources,omitempty"`
+	ControlledValues    string            `json:"controlledValues,omitempty"`
+	MinAllowed          map[string]string `json:"minAllowed,omitempty"`
+	MaxAllowed          map[string]string `json:"maxAllowed,omitempty"`
+}
+
+// workloadAPIVersions are the API versions of the built-in workload kinds
+var workloadAPIVersions = map[string]string{
+	"deployment":  "apps/v1",
+	"statefulset": "apps/v1",
+	"daemonset":   "apps/v1",
+	"replicaset":  "apps/v1",
+	"cronjob":     "batch/v1",
+	"job":         "batch/v1",
+}
+
+// WorkloadAPIVersion returns the API version of a workload kind, and whether it is a built-in kind
+func WorkloadAPIVersion(kind string) (string, bool) {
+	version, ok := workloadAPIVersions[strings.ToLower(kind)]
+	return version, ok
+}
+
+// NewVPA returns a VerticalPodAutoscaler named after the workload it targets
+func NewVPA(namespace, kind, name, apiVersion, updateMode string, policies []VPAContainerPolicy) VerticalPodAutoscaler {
+	return VerticalPodAutoscaler{
+		APIVersion: "autoscaling.k8s.io/v1",
+		Kind:       "VerticalPodAutoscaler",
+		Metadata:   ObjectMeta{Name: name, Namespace: namespace},
+		Spec: VPASpec{
+			TargetRef:      TargetRef{APIVersion: apiVersion, Kind: kind, Name: name},
+			UpdatePolicy:   VPAUpdatePolicy{UpdateMode: updateMode},
+			ResourcePolicy: VPAResourcePolicy{ContainerPolicies: policies},
+		},
+	}
+}
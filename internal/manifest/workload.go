@@ -0,0 +1,146 @@
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+)
+
+// serverMetadata are metadata fields the API server maintains, which do not belong in a
+// manifest meant to be committed
+var serverMetadata = []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation", "selfLink"}
+
+// serverAnnotations are annotations written by kubectl and controllers
+var serverAnnotations = []string{"kubectl.kubernetes.io/last-applied-configuration", "deployment.kubernetes.io/revision"}
+
+// Clean strips the status and the server-maintained metadata from a live object, leaving the
+// manifest its owner would write
+func Clean(object map[string]any) {
+	delete(object, "status")
+	metadata, _ := object["metadata"].(map[string]any)
+	if metadata == nil {
+		return
+	}
+	for _, field := range serverMetadata {
+		delete(metadata, field)
+	}
+	if annotations, ok := metadata["annotations"].(map[string]any); ok {
+		for _, annotation := range serverAnnotations {
+			delete(annotations, annotation)
+		}
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+	}
+}
+
+// podSpec returns the pod spec of a workload object
+func podSpec(object map[string]any, kind string) (map[string]any, error) {
+	path, ok := krr.PodSpecPath(kind)
+	if !ok {
+		return nil, fmt.Errorf("%s workloads have no supported pod template", kind)
+	}
+	current := object
+	for _, field := range path {
+		next, ok := current[field].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("the %s has no %s", kind, strings.Join(path, "."))
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// containerIndex returns the position of the named container in a pod spec
+func containerIndex(spec map[string]any, name string) (int, map[string]any, error) {
+	containers, _ := spec["containers"].([]any)
+	for i, item := range containers {
+		if container, ok := item.(map[string]any); ok && container["name"] == name {
+			return i, container, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("no container named %q", name)
+}
+
+// SetResources writes the patched resources into the containers of a live workload object
+func SetResources(object map[string]any, patch krr.WorkloadPatch) error {
+	spec, err := podSpec(object, patch.Kind)
+	if err != nil {
+		return err
+	}
+	for _, resources := range patch.Containers {
+		_, container, err := containerIndex(spec, resources.Name)
+		if err != nil {
+			return err
+		}
+		current, _ := container["resources"].(map[string]any)
+		if current == nil {
+			current = map[string]any{}
+			container["resources"] = current
+		}
+		for section, values := range map[string]map[string]string{"requests": resources.Requests, "limits": resources.Limits} {
+			if len(values) == 0 {
+				continue
+			}
+			target, _ := current[section].(map[string]any)
+			if target == nil {
+				target = map[string]any{}
+				current[section] = target
+			}
+			for resource, quantity := range values {
+				target[resource] = quantity
+			}
+		}
+	}
+	return nil
+}
+
+// Operation is one RFC 6902 JSON patch operation
+type Operation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// JSONPatch returns the RFC 6902 operations that set the patched resources on a live workload
+// object. Containers are addressed by their position in the live object, and missing
+// resources, requests or limits objects are added before their fields.
+func JSONPatch(object map[string]any, patch krr.WorkloadPatch) ([]Operation, error) {
+	spec, err := podSpec(object, patch.Kind)
+	if err != nil {
+		return nil, err
+	}
+	path, _ := krr.PodSpecPath(patch.Kind)
+
+	var operations []Operation
+	for _, resources := range patch.Containers {
+		index, container, err := containerIndex(spec, resources.Name)
+		if err != nil {
+			return nil, err
+		}
+		base := "/" + strings.Join(path, "/") + "/containers/" + strconv.Itoa(index) + "/resources"
+		current, _ := container["resources"].(map[string]any)
+		if current == nil {
+			operations = append(operations, Operation{Op: "add", Path: base, Value: map[string]any{}})
+		}
+		for _, section := range []struct {
+			name   string
+			values map[string]string
+		}{{"requests", resources.Requests}, {"limits", resources.Limits}} {
+			if len(section.values) == 0 {
+				continue
+			}
+			if _, ok := current[section.name].(map[string]any); !ok {
+				operations = append(operations, Operation{Op: "add", Path: base + "/" + section.name, Value: map[string]any{}})
+			}
+			for _, resource := range []string{"cpu", "memory"} {
+				if quantity, ok := section.values[resource]; ok {
+					operations = append(operations, Operation{Op: "add", Path: base + "/" + section.name + "/" + resource, Value: quantity})
+				}
+			}
+		}
+	}
+	return operations, nil
+}
@@ -0,0 +1,184 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// node is a decoded JSON value that keeps the order of object keys
+type node struct {
+	keys   []string // object keys, in document order
+	fields []*node  // object values, by position of their key
+	items  []*node  // array items
+	scalar string   // YAML form of a string, number, boolean or null
+	kind   byte     // '{', '[' or 0 for scalars
+}
+
+// ToYAML converts a JSON document to block-style YAML, keeping the order of object keys.
+// Lists are indented like kubectl does, at the level of their key.
+func ToYAML(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	root, err := decodeNode(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert JSON to YAML: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("failed to convert JSON to YAML: trailing data after the document")
+	}
+
+	var b strings.Builder
+	switch {
+	case root.kind == 0:
+		b.WriteString(root.scalar + "\n")
+	case root.empty():
+		b.WriteString(root.flow() + "\n")
+	default:
+		writeBlock(&b, root, 0)
+	}
+	return []byte(b.String()), nil
+}
+
+// MarshalYAML encodes a value as JSON, then converts it to YAML. Struct fields keep their
+// declaration order and map keys are sorted, as encoding/json does.
+func MarshalYAML(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return ToYAML(data)
+}
+
+// decodeNode reads the next JSON value
+func decodeNode(decoder *json.Decoder) (*node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch value := token.(type) {
+	case json.Delim:
+		n := &node{kind: byte(value)}
+		for decoder.More() {
+			if n.kind == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				field, err := decodeNode(decoder)
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, key.(string))
+				n.fields = append(n.fields, field)
+				continue
+			}
+			item, err := decodeNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+		}
+		if _, err := decoder.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &node{scalar: quoteString(value)}, nil
+	case json.Number:
+		return &node{scalar: value.String()}, nil
+	case bool:
+		return &node{scalar: fmt.Sprint(value)}, nil
+	case nil:
+		return &node{scalar: "null"}, nil
+	}
+	return nil, fmt.Errorf("unexpected JSON token %v", token)
+}
+
+// empty reports whether n is an empty object or array
+func (n *node) empty() bool {
+	return n.kind != 0 && len(n.keys) == 0 && len(n.items) == 0
+}
+
+// flow returns the inline form of a scalar or an empty collection
+func (n *node) flow() string {
+	switch {
+	case n.kind == '{':
+		return "{}"
+	case n.kind == '[':
+		return "[]"
+	}
+	return n.scalar
+}
+
+// writeBlock writes a non-empty object or array at the given indentation
+func writeBlock(b *strings.Builder, n *node, indent int) {
+	pad := strings.Repeat(" ", indent)
+	if n.kind == '{' {
+		for i, key := range n.keys {
+			writeEntry(b, pad, quoteString(key)+":", n.fields[i], indent)
+		}
+		return
+	}
+	for _, item := range n.items {
+		if item.kind == '{' && !item.empty() {
+			// The first key of an object item goes on the dash line, the rest below it
+			for i, key := range item.keys {
+				prefix := pad + "  "
+				if i == 0 {
+					prefix = pad + "- "
+				}
+				writeEntry(b, prefix, quoteString(key)+":", item.fields[i], indent+2)
+			}
+			continue
+		}
+		writeEntry(b, pad, "-", item, indent)
+	}
+}
+
+// writeEntry writes a key or dash followed by its value, nesting collections below it. prefix
+// holds the indentation of the line, and indent that of the entry's own level.
+func writeEntry(b *strings.Builder, prefix, label string, value *node, indent int) {
+	if value.kind == 0 || value.empty() {
+		b.WriteString(prefix + label + " " + value.flow() + "\n")
+		return
+	}
+	b.WriteString(prefix + label + "\n")
+	switch {
+	case label == "-":
+		writeBlock(b, value, indent+2)
+	case value.kind == '[':
+		writeBlock(b, value, indent)
+	default:
+		writeBlock(b, value, indent+2)
+	}
+}
+
+// plainPattern matches strings that read back as the same string when written unquoted
+var plainPattern = regexp.MustCompile(`^[A-Za-z_./][A-Za-z0-9_./ -]*$`)
+
+// reservedWords read back as booleans or null when written unquoted
+var reservedWords = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"y": true, "n": true, "null": true, "~": true,
+}
+
+// quoteString writes a string plainly when that is unambiguous, and as a double-quoted JSON
+// string, which YAML reads the same, otherwise
+func quoteString(s string) string {
+	if plainPattern.MatchString(s) && !strings.HasSuffix(s, " ") && !reservedWords[strings.ToLower(s)] {
+		return s
+	}
+	// Quantities such as 100m or 256Mi are common in manifests and never ambiguous
+	if quantityPattern.MatchString(s) {
+		return s
+	}
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// quantityPattern matches Kubernetes quantities with a suffix, which YAML reads as strings
+var quantityPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei)$`)
@@ -0,0 +1,112 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+// PushgatewayClient pushes metrics in the Prometheus text exposition format
+type PushgatewayClient interface {
+	Push(ctx context.Context, scope string, metrics []byte) error
+}
+
+// HTTPPushgateway pushes metrics to a Prometheus Pushgateway
+type HTTPPushgateway struct {
+	url    string
+	job    string
+	client *http.Client
+}
+
+// NewHTTPPushgateway creates a Pushgateway client for the given base URL and job name
+func NewHTTPPushgateway(baseURL, job string) *HTTPPushgateway {
+	return &HTTPPushgateway{
+		url:    strings.TrimRight(baseURL, "/"),
+		job:    job,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Push replaces the metrics of the job's group for the given scan scope. Each scope (a
+// namespace, or "all") is its own group, so a namespace scan does not wipe the series of others.
+func (c *HTTPPushgateway) Push(ctx context.Context, scope string, metrics []byte) error {
+	target := fmt.Sprintf("%s/metrics/job/%s/scope/%s", c.url, url.PathEscape(c.job), url.PathEscape(scope))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(metrics))
+	if err != nil {
+		return fmt.Errorf("failed to create pushgateway request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push to pushgateway: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pushgateway returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// FormatMetrics renders a cluster summary as Prometheus metrics, labeled by namespace
+func FormatMetrics(summary krr.ClusterSummary) []byte {
+	var buf bytes.Buffer
+
+	gauges := []struct {
+		name, help string
+		value      func(krr.Summary) float64
+	}{
+		{"greenops_reclaimable_cpu_cores", "CPU cores that could be released by applying KRR's request recommendations",
+			func(s krr.Summary) float64 { return s.ReclaimableCPUCores }},
+		{"greenops_reclaimable_memory_bytes", "Memory bytes that could be released by applying KRR's request recommendations",
+			func(s krr.Summary) float64 { return s.ReclaimableMemoryBytes }},
+	}
+	for _, gauge := range gauges {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n", gauge.name, gauge.help, gauge.name)
+		for _, namespace := range summary.Namespaces {
+			fmt.Fprintf(&buf, "%s{namespace=%s} %s\n", gauge.name, quoteLabel(namespace.Namespace), formatValue(gauge.value(namespace.Summary)))
+		}
+	}
+
+	const workloads = "greenops_workloads"
+	fmt.Fprintf(&buf, "# HELP %s Scanned containers by KRR severity\n# TYPE %s gauge\n", workloads, workloads)
+	for _, namespace := range summary.Namespaces {
+		counts := []struct {
+			severity string
+			count    int
+		}{
+			{"CRITICAL", namespace.CriticalSeverity},
+			{"HIGH", namespace.HighSeverity},
+			{"MEDIUM", namespace.MediumSeverity},
+			{"LOW", namespace.LowSeverity},
+			{"WARNING", namespace.WarningSeverity},
+			{"OK", namespace.OKSeverity},
+		}
+		for _, c := range counts {
+			fmt.Fprintf(&buf, "%s{namespace=%s,severity=%q} %d\n", workloads, quoteLabel(namespace.Namespace), c.severity, c.count)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// quoteLabel quotes a label value using the exposition format's escaping rules
+func quoteLabel(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return `"` + value + `"`
+}
+
+// formatValue formats a sample value without exponent notation for ordinary numbers
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+// SlackMessage is a Slack message payload using Block Kit
+type SlackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks,omitempty"`
+}
+
+// SlackBlock is a single Block Kit block
+type SlackBlock struct {
+	Type string     `json:"type"`
+	Text *SlackText `json:"text,omitempty"`
+}
+
+// SlackText is a Block Kit text object
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SlackClient posts messages to Slack
+type SlackClient interface {
+	Post(ctx context.Context, message SlackMessage) error
+}
+
+// WebhookClient posts messages through a Slack incoming webhook
+type WebhookClient struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookClient creates a Slack client for the given incoming webhook URL
+func NewWebhookClient(url string) *WebhookClient {
+	return &WebhookClient{
+		url:    url,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Post sends the message to the webhook
+func (c *WebhookClient) Post(ctx context.Context, message SlackMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("slack webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// topRecommendationCount is the number of workloads listed in a scan notification
+const topRecommendationCount = 5
+
+// ScanMessage builds the notification posted after a successful scan
+func ScanMessage(result *krr.ScanResult, scope string, model krr.CostModel) SlackMessage {
+	summary := result.Summary
+	if scope == "" {
+		scope = "all namespaces"
+	}
+
+	var summaryText strings.Builder
+	fmt.Fprintf(&summaryText, "*KRR rightsizing report* for %s\n", scope)
+	fmt.Fprintf(&summaryText, "• Workloads scanned: %d (%d with recommendations)\n", summary.TotalResources, summary.ResourcesWithRecommendations)
+	fmt.Fprintf(&summaryText, "• Reclaimable CPU: %.2f cores\n", summary.ReclaimableCPUCores)
+	fmt.Fprintf(&summaryText, "• Reclaimable memory: %s\n", krr.FormatMemory(summary.ReclaimableMemoryBytes))
+	if model.Enabled() {
+		cost := krr.EstimateCost(summary, model)
+		fmt.Fprintf(&summaryText, "• Estimated monthly savings: %.2f\n", cost.EstimatedMonthlySavings)
+	}
+
+	message := SlackMessage{
+		Text: fmt.Sprintf("KRR rightsizing report for %s: %.2f CPU cores and %s memory reclaimable",
+			scope, summary.ReclaimableCPUCores, krr.FormatMemory(summary.ReclaimableMemoryBytes)),
+		Blocks: []SlackBlock{
+			{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: summaryText.String()}},
+		},
+	}
+
+	top := krr.TopRecommendations(result.Resources, model, topRecommendationCount)
+	if len(top) > 0 {
+		var topText strings.Builder
+		topText.WriteString("*Top recommendations*\n")
+		for _, resource := range top {
+			fmt.Fprintf(&topText, "• `%s/%s` (%s): CPU %s → %s, memory %s → %s\n",
+				resource.Namespace, resource.Name, resource.Container,
+				orUnset(resource.Current.CPU), orUnknown(resource.Recommended.CPU),
+				orUnset(resource.Current.Memory), orUnknown(resource.Recommended.Memory))
+		}
+		message.Blocks = append(message.Blocks,
+			SlackBlock{Type: "divider"},
+			SlackBlock{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: topText.String()}},
+		)
+	}
+
+	return message
+}
+
+func orUnset(quantity string) string {
+	if quantity == "" {
+		return "unset"
+	}
+	return quantity
+}
+
+func orUnknown(quantity string) string {
+	if quantity == "" {
+		return "?"
+	}
+	return quantity
+}
@@ -0,0 +1,87 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+
+	_ "crypto/sha256" // registers SHA-256 for crypto.Hash
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for crypto.Hash
+)
+
+// curves are the EC curves a JWK may name
+var curves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// algorithms are the supported JWS signature algorithms; "none" and HMAC are deliberately absent
+var algorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// algorithmCurves binds each ECDSA algorithm to the one curve RFC 7518 allows it with
+var algorithmCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// verifySignature checks a JWS signature over signingInput with the key, which must match the
+// algorithm's key type and, for ECDSA, its curve
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	hash, ok := algorithms[alg]
+	if !ok {
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+				return fmt.Errorf("bad signature")
+			}
+			return nil
+		case "PS":
+			if err := rsa.VerifyPSS(key, hash, digest, signature, nil); err != nil {
+				return fmt.Errorf("bad signature")
+			}
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		if alg[:2] != "ES" {
+			break
+		}
+		if key.Curve != algorithmCurves[alg] {
+			return fmt.Errorf("signing algorithm %s does not match curve %s", alg, key.Curve.Params().Name)
+		}
+		// JWS encodes ECDSA signatures as the fixed-size concatenation of r and s
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("bad signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("bad signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("signing algorithm %s does not match the key type", alg)
+}
@@ -0,0 +1,341 @@
+// Package oidc verifies OpenID Connect ID and access tokens (signed JWTs) against an issuer's
+// published signing keys, discovered through its /.well-known/openid-configuration document.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidToken is wrapped by every error caused by the token rather than by the issuer
+var ErrInvalidToken = errors.New("invalid token")
+
+// clockSkew is how far exp and nbf may be off to allow for clock drift between issuer and server
+const clockSkew = time.Minute
+
+// minKeyRefresh bounds how often an unknown key ID may trigger a JWKS refetch, so tokens with
+// made-up key IDs cannot hammer the issuer
+const minKeyRefresh = time.Minute
+
+// Claims are the verified claims of a token. Raw holds every claim, including the standard ones.
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	Email     string
+	Scopes    []string
+	ExpiresAt time.Time
+	Raw       map[string]any
+}
+
+// Verifier checks tokens issued by one issuer for one audience
+type Verifier struct {
+	issuer   string
+	audience string
+	client   *http.Client
+	now      func() time.Time
+
+	mu        sync.Mutex
+	jwksURL   string
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// VerifierOption configures a Verifier
+type VerifierOption func(*Verifier)
+
+// WithHTTPClient sets the HTTP client used for discovery and key fetches
+func WithHTTPClient(client *http.Client) VerifierOption {
+	return func(v *Verifier) {
+		v.client = client
+	}
+}
+
+// NewVerifier creates a verifier for tokens of issuer whose audience includes audience. The
+// issuer's keys are fetched on first use, so the issuer need not be reachable at startup.
+func NewVerifier(issuer, audience string, opts ...VerifierOption) *Verifier {
+	v := &Verifier{
+		issuer:   strings.TrimSuffix(issuer, "/"),
+		audience: audience,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Verify checks a token's signature, issuer, audience and validity period and returns its claims
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrInvalidToken, err)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var raw map[string]any
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	claims, err := parseClaims(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	now := v.now()
+	if claims.Issuer != v.issuer && strings.TrimSuffix(claims.Issuer, "/") != v.issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if !slices.Contains(claims.Audience, v.audience) {
+		return nil, fmt.Errorf("%w: audience does not include %q", ErrInvalidToken, v.audience)
+	}
+	if claims.ExpiresAt.IsZero() {
+		return nil, fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+	if now.After(claims.ExpiresAt.Add(clockSkew)) {
+		return nil, fmt.Errorf("%w: expired at %s", ErrInvalidToken, claims.ExpiresAt.Format(time.RFC3339))
+	}
+	if nbf, ok := numericDate(raw["nbf"]); ok && now.Add(clockSkew).Before(nbf) {
+		return nil, fmt.Errorf("%w: not valid before %s", ErrInvalidToken, nbf.Format(time.RFC3339))
+	}
+	return claims, nil
+}
+
+// key returns the signing key with the given ID, (re)fetching the issuer's key set when the ID
+// is unknown
+func (v *Verifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	if v.keys != nil && v.now().Sub(v.fetchedAt) < minKeyRefresh {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+	}
+	if err := v.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+}
+
+// lookup finds a cached key by ID; a token without a key ID matches an issuer with a single key
+func (v *Verifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// refresh discovers the issuer's JWKS URL, once, and fetches its key set
+func (v *Verifier) refresh(ctx context.Context) error {
+	if v.jwksURL == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, v.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+			return fmt.Errorf("OIDC discovery: %w", err)
+		}
+		if strings.TrimSuffix(discovery.Issuer, "/") != v.issuer {
+			return fmt.Errorf("OIDC discovery: issuer %q does not match %q", discovery.Issuer, v.issuer)
+		}
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("OIDC discovery: no jwks_uri")
+		}
+		v.jwksURL = discovery.JWKSURI
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := v.getJSON(ctx, v.jwksURL, &set); err != nil {
+		return fmt.Errorf("fetching OIDC signing keys: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		// Keys of unsupported types are skipped; tokens signed with them fail as unknown keys
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	v.keys = keys
+	v.fetchedAt = v.now()
+	return nil
+}
+
+// getJSON fetches url and decodes its JSON body into out
+func (v *Verifier) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// decodeSegment decodes a base64url JSON segment of a JWT
+func decodeSegment(segment string, out any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// parseClaims extracts the standard claims; aud may be a string or a list, and scopes come from
+// either a space-separated "scope" or an "scp" list
+func parseClaims(raw map[string]any) (*Claims, error) {
+	claims := &Claims{Raw: raw}
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Email, _ = raw["email"].(string)
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("missing sub")
+	}
+
+	switch aud := raw["aud"].(type) {
+	case string:
+		claims.Audience = []string{aud}
+	case []any:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				claims.Audience = append(claims.Audience, s)
+			}
+		}
+	}
+
+	if scope, ok := raw["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	} else if scp, ok := raw["scp"].([]any); ok {
+		for _, s := range scp {
+			if s, ok := s.(string); ok {
+				claims.Scopes = append(claims.Scopes, s)
+			}
+		}
+	}
+
+	if exp, ok := numericDate(raw["exp"]); ok {
+		claims.ExpiresAt = exp
+	}
+	return claims, nil
+}
+
+// numericDate converts a JWT NumericDate (seconds since the epoch) to a time
+func numericDate(value any) (time.Time, bool) {
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+// jwk is a JSON Web Key; only the RSA and EC public key members are read
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey converts the key to an *rsa.PublicKey or *ecdsa.PublicKey
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url big-endian unsigned integer
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
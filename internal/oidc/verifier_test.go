@@ -0,0 +1,230 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAudience = "greenops-mcp"
+
+// testIssuer is an OIDC issuer serving discovery and a key set with one RSA and two EC keys
+type testIssuer struct {
+	url  string
+	rsa  *rsa.PrivateKey
+	p256 *ecdsa.PrivateKey
+	p384 *ecdsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	issuer := &testIssuer{}
+	var err error
+	if issuer.rsa, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		t.Fatal(err)
+	}
+	if issuer.p256, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	if issuer.p384, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": issuer.url, "jwks_uri": issuer.url + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encodeBigInt(issuer.rsa.N), "e": encodeBigInt(big.NewInt(int64(issuer.rsa.E)))},
+			ecJWK("p256", "P-256", &issuer.p256.PublicKey),
+			ecJWK("p384", "P-384", &issuer.p384.PublicKey),
+		}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	issuer.url = server.URL
+	return issuer
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func ecJWK(kid, crv string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "EC", "kid": kid, "crv": crv, "x": encodeBigInt(key.X), "y": encodeBigInt(key.Y)}
+}
+
+// sign builds a token with the given header algorithm and key ID, signed with key using the
+// hash of hashAlg, which lets tests pair an algorithm with the wrong kind of key
+func sign(t *testing.T, alg, hashAlg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	if key == nil {
+		return input + "."
+	}
+
+	hash := algorithms[hashAlg]
+	h := hash.New()
+	h.Write([]byte(input))
+	digest := h.Sum(nil)
+
+	var signature []byte
+	var err error
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if strings.HasPrefix(hashAlg, "PS") {
+			signature, err = rsa.SignPSS(rand.Reader, key, hash, digest, nil)
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+		}
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerify(t *testing.T) {
+	issuer := newTestIssuer(t)
+	now := time.Unix(1_700_000_000, 0)
+	claims := func(changes map[string]any) map[string]any {
+		c := map[string]any{
+			"iss":   issuer.url,
+			"sub":   "alice",
+			"aud":   testAudience,
+			"exp":   now.Add(time.Hour).Unix(),
+			"scope": "krr:scan krr:read",
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(c, name)
+			} else {
+				c[name] = value
+			}
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr string
+	}{
+		{"RS256", func() string { return sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(nil)) }, ""},
+		{"PS384", func() string { return sign(t, "PS384", "PS384", "rsa", issuer.rsa, claims(nil)) }, ""},
+		{"ES256 on P-256", func() string { return sign(t, "ES256", "ES256", "p256", issuer.p256, claims(nil)) }, ""},
+		{"ES384 on P-384", func() string { return sign(t, "ES384", "ES384", "p384", issuer.p384, claims(nil)) }, ""},
+		{"audience list", func() string {
+			return sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(map[string]any{"aud": []string{"other", testAudience}}))
+		}, ""},
+		{"within clock skew of exp", func() string {
+			return sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(map[string]any{"exp": now.Add(-30 * time.Second).Unix()}))
+		}, ""},
+
+		{"ES256 on P-384", func() string { return sign(t, "ES256", "ES256", "p384", issuer.p384, claims(nil)) }, "does not match curve"},
+		{"ES384 on P-256", func() string { return sign(t, "ES384", "ES384", "p256", issuer.p256, claims(nil)) }, "does not match curve"},
+		{"RS256 with an EC key", func() string { return sign(t, "RS256", "ES256", "p256", issuer.p256, claims(nil)) }, "does not match the key type"},
+		{"ES256 with an RSA key", func() string { return sign(t, "ES256", "RS256", "rsa", issuer.rsa, claims(nil)) }, "does not match the key type"},
+		{"none", func() string { return sign(t, "none", "", "rsa", nil, claims(nil)) }, "unsupported signing algorithm"},
+		{"HS256", func() string { return sign(t, "HS256", "", "rsa", nil, claims(nil)) + "c2lnbmF0dXJl" }, "unsupported signing algorithm"},
+		{"tampered claims", func() string {
+			parts := strings.Split(sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(nil)), ".")
+			other := strings.Split(sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(map[string]any{"sub": "mallory"})), ".")
+			return parts[0] + "." + other[1] + "." + parts[2]
+		}, "bad signature"},
+		{"unknown kid", func() string { return sign(t, "RS256", "RS256", "rotated", issuer.rsa, claims(nil)) }, `unknown signing key "rotated"`},
+		{"expired", func() string {
+			return sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(map[string]any{"exp": now.Add(-2 * time.Minute).Unix()}))
+		}, "expired at"},
+		{"missing exp", func() string { return sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(map[string]any{"exp": nil})) }, "missing exp"},
+		{"not yet valid", func() string {
+			return sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(map[string]any{"nbf": now.Add(2 * time.Minute).Unix()}))
+		}, "not valid before"},
+		{"wrong issuer", func() string {
+			return sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(map[string]any{"iss": "https://evil.example.com"}))
+		}, "unexpected issuer"},
+		{"wrong audience", func() string {
+			return sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(map[string]any{"aud": "another-app"}))
+		}, "audience does not include"},
+		{"missing sub", func() string { return sign(t, "RS256", "RS256", "rsa", issuer.rsa, claims(map[string]any{"sub": nil})) }, "missing sub"},
+		{"not a JWT", func() string { return "not-a-token" }, "not a JWT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier(issuer.url, testAudience)
+			verifier.now = func() time.Time { return now }
+
+			got, err := verifier.Verify(context.Background(), tt.token())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+				if got.Subject != "alice" || len(got.Scopes) != 2 {
+					t.Errorf("Verify() claims = %+v", got)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want an invalid token error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyRefetchesKeysAtMostOncePerMinute(t *testing.T) {
+	issuer := newTestIssuer(t)
+	fetches := 0
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/keys" {
+			fetches++
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	now := time.Unix(1_700_000_000, 0)
+	verifier := NewVerifier(issuer.url, testAudience, WithHTTPClient(client))
+	verifier.now = func() time.Time { return now }
+
+	token := sign(t, "RS256", "RS256", "unknown", issuer.rsa, map[string]any{"iss": issuer.url, "sub": "alice", "aud": testAudience, "exp": now.Add(time.Hour).Unix()})
+	for range 3 {
+		if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify() error = %v, want an invalid token error", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("key set fetched %d times, want 1", fetches)
+	}
+
+	now = now.Add(minKeyRefresh)
+	verifier.Verify(context.Background(), token)
+	if fetches != 2 {
+		t.Errorf("key set fetched %d times after a minute, want 2", fetches)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
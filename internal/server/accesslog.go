@@ -0,0 +1,86 @@
+package server
+
+import (
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// sessionIDHeader carries the streamable HTTP transport's session ID, set by the server in the
+// response to initialize and sent back by the client on every later request
+const sessionIDHeader = "Mcp-Session-Id"
+
+// accessRecorder captures the status and size of a response for the access log
+type accessRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the status
+func (a *accessRecorder) WriteHeader(status int) {
+	if a.status == 0 {
+		a.status = status
+	}
+	a.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the size, and an implicit 200
+func (a *accessRecorder) Write(data []byte) (int, error) {
+	if a.status == 0 {
+		a.status = http.StatusOK
+	}
+	n, err := a.ResponseWriter.Write(data)
+	a.bytes += int64(n)
+	return n, err
+}
+
+// Flush forwards to the underlying writer, which event streams rely on
+func (a *accessRecorder) Flush() {
+	if flusher, ok := a.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (a *accessRecorder) Unwrap() http.ResponseWriter {
+	return a.ResponseWriter
+}
+
+// withAccessLog logs one line per request once it completes, as key=value pairs: method, path,
+// status, latency, response size, client address, MCP session and request ID. It is a no-op
+// unless access_log is set. Event streams are logged when they close, so their latency is the
+// stream's lifetime.
+func (s *MCPServer) withAccessLog(next http.Handler) http.HandlerFunc {
+	if !s.config().AccessLog {
+		return next.ServeHTTP
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &accessRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status < http.StatusBadRequest && rand.Float64() >= s.config().AccessLogSampleRate {
+			return
+		}
+
+		// A session ID is only in the response for initialize; the legacy SSE transport
+		// passes it as a query parameter instead
+		session := r.Header.Get(sessionIDHeader)
+		if session == "" {
+			session = w.Header().Get(sessionIDHeader)
+		}
+		if session == "" {
+			session = r.URL.Query().Get("sessionid")
+		}
+
+		log.Printf("Access method=%s path=%q status=%d duration=%s bytes=%d client=%s session=%q request_id=%q",
+			r.Method, r.URL.Path, status, time.Since(start).Round(time.Microsecond), recorder.bytes,
+			r.RemoteAddr, session, r.Header.Get(requestIDHeader))
+	}
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"greenops-mcp/internal/krr"
+)
+
+// Scan runs a KRR scan and returns the parsed result without any MCP formatting, for embedding
+// the server in other Go programs. Empty namespace and strategy fall back to the configured
+// defaults, and KRR's JSON formatter is always used so the result is fully parsed. The scan is
+// bounded by default_timeout unless ctx has an earlier deadline, and shares the server's scan slots.
+func (s *MCPServer) Scan(ctx context.Context, options krr.ScanOptions) (*krr.ScanResult, error) {
+	if options.Namespace == "" {
+		options.Namespace = s.config().DefaultNamespace
+	}
+	if options.Strategy == "" {
+		options.Strategy = s.config().DefaultStrategy
+	}
+	if options.Strategy != "" {
+		if err := krr.ValidateStrategy(options.Strategy, options.StrategyPath != ""); err != nil {
+			return nil, fmt.Errorf("invalid scan options: %w", err)
+		}
+	}
+	if options.ClusterLabelValue != "" && options.PrometheusLabel == "" {
+		return nil, fmt.Errorf("invalid scan options: cluster_label_value requires prometheus_label")
+	}
+	options.Output = krr.OutputJSON
+
+	ctx, cancel := context.WithTimeout(ctx, s.config().DefaultTimeout)
+	defer cancel()
+
+	return s.runScan(ctx, s.live().executor, options)
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// apiKeyHeader is the alternative to an "Authorization: Bearer" header for static API keys
+const apiKeyHeader = "X-API-Key"
+
+// requestToken returns the bearer token or, failing that, the X-API-Key header of a request
+func requestToken(header http.Header) string {
+	if token := bearerToken(header); token != "" {
+		return token
+	}
+	return strings.TrimSpace(header.Get(apiKeyHeader))
+}
+
+// validAuthToken compares a token against every static API key in constant time
+func (s *MCPServer) validAuthToken(token string) bool {
+	valid := false
+	for _, key := range s.live().authTokens {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// withAuth rejects MCP requests without one of the static API keys (401). It is a no-op when
+// none are configured.
+func (s *MCPServer) withAuth(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(s.live().authTokens) > 0 {
+			token := requestToken(r.Header)
+			if token == "" {
+				authFailure(w, r, http.StatusUnauthorized, "missing API key")
+				return
+			}
+			if !s.validAuthToken(token) {
+				authFailure(w, r, http.StatusUnauthorized, "invalid API key")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// authFailure rejects a request and writes an audit log line. The presented credential is
+// never logged.
+func authFailure(w http.ResponseWriter, r *http.Request, status int, reason string) {
+	logAuthFailure(r, reason)
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="greenops-mcp"`)
+	}
+	http.Error(w, reason, status)
+}
+
+// logAuthFailure writes the audit log line for a rejected request
+func logAuthFailure(r *http.Request, reason string) {
+	if id := r.Header.Get(requestIDHeader); id != "" {
+		log.Printf("Auth failure: %s from %s for %s %s (request %s)", reason, r.RemoteAddr, r.Method, r.URL.Path, id)
+		return
+	}
+	log.Printf("Auth failure: %s from %s for %s %s", reason, r.RemoteAddr, r.Method, r.URL.Path)
+}
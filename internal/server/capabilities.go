@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// capabilities is the JSON body returned by /capabilities. It describes the server without an
+// MCP handshake, so it must never carry secrets: only names, schemas and limits.
+type capabilities struct {
+	Server        string               `json:"server"`
+	Version       string               `json:"version"`
+	MCPPath       string               `json:"mcp_path"`
+	Tools         []toolCapability     `json:"tools"`
+	OutputFormats []string             `json:"output_formats"`
+	Views         []krr.View           `json:"views"`
+	Defaults      capabilitiesDefaults `json:"defaults"`
+}
+
+// toolCapability describes one registered tool
+type toolCapability struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	InputSchema *jsonschema.Schema `json:"input_schema,omitempty"`
+}
+
+// capabilitiesDefaults are the config-driven defaults and limits tool calls run with
+type capabilitiesDefaults struct {
+	Strategy           string   `json:"strategy"`
+	Namespace          string   `json:"namespace,omitempty"`
+	Timeout            string   `json:"timeout"`
+	MaxTimeout         string   `json:"max_timeout"`
+	MaxConcurrentScans int      `json:"max_concurrent_scans"`
+	MaxOutputRows      int      `json:"max_output_rows"`
+	MaxHistoryDuration string   `json:"max_history_duration,omitempty"`
+	RequireNamespace   bool     `json:"require_namespace"`
+	Profiles           []string `json:"profiles"`
+	MultiTenant        bool     `json:"multi_tenant"`
+}
+
+// handleCapabilities describes the registered tools and the effective defaults. It is
+// unauthenticated, like the health checks.
+func (s *MCPServer) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	body := capabilities{
+		Server:        s.config().ServerName,
+		Version:       s.config().ServerVersion,
+		MCPPath:       s.config().RoutePath(s.config().MCPPath),
+		Tools:         make([]toolCapability, 0, len(toolRegistry)),
+		OutputFormats: slices.Sorted(maps.Keys(outputModes)),
+		Views:         []krr.View{krr.ViewAll, krr.ViewRecommendOnly, krr.ViewProblems},
+		Defaults: capabilitiesDefaults{
+			Strategy:           s.config().DefaultStrategy,
+			Namespace:          s.config().DefaultNamespace,
+			Timeout:            s.config().DefaultTimeout.String(),
+			MaxTimeout:         s.config().MaxTimeout.String(),
+			MaxConcurrentScans: s.config().MaxConcurrentScans,
+			MaxOutputRows:      s.config().MaxOutputRows,
+			RequireNamespace:   s.config().RequireNamespace,
+			Profiles:           slices.Sorted(maps.Keys(s.config().Profiles)),
+			MultiTenant:        len(s.live().tenants) > 0,
+		},
+	}
+	if s.config().MaxHistoryDuration > 0 {
+		body.Defaults.MaxHistoryDuration = s.config().MaxHistoryDuration.String()
+	}
+	if body.Defaults.Profiles == nil {
+		body.Defaults.Profiles = []string{}
+	}
+
+	for _, tool := range toolRegistry {
+		capability := toolCapability{Name: tool.Name(), Description: tool.Description()}
+		schema, err := tool.InputSchema()
+		if err != nil {
+			log.Printf("Failed to build input schema for %s: %v", tool.Name(), err)
+		}
+		capability.InputSchema = schema
+		body.Tools = append(body.Tools, capability)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to write capabilities: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/kube"
+)
+
+// cluster is a cluster registry entry with the executor and cluster client bound to its
+// kubeconfig
+type cluster struct {
+	name     string
+	config   config.ClusterConfig
+	executor krr.Executor
+	kube     *kube.Client
+}
+
+// newClusters builds the cluster registry of a configuration
+func newClusters(cfg *config.Config) map[string]*cluster {
+	clusters := make(map[string]*cluster, len(cfg.Clusters))
+	for name, clusterConfig := range cfg.Clusters {
+		var kubeOpts []kube.ClientOption
+		scoped := *cfg
+		if clusterConfig.Kubeconfig != "" {
+			scoped.KubeconfigData = ""
+			kubeOpts = append(kubeOpts, kube.WithClientKubeconfigPath(clusterConfig.Kubeconfig))
+		}
+		clusters[name] = &cluster{
+			name:     name,
+			config:   clusterConfig,
+			executor: clusterExecutor(cfg, clusterConfig, cfg.KRRPath),
+			kube:     newKubeClient(&scoped, kubeOpts...),
+		}
+	}
+	return clusters
+}
+
+// clusterExecutor creates a KRR executor for a registry cluster. A cluster with its own
+// kubeconfig file uses it instead of the server's kubeconfig_data.
+func clusterExecutor(cfg *config.Config, clusterConfig config.ClusterConfig, krrPath string) krr.Executor {
+	if clusterConfig.Kubeconfig == "" {
+		return newExecutor(cfg, krrPath)
+	}
+	scoped := *cfg
+	scoped.KubeconfigData = ""
+	return newExecutor(&scoped, krrPath, krr.WithKubeconfigPath(clusterConfig.Kubeconfig))
+}
+
+// registryCluster looks a cluster up by name, listing the registered names when it is unknown
+func (s *MCPServer) registryCluster(name string) (*cluster, error) {
+	c, ok := s.live().clusters[strings.TrimSpace(name)]
+	if !ok {
+		names := slices.Sorted(maps.Keys(s.live().clusters))
+		if len(names) == 0 {
+			return nil, fmt.Errorf("unknown cluster (the server defines no clusters)")
+		}
+		return nil, fmt.Errorf("unknown cluster (registered clusters: %s)", strings.Join(names, ", "))
+	}
+	return c, nil
+}
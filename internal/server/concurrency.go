@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+// runScan runs a scan with the given executor, waiting for a free scan slot first so that
+// at most max_concurrent_scans KRR processes run at once. The outcome is recorded in the
+// recent-scans buffer. Every scan passes through here, so this is where the scan policy is
+// enforced; a rejected scan never reaches KRR and fails with a *policyError.
+func (s *MCPServer) runScan(ctx context.Context, executor krr.Executor, options krr.ScanOptions) (result *krr.ScanResult, err error) {
+	if violations := s.applyScanPolicy(&options); len(violations) > 0 {
+		return nil, &policyError{violations: violations}
+	}
+	options.PrometheusUserAgent = s.config().PrometheusUserAgent
+	if options.MaxWorkers == 0 {
+		options.MaxWorkers = s.config().DefaultKRRWorkers
+	}
+
+	info, _ := trackedScan(ctx)
+	if info.User != "" {
+		log.Printf("Scan %s (%s) triggered by %s", info.RequestID, info.Tool, info.User)
+	}
+
+	ctx = krr.WithRetryObserver(ctx, s.metrics.recordRetry)
+	startedAt := time.Now()
+	defer func() {
+		s.recent.add(newRecentScan(ctx, options, startedAt, err))
+		s.metrics.recordScan(info.Tool, time.Since(startedAt), err)
+	}()
+
+	select {
+	case s.scanSlots <- struct{}{}:
+	default:
+		krr.ReportProgress(ctx, "waiting for a free scan slot")
+		select {
+		case s.scanSlots <- struct{}{}:
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil, fmt.Errorf("canceled while waiting for a free scan slot: %w", ctx.Err())
+			}
+			return nil, fmt.Errorf("timed out waiting for a free scan slot: %w", ctx.Err())
+		}
+	}
+	defer func() { <-s.scanSlots }()
+
+	result, err = executor.Scan(ctx, options)
+	if err != nil && krr.ClassifyError(err) == krr.ErrorKindCanceled {
+		log.Printf("Scan %s canceled; KRR process killed", info.RequestID)
+	}
+	if err == nil && result.Prometheus != nil {
+		s.prometheus.Store(result.Prometheus)
+	}
+	return result, err
+}
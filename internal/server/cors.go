@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// corsExposedHeaders are the response headers browser clients need to read: the MCP session ID,
+// and the auth challenge of a 401
+const corsExposedHeaders = "Mcp-Session-Id, WWW-Authenticate"
+
+// corsMaxAge is how long, in seconds, browsers may cache a preflight response
+const corsMaxAge = "600"
+
+// withCORS answers CORS preflight requests and adds CORS headers for the allowed origins. It
+// runs before authentication, since browsers send preflights without credentials. Requests from
+// other origins get no CORS headers, so browsers refuse them; it is a no-op when no origins are
+// configured.
+func (s *MCPServer) withCORS(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(s.config().CORSAllowedOrigins) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		allowed := origin != "" && s.corsOriginAllowed(origin)
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if allowed {
+			if slices.Contains(s.config().CORSAllowedOrigins, "*") {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+		}
+		if preflight {
+			if allowed {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(s.config().CORSAllowedMethods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(s.config().CORSAllowedHeaders, ", "))
+				w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if allowed {
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// corsOriginAllowed reports whether cors_allowed_origins lists an origin, or "*". Origins
+// compare case-insensitively and ignore a trailing slash in the configuration.
+func (s *MCPServer) corsOriginAllowed(origin string) bool {
+	for _, allowed := range s.config().CORSAllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
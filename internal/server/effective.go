@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+// EffectiveScanOptions are the fully resolved settings a scan ran with, after defaults,
+// profiles and policy caps were applied
+type EffectiveScanOptions struct {
+	krr.ScanOptions
+
+	// HistoryDuration shadows the embedded field so it is reported as "336h0m0s" rather than
+	// nanoseconds; empty means KRR's default window
+	HistoryDuration string `json:"history_duration,omitempty"`
+
+	// Output is the krr_scan output mode, which hides the KRR formatter it was translated to
+	Output  string `json:"output"`
+	View    string `json:"view"`
+	Timeout string `json:"timeout"`
+
+	SummaryOnly bool `json:"summary_only,omitempty"`
+}
+
+// newEffectiveScanOptions describes the resolved options of a krr_scan call
+func newEffectiveScanOptions(options krr.ScanOptions, mode string, view krr.View, timeout time.Duration) *EffectiveScanOptions {
+	effective := &EffectiveScanOptions{
+		ScanOptions: options,
+		Output:      mode,
+		View:        string(view),
+		Timeout:     timeout.String(),
+	}
+	if options.HistoryDuration > 0 {
+		effective.HistoryDuration = options.HistoryDuration.String()
+	}
+	return effective
+}
+
+// logEffectiveOptions logs the options a scan is about to run with, for reproducibility
+func logEffectiveOptions(requestID string, effective *EffectiveScanOptions) {
+	data, err := json.Marshal(effective)
+	if err != nil {
+		log.Printf("Scan %s: failed to marshal effective options: %v", requestID, err)
+		return
+	}
+	log.Printf("Scan %s effective options: %s", requestID, data)
+}
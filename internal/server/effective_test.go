@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+)
+
+func TestEffectiveOptionsReportDefaults(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DefaultNamespace = "shop"
+	cfg.DefaultKRRWorkers = 6
+	cfg.DefaultTimeout = 7 * time.Minute
+	s, _ := newTestServer(t, cfg)
+
+	// Nothing is passed, so everything reported comes from the server defaults
+	result, out, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{})
+	if result != nil {
+		t.Fatalf("handleScanTyped() = %s", resultText(result))
+	}
+	effective := out.EffectiveOptions
+	if effective == nil {
+		t.Fatal("no effective options reported")
+	}
+	checks := []struct {
+		field     string
+		got, want any
+	}{
+		{"namespace", effective.Namespace, "shop"},
+		{"strategy", effective.Strategy, "simple"},
+		{"max_workers", effective.MaxWorkers, 6},
+		{"no_color", effective.NoColor, true},
+		{"output", effective.Output, outputModeTable},
+		{"view", effective.View, string(krr.ViewAll)},
+		{"timeout", effective.Timeout, "7m0s"},
+		{"history_duration", effective.HistoryDuration, ""},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("effective %s = %v, want %v", check.field, check.got, check.want)
+		}
+	}
+}
+
+func TestEffectiveOptionsReportArguments(t *testing.T) {
+	s, _ := newTestServer(t, nil)
+	history, timeout := "2d", 90
+
+	_, out, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{HistoryDuration: &history, TimeoutSeconds: &timeout})
+	if out.EffectiveOptions == nil {
+		t.Fatal("no effective options reported")
+	}
+	if got := out.EffectiveOptions.HistoryDuration; got != "48h0m0s" {
+		t.Errorf("effective history_duration = %q, want 48h0m0s", got)
+	}
+	if got := out.EffectiveOptions.Timeout; got != "1m30s" {
+		t.Errorf("effective timeout = %q, want 1m30s", got)
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// scanErrorReport is the JSON document returned for failed scans in structured output modes
+type scanErrorReport struct {
+	Error     krr.ErrorKind `json:"error"`
+	Message   string        `json:"message"`
+	ExitCode  *int          `json:"exit_code,omitempty"`
+	Stderr    string        `json:"stderr,omitempty"`
+	RequestID string        `json:"request_id,omitempty"`
+}
+
+// scanErrorResult builds the tool result for a failed scan: a JSON error object when the
+// caller asked for structured output, or a friendly message otherwise. A scan the scan policy
+// rejected gets the policy_violation result either way.
+func scanErrorResult(err error, structured bool, requestID string) *mcp.CallToolResult {
+	var policyErr *policyError
+	if errors.As(err, &policyErr) {
+		return policyResult(policyErr.violations)
+	}
+
+	kind := krr.ClassifyError(err)
+	if !structured {
+		if kind == krr.ErrorKindCanceled {
+			return errorResult("KRR scan canceled before it finished; no recommendations were produced")
+		}
+		message := fmt.Sprintf("KRR scan failed: %v", err)
+		if kind == krr.ErrorKindNotInstalled {
+			message += "\n\nKRR CLI is not installed or not in PATH. Please install it with:\n  pip install krr\n\nThen verify installation with:\n  krr --version"
+		}
+		return errorResult(message)
+	}
+
+	report := scanErrorReport{
+		Error:     kind,
+		Message:   err.Error(),
+		RequestID: requestID,
+	}
+	var scanErr *krr.ScanError
+	if errors.As(err, &scanErr) {
+		report.Message = fmt.Sprintf("KRR scan failed: %v", scanErr.Err)
+		if kind == krr.ErrorKindCanceled {
+			report.Message = "KRR scan canceled"
+		}
+		report.Stderr = scanErr.Stderr
+		if scanErr.ExitCode >= 0 {
+			report.ExitCode = &scanErr.ExitCode
+		}
+	}
+
+	data, marshalErr := json.MarshalIndent(report, "", "  ")
+	if marshalErr != nil {
+		return errorResult(fmt.Sprintf("KRR scan failed: %v", err))
+	}
+	return errorResult(string(data))
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/kube"
+)
+
+const (
+	// readinessTimeout bounds the KRR version check run by /readyz
+	readinessTimeout = 2 * time.Second
+
+	// cancelGracePeriod is how long shutdown waits for canceled scans to return
+	cancelGracePeriod = 5 * time.Second
+
+	// shutdownTimeout bounds closing the HTTP server once scans are drained, so that open
+	// event streams cannot hold up the exit
+	shutdownTimeout = 10 * time.Second
+
+	// readinessCacheTTL is how long a successful check is reused, so frequent probes
+	// don't spawn a KRR process each time
+	readinessCacheTTL = 5 * time.Second
+)
+
+// readinessCache remembers the last successful KRR version and kubeconfig checks
+type readinessCache struct {
+	mu        sync.Mutex
+	version   string
+	checkedAt time.Time
+
+	kubeContext   string
+	kubeCheckedAt time.Time
+}
+
+// readinessStatus is the JSON body returned by /readyz
+type readinessStatus struct {
+	Status      string `json:"status"`
+	KRRVersion  string `json:"krr_version,omitempty"`
+	KubeContext string `json:"kube_context,omitempty"`
+	Error       string `json:"error,omitempty"`
+
+	// Prometheus is what the most recent scan discovered; it is informational and never
+	// affects readiness, since /readyz does not query Prometheus itself
+	Prometheus *krr.PrometheusDiscovery `json:"prometheus,omitempty"`
+}
+
+// handleHealthz reports liveness. It stays 200 until the process exits, including while draining.
+func (s *MCPServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok\n"))
+}
+
+// handleReadyz reports readiness: KRR must be runnable, checked with a cheap version call
+// rather than a scan, and the kubeconfig's current context must exist when a kubeconfig is
+// used. It returns 503 as soon as shutdown starts so that load balancers stop routing new
+// requests while in-flight ones finish.
+func (s *MCPServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		writeReadiness(w, http.StatusServiceUnavailable, readinessStatus{Status: "draining"})
+		return
+	}
+
+	version, err := s.krrVersion(r.Context())
+	if err != nil {
+		writeReadiness(w, http.StatusServiceUnavailable, readinessStatus{Status: "not_ready", Error: err.Error()})
+		return
+	}
+	kubeContext, err := s.kubeconfigContext(r.Context())
+	if err != nil {
+		writeReadiness(w, http.StatusServiceUnavailable, readinessStatus{Status: "not_ready", KRRVersion: version, Error: fmt.Sprintf("invalid kubeconfig: %v", err)})
+		return
+	}
+	writeReadiness(w, http.StatusOK, readinessStatus{Status: "ready", KRRVersion: version, KubeContext: kubeContext, Prometheus: s.prometheus.Load()})
+}
+
+// krrVersion returns the KRR version, reusing a recent successful check when there is one
+func (s *MCPServer) krrVersion(ctx context.Context) (string, error) {
+	s.readiness.mu.Lock()
+	defer s.readiness.mu.Unlock()
+
+	if !s.readiness.checkedAt.IsZero() && time.Since(s.readiness.checkedAt) < readinessCacheTTL {
+		return s.readiness.version, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	version, err := s.live().executor.GetVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.readiness.version = version
+	s.readiness.checkedAt = time.Now()
+	return version, nil
+}
+
+// kubeconfigContext checks the kubeconfig locally with kubectl and returns its current context,
+// reusing a recent successful check. Without a kubeconfig (in-cluster credentials) there is
+// nothing to check and it returns "".
+func (s *MCPServer) kubeconfigContext(ctx context.Context) (string, error) {
+	if !kube.HasKubeconfig(s.config().KubeconfigData) {
+		return "", nil
+	}
+
+	s.readiness.mu.Lock()
+	defer s.readiness.mu.Unlock()
+
+	if !s.readiness.kubeCheckedAt.IsZero() && time.Since(s.readiness.kubeCheckedAt) < readinessCacheTTL {
+		return s.readiness.kubeContext, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	kubeContext, err := s.live().kube.CurrentContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.readiness.kubeContext = kubeContext
+	s.readiness.kubeCheckedAt = time.Now()
+	return kubeContext, nil
+}
+
+// writeReadiness writes a readiness response as JSON
+func writeReadiness(w http.ResponseWriter, status int, body readinessStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// beginShutdown is the shutdown hook run before the HTTP server stops: it marks the server as
+// draining so /readyz fails while /healthz keeps succeeding, and new tool calls are refused
+func (s *MCPServer) beginShutdown() {
+	if s.draining.CompareAndSwap(false, true) {
+		log.Printf("Shutdown started, reporting not ready on /readyz")
+	}
+}
+
+// drain waits up to drain_timeout for in-flight scans to finish, then cancels the rest, which
+// kills their KRR processes rather than orphaning them, and gives their tool calls
+// cancelGracePeriod to report the cancellation
+func (s *MCPServer) drain() {
+	inFlight := len(s.running.list(""))
+	if inFlight == 0 {
+		return
+	}
+	log.Printf("Waiting up to %s for %d in-flight scans to finish", s.config().DrainTimeout, inFlight)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config().DrainTimeout)
+	defer cancel()
+	if s.running.wait(ctx) {
+		log.Printf("All in-flight scans finished")
+		return
+	}
+
+	log.Printf("Drain timeout reached, canceling %d scans", s.running.cancelAll())
+	ctx, cancel = context.WithTimeout(context.Background(), cancelGracePeriod)
+	defer cancel()
+	if !s.running.wait(ctx) {
+		log.Printf("Scans still running after cancellation: %d", len(s.running.list("")))
+	}
+}
+
+// errShuttingDown is returned for tool calls made after shutdown started
+var errShuttingDown = errors.New("server is shutting down; retry the call against another instance")
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBeginShutdownFlipsReadiness(t *testing.T) {
+	// Without a kubeconfig, readiness only depends on KRR answering its version call
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv("HOME", t.TempDir())
+	s, fake := newTestServer(t, nil)
+	fake.version = "1.8.3"
+
+	readyz := func() (int, readinessStatus) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		s.handleReadyz(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var status readinessStatus
+		if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+			t.Fatalf("/readyz body %q: %v", recorder.Body, err)
+		}
+		return recorder.Code, status
+	}
+	healthz := func() int {
+		recorder := httptest.NewRecorder()
+		s.handleHealthz(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		return recorder.Code
+	}
+
+	if code, status := readyz(); code != http.StatusOK || status.Status != "ready" || status.KRRVersion != "1.8.3" {
+		t.Fatalf("/readyz before shutdown = %d %+v, want 200 ready", code, status)
+	}
+
+	s.beginShutdown()
+	if code, status := readyz(); code != http.StatusServiceUnavailable || status.Status != "draining" {
+		t.Errorf("/readyz while draining = %d %+v, want 503 draining", code, status)
+	}
+	if code := healthz(); code != http.StatusOK {
+		t.Errorf("/healthz while draining = %d, want 200", code)
+	}
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scan job states
+const (
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+)
+
+// maxRetainedJobs bounds the finished jobs kept at once; the oldest are dropped first
+const maxRetainedJobs = 500
+
+// ScanJob describes an asynchronous scan started by krr_scan_async
+type ScanJob struct {
+	JobID       string     `json:"job_id"`
+	Status      string     `json:"status"`
+	Tenant      string     `json:"tenant,omitempty"`
+	User        string     `json:"user,omitempty"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// jobRecord is a job together with its output once it succeeded; it is also the format of
+// persisted job files
+type jobRecord struct {
+	ScanJob
+	Output *KRRScanOutput `json:"output,omitempty"`
+}
+
+// jobStore keeps asynchronous scan jobs in memory and, when dir is set, one JSON file per job
+// in dir, so results survive a restart. Finished jobs are dropped after the retention period.
+type jobStore struct {
+	mu   sync.Mutex
+	dir  string
+	jobs map[string]*jobRecord
+}
+
+// newJobStore creates a job store, loading the jobs persisted in dir. Jobs that were still
+// running when the previous process exited are marked failed.
+func newJobStore(dir string) (*jobStore, error) {
+	store := &jobStore{dir: dir, jobs: make(map[string]*jobRecord)}
+	if dir == "" {
+		return store, nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job directory: %w", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Printf("Skipping job file %s: %v", file, err)
+			continue
+		}
+		var record jobRecord
+		if err := json.Unmarshal(data, &record); err != nil || record.JobID == "" {
+			log.Printf("Skipping job file %s: not a scan job", file)
+			continue
+		}
+		if record.Status == jobRunning {
+			now := time.Now()
+			record.Status, record.Error, record.FinishedAt = jobFailed, "interrupted by a server restart", &now
+			store.persist(&record)
+		}
+		store.jobs[record.JobID] = &record
+	}
+	return store, nil
+}
+
+// add records a newly submitted job
+func (j *jobStore) add(job ScanJob) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	record := &jobRecord{ScanJob: job}
+	j.jobs[job.JobID] = record
+	j.persist(record)
+}
+
+// finish records the outcome of a job: its output, or the error it failed with
+func (j *jobStore) finish(id string, output *KRRScanOutput, failure string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	record, ok := j.jobs[id]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	record.FinishedAt = &now
+	if failure != "" {
+		record.Status, record.Error = jobFailed, failure
+	} else {
+		record.Status, record.Output = jobSucceeded, output
+	}
+	j.persist(record)
+}
+
+// get returns the job with the given ID if it is visible to tenant. Tenants only see their own
+// jobs; an empty tenant sees every job.
+func (j *jobStore) get(id, tenant string) (jobRecord, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	record, ok := j.jobs[id]
+	if !ok || (tenant != "" && record.Tenant != tenant) {
+		return jobRecord{}, false
+	}
+	return *record, true
+}
+
+// prune drops the jobs that finished more than retention ago, and the oldest finished jobs
+// beyond maxRetainedJobs. Running jobs are always kept.
+func (j *jobStore) prune(retention time.Duration, now time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var finished []*jobRecord
+	for id, record := range j.jobs {
+		if record.FinishedAt == nil {
+			continue
+		}
+		if now.Sub(*record.FinishedAt) > retention {
+			j.remove(id)
+			continue
+		}
+		finished = append(finished, record)
+	}
+	if len(finished) <= maxRetainedJobs {
+		return
+	}
+	sort.Slice(finished, func(a, b int) bool {
+		return finished[a].FinishedAt.Before(*finished[b].FinishedAt)
+	})
+	for _, record := range finished[:len(finished)-maxRetainedJobs] {
+		j.remove(record.JobID)
+	}
+}
+
+// remove deletes a job and its file; the caller holds mu
+func (j *jobStore) remove(id string) {
+	delete(j.jobs, id)
+	if j.dir != "" {
+		if err := os.Remove(j.path(id)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove job file for %s: %v", id, err)
+		}
+	}
+}
+
+// persist writes a job's file, replacing it atomically; the caller holds mu. Failures are
+// logged, leaving the job in memory only.
+func (j *jobStore) persist(record *jobRecord) {
+	if j.dir == "" {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("Failed to encode job %s: %v", record.JobID, err)
+		return
+	}
+	tmp := j.path(record.JobID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		log.Printf("Failed to persist job %s: %v", record.JobID, err)
+		return
+	}
+	if err := os.Rename(tmp, j.path(record.JobID)); err != nil {
+		log.Printf("Failed to persist job %s: %v", record.JobID, err)
+		os.Remove(tmp)
+	}
+}
+
+// path is the file a job is persisted in. Job IDs are generated hex strings, but are cleaned
+// anyway since they also arrive as tool arguments.
+func (j *jobStore) path(id string) string {
+	return filepath.Join(j.dir, strings.NewReplacer("/", "_", `\`, "_", "..", "_").Replace(id)+".json")
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+// scanDurationBuckets are the upper bounds, in seconds, of the scan duration histogram. KRR
+// scans take from seconds for a namespace to tens of minutes for a large cluster.
+var scanDurationBuckets = []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600}
+
+// histogram is a cumulative Prometheus histogram with fixed buckets
+type histogram struct {
+	counts []uint64 // per bucket of scanDurationBuckets, non-cumulative
+	count  uint64
+	sum    float64
+}
+
+// observe records one value
+func (h *histogram) observe(value float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(scanDurationBuckets))
+	}
+	for i, bound := range scanDurationBuckets {
+		if value <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// toolCallKey labels a tool invocation counter
+type toolCallKey struct {
+	tool   string
+	result string
+}
+
+// serverMetrics are the server's own metrics, exposed on /metrics. In-flight scans are read
+// from the scan registry at scrape time rather than counted here.
+type serverMetrics struct {
+	mu            sync.Mutex
+	toolCalls     map[toolCallKey]uint64
+	scanDurations map[string]*histogram
+	scanFailures  map[krr.ErrorKind]uint64
+	scanRetries   map[krr.ErrorKind]uint64
+}
+
+// recordToolCall counts a finished tool call; a call failed when it returned an error or an
+// error result
+func (m *serverMetrics) recordToolCall(tool string, failed bool) {
+	result := "success"
+	if failed {
+		result = "error"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.toolCalls == nil {
+		m.toolCalls = make(map[toolCallKey]uint64)
+	}
+	m.toolCalls[toolCallKey{tool: tool, result: result}]++
+}
+
+// recordScan records the duration of a KRR run started by tool and, when it failed, the kind
+// of failure. Untracked runs come from the Go API (MCPServer.Scan) and are labeled "api".
+func (m *serverMetrics) recordScan(tool string, duration time.Duration, err error) {
+	if tool == "" {
+		tool = "api"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.scanDurations == nil {
+		m.scanDurations = make(map[string]*histogram)
+	}
+	h := m.scanDurations[tool]
+	if h == nil {
+		h = &histogram{}
+		m.scanDurations[tool] = h
+	}
+	h.observe(duration.Seconds())
+
+	if err != nil {
+		if m.scanFailures == nil {
+			m.scanFailures = make(map[krr.ErrorKind]uint64)
+		}
+		m.scanFailures[krr.ClassifyError(err)]++
+	}
+}
+
+// recordRetry counts a KRR run retried after failing with kind
+func (m *serverMetrics) recordRetry(kind krr.ErrorKind) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.scanRetries == nil {
+		m.scanRetries = make(map[krr.ErrorKind]uint64)
+	}
+	m.scanRetries[kind]++
+}
+
+// handleMetrics serves the server's metrics in the Prometheus text exposition format
+func (s *MCPServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(s.metrics.format(len(s.running.list("")), len(s.scanSlots)))
+}
+
+// format renders the metrics; inFlight and slotsInUse are sampled by the caller
+func (m *serverMetrics) format(inFlight, slotsInUse int) []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var buf bytes.Buffer
+
+	const toolCalls = "greenops_mcp_tool_calls_total"
+	fmt.Fprintf(&buf, "# HELP %s MCP tool calls by tool and result\n# TYPE %s counter\n", toolCalls, toolCalls)
+	keys := make([]toolCallKey, 0, len(m.toolCalls))
+	for key := range m.toolCalls {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].tool != keys[j].tool {
+			return keys[i].tool < keys[j].tool
+		}
+		return keys[i].result < keys[j].result
+	})
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "%s{tool=%q,result=%q} %d\n", toolCalls, key.tool, key.result, m.toolCalls[key])
+	}
+
+	const durations = "greenops_mcp_scan_duration_seconds"
+	fmt.Fprintf(&buf, "# HELP %s Duration of KRR runs by the tool that started them\n# TYPE %s histogram\n", durations, durations)
+	tools := make([]string, 0, len(m.scanDurations))
+	for tool := range m.scanDurations {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		h := m.scanDurations[tool]
+		var cumulative uint64
+		for i, bound := range scanDurationBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&buf, "%s_bucket{tool=%q,le=%q} %d\n", durations, tool, strconv.FormatFloat(bound, 'f', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&buf, "%s_bucket{tool=%q,le=\"+Inf\"} %d\n", durations, tool, h.count)
+		fmt.Fprintf(&buf, "%s_sum{tool=%q} %s\n", durations, tool, strconv.FormatFloat(h.sum, 'f', -1, 64))
+		fmt.Fprintf(&buf, "%s_count{tool=%q} %d\n", durations, tool, h.count)
+	}
+
+	// Every error kind is exported, zero or not, so rate() works from the first failure
+	const failures = "greenops_mcp_scan_failures_total"
+	fmt.Fprintf(&buf, "# HELP %s Failed KRR runs by error kind\n# TYPE %s counter\n", failures, failures)
+	for _, kind := range krr.ErrorKinds() {
+		fmt.Fprintf(&buf, "%s{error_kind=%q} %d\n", failures, kind, m.scanFailures[kind])
+	}
+
+	const retries = "greenops_mcp_scan_retries_total"
+	fmt.Fprintf(&buf, "# HELP %s KRR runs retried, by the error kind of the failed attempt\n# TYPE %s counter\n", retries, retries)
+	for _, kind := range krr.ErrorKinds() {
+		fmt.Fprintf(&buf, "%s{error_kind=%q} %d\n", retries, kind, m.scanRetries[kind])
+	}
+
+	gauges := []struct {
+		name, help string
+		value      int
+	}{
+		{"greenops_mcp_scans_in_flight", "Tracked scans in progress, including those waiting for a scan slot", inFlight},
+		{"greenops_mcp_scan_slots_in_use", "KRR processes running, out of max_concurrent_scans", slotsInUse},
+	}
+	for _, gauge := range gauges {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", gauge.name, gauge.help, gauge.name, gauge.name, gauge.value)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+func TestMetricsCountFailuresByKind(t *testing.T) {
+	for _, kind := range krr.ErrorKinds() {
+		t.Run(string(kind), func(t *testing.T) {
+			var m serverMetrics
+			m.recordScan("krr_scan", time.Second, &krr.ScanError{Kind: kind, ExitCode: 1})
+
+			out := string(m.format(0, 0))
+			for _, other := range krr.ErrorKinds() {
+				want := 0
+				if other == kind {
+					want = 1
+				}
+				line := fmt.Sprintf("greenops_mcp_scan_failures_total{error_kind=%q} %d\n", other, want)
+				if !strings.Contains(out, line) {
+					t.Errorf("metrics lack %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestMetricsClassifyPlainErrors(t *testing.T) {
+	var m serverMetrics
+	m.recordScan("krr_scan", time.Second, context.DeadlineExceeded)
+	m.recordScan("krr_scan", time.Second, errors.New("boom"))
+	m.recordScan("krr_scan", time.Second, nil)
+
+	out := string(m.format(0, 0))
+	for _, line := range []string{
+		`greenops_mcp_scan_failures_total{error_kind="timeout"} 1`,
+		`greenops_mcp_scan_failures_total{error_kind="krr_failed"} 1`,
+		`greenops_mcp_scan_duration_seconds_count{tool="krr_scan"} 3`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("metrics lack %q", line)
+		}
+	}
+}
+
+func TestMetricsCountRetriesByKind(t *testing.T) {
+	var m serverMetrics
+	out := string(m.format(0, 0))
+	for _, kind := range krr.ErrorKinds() {
+		line := fmt.Sprintf("greenops_mcp_scan_retries_total{error_kind=%q} 0\n", kind)
+		if !strings.Contains(out, line) {
+			t.Errorf("metrics lack %q before any retry", line)
+		}
+	}
+
+	m.recordRetry(krr.ErrorKindRateLimited)
+	m.recordRetry(krr.ErrorKindRateLimited)
+	out = string(m.format(0, 0))
+	if line := `greenops_mcp_scan_retries_total{error_kind="rate_limited"} 2`; !strings.Contains(out, line+"\n") {
+		t.Errorf("metrics lack %q", line)
+	}
+	if line := `greenops_mcp_scan_retries_total{error_kind="timeout"} 0`; !strings.Contains(out, line+"\n") {
+		t.Errorf("metrics lack %q", line)
+	}
+}
+
+func TestRunScanCountsRetries(t *testing.T) {
+	s, fake := newTestServer(t, nil)
+	fake.err = &krr.ScanError{Kind: krr.ErrorKindRateLimited, ExitCode: 1, Stderr: "429 Too Many Requests"}
+	executor := krr.NewRetryingExecutor(fake, 2, time.Millisecond)
+
+	if _, err := s.runScan(context.Background(), executor, krr.ScanOptions{}); err == nil {
+		t.Fatal("runScan() succeeded, want the rate limit error")
+	}
+	out := string(s.metrics.format(0, 0))
+	for _, line := range []string{
+		`greenops_mcp_scan_retries_total{error_kind="rate_limited"} 2`,
+		`greenops_mcp_scan_failures_total{error_kind="rate_limited"} 1`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("metrics lack %q", line)
+		}
+	}
+}
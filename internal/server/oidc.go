@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/oidc"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// identityClaim is the TokenInfo.Extra key holding the caller's identity
+const identityClaim = "identity"
+
+// withOIDC rejects MCP requests without a valid bearer JWT from the configured issuer (401) and
+// attaches the token's claims to the request, where the SDK hands them to tool handlers as
+// req.Extra.TokenInfo. The verifier is looked up per request, so enabling or disabling OIDC
+// on reload takes effect immediately; requests pass straight through while it is disabled.
+func (s *MCPServer) withOIDC(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		verifier := s.live().oidc
+		if verifier == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if bearerToken(r.Header) == "" {
+			authFailure(w, r, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+		verify := func(ctx context.Context, token string, r *http.Request) (*auth.TokenInfo, error) {
+			return s.verifyOIDCToken(ctx, verifier, token, r)
+		}
+		auth.RequireBearerToken(verify, nil)(next).ServeHTTP(w, r)
+	}
+}
+
+// verifyOIDCToken verifies an OIDC token for auth.RequireBearerToken. Failures caused by the
+// token map to 401; failures reaching the issuer map to 500.
+func (s *MCPServer) verifyOIDCToken(ctx context.Context, verifier *oidc.Verifier, token string, r *http.Request) (*auth.TokenInfo, error) {
+	claims, err := verifier.Verify(ctx, token)
+	if err != nil {
+		logAuthFailure(r, err.Error())
+		if errors.Is(err, oidc.ErrInvalidToken) {
+			return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+		}
+		return nil, err
+	}
+
+	identity, _ := claims.Raw[s.config().OIDCUsernameClaim].(string)
+	if identity == "" {
+		identity = claims.Subject
+	}
+	info := &auth.TokenInfo{
+		Scopes:     claims.Scopes,
+		Expiration: claims.ExpiresAt,
+		Extra: map[string]any{
+			"sub":         claims.Subject,
+			identityClaim: identity,
+		},
+	}
+	if s.config().TenantRouting == config.TenantRoutingClaim {
+		info.Extra[tenantsClaim] = claimStrings(claims.Raw[s.config().TenantClaim])
+	}
+	return info, nil
+}
+
+// claimStrings reads a claim that is either a single string or an array of strings
+func claimStrings(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		if value != "" {
+			return []string{value}
+		}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// callerIdentity returns the identity of the OIDC-authenticated caller of a tool, or ""
+func callerIdentity(req *mcp.CallToolRequest) string {
+	if req == nil || req.Extra == nil || req.Extra.TokenInfo == nil {
+		return ""
+	}
+	identity, _ := req.Extra.TokenInfo.Extra[identityClaim].(string)
+	return identity
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"greenops-mcp/internal/config"
+)
+
+func TestOIDCFollowsReload(t *testing.T) {
+	cfg := config.DefaultConfig()
+	s, _ := newTestServer(t, cfg)
+	handler := s.withOIDC(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(token string) int {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, r)
+		return recorder.Code
+	}
+	reloadWith := func(issuer, audience string) {
+		t.Helper()
+		next := *cfg
+		next.OIDCIssuerURL, next.OIDCAudience = issuer, audience
+		s.loadConfig = func() (*config.Config, error) { return &next, nil }
+		if err := s.reload(); err != nil {
+			t.Fatalf("reload() error = %v", err)
+		}
+	}
+
+	if code := serve(""); code != http.StatusOK {
+		t.Fatalf("without OIDC: status %d, want 200", code)
+	}
+
+	// The handler was built with OIDC disabled, yet enforces it once a reload enables it
+	reloadWith("https://issuer.example.com", "greenops")
+	if code := serve(""); code != http.StatusUnauthorized {
+		t.Errorf("OIDC enabled, no token: status %d, want 401", code)
+	}
+	if code := serve("not-a-jwt"); code != http.StatusUnauthorized {
+		t.Errorf("OIDC enabled, malformed token: status %d, want 401", code)
+	}
+
+	// Disabling it again lets requests through, token or not, instead of verifying with no verifier
+	reloadWith("", "")
+	if code := serve("not-a-jwt"); code != http.StatusOK {
+		t.Errorf("OIDC disabled, with token: status %d, want 200", code)
+	}
+	if code := serve(""); code != http.StatusOK {
+		t.Errorf("OIDC disabled, no token: status %d, want 200", code)
+	}
+}
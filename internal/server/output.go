@@ -0,0 +1,167 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+)
+
+// Output modes accepted by the krr_scan output_format argument
+const (
+	outputModeTable    = "table"
+	outputModeCost     = "cost"
+	outputModeMarkdown = "markdown"
+	outputModeDelta    = "delta"
+	outputModeJSON     = "json"
+	outputModeYAML     = "yaml"
+)
+
+// outputModes maps each output mode to the KRR formatter it needs
+var outputModes = map[string]krr.OutputFormat{
+	outputModeTable:    krr.OutputTable,
+	outputModeCost:     krr.OutputJSON,
+	outputModeMarkdown: krr.OutputJSON,
+	outputModeDelta:    krr.OutputJSON,
+	outputModeJSON:     krr.OutputJSON,
+	outputModeYAML:     krr.OutputYAML,
+}
+
+// isStructuredMode reports whether an output mode returns machine-readable JSON, in which
+// case failures are returned as JSON too
+func isStructuredMode(mode string) bool {
+	return mode == outputModeCost || mode == outputModeDelta || mode == outputModeJSON
+}
+
+// resolveOutputMode validates the requested output format, defaulting to the server's
+// default_output_format
+func resolveOutputMode(format *string, fallback string) (string, error) {
+	if format == nil || strings.TrimSpace(*format) == "" {
+		format = &fallback
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(*format))
+	if _, ok := outputModes[mode]; !ok {
+		return "", fmt.Errorf("unsupported output format (supported: table, json, yaml, cost, markdown, delta)")
+	}
+	return mode, nil
+}
+
+// costReport is the document returned by the cost output mode
+type costReport struct {
+	Summary krr.Summary      `json:"summary"`
+	Cost    krr.CostEstimate `json:"cost"`
+}
+
+// encodeIndented encodes v as indented JSON after prefix. Tool results carry text content, so
+// the whole document is still built in memory; encoding straight into one builder only saves
+// the intermediate byte slice and the copies json.MarshalIndent plus concatenation would make.
+func encodeIndented(prefix string, v any) (string, error) {
+	var buf strings.Builder
+	buf.WriteString(prefix)
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// costModel returns the cost model configured for the server
+func (s *MCPServer) costModel() krr.CostModel {
+	return krr.CostModel{
+		CPUCostPerCoreHour:   s.config().CPUCostPerCoreHour,
+		MemoryCostPerGiBHour: s.config().MemoryCostPerGiBHour,
+		HoursPerMonth:        krr.HoursPerMonth,
+	}
+}
+
+// truncateRows limits table output to maxRows data rows, keeping the header and any closing
+// border intact and appending a footer with the number of omitted rows. Lines made up only of
+// box-drawing characters (KRR's table borders and row separators) are not counted as rows.
+func truncateRows(table string, maxRows int) string {
+	if maxRows <= 0 {
+		return table
+	}
+
+	lines := strings.Split(strings.TrimRight(table, "\n"), "\n")
+
+	// The header runs up to the first border line that follows some text, or is the first line
+	header := 1
+	for i, line := range lines {
+		if i > 0 && isBorderLine(line) && hasText(lines[:i]) {
+			header = i + 1
+			break
+		}
+		if i >= 5 {
+			break
+		}
+	}
+	if header > len(lines) {
+		return table
+	}
+
+	body := lines[header:]
+	var closing string
+	if len(body) > 0 && isBorderLine(body[len(body)-1]) {
+		closing = body[len(body)-1]
+		body = body[:len(body)-1]
+	}
+
+	rows := 0
+	cut := len(body)
+	for i, line := range body {
+		if isBorderLine(line) {
+			continue
+		}
+		if rows == maxRows {
+			cut = i
+			break
+		}
+		rows++
+	}
+	omitted := 0
+	for _, line := range body[cut:] {
+		if !isBorderLine(line) {
+			omitted++
+		}
+	}
+	if omitted == 0 {
+		return table
+	}
+
+	kept := append(lines[:header:header], body[:cut]...)
+	for len(kept) > header && isBorderLine(kept[len(kept)-1]) {
+		kept = kept[:len(kept)-1]
+	}
+	if closing != "" {
+		kept = append(kept, closing)
+	}
+	return strings.Join(kept, "\n") + fmt.Sprintf("\n(%d more rows omitted)\n", omitted)
+}
+
+// isBorderLine reports whether a line only contains table border characters
+func isBorderLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+	for _, r := range trimmed {
+		if !strings.ContainsRune("-=+|─━│┃┌┐└┘├┤┬┴┼┏┓┗┛┡┩┢┪╇╈╋╞╡╪═║╔╗╚╝╠╣╦╩╬ ", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasText reports whether any of the lines contains something other than borders
+func hasText(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" && !isBorderLine(line) {
+			return true
+		}
+	}
+	return false
+}
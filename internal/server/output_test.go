@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"greenops-mcp/internal/krr"
+)
+
+// largeScanResult is a parsed scan of n containers whose RawOutput holds KRR's JSON for them,
+// as the executor returns it
+func largeScanResult(b *testing.B, n int) *krr.ScanResult {
+	b.Helper()
+	result := &krr.ScanResult{Cluster: "prod"}
+	for i := range n {
+		result.Resources = append(result.Resources, krr.Resource{
+			Name:        fmt.Sprintf("workload-%d", i),
+			Namespace:   fmt.Sprintf("team-%d", i%40),
+			Kind:        "Deployment",
+			Container:   "app",
+			Pods:        []string{fmt.Sprintf("workload-%d-7d9f8b6c4d-2xk8p", i)},
+			Current:     krr.ResourceRequirements{CPU: "500m", Memory: "512Mi"},
+			Recommended: krr.ResourceRequirements{CPU: "120m", Memory: "300Mi"},
+			Severity:    "WARNING",
+		})
+	}
+	raw, err := json.Marshal(result.Resources)
+	if err != nil {
+		b.Fatal(err)
+	}
+	result.RawOutput = string(raw)
+	return result
+}
+
+func BenchmarkEncodeScanResult(b *testing.B) {
+	result := largeScanResult(b, 5000)
+
+	// What the JSON output mode did before: marshal everything, RawOutput included, then copy
+	b.Run("MarshalIndent", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			data, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = "Scan results:\n" + string(data)
+		}
+	})
+
+	b.Run("encodeIndented", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			structured := *result
+			structured.RawOutput = ""
+			if _, err := encodeIndented("Scan results:\n", &structured); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestEncodeIndented(t *testing.T) {
+	got, err := encodeIndented("Summary:\n", map[string]any{"url": "https://example.com/?a=1&b=<2>", "n": 1})
+	if err != nil {
+		t.Fatalf("encodeIndented() error = %v", err)
+	}
+	want := "Summary:\n{\n  \"n\": 1,\n  \"url\": \"https://example.com/?a=1&b=<2>\"\n}"
+	if got != want {
+		t.Errorf("encodeIndented() = %q, want %q", got, want)
+	}
+}
+
+// krrTable is a KRR table as Rich renders it, with a separator between rows
+const krrTable = `┏━━━━━┳━━━━━━━━━━━┳━━━━━━┓
+┃ Num ┃ Namespace ┃ Name ┃
+┡━━━━━╇━━━━━━━━━━━╇━━━━━━┩
+│ 1   │ shop      │ web  │
+├─────┼───────────┼──────┤
+│ 2   │ shop      │ api  │
+├─────┼───────────┼──────┤
+│ 3   │ shop      │ db   │
+└─────┴───────────┴──────┘
+`
+
+func TestTruncateRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   string
+		maxRows int
+		want    string
+	}{
+		{
+			name:    "rich table keeps whole rows and the closing border",
+			table:   krrTable,
+			maxRows: 2,
+			want: `┏━━━━━┳━━━━━━━━━━━┳━━━━━━┓
+┃ Num ┃ Namespace ┃ Name ┃
+┡━━━━━╇━━━━━━━━━━━╇━━━━━━┩
+│ 1   │ shop      │ web  │
+├─────┼───────────┼──────┤
+│ 2   │ shop      │ api  │
+└─────┴───────────┴──────┘
+(1 more rows omitted)
+`,
+		},
+		{
+			name:    "rich table down to one row",
+			table:   krrTable,
+			maxRows: 1,
+			want: `┏━━━━━┳━━━━━━━━━━━┳━━━━━━┓
+┃ Num ┃ Namespace ┃ Name ┃
+┡━━━━━╇━━━━━━━━━━━╇━━━━━━┩
+│ 1   │ shop      │ web  │
+└─────┴───────────┴──────┘
+(2 more rows omitted)
+`,
+		},
+		{
+			name:    "csv keeps the header line",
+			table:   "namespace,name\nshop,web\nshop,api\nshop,db\n",
+			maxRows: 2,
+			want:    "namespace,name\nshop,web\nshop,api\n(1 more rows omitted)\n",
+		},
+		{name: "fewer rows than the limit", table: krrTable, maxRows: 3, want: krrTable},
+		{name: "no limit", table: krrTable, maxRows: 0, want: krrTable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateRows(tt.table, tt.maxRows); got != tt.want {
+				t.Errorf("truncateRows(%d) =\n%s\nwant\n%s", tt.maxRows, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"log"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// applyScanPolicy checks the operator's scan scope limits (max_history_duration and
+// require_namespace) on options. A scan without an explicit history is capped at
+// max_history_duration when KRR's default window would exceed it. runScan enforces the policy
+// on every scan; tools may check it earlier to reject a call before doing any work.
+func (s *MCPServer) applyScanPolicy(options *krr.ScanOptions) validationErrors {
+	var violations validationErrors
+
+	if s.config().RequireNamespace && options.Namespace == "" {
+		violations.add("namespace", nil, "the server requires every scan to target a namespace")
+	}
+
+	if limit := s.config().MaxHistoryDuration; limit > 0 {
+		if options.HistoryDuration > limit {
+			violations.add("history_duration", options.HistoryDuration.String(), "exceeds the server's max_history_duration of "+limit.String())
+		} else if options.HistoryDuration == 0 && krr.DefaultHistoryDuration > limit {
+			log.Printf("Capping scan history at max_history_duration %s", limit)
+			options.HistoryDuration = limit
+		}
+	}
+
+	return violations
+}
+
+// policyError is returned by runScan for a scan the scan policy rejects
+type policyError struct {
+	violations validationErrors
+}
+
+// Error implements the error interface
+func (e *policyError) Error() string {
+	messages := make([]string, len(e.violations))
+	for i, violation := range e.violations {
+		messages[i] = violation.Error()
+	}
+	return "scan rejected by server policy: " + strings.Join(messages, "; ")
+}
+
+// policyResult builds the tool result for scan policy violations
+func policyResult(violations validationErrors) *mcp.CallToolResult {
+	return violations.resultWithCode("policy_violation", "Scan rejected by server policy")
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// policyConfig is a configuration requiring a namespace and capping history at 7 days
+func policyConfig(t *testing.T) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.RequireNamespace = true
+	cfg.MaxHistoryDuration = 7 * 24 * time.Hour
+	cfg.ArtifactDir = t.TempDir()
+	return cfg
+}
+
+// assertPolicyResult fails unless result is a policy_violation error result
+func assertPolicyResult(t *testing.T, result *mcp.CallToolResult) {
+	t.Helper()
+	if result == nil || !result.IsError {
+		t.Fatalf("result = %+v, want a policy violation", result)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, `"error": "policy_violation"`) {
+		t.Fatalf("result = %s, want a policy violation", text)
+	}
+}
+
+func TestApplyScanPolicy(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	tests := []struct {
+		name        string
+		require     bool
+		maxHistory  time.Duration
+		options     krr.ScanOptions
+		wantFields  []string
+		wantHistory time.Duration
+	}{
+		{
+			name:    "disabled by default",
+			options: krr.ScanOptions{HistoryDuration: 60 * 24 * time.Hour},
+			// Nothing is capped either
+			wantHistory: 60 * 24 * time.Hour,
+		},
+		{
+			name:       "namespace required",
+			require:    true,
+			wantFields: []string{"namespace"},
+		},
+		{
+			name:    "namespace given",
+			require: true,
+			options: krr.ScanOptions{Namespace: "shop"},
+		},
+		{
+			name:        "history within the limit",
+			maxHistory:  week,
+			options:     krr.ScanOptions{HistoryDuration: week},
+			wantHistory: week,
+		},
+		{
+			name:        "history over the limit",
+			maxHistory:  week,
+			options:     krr.ScanOptions{HistoryDuration: week + time.Hour},
+			wantFields:  []string{"history_duration"},
+			wantHistory: week + time.Hour,
+		},
+		{
+			name:        "KRR's default history is capped",
+			maxHistory:  week,
+			wantHistory: week,
+		},
+		{
+			name:        "both limits",
+			require:     true,
+			maxHistory:  week,
+			options:     krr.ScanOptions{HistoryDuration: 30 * 24 * time.Hour},
+			wantFields:  []string{"namespace", "history_duration"},
+			wantHistory: 30 * 24 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.RequireNamespace = tt.require
+			cfg.MaxHistoryDuration = tt.maxHistory
+			s, _ := newTestServer(t, cfg)
+
+			options := tt.options
+			violations := s.applyScanPolicy(&options)
+			var fields []string
+			for _, violation := range violations {
+				fields = append(fields, violation.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("violations on %v, want %v", fields, tt.wantFields)
+			}
+			if options.HistoryDuration != tt.wantHistory {
+				t.Errorf("history duration = %s, want %s", options.HistoryDuration, tt.wantHistory)
+			}
+		})
+	}
+}
+
+func TestRunScanEnforcesPolicy(t *testing.T) {
+	s, fake := newTestServer(t, policyConfig(t))
+
+	_, err := s.runScan(context.Background(), fake, krr.ScanOptions{})
+	var policyErr *policyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("runScan() error = %v, want a policy error", err)
+	}
+	if len(fake.options()) != 0 {
+		t.Fatal("KRR ran for a scan the policy rejects")
+	}
+
+	if _, err := s.runScan(context.Background(), fake, krr.ScanOptions{Namespace: "shop"}); err != nil {
+		t.Fatalf("runScan() error = %v", err)
+	}
+	if got := fake.options()[0].HistoryDuration; got != 7*24*time.Hour {
+		t.Errorf("KRR ran with history %s, want it capped at 7 days", got)
+	}
+}
+
+func TestScanEntryPointsEnforcePolicy(t *testing.T) {
+	ctx := context.Background()
+	tooLong := "30d"
+	namespace := "shop"
+
+	tests := []struct {
+		name string
+		call func(s *MCPServer) *mcp.CallToolResult
+	}{
+		{"krr_scan without namespace", func(s *MCPServer) *mcp.CallToolResult {
+			result, _, _ := s.handleScanTyped(ctx, nil, KRRScanArguments{})
+			return result
+		}},
+		{"krr_scan over max_history_duration", func(s *MCPServer) *mcp.CallToolResult {
+			result, _, _ := s.handleScanTyped(ctx, nil, KRRScanArguments{Namespace: &namespace, HistoryDuration: &tooLong})
+			return result
+		}},
+		{"krr_cluster_summary", func(s *MCPServer) *mcp.CallToolResult {
+			result, _, _ := s.handleClusterSummary(ctx, nil, KRRClusterSummaryArguments{})
+			return result
+		}},
+		{"krr_export_resources", func(s *MCPServer) *mcp.CallToolResult {
+			result, _, _ := s.handleExportResources(ctx, nil, KRRExportResourcesArguments{Namespace: &namespace, HistoryDuration: &tooLong})
+			return result
+		}},
+		{"krr_watch", func(s *MCPServer) *mcp.CallToolResult {
+			result, _, _ := s.handleWatch(ctx, nil, KRRWatchArguments{})
+			return result
+		}},
+		{"krr_schedule_scan", func(s *MCPServer) *mcp.CallToolResult {
+			result, _, _ := s.handleScheduleScan(ctx, nil, KRRScheduleScanArguments{Name: "nightly", Cron: "@daily"})
+			return result
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake := newTestServer(t, policyConfig(t))
+			assertPolicyResult(t, tt.call(s))
+			if len(fake.options()) != 0 {
+				t.Fatal("KRR ran for a scan the policy rejects")
+			}
+		})
+	}
+}
+
+func TestBatchScanCapsHistory(t *testing.T) {
+	s, fake := newTestServer(t, policyConfig(t))
+
+	result, _, _ := s.handleBatchScan(context.Background(), nil, KRRBatchScanArguments{Namespaces: []string{"shop", "billing"}})
+	if result != nil {
+		t.Fatalf("handleBatchScan() = %+v", result)
+	}
+	scans := fake.options()
+	if len(scans) != 2 {
+		t.Fatalf("KRR ran %d times, want 2", len(scans))
+	}
+	for _, options := range scans {
+		if options.HistoryDuration != 7*24*time.Hour {
+			t.Errorf("namespace %s scanned with history %s, want it capped at 7 days", options.Namespace, options.HistoryDuration)
+		}
+	}
+}
+
+func TestAPIScanEnforcesPolicy(t *testing.T) {
+	s, fake := newTestServer(t, policyConfig(t))
+
+	_, err := s.Scan(context.Background(), krr.ScanOptions{})
+	var policyErr *policyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Scan() error = %v, want a policy error", err)
+	}
+	if _, err := s.Scan(context.Background(), krr.ScanOptions{Namespace: "shop", HistoryDuration: 30 * 24 * time.Hour}); !errors.As(err, &policyErr) {
+		t.Fatalf("Scan() error = %v, want a policy error", err)
+	}
+	if len(fake.options()) != 0 {
+		t.Fatal("KRR ran for a scan the policy rejects")
+	}
+}
+
+func TestScheduledScanEnforcesPolicy(t *testing.T) {
+	cfg := policyConfig(t)
+	cfg.ScheduledScans = []config.ScheduleEntry{{Name: "nightly", Cron: "@daily"}}
+	s, fake := newTestServer(t, cfg)
+
+	scan := s.schedules.scans["nightly"]
+	s.runScheduledScan(context.Background(), scan, time.Now())
+	if len(fake.options()) != 0 {
+		t.Fatal("KRR ran for a scheduled scan the policy rejects")
+	}
+	if last := scan.last.Load(); last == nil || !strings.Contains(last.Error, "policy") {
+		t.Errorf("last run = %+v, want a policy error", last)
+	}
+}
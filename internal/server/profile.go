@@ -0,0 +1,24 @@
+package server
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+)
+
+// scanProfile returns the named scan profile from the configuration, or an error listing the
+// defined profiles
+func (s *MCPServer) scanProfile(name string) (krr.ScanOptions, error) {
+	profile, ok := s.config().Profiles[strings.TrimSpace(name)]
+	if !ok {
+		names := slices.Sorted(maps.Keys(s.config().Profiles))
+		if len(names) == 0 {
+			return krr.ScanOptions{}, fmt.Errorf("unknown profile (the server defines no profiles)")
+		}
+		return krr.ScanOptions{}, fmt.Errorf("unknown profile (defined profiles: %s)", strings.Join(names, ", "))
+	}
+	return profile, nil
+}
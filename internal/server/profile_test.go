@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+)
+
+// profileConfig is a configuration with the profiles staging and prod
+func profileConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Profiles = map[string]krr.ScanOptions{
+		"staging": {
+			Namespace:       "staging",
+			Strategy:        "simple-limit",
+			HistoryDuration: 72 * time.Hour,
+			CPUMin:          "50m",
+			MaxWorkers:      4,
+		},
+		"prod": {Namespace: "prod"},
+	}
+	return cfg
+}
+
+func TestScanProfilePrecedence(t *testing.T) {
+	staging, strategy, namespace, history := "staging", "simple", "checkout", "24h"
+	workers := 8
+	tests := []struct {
+		name      string
+		arguments KRRScanArguments
+		want      krr.ScanOptions
+	}{
+		{
+			name:      "profile only",
+			arguments: KRRScanArguments{Profile: &staging},
+			want:      krr.ScanOptions{Namespace: "staging", Strategy: "simple-limit", HistoryDuration: 72 * time.Hour, CPUMin: "50m", MaxWorkers: 4},
+		},
+		{
+			name:      "arguments override the profile",
+			arguments: KRRScanArguments{Profile: &staging, Namespace: &namespace, Strategy: &strategy, HistoryDuration: &history, KRRWorkers: &workers},
+			want:      krr.ScanOptions{Namespace: "checkout", Strategy: "simple", HistoryDuration: 24 * time.Hour, CPUMin: "50m", MaxWorkers: 8},
+		},
+		{
+			name:      "server defaults without a profile",
+			arguments: KRRScanArguments{Namespace: &namespace},
+			want:      krr.ScanOptions{Namespace: "checkout", Strategy: "simple"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake := newTestServer(t, profileConfig())
+			if result, _, _ := s.handleScanTyped(context.Background(), nil, tt.arguments); result != nil {
+				t.Fatalf("handleScanTyped() = %s", resultText(result))
+			}
+			got := fake.options()[0]
+			if got.Namespace != tt.want.Namespace || got.Strategy != tt.want.Strategy || got.HistoryDuration != tt.want.HistoryDuration || got.CPUMin != tt.want.CPUMin {
+				t.Errorf("options = %+v, want %+v", got, tt.want)
+			}
+			if tt.want.MaxWorkers != 0 && got.MaxWorkers != tt.want.MaxWorkers {
+				t.Errorf("MaxWorkers = %d, want %d", got.MaxWorkers, tt.want.MaxWorkers)
+			}
+		})
+	}
+}
+
+func TestScanUnknownProfile(t *testing.T) {
+	s, fake := newTestServer(t, profileConfig())
+	name := "qa"
+
+	result, _, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{Profile: &name})
+	if result == nil || !result.IsError {
+		t.Fatal("handleScanTyped() succeeded with an unknown profile")
+	}
+	if text := resultText(result); !strings.Contains(text, "defined profiles: prod, staging") {
+		t.Errorf("result = %s, want the defined profiles listed", text)
+	}
+	if n := len(fake.options()); n != 0 {
+		t.Errorf("KRR ran %d times", n)
+	}
+}
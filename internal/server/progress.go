@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressHeartbeat is how often a scan that stays in one stage re-reports it with the time
+// elapsed, so clients can tell a long KRR run from a stalled one
+const progressHeartbeat = 15 * time.Second
+
+// scanProgress sends a tool call's scan stages as MCP progress notifications, if the client
+// asked for them with a progress token. The total is unknown, so progress counts notifications.
+type scanProgress struct {
+	req       *mcp.CallToolRequest
+	token     any
+	requestID string
+	startedAt time.Time
+
+	mu    sync.Mutex
+	sent  int
+	stage string
+}
+
+// newScanProgress prepares progress notifications for the scan of a tool call
+func newScanProgress(req *mcp.CallToolRequest, requestID string) *scanProgress {
+	progress := &scanProgress{req: req, requestID: requestID, startedAt: time.Now()}
+	if req != nil && req.Params != nil && req.Session != nil {
+		progress.token = req.Params.GetProgressToken()
+	}
+	return progress
+}
+
+// start attaches the progress to ctx, so runScan and the executor report their stages, and
+// starts the heartbeat. The returned func stops the heartbeat.
+func (p *scanProgress) start(ctx context.Context) (context.Context, func()) {
+	if p.token == nil {
+		return ctx, func() {}
+	}
+	ctx = krr.WithProgress(ctx, func(stage string) { p.report(ctx, stage) })
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(progressHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.mu.Lock()
+				stage := p.stage
+				p.mu.Unlock()
+				if stage != "" {
+					p.send(ctx, fmt.Sprintf("%s (%s elapsed)", stage, time.Since(p.startedAt).Round(time.Second)))
+				}
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ctx, func() { close(done) }
+}
+
+// report enters a new stage
+func (p *scanProgress) report(ctx context.Context, stage string) {
+	if p.token == nil {
+		return
+	}
+	p.mu.Lock()
+	p.stage = stage
+	p.mu.Unlock()
+	p.send(ctx, stage)
+}
+
+// send sends one notification. Progress must increase with every notification, so it is the
+// running count rather than a fraction of an unknown total.
+func (p *scanProgress) send(ctx context.Context, message string) {
+	p.mu.Lock()
+	p.sent++
+	params := &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Message:       message,
+		Progress:      float64(p.sent),
+	}
+	if err := p.req.Session.NotifyProgress(ctx, params); err != nil {
+		log.Printf("Scan %s: failed to send progress notification: %v", p.requestID, err)
+	}
+	p.mu.Unlock()
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultTopWorkloads is how many workloads the top_overprovisioned prompt ranks by default
+const defaultTopWorkloads = 10
+
+// greenOpsPrompt is a prebuilt analysis workflow: an MCP prompt whose message tells the model
+// which tools to call and how to present the result, so every user gets the same analysis
+type greenOpsPrompt struct {
+	prompt *mcp.Prompt
+	render func(args map[string]string) (string, error)
+}
+
+// Arguments shared by the prompts
+var (
+	namespacePromptArgument = &mcp.PromptArgument{Name: "namespace", Description: "Kubernetes namespace to analyse (optional, all namespaces if not specified)"}
+	contextPromptArgument   = &mcp.PromptArgument{Name: "context", Description: "Kubernetes context of the cluster to analyse (optional, the server's current context if not specified)"}
+)
+
+// greenOpsPrompts are the prompts the server offers
+var greenOpsPrompts = []greenOpsPrompt{
+	{
+		prompt: &mcp.Prompt{
+			Name:        "top_overprovisioned",
+			Title:       "Top over-provisioned workloads",
+			Description: "Summarize the workloads that waste the most CPU and memory, ranked by what right-sizing them would free",
+			Arguments: []*mcp.PromptArgument{
+				namespacePromptArgument,
+				contextPromptArgument,
+				{Name: "count", Description: fmt.Sprintf("How many workloads to list (optional, default %d)", defaultTopWorkloads)},
+			},
+		},
+		render: renderTopOverprovisioned,
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "rightsizing_pr_description",
+			Title:       "Right-sizing PR description",
+			Description: "Draft a pull request description for applying KRR's recommendations to a namespace",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "namespace", Description: "Kubernetes namespace whose workloads the pull request right-sizes", Required: true},
+				contextPromptArgument,
+			},
+		},
+		render: renderRightsizingPR,
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "explain_recommendation",
+			Title:       "Explain a recommendation to a developer",
+			Description: "Explain in plain language why KRR recommends changing one workload's requests, for the developers who own it",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "namespace", Description: "Namespace of the workload", Required: true},
+				{Name: "name", Description: "Name of the workload (e.g. the Deployment name)", Required: true},
+				{Name: "container", Description: "Container to explain (optional, every container if not specified)"},
+				contextPromptArgument,
+			},
+		},
+		render: renderExplainRecommendation,
+	},
+}
+
+// registerPrompts registers the prebuilt prompts
+func (s *MCPServer) registerPrompts() {
+	for _, p := range greenOpsPrompts {
+		s.server.AddPrompt(p.prompt, p.handler)
+	}
+}
+
+// handler checks the required arguments and renders the prompt as a single user message. The
+// SDK does not enforce required arguments itself.
+func (p greenOpsPrompt) handler(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := make(map[string]string, len(req.Params.Arguments))
+	for name, value := range req.Params.Arguments {
+		args[name] = strings.TrimSpace(value)
+	}
+	for _, argument := range p.prompt.Arguments {
+		if argument.Required && args[argument.Name] == "" {
+			return nil, fmt.Errorf("prompt %s: argument %q is required", p.prompt.Name, argument.Name)
+		}
+	}
+
+	text, err := p.render(args)
+	if err != nil {
+		return nil, fmt.Errorf("prompt %s: %w", p.prompt.Name, err)
+	}
+	return &mcp.GetPromptResult{
+		Description: p.prompt.Description,
+		Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: text}},
+		},
+	}, nil
+}
+
+// promptScope describes the namespace and cluster a prompt covers, in words and as the
+// krr_scan arguments that select them
+func promptScope(args map[string]string) (words, toolArgs string) {
+	words = "all namespaces"
+	if args["namespace"] != "" {
+		words = fmt.Sprintf("namespace %q", args["namespace"])
+		toolArgs += fmt.Sprintf(", namespace %q", args["namespace"])
+	}
+	if args["context"] != "" {
+		words += fmt.Sprintf(" of the cluster in context %q", args["context"])
+		toolArgs += fmt.Sprintf(", context %q", args["context"])
+	}
+	return words, toolArgs
+}
+
+// renderTopOverprovisioned renders the top_overprovisioned prompt
+func renderTopOverprovisioned(args map[string]string) (string, error) {
+	count := defaultTopWorkloads
+	if args["count"] != "" {
+		n, err := strconv.Atoi(args["count"])
+		if err != nil || n <= 0 {
+			return "", fmt.Errorf("count must be a positive whole number, got %q", args["count"])
+		}
+		count = n
+	}
+	scope, toolArgs := promptScope(args)
+
+	return fmt.Sprintf(`Summarize the %[1]d most over-provisioned workloads in %[2]s.
+
+1. Call krr_scan with output_format "json" and view "recommend-only"%[3]s.
+2. Rank the containers by the CPU and memory they would free by adopting the recommended requests (current minus recommended). Skip containers whose recommendation is higher than their current request, or that have no recommendation.
+3. Present the top %[1]d as a table: namespace, workload (kind/name), container, current and recommended CPU, current and recommended memory, and what right-sizing frees.
+4. Call krr_scan again with output_format "cost"%[3]s. If it reports a cost model, give the estimated monthly savings; otherwise leave money out rather than guess.
+5. Close with two or three sentences on patterns (for example one team, namespace or workload kind dominating the list) and which workloads to right-size first.
+
+Keep it short enough for a team channel. Do not invent numbers the scan did not return.`, count, scope, toolArgs), nil
+}
+
+// renderRightsizingPR renders the rightsizing_pr_description prompt
+func renderRightsizingPR(args map[string]string) (string, error) {
+	scope, toolArgs := promptScope(args)
+
+	return fmt.Sprintf(`Draft a pull request description for right-sizing the workloads in %[1]s.
+
+1. Call krr_scan with output_format "delta"%[2]s to get, per container, only the requests and limits whose recommendation differs from the current value.
+2. Call krr_scan with output_format "cost"%[2]s for the estimated savings, if a cost model is configured.
+
+Write the description in Markdown with these sections:
+- Summary: one or two sentences on what changes and why (KRR's recommendations from observed Prometheus usage).
+- Changes: a table of workload, container, resource and the old and new value. Mark increases clearly; they fix under-provisioning rather than save money.
+- Impact: the total CPU and memory freed and, if known, the estimated monthly savings.
+- Risk and rollout: call out memory decreases (OOM risk) and workloads with little history, and suggest rolling out one workload at a time while watching restarts and throttling.
+
+Use only values from the scans. Do not include workloads without a change.`, scope, toolArgs), nil
+}
+
+// renderExplainRecommendation renders the explain_recommendation prompt
+func renderExplainRecommendation(args map[string]string) (string, error) {
+	target := fmt.Sprintf("workload %q in namespace %q", args["name"], args["namespace"])
+	toolArgs := fmt.Sprintf("namespace %q, name %q", args["namespace"], args["name"])
+	if args["container"] != "" {
+		target = fmt.Sprintf("container %q of %s", args["container"], target)
+		toolArgs += fmt.Sprintf(", container %q", args["container"])
+	}
+	if args["context"] != "" {
+		target += fmt.Sprintf(" (cluster context %q)", args["context"])
+		toolArgs += fmt.Sprintf(", context %q", args["context"])
+	}
+
+	return fmt.Sprintf(`Explain KRR's resource recommendation for the %[1]s to the developer who owns it. Assume they know their service well but not Kubernetes resource management.
+
+1. Call krr_explain with %[2]s.
+2. Explain in plain language:
+   - what the container requests today and what KRR recommends, for CPU and memory;
+   - what that implies about its real usage over the analysed history window (for example "it rarely uses more than a quarter of the CPU it reserves");
+   - why requests matter: they reserve capacity on the nodes whether it is used or not, and too-low memory requests risk the container being killed;
+   - what they should do: the values to set, and what to watch after the change.
+
+Avoid jargon such as "p99" or "strategy" unless you explain it. If KRR has no recommendation, say why (usually missing metrics) instead of guessing.`, target, toolArgs), nil
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"path"
+	"time"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/notify"
+)
+
+// uploadTimeout bounds a single background report upload
+const uploadTimeout = 2 * time.Minute
+
+// notifyTimeout bounds a single background Slack notification
+const notifyTimeout = 30 * time.Second
+
+// uploadReport uploads the structured scan result in the background and returns its URL.
+// A tenant's reports go under its own prefix. Upload failures are logged and never fail the
+// scan itself.
+func (s *MCPServer) uploadReport(result *krr.ScanResult, tenant string, now time.Time) string {
+	if s.live().uploader == nil {
+		return ""
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("Failed to marshal scan result for upload: %v", err)
+		return ""
+	}
+
+	key := path.Join(tenantDir(s.config().S3Prefix, tenant), "krr-scan-"+now.UTC().Format("20060102T150405.000Z")+".json")
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+		defer cancel()
+		if err := s.live().uploader.Upload(ctx, key, body, "application/json"); err != nil {
+			log.Printf("Failed to upload scan report %s: %v", key, err)
+			return
+		}
+		log.Printf("Uploaded scan report to %s", s.live().uploader.URL(key))
+	}()
+
+	return s.live().uploader.URL(key)
+}
+
+// tenantDir is where a tenant's stored results live under dir: a "tenants/<name>"
+// subdirectory, or dir itself outside multi-tenant mode
+func tenantDir(dir, tenant string) string {
+	if tenant == "" {
+		return dir
+	}
+	return path.Join(dir, "tenants", tenant)
+}
+
+// pushMetrics pushes the scan's savings metrics to the Pushgateway in the background.
+// Failures are logged and never affect the tool response.
+func (s *MCPServer) pushMetrics(result *krr.ScanResult, namespace string) {
+	if s.live().pushgw == nil {
+		return
+	}
+
+	scope := namespace
+	if scope == "" {
+		scope = "all"
+	}
+	metrics := notify.FormatMetrics(krr.SummarizeCluster(result))
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := s.live().pushgw.Push(ctx, scope, metrics); err != nil {
+			log.Printf("Failed to push scan metrics to the Pushgateway: %v", err)
+		}
+	}()
+}
+
+// notifySlack posts the scan's savings summary to Slack in the background.
+// Failures are logged and never affect the tool response.
+func (s *MCPServer) notifySlack(result *krr.ScanResult, namespace string) {
+	if s.live().slack == nil {
+		return
+	}
+
+	message := notify.ScanMessage(result, namespace, s.costModel())
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := s.live().slack.Post(ctx, message); err != nil {
+			log.Printf("Failed to post scan summary to Slack: %v", err)
+		}
+	}()
+}
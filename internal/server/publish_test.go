@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/artifact"
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+)
+
+// fakeUploader records uploaded objects and fails with err when set
+type fakeUploader struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	err      error
+	uploaded chan string
+}
+
+func newFakeUploader() *fakeUploader {
+	return &fakeUploader{objects: make(map[string][]byte), uploaded: make(chan string, 16)}
+}
+
+func (f *fakeUploader) Upload(ctx context.Context, key string, body []byte, contentType string) error {
+	if f.err == nil {
+		f.mu.Lock()
+		f.objects[key] = body
+		f.mu.Unlock()
+	}
+	f.uploaded <- key
+	return f.err
+}
+
+func (f *fakeUploader) URL(key string) string {
+	return "https://reports.example.com/" + key
+}
+
+// wait blocks until an upload is attempted and returns its key, failing the test after a while
+func (f *fakeUploader) wait(t *testing.T) string {
+	t.Helper()
+	select {
+	case key := <-f.uploaded:
+		return key
+	case <-time.After(5 * time.Second):
+		t.Fatal("no report was uploaded")
+		return ""
+	}
+}
+
+// withUploader makes s upload scan reports to uploader
+func withUploader(s *MCPServer, uploader artifact.Uploader) {
+	state := *s.live()
+	state.uploader = uploader
+	s.state.Store(&state)
+}
+
+func TestScanUploadsReport(t *testing.T) {
+	s, fake := newTestServer(t, nil)
+	fake.result = &krr.ScanResult{Resources: []krr.Resource{{Name: "web", Namespace: "shop", Kind: "Deployment"}}}
+	uploader := newFakeUploader()
+	withUploader(s, uploader)
+	format := outputModeJSON
+
+	result, out, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{OutputFormat: &format})
+	if result != nil {
+		t.Fatalf("handleScanTyped() = %s", resultText(result))
+	}
+	key := uploader.wait(t)
+	if want := uploader.URL(key); out.ReportURL != want {
+		t.Errorf("ReportURL = %q, want %q", out.ReportURL, want)
+	}
+	if !strings.HasPrefix(key, "krr-scan-") || !strings.HasSuffix(key, ".json") {
+		t.Errorf("object key = %q, want krr-scan-<timestamp>.json", key)
+	}
+
+	uploader.mu.Lock()
+	body := uploader.objects[key]
+	uploader.mu.Unlock()
+	var uploaded krr.ScanResult
+	if err := json.Unmarshal(body, &uploaded); err != nil {
+		t.Fatalf("uploaded report is not a scan result: %v", err)
+	}
+	if len(uploaded.Resources) != 1 || uploaded.Resources[0].Name != "web" {
+		t.Errorf("uploaded resources = %+v, want the scanned web deployment", uploaded.Resources)
+	}
+}
+
+func TestScanSucceedsWhenUploadFails(t *testing.T) {
+	s, _ := newTestServer(t, nil)
+	uploader := newFakeUploader()
+	uploader.err = errors.New("bucket unavailable")
+	withUploader(s, uploader)
+	format := outputModeJSON
+
+	result, out, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{OutputFormat: &format})
+	if result != nil {
+		t.Fatalf("handleScanTyped() = %s, want the scan to succeed", resultText(result))
+	}
+	uploader.wait(t)
+	if out.ReportURL == "" || out.Result == "" {
+		t.Errorf("output = %+v, want the scan result and report URL", out)
+	}
+}
+
+func TestNoUploadWithoutUploader(t *testing.T) {
+	s, _ := newTestServer(t, nil)
+	format := outputModeJSON
+
+	if _, out, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{OutputFormat: &format}); out.ReportURL != "" {
+		t.Errorf("ReportURL = %q without an uploader", out.ReportURL)
+	}
+}
+
+func TestScanNotifiesSlack(t *testing.T) {
+	s, fake := newTestServer(t, nil)
+	fake.result = &krr.ScanResult{Resources: []krr.Resource{{Name: "web", Namespace: "shop", Kind: "Deployment", Container: "app",
+		Current: krr.ResourceRequirements{CPU: "500m"}, Recommended: krr.ResourceRequirements{CPU: "100m"}}}}
+	slack := newFakeSlack()
+	withSlack(s, slack)
+	notify, format := true, outputModeJSON
+
+	if result, _, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{NotifySlack: &notify, OutputFormat: &format}); result != nil {
+		t.Fatalf("handleScanTyped() = %s", resultText(result))
+	}
+	slack.wait(t)
+	slack.mu.Lock()
+	message := slack.messages[0]
+	slack.mu.Unlock()
+	var blocks []string
+	for _, block := range message.Blocks {
+		if block.Text != nil {
+			blocks = append(blocks, block.Text.Text)
+		}
+	}
+	if text := strings.Join(blocks, "\n"); !strings.Contains(text, "Top recommendations") || !strings.Contains(text, "shop/web") {
+		t.Errorf("message blocks = %q, want shop/web among the top recommendations", text)
+	}
+}
+
+func TestScanSucceedsWhenSlackFails(t *testing.T) {
+	s, _ := newTestServer(t, nil)
+	slack := newFakeSlack()
+	slack.err = errors.New("webhook returned status 500")
+	withSlack(s, slack)
+	notify, format := true, outputModeJSON
+
+	result, out, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{NotifySlack: &notify, OutputFormat: &format})
+	if result != nil {
+		t.Fatalf("handleScanTyped() = %s, want the scan to succeed", resultText(result))
+	}
+	slack.wait(t)
+	if out.Result == "" {
+		t.Error("the scan returned no result")
+	}
+}
+
+func TestScanSlackOptOutOverridesDefault(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DefaultNotifySlack = true
+	s, _ := newTestServer(t, cfg)
+	slack := newFakeSlack()
+	withSlack(s, slack)
+	format := outputModeJSON
+
+	notify := false
+	if result, _, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{NotifySlack: &notify, OutputFormat: &format}); result != nil {
+		t.Fatalf("handleScanTyped() = %s", resultText(result))
+	}
+	// The server default applies when the argument is absent
+	if result, _, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{OutputFormat: &format}); result != nil {
+		t.Fatalf("handleScanTyped() = %s", resultText(result))
+	}
+	slack.wait(t)
+	if n := slack.count(); n != 1 {
+		t.Errorf("posted %d Slack messages, want 1", n)
+	}
+}
+
+func TestScanNotifySlackRequiresWebhook(t *testing.T) {
+	s, fake := newTestServer(t, nil)
+	notify := true
+
+	result, _, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{NotifySlack: &notify})
+	if result == nil || !strings.Contains(resultText(result), `"field": "notify_slack"`) {
+		t.Fatalf("handleScanTyped() = %+v, want a notify_slack validation error", result)
+	}
+	if n := len(fake.options()); n != 0 {
+		t.Errorf("KRR ran %d times", n)
+	}
+}
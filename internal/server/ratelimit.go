@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxIdleClients bounds the limiter's per-client state; beyond it, clients whose bucket has
+// refilled completely are forgotten, which loses nothing
+const maxIdleClients = 1024
+
+// tokenBucket allows rate calls per minute, in bursts of up to rate
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// clientLimiter enforces the per-client limits: a token bucket of tool calls per minute and a
+// count of scanning tool calls in flight
+type clientLimiter struct {
+	mu       sync.Mutex
+	buckets  map[string]*tokenBucket
+	inFlight map[string]int
+}
+
+// allow takes a token from the client's bucket, or returns how long until one is available
+func (l *clientLimiter) allow(client string, rate int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.buckets == nil {
+		l.buckets = make(map[string]*tokenBucket)
+	}
+	perSecond := float64(rate) / 60
+	bucket := l.buckets[client]
+	if bucket == nil {
+		if len(l.buckets) >= maxIdleClients {
+			l.prune(rate, perSecond, now)
+		}
+		bucket = &tokenBucket{tokens: float64(rate), updated: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = math.Min(float64(rate), bucket.tokens+now.Sub(bucket.updated).Seconds()*perSecond)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / perSecond * float64(time.Second))
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// prune forgets the clients whose bucket would be full by now
+func (l *clientLimiter) prune(rate int, perSecond float64, now time.Time) {
+	for client, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*perSecond >= float64(rate) {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// acquire reserves one of the client's limit scan slots and returns the func releasing it, or
+// false when the client already has limit scans in flight
+func (l *clientLimiter) acquire(client string, limit int) (func(), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight == nil {
+		l.inFlight = make(map[string]int)
+	}
+	if l.inFlight[client] >= limit {
+		return nil, false
+	}
+	l.inFlight[client]++
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.inFlight[client]--; l.inFlight[client] <= 0 {
+			delete(l.inFlight, client)
+		}
+	}, true
+}
+
+// clientKey identifies the client of a tool call for rate limiting: its tenant, its OIDC
+// identity or else its MCP session. Calls without any of them (stdio) share one key.
+func (s *MCPServer) clientKey(req *mcp.CallToolRequest) string {
+	if req == nil {
+		return ""
+	}
+	if len(s.live().tenants) > 0 {
+		if t := s.requestTenant(req); t != nil {
+			return "tenant:" + t.config.Name
+		}
+	}
+	if identity := callerIdentity(req); identity != "" {
+		return "user:" + identity
+	}
+	if req.Session != nil && req.Session.ID() != "" {
+		return "session:" + req.Session.ID()
+	}
+	return ""
+}
+
+// clientDisplay names a client key in error messages
+func clientDisplay(client string) string {
+	if client == "" {
+		return "this client"
+	}
+	return client
+}
+
+// checkRateLimit returns an error when the client of a tool call has used up its
+// client_rate_limit. It is checked for every tool before its handler runs, so it is a plain
+// error rather than a result: the SDK validates a result's structured output, which a handler's
+// zero output may not satisfy.
+func (s *MCPServer) checkRateLimit(req *mcp.CallToolRequest) error {
+	if s.config().ClientRateLimit <= 0 {
+		return nil
+	}
+	client := s.clientKey(req)
+	if ok, retryAfter := s.limits.allow(client, s.config().ClientRateLimit, time.Now()); !ok {
+		return fmt.Errorf("rate limit exceeded: %s may make %d tool calls per minute; retry in %s",
+			clientDisplay(client), s.config().ClientRateLimit, retryAfter.Truncate(time.Second)+time.Second)
+	}
+	return nil
+}
+
+// acquireClientScan reserves a scan for the client of a tool call under
+// client_max_concurrent_scans. It returns the func releasing the reservation, or an error
+// result when the client is at its limit.
+func (s *MCPServer) acquireClientScan(req *mcp.CallToolRequest) (func(), *mcp.CallToolResult) {
+	if s.config().ClientMaxConcurrentScans <= 0 {
+		return func() {}, nil
+	}
+	client := s.clientKey(req)
+	release, ok := s.limits.acquire(client, s.config().ClientMaxConcurrentScans)
+	if !ok {
+		return nil, errorResult(fmt.Sprintf("Concurrent scan limit reached: %s already has %d scans in progress; wait for one to finish or cancel one with krr_cancel",
+			clientDisplay(client), s.config().ClientMaxConcurrentScans))
+	}
+	return release, nil
+}
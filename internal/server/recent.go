@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+// RecentScan is the outcome of a finished KRR run, kept for diagnostics
+type RecentScan struct {
+	RequestID string `json:"request_id,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	Tenant    string `json:"tenant,omitempty"`
+	User      string `json:"user,omitempty"`
+
+	Namespace string `json:"namespace,omitempty"`
+	Context   string `json:"context,omitempty"`
+	Strategy  string `json:"strategy,omitempty"`
+
+	StartedAt time.Time     `json:"started_at"`
+	Duration  string        `json:"duration"`
+	Success   bool          `json:"success"`
+	ErrorKind krr.ErrorKind `json:"error_kind,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// newRecentScan describes a finished run; ctx supplies the request ID, tool, tenant and user when
+// the scan was tracked
+func newRecentScan(ctx context.Context, options krr.ScanOptions, startedAt time.Time, err error) RecentScan {
+	scan := RecentScan{
+		Namespace: options.Namespace,
+		Context:   options.Context,
+		Strategy:  options.Strategy,
+		StartedAt: startedAt,
+		Duration:  time.Since(startedAt).Round(time.Millisecond).String(),
+		Success:   err == nil,
+	}
+	if info, ok := trackedScan(ctx); ok {
+		scan.RequestID = info.RequestID
+		scan.Tool = info.Tool
+		scan.Tenant = info.Tenant
+		scan.User = info.User
+	}
+	if err != nil {
+		scan.ErrorKind = krr.ClassifyError(err)
+		scan.Error = err.Error()
+	}
+	return scan
+}
+
+// recentScans is a fixed-size ring buffer of the last finished scans. The zero capacity keeps
+// nothing.
+type recentScans struct {
+	mu       sync.Mutex
+	capacity int
+	scans    []RecentScan
+	next     int // index the next scan is written to once the buffer is full
+}
+
+// add records a scan, overwriting the oldest one when the buffer is full
+func (r *recentScans) add(scan RecentScan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.capacity <= 0 {
+		return
+	}
+	if len(r.scans) < r.capacity {
+		r.scans = append(r.scans, scan)
+		return
+	}
+	r.scans[r.next] = scan
+	r.next = (r.next + 1) % r.capacity
+}
+
+// list returns the recorded scans visible to tenant, newest first. Tenants only see their own
+// scans; an empty tenant sees every scan.
+func (r *recentScans) list(tenant string) []RecentScan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scans := make([]RecentScan, 0, len(r.scans))
+	for i := range len(r.scans) {
+		// Walk backwards from the newest entry, which sits just before next
+		scan := r.scans[(r.next-1-i+2*len(r.scans))%len(r.scans)]
+		if tenant == "" || scan.Tenant == tenant {
+			scans = append(scans, scan)
+		}
+	}
+	return scans
+}
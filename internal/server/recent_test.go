@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestRecentScansWrapAround(t *testing.T) {
+	for added := range 8 {
+		t.Run(fmt.Sprintf("%d added", added), func(t *testing.T) {
+			recent := recentScans{capacity: 3}
+			for i := 1; i <= added; i++ {
+				recent.add(RecentScan{RequestID: strconv.Itoa(i)})
+			}
+
+			// The newest three survive, newest first
+			var want []string
+			for i := added; i > 0 && len(want) < 3; i-- {
+				want = append(want, strconv.Itoa(i))
+			}
+			var got []string
+			for _, scan := range recent.list("") {
+				got = append(got, scan.RequestID)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("list() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRecentScansFiltersByTenant(t *testing.T) {
+	recent := recentScans{capacity: 4}
+	for i, tenant := range []string{"alpha", "beta", "alpha", "beta", "alpha"} {
+		recent.add(RecentScan{RequestID: strconv.Itoa(i), Tenant: tenant})
+	}
+	var got []string
+	for _, scan := range recent.list("alpha") {
+		got = append(got, scan.RequestID)
+	}
+	// Scan 0 was overwritten by scan 4
+	if want := []string{"4", "2"}; !slices.Equal(got, want) {
+		t.Errorf("list(alpha) = %v, want %v", got, want)
+	}
+	if n := len(recent.list("")); n != 4 {
+		t.Errorf("list() returned %d scans, want 4", n)
+	}
+}
+
+func TestRecentScansZeroCapacity(t *testing.T) {
+	var recent recentScans
+	recent.add(RecentScan{RequestID: "1"})
+	if scans := recent.list(""); len(scans) != 0 {
+		t.Errorf("list() = %+v, want nothing kept", scans)
+	}
+}
+
+func TestRecentScansConcurrentUse(t *testing.T) {
+	recent := recentScans{capacity: 5}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				recent.add(RecentScan{RequestID: fmt.Sprintf("%d-%d", i, j)})
+				recent.list("")
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(recent.list("")); n != 5 {
+		t.Errorf("list() returned %d scans, want 5", n)
+	}
+}
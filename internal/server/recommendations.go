@@ -0,0 +1,199 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/store"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WorkloadSelector selects containers of a scan; empty fields match anything
+type WorkloadSelector struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Namespace of the workload (optional)"`
+	Kind      string `json:"kind,omitempty" jsonschema:"Workload kind, e.g. 'Deployment' (optional, case-insensitive)"`
+	Name      string `json:"name,omitempty" jsonschema:"Workload name (optional)"`
+	Container string `json:"container,omitempty" jsonschema:"Container name (optional)"`
+}
+
+// matches reports whether the selector selects resource
+func (w WorkloadSelector) matches(resource krr.Resource) bool {
+	return (w.Namespace == "" || w.Namespace == resource.Namespace) &&
+		(w.Kind == "" || strings.EqualFold(w.Kind, resource.Kind)) &&
+		(w.Name == "" || w.Name == resource.Name) &&
+		(w.Container == "" || w.Container == resource.Container)
+}
+
+// selectResources returns the resources any of the selectors selects, in scan order; no
+// selectors select everything
+func selectResources(resources []krr.Resource, selectors []WorkloadSelector) []krr.Resource {
+	if len(selectors) == 0 {
+		return resources
+	}
+	var selected []krr.Resource
+	for _, resource := range resources {
+		for _, selector := range selectors {
+			if selector.matches(resource) {
+				selected = append(selected, resource)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// storedScan is a past scan whose parsed recommendations the server still has
+type storedScan struct {
+	ID         string
+	FinishedAt time.Time
+
+	// Cluster is the registry cluster the scan ran against, when the history recorded one
+	Cluster   string
+	Options   krr.ScanOptions
+	Resources []krr.Resource
+}
+
+// loadScan finds a past scan visible to tenant by request ID, or the newest one for "latest".
+// It looks at the kept scan outputs first, then at the scan history.
+func (s *MCPServer) loadScan(id, tenant string) (storedScan, error) {
+	if scan, ok := s.outputs.get(id, tenant); ok && scan.Output.Summary != nil {
+		loaded := storedScan{ID: scan.RequestID, FinishedAt: scan.FinishedAt, Resources: scan.Output.Recommendations}
+		if scan.Output.EffectiveOptions != nil {
+			loaded.Options = scan.Output.EffectiveOptions.ScanOptions
+		}
+		// The newest kept output is only the latest scan if the history has nothing newer
+		if id != "latest" || s.history == nil {
+			return loaded, nil
+		}
+		if newest := s.history.Query(store.Filter{Tenant: tenant, Limit: 1}); len(newest) == 0 || !newest[0].Timestamp.After(loaded.FinishedAt) {
+			return loaded, nil
+		}
+	}
+
+	if s.history != nil {
+		if id == "latest" {
+			newest := s.history.Query(store.Filter{Tenant: tenant, Limit: 1})
+			if len(newest) == 0 {
+				return storedScan{}, fmt.Errorf("no stored scan yet; run krr_scan first")
+			}
+			id = newest[0].ID
+		}
+		if entry, result, err := s.history.Result(id, tenant); err == nil {
+			return storedScan{ID: entry.ID, FinishedAt: entry.Timestamp, Cluster: entry.Cluster, Options: entry.Options, Resources: result.Resources}, nil
+		}
+	}
+
+	if id == "latest" {
+		return storedScan{}, fmt.Errorf("no scan with kept recommendations yet; run krr_scan with output_format json, or set history_dir to store every scan")
+	}
+	return storedScan{}, fmt.Errorf("scan %s not found; only json-mode scans among the last %d, and scans in the scan history, keep their recommendations", id, s.outputs.capacity)
+}
+
+// loadScopedScan loads a stored scan for the caller ("latest" when scanID is empty), keeping
+// only the containers in namespaces the caller may see
+func (s *MCPServer) loadScopedScan(req *mcp.CallToolRequest, scanID *string) (storedScan, scanScope, error) {
+	id := "latest"
+	if scanID != nil && strings.TrimSpace(*scanID) != "" {
+		id = strings.TrimSpace(*scanID)
+	}
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return storedScan{}, scanScope{}, err
+	}
+	scan, err := s.loadScan(id, scope.tenant)
+	if err != nil {
+		return storedScan{}, scanScope{}, err
+	}
+
+	var resources []krr.Resource
+	for _, resource := range scan.Resources {
+		if scope.allows(resource.Namespace) {
+			resources = append(resources, resource)
+		}
+	}
+	scan.Resources = resources
+	return scan, scope, nil
+}
+
+// SkippedWorkload is a selected workload that is left out, with the reason
+type SkippedWorkload struct {
+	Workload string `json:"workload"`
+	Reason   string `json:"reason"`
+}
+
+// workloadPlan is what the recommendations of a stored scan change in the selected workloads
+type workloadPlan struct {
+	scan storedScan
+
+	// scope carries the kube client of the scan's cluster, and context its context
+	scope   scanScope
+	context string
+
+	// resources are the selected containers the caller may change, and patches the changes
+	// their recommendations make, one per workload
+	resources []krr.Resource
+	patches   []krr.WorkloadPatch
+	skipped   []SkippedWorkload
+
+	// excluded are the selected containers outside the tenant's scope, also listed in skipped
+	excluded []SkippedWorkload
+}
+
+// planWorkloads loads a stored scan ("latest" when scanID is empty) and selects the containers
+// to change. Tenants only reach their own scans and namespaces, through their own kubeconfig.
+func (s *MCPServer) planWorkloads(req *mcp.CallToolRequest, scanID *string, selectors []WorkloadSelector, includeLimits bool) (*workloadPlan, *mcp.CallToolResult) {
+	id := "latest"
+	if scanID != nil && strings.TrimSpace(*scanID) != "" {
+		id = strings.TrimSpace(*scanID)
+	}
+	tenantScope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return nil, errorResult(err.Error())
+	}
+	scan, err := s.loadScan(id, tenantScope.tenant)
+	if err != nil {
+		return nil, errorResult(err.Error())
+	}
+
+	// Workloads live in the cluster the scan ran against; KRR gets cluster_name as the last
+	// --context, so that is the context it read when the scan set one
+	options := krr.ScanOptions{Context: scan.Options.Context, ClusterName: scan.Options.ClusterName}
+	scope, err := s.scopeFor(req, &options)
+	if err != nil {
+		return nil, errorResult(err.Error())
+	}
+	if options.ClusterName != "" {
+		options.Context = options.ClusterName
+	}
+	if scope.tenant == "" && scan.Cluster != "" {
+		if registered, err := s.registryCluster(scan.Cluster); err == nil {
+			scope.kube = registered.kube
+		}
+	}
+
+	plan := &workloadPlan{scan: scan, scope: scope, context: options.Context, excluded: []SkippedWorkload{}}
+	selected := selectResources(scan.Resources, selectors)
+	if len(selected) == 0 {
+		return nil, errorResult(fmt.Sprintf("No container of scan %s matches the selected workloads", scan.ID))
+	}
+	for _, resource := range selected {
+		if !scope.allows(resource.Namespace) {
+			plan.excluded = append(plan.excluded, SkippedWorkload{Workload: resource.Namespace + "/" + resource.Kind + "/" + resource.Name, Reason: "namespace outside the tenant's scope"})
+			continue
+		}
+		plan.resources = append(plan.resources, resource)
+	}
+
+	patches, skipped := krr.BuildPatches(krr.ComputeDelta(&krr.ScanResult{Resources: plan.resources}), includeLimits)
+	plan.patches = patches
+	plan.skipped = append([]SkippedWorkload{}, plan.excluded...)
+	for workload, reason := range skipped {
+		plan.skipped = append(plan.skipped, SkippedWorkload{Workload: workload, Reason: reason})
+	}
+	sort.Slice(plan.skipped, func(i, j int) bool { return plan.skipped[i].Workload < plan.skipped[j].Workload })
+	return plan, nil
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/store"
+)
+
+func TestPlanWorkloadsTargetsTheContextTheScanRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		options krr.ScanOptions
+		want    string
+	}{
+		{name: "context", options: krr.ScanOptions{Context: "kind-dev"}, want: "kind-dev"},
+		{name: "cluster_name wins over context", options: krr.ScanOptions{Context: "kind-dev", ClusterName: "prod-eu"}, want: "prod-eu"},
+		{name: "cluster_name alone", options: krr.ScanOptions{ClusterName: "prod-eu"}, want: "prod-eu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.HistoryDir = t.TempDir()
+			s, _ := newTestServer(t, cfg)
+
+			result := &krr.ScanResult{Resources: []krr.Resource{{
+				Kind: "Deployment", Namespace: "shop", Name: "web", Container: "app",
+				Current:     krr.ResourceRequirements{CPU: "500m", Memory: "512Mi"},
+				Recommended: krr.ResourceRequirements{CPU: "100m", Memory: "256Mi"},
+			}}}
+			entry := store.Entry{ID: "scan-1", Timestamp: time.Now(), Source: "krr_scan", Cluster: historyCluster("", tt.options), Options: tt.options}
+			if err := s.history.Add(entry, result); err != nil {
+				t.Fatal(err)
+			}
+
+			plan, failure := s.planWorkloads(nil, nil, []WorkloadSelector{{Namespace: "shop", Name: "web"}}, false)
+			if failure != nil {
+				t.Fatalf("planWorkloads() = %s", resultText(failure))
+			}
+			if plan.context != tt.want {
+				t.Errorf("plan context = %q, want %q", plan.context, tt.want)
+			}
+			if len(plan.patches) != 1 {
+				t.Errorf("plan patches = %+v, want one for shop/web", plan.patches)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	"greenops-mcp/internal/config"
+)
+
+// SetConfigLoader enables configuration reloads on SIGHUP. load reads the configuration the
+// same way it was read at startup; a reload that fails to load or validate keeps the running
+// configuration.
+func (s *MCPServer) SetConfigLoader(load func() (*config.Config, error)) {
+	s.loadConfig = load
+}
+
+// watchReloads reloads the configuration on every SIGHUP until ctx is done. Without a config
+// loader it does nothing, leaving SIGHUP its default behavior.
+func (s *MCPServer) watchReloads(ctx context.Context) {
+	if s.loadConfig == nil {
+		return
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-hup:
+				log.Printf("Received SIGHUP, reloading configuration")
+				if err := s.reload(); err != nil {
+					log.Printf("Configuration reload failed, keeping the running configuration: %v", err)
+					continue
+				}
+				log.Printf("Configuration reloaded")
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// reload re-reads the configuration and swaps in a runtime state built from it. MCP sessions,
+// in-flight scans and client limits carry over. Settings that only take effect at startup keep
+// their running values and are logged as needing a restart.
+func (s *MCPServer) reload() error {
+	if s.loadConfig == nil {
+		return fmt.Errorf("configuration reloading is not set up")
+	}
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	current := s.live()
+	for _, name := range keepStartupSettings(current.config, cfg) {
+		log.Printf("Configuration reload: %s changed, restart the server to apply it", name)
+	}
+
+	state, err := newRuntimeState(cfg)
+	if err != nil {
+		return err
+	}
+	// A new verifier would rediscover the issuer and refetch its keys for nothing
+	if cfg.OIDCIssuerURL == current.config.OIDCIssuerURL && cfg.OIDCAudience == current.config.OIDCAudience {
+		state.oidc = current.oidc
+	}
+	s.state.Store(state)
+
+	// The KRR path or kubeconfig may have changed, so readiness is checked afresh
+	s.readiness.mu.Lock()
+	s.readiness.checkedAt = time.Time{}
+	s.readiness.kubeCheckedAt = time.Time{}
+	s.readiness.mu.Unlock()
+	return nil
+}
+
+// keepStartupSettings copies the settings that are only read at startup (listener, routes,
+// TLS, scan slots, schedules, logging) from running onto cfg, and returns the names of those
+// that differed
+func keepStartupSettings(running, cfg *config.Config) []string {
+	var changed []string
+	keep := func(name string, differs bool) {
+		if differs {
+			changed = append(changed, name)
+		}
+	}
+	keep("server_name", keepSetting(running.ServerName, &cfg.ServerName))
+	keep("server_version", keepSetting(running.ServerVersion, &cfg.ServerVersion))
+	keep("transport", keepSetting(running.Transport, &cfg.Transport))
+	keep("listen_addr", keepSetting(running.ListenAddr, &cfg.ListenAddr))
+	keep("tls_cert_file", keepSetting(running.TLSCertFile, &cfg.TLSCertFile))
+	keep("tls_key_file", keepSetting(running.TLSKeyFile, &cfg.TLSKeyFile))
+	keep("tls_auto_reload", keepSetting(running.TLSAutoReload, &cfg.TLSAutoReload))
+	keep("tls_client_ca_file", keepSetting(running.TLSClientCAFile, &cfg.TLSClientCAFile))
+	keep("mcp_path", keepSetting(running.MCPPath, &cfg.MCPPath))
+	keep("base_path", keepSetting(running.BasePath, &cfg.BasePath))
+	keep("stateless", keepSetting(running.Stateless, &cfg.Stateless))
+	keep("enable_sse", keepSetting(running.EnableSSE, &cfg.EnableSSE))
+	keep("sse_path", keepSetting(running.SSEPath, &cfg.SSEPath))
+	keep("read_timeout", keepSetting(running.ReadTimeout, &cfg.ReadTimeout))
+	keep("write_timeout", keepSetting(running.WriteTimeout, &cfg.WriteTimeout))
+	keep("idle_timeout", keepSetting(running.IdleTimeout, &cfg.IdleTimeout))
+	keep("max_concurrent_scans", keepSetting(running.MaxConcurrentScans, &cfg.MaxConcurrentScans))
+	keep("recent_scans", keepSetting(running.RecentScans, &cfg.RecentScans))
+	keep("job_dir", keepSetting(running.JobDir, &cfg.JobDir))
+	keep("history_dir", keepSetting(running.HistoryDir, &cfg.HistoryDir))
+	keep("history_retention", keepSetting(running.HistoryRetention, &cfg.HistoryRetention))
+	keep("compress_stored", keepSetting(running.CompressStored, &cfg.CompressStored))
+	keep("scheduled_scans", keepSetting(running.ScheduledScans, &cfg.ScheduledScans))
+	keep("schedule_file", keepSetting(running.ScheduleFile, &cfg.ScheduleFile))
+	keep("log_level", keepSetting(running.LogLevel, &cfg.LogLevel))
+	keep("log_file", keepSetting(running.LogFile, &cfg.LogFile))
+	return changed
+}
+
+// keepSetting sets *field to running, reporting whether it held something else
+func keepSetting[T any](running T, field *T) bool {
+	differs := !reflect.DeepEqual(running, *field)
+	*field = running
+	return differs
+}
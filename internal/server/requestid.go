@@ -0,0 +1,29 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// requestIDHeader lets clients supply their own request ID for correlation
+const requestIDHeader = "X-Request-ID"
+
+// requestID returns the client-supplied request ID, or a new random one
+func requestID(req *mcp.CallToolRequest) string {
+	if req != nil && req.Extra != nil && req.Extra.Header != nil {
+		if id := strings.TrimSpace(req.Extra.Header.Get(requestIDHeader)); id != "" && len(id) <= 128 {
+			return id
+		}
+	}
+	return newRequestID()
+}
+
+// newRequestID generates a random 16-character hex request ID
+func newRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"greenops-mcp/internal/artifact"
+	"greenops-mcp/internal/krr"
+)
+
+// resolveResourcesFile validates a resources_file argument: a saved KRR JSON report under
+// artifact_dir, such as the .raw.json file written by save_to_path or krr_export_resources
+func (s *MCPServer) resolveResourcesFile(relative string) (string, error) {
+	if s.config().ArtifactDir == "" {
+		return "", fmt.Errorf("requires the server to be configured with an artifact_dir")
+	}
+	path, err := artifact.ResolvePath(s.config().ArtifactDir, relative)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("not a readable file in the artifact directory")
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err != nil || info.IsDir() {
+		return "", fmt.Errorf("not a readable file in the artifact directory")
+	}
+	return path, nil
+}
+
+// loadResourcesFile analyses a saved KRR report instead of running a scan. Severity is
+// reclassified with the server's current thresholds, and the namespace, or else the namespace
+// list, is applied as a filter.
+func (s *MCPServer) loadResourcesFile(path string, options krr.ScanOptions) (*krr.ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result, err := krr.ParseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	result.RawOutput = string(data)
+	if info, err := os.Stat(path); err == nil {
+		result.Timestamp = info.ModTime().Format(time.RFC3339)
+	}
+
+	result = krr.ClassifySeverity(result, severityThresholds(s.config()))
+	if options.Namespace != "" {
+		result = krr.FilterResources(result, func(resource krr.Resource) bool {
+			return resource.Namespace == options.Namespace
+		})
+	} else if len(options.Namespaces) > 0 {
+		result = krr.FilterResources(result, func(resource krr.Resource) bool {
+			return slices.Contains(options.Namespaces, resource.Namespace)
+		})
+	}
+	return result, nil
+}
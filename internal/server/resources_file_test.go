@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resourcesFileConfig is a configuration whose artifact directory holds report.raw.json, the
+// saved KRR report of the shop and batch namespaces
+func resourcesFileConfig(t *testing.T) *config.Config {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "krr", "testdata", "v3-simple.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.ArtifactDir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(cfg.ArtifactDir, "report.raw.json"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Profiles = map[string]krr.ScanOptions{
+		"batch":    {Namespaces: []string{"batch"}},
+		"staging":  {Context: "staging"},
+		"longer":   {HistoryDuration: 30 * 24 * time.Hour},
+		"thanos":   {PrometheusLabel: "cluster", ClusterLabelValue: "prod"},
+		"strategy": {Strategy: "simple-limit"},
+	}
+	return cfg
+}
+
+func TestScanResourcesFileFiltersNamespaces(t *testing.T) {
+	shop := "shop"
+	profile := "batch"
+	tests := []struct {
+		name           string
+		arguments      KRRScanArguments
+		wantNamespaces []string
+	}{
+		{"whole report", KRRScanArguments{}, []string{"batch", "shop"}},
+		{"namespace", KRRScanArguments{Namespace: &shop}, []string{"shop"}},
+		{"profile namespaces", KRRScanArguments{Profile: &profile}, []string{"batch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake := newTestServer(t, resourcesFileConfig(t))
+			file, format := "report.raw.json", outputModeJSON
+			arguments := tt.arguments
+			arguments.ResourcesFile, arguments.OutputFormat = &file, &format
+
+			result, out, _ := s.handleScanTyped(context.Background(), nil, arguments)
+			if result != nil {
+				t.Fatalf("handleScanTyped() = %+v", result.Content[0].(*mcp.TextContent).Text)
+			}
+			var namespaces []string
+			for _, resource := range out.Recommendations {
+				if !slices.Contains(namespaces, resource.Namespace) {
+					namespaces = append(namespaces, resource.Namespace)
+				}
+			}
+			slices.Sort(namespaces)
+			if !slices.Equal(namespaces, tt.wantNamespaces) {
+				t.Errorf("recommendations cover %v, want %v", namespaces, tt.wantNamespaces)
+			}
+			if len(fake.options()) != 0 {
+				t.Error("KRR ran for a resources_file scan")
+			}
+		})
+	}
+}
+
+func TestScanResourcesFileRejectsLiveOptions(t *testing.T) {
+	kubeContext, clusterName, strategy, history := "prod", "prod-eu", "simple", "7d"
+	workers := 2
+	profile := func(name string) *string { return &name }
+	tests := []struct {
+		name      string
+		arguments KRRScanArguments
+		wantField string
+	}{
+		{"context argument", KRRScanArguments{Context: &kubeContext}, "context"},
+		{"cluster_name argument", KRRScanArguments{ClusterName: &clusterName}, "cluster_name"},
+		{"strategy argument", KRRScanArguments{Strategy: &strategy}, "strategy"},
+		{"history_duration argument", KRRScanArguments{HistoryDuration: &history}, "history_duration"},
+		{"krr_workers argument", KRRScanArguments{KRRWorkers: &workers}, "krr_workers"},
+		{"context from a profile", KRRScanArguments{Profile: profile("staging")}, "context"},
+		{"strategy from a profile", KRRScanArguments{Profile: profile("strategy")}, "strategy"},
+		{"history_duration from a profile", KRRScanArguments{Profile: profile("longer")}, "history_duration"},
+		{"prometheus_label from a profile", KRRScanArguments{Profile: profile("thanos")}, "prometheus_label"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake := newTestServer(t, resourcesFileConfig(t))
+			file := "report.raw.json"
+			arguments := tt.arguments
+			arguments.ResourcesFile = &file
+
+			result, _, _ := s.handleScanTyped(context.Background(), nil, arguments)
+			if result == nil || !result.IsError {
+				t.Fatalf("handleScanTyped() = %+v, want a validation error", result)
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			if !strings.Contains(text, `"field": "`+tt.wantField+`"`) || !strings.Contains(text, "cannot be combined with resources_file") {
+				t.Errorf("result = %s, want %s rejected", text, tt.wantField)
+			}
+			if len(fake.options()) != 0 {
+				t.Error("KRR ran for a rejected scan")
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+// RunningScan describes a scan that is currently in flight
+type RunningScan struct {
+	RequestID string          `json:"request_id"`
+	Tool      string          `json:"tool"`
+	Tenant    string          `json:"tenant,omitempty"`
+	User      string          `json:"user,omitempty"`
+	Options   krr.ScanOptions `json:"options"`
+	StartedAt time.Time       `json:"started_at"`
+}
+
+// runningScan is a registry entry: the scan's description and the cancel func of its context
+type runningScan struct {
+	info   RunningScan
+	cancel context.CancelFunc
+}
+
+// runningScanKey is the context key under which track stores a scan's description
+type runningScanKey struct{}
+
+// trackedScan returns the description of the tracked scan ctx belongs to, if any
+func trackedScan(ctx context.Context) (RunningScan, bool) {
+	info, ok := ctx.Value(runningScanKey{}).(RunningScan)
+	return info, ok
+}
+
+// scanRegistry tracks in-flight scans by request ID so they can be listed and canceled
+type scanRegistry struct {
+	mu    sync.Mutex
+	scans map[string]*runningScan
+
+	// emptied is closed when the last scan is removed, waking wait
+	emptied chan struct{}
+}
+
+// track registers a scan started within scope, whose tenant and user are empty outside
+// multi-tenant mode and without OIDC. It returns a cancelable context for the scan carrying its
+// description, the request ID it was registered under and a func that removes it again. A
+// request ID already in use (clients may reuse X-Request-ID) is replaced by a fresh one.
+func (r *scanRegistry) track(ctx context.Context, id, tool string, scope scanScope, options krr.ScanOptions) (context.Context, string, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.scans == nil {
+		r.scans = make(map[string]*runningScan)
+	}
+	for r.scans[id] != nil {
+		id = newRequestID()
+	}
+	info := RunningScan{
+		RequestID: id,
+		Tool:      tool,
+		Tenant:    scope.tenant,
+		User:      scope.user,
+		Options:   options,
+		StartedAt: time.Now(),
+	}
+	r.scans[id] = &runningScan{info: info, cancel: cancel}
+
+	return context.WithValue(ctx, runningScanKey{}, info), id, func() {
+		r.mu.Lock()
+		delete(r.scans, id)
+		if len(r.scans) == 0 && r.emptied != nil {
+			close(r.emptied)
+			r.emptied = nil
+		}
+		r.mu.Unlock()
+		cancel()
+	}
+}
+
+// list returns the in-flight scans visible to tenant, oldest first. Tenants only see their own
+// scans; an empty tenant sees every scan.
+func (r *scanRegistry) list(tenant string) []RunningScan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scans := make([]RunningScan, 0, len(r.scans))
+	for _, scan := range r.scans {
+		if tenant == "" || scan.info.Tenant == tenant {
+			scans = append(scans, scan.info)
+		}
+	}
+	sort.Slice(scans, func(i, j int) bool {
+		return scans[i].StartedAt.Before(scans[j].StartedAt)
+	})
+	return scans
+}
+
+// cancel cancels the in-flight scan with the given request ID, reporting whether it was found
+// among the scans visible to tenant. Canceling the context kills the KRR process; the scan's own
+// call removes it from the registry.
+func (r *scanRegistry) cancel(id, tenant string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scan, ok := r.scans[id]
+	ok = ok && (tenant == "" || scan.info.Tenant == tenant)
+	if ok {
+		scan.cancel()
+	}
+	return ok
+}
+
+// wait blocks until no scans are in flight or ctx is done, reporting whether the registry emptied
+func (r *scanRegistry) wait(ctx context.Context) bool {
+	for {
+		r.mu.Lock()
+		if len(r.scans) == 0 {
+			r.mu.Unlock()
+			return true
+		}
+		if r.emptied == nil {
+			r.emptied = make(chan struct{})
+		}
+		emptied := r.emptied
+		r.mu.Unlock()
+
+		select {
+		case <-emptied:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// cancelAll cancels every in-flight scan and returns how many there were
+func (r *scanRegistry) cancelAll() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, scan := range r.scans {
+		scan.cancel()
+	}
+	return len(r.scans)
+}
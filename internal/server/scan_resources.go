@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Scan outputs are readable as MCP resources under these URIs
+const (
+	scanResourcePrefix   = "greenops://scans/"
+	latestScanURI        = scanResourcePrefix + "latest"
+	scanResourceTemplate = scanResourcePrefix + "{id}"
+	scanResourceMIMEType = "application/json"
+)
+
+// ScanResource is the document served by the scan resources: a finished krr_scan's output
+// together with where it came from
+type ScanResource struct {
+	RequestID  string        `json:"request_id"`
+	Tenant     string        `json:"tenant,omitempty"`
+	User       string        `json:"user,omitempty"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Output     KRRScanOutput `json:"output"`
+}
+
+// scanOutputs keeps the outputs of the last successful scans, oldest first. The zero capacity
+// keeps nothing.
+type scanOutputs struct {
+	mu       sync.Mutex
+	capacity int
+	scans    []ScanResource
+}
+
+// add records a scan and returns the scans evicted to make room for it
+func (o *scanOutputs) add(scan ScanResource) []ScanResource {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.capacity <= 0 {
+		return nil
+	}
+	o.scans = append(o.scans, scan)
+	evicted := len(o.scans) - o.capacity
+	if evicted <= 0 {
+		return nil
+	}
+	dropped := append([]ScanResource(nil), o.scans[:evicted]...)
+	o.scans = append(o.scans[:0], o.scans[evicted:]...)
+	return dropped
+}
+
+// get returns the scan with the given request ID if it is visible to tenant, or the newest
+// visible scan for the ID "latest". Tenants only see their own scans; an empty tenant sees
+// every scan.
+func (o *scanOutputs) get(id, tenant string) (ScanResource, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i := len(o.scans) - 1; i >= 0; i-- {
+		scan := o.scans[i]
+		if (id == "latest" || scan.RequestID == id) && (tenant == "" || scan.Tenant == tenant) {
+			return scan, true
+		}
+	}
+	return ScanResource{}, false
+}
+
+// registerResources registers the scan resources. Subscriptions are accepted for any scan URI;
+// only greenops://scans/latest ever changes.
+func (s *MCPServer) registerResources() {
+	s.server.AddResource(&mcp.Resource{
+		URI:         latestScanURI,
+		Name:        "latest-scan",
+		Title:       "Latest scan",
+		Description: "Output of the most recent successful krr_scan, as krr_scan returned it",
+		MIMEType:    scanResourceMIMEType,
+	}, s.readScanResource)
+	s.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: scanResourceTemplate,
+		Name:        "scan",
+		Title:       "Scan by request ID",
+		Description: "Output of a recent successful krr_scan, by the request ID krr_recent and krr_list_running report",
+		MIMEType:    scanResourceMIMEType,
+	}, s.readScanResource)
+}
+
+// subscribeScanResource accepts subscriptions to the scan resources; the SDK keeps track of them
+func subscribeScanResource(ctx context.Context, req *mcp.SubscribeRequest) error {
+	if !strings.HasPrefix(req.Params.URI, scanResourcePrefix) {
+		return mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	return nil
+}
+
+// unsubscribeScanResource is the counterpart of subscribeScanResource
+func unsubscribeScanResource(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	return nil
+}
+
+// readScanResource serves a scan resource. A scan another tenant ran reads as not found, like
+// one that was never recorded or has been evicted.
+func (s *MCPServer) readScanResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	tenant := ""
+	if len(s.live().tenants) > 0 {
+		t := s.extraTenant(req.Extra)
+		if t == nil {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		tenant = t.config.Name
+	}
+
+	scan, ok := s.outputs.get(strings.TrimPrefix(uri, scanResourcePrefix), tenant)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	data, err := json.MarshalIndent(scan, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode scan %s: %w", scan.RequestID, err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: scanResourceMIMEType, Text: string(data)}},
+	}, nil
+}
+
+// publishScan records a successful scan for the scan resources and tells subscribers of
+// greenops://scans/latest. Scans outside multi-tenant mode are also listed as resources of
+// their own, which makes the SDK send a list-changed notification; tenants' scans are only
+// reachable through the template, so the list does not reveal them to other tenants.
+func (s *MCPServer) publishScan(ctx context.Context, scan ScanResource) {
+	evicted := s.outputs.add(scan)
+	if s.outputs.capacity <= 0 {
+		return
+	}
+
+	var removed []string
+	for _, old := range evicted {
+		if old.Tenant == "" {
+			removed = append(removed, scanResourcePrefix+old.RequestID)
+		}
+	}
+	if len(removed) > 0 {
+		s.server.RemoveResources(removed...)
+	}
+	if scan.Tenant == "" {
+		s.server.AddResource(&mcp.Resource{
+			URI:         scanResourcePrefix + scan.RequestID,
+			Name:        "scan-" + scan.RequestID,
+			Title:       fmt.Sprintf("Scan %s", scan.RequestID),
+			Description: fmt.Sprintf("krr_scan output from %s", scan.FinishedAt.UTC().Format(time.RFC3339)),
+			MIMEType:    scanResourceMIMEType,
+		}, s.readScanResource)
+	}
+
+	if err := s.server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: latestScanURI}); err != nil {
+		log.Printf("Scan %s: failed to notify resource subscribers: %v", scan.RequestID, err)
+	}
+}
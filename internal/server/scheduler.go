@@ -0,0 +1,347 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/schedule"
+	"greenops-mcp/internal/store"
+)
+
+// clock abstracts time for the scheduler so that it can be driven by a fake clock
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the wall clock
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Where a scheduled scan was defined
+const (
+	scheduleFromConfig = "config" // scheduled_scans in the configuration
+	scheduleFromTool   = "tool"   // krr_schedule_scan
+)
+
+// scheduledScan is a scan entry with its parsed schedule
+type scheduledScan struct {
+	entry    config.ScheduleEntry
+	schedule *schedule.Schedule
+
+	// source is scheduleFromConfig or scheduleFromTool; createdBy and createdAt describe the
+	// krr_schedule_scan call of a tool-defined schedule
+	source    string
+	createdBy string
+	createdAt time.Time
+
+	// stop ends the schedule and cancels its in-flight run; nil until the schedule starts
+	stop context.CancelFunc
+
+	// running is set while a run is in progress; ticks that arrive meanwhile are skipped
+	running atomic.Bool
+
+	// last is the outcome of the most recent run
+	last atomic.Pointer[scheduleRun]
+
+	// signature is the recommendation signature of the last successful run
+	signature atomic.Pointer[string]
+}
+
+// scheduleRun is the outcome of one run of a scheduled scan
+type scheduleRun struct {
+	At    time.Time
+	Error string
+}
+
+// storedSchedule is a tool-defined schedule as persisted in schedule_file
+type storedSchedule struct {
+	config.ScheduleEntry
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// schedules holds the scheduled scans by name. Schedules added before the scheduler starts are
+// started with it; later ones start right away.
+type schedules struct {
+	mu    sync.Mutex
+	file  string
+	scans map[string]*scheduledScan
+
+	// Set by startScheduler; ctx is nil until then
+	ctx context.Context
+	clk clock
+	wg  sync.WaitGroup
+}
+
+// newSchedules creates the schedule registry from the configured schedules and the
+// tool-defined ones persisted in file. A persisted schedule whose name the configuration now
+// uses is dropped.
+func newSchedules(cfg *config.Config) (*schedules, error) {
+	registry := &schedules{file: cfg.ScheduleFile, scans: make(map[string]*scheduledScan)}
+	for _, entry := range cfg.ScheduledScans {
+		sched, err := schedule.Parse(entry.Cron)
+		if err != nil {
+			log.Printf("Skipping scheduled scan %s: %v", entry.Name, err)
+			continue
+		}
+		registry.scans[entry.Name] = &scheduledScan{entry: entry, schedule: sched, source: scheduleFromConfig}
+	}
+	if registry.file == "" {
+		return registry, nil
+	}
+
+	data, err := os.ReadFile(registry.file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule_file: %w", err)
+	}
+	var stored []storedSchedule
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule_file: %w", err)
+	}
+	for _, saved := range stored {
+		if _, taken := registry.scans[saved.Name]; taken {
+			log.Printf("Dropping stored schedule %s: the configuration defines a scheduled scan with that name", saved.Name)
+			continue
+		}
+		sched, err := schedule.Parse(saved.Cron)
+		if err != nil {
+			log.Printf("Dropping stored schedule %s: %v", saved.Name, err)
+			continue
+		}
+		registry.scans[saved.Name] = &scheduledScan{
+			entry:     saved.ScheduleEntry,
+			schedule:  sched,
+			source:    scheduleFromTool,
+			createdBy: saved.CreatedBy,
+			createdAt: saved.CreatedAt,
+		}
+	}
+	return registry, nil
+}
+
+// list returns the scheduled scans sorted by name
+func (r *schedules) list() []*scheduledScan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scans := make([]*scheduledScan, 0, len(r.scans))
+	for _, scan := range r.scans {
+		scans = append(scans, scan)
+	}
+	sort.Slice(scans, func(i, j int) bool { return scans[i].entry.Name < scans[j].entry.Name })
+	return scans
+}
+
+// count returns the number of schedules from source
+func (r *schedules) count(source string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, scan := range r.scans {
+		if scan.source == source {
+			n++
+		}
+	}
+	return n
+}
+
+// persist writes the tool-defined schedules to file, if one is set. The caller holds mu.
+func (r *schedules) persist() error {
+	if r.file == "" {
+		return nil
+	}
+	stored := []storedSchedule{}
+	for _, scan := range r.scans {
+		if scan.source == scheduleFromTool {
+			stored = append(stored, storedSchedule{ScheduleEntry: scan.entry, CreatedBy: scan.createdBy, CreatedAt: scan.createdAt})
+		}
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Name < stored[j].Name })
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schedules: %w", err)
+	}
+	// Replace the file atomically, so a crash never leaves it half written
+	tmp := r.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write schedule_file: %w", err)
+	}
+	if err := os.Rename(tmp, r.file); err != nil {
+		return fmt.Errorf("failed to write schedule_file: %w", err)
+	}
+	return nil
+}
+
+// startScheduler starts one goroutine per scheduled scan. Runs fire exactly at the cron times,
+// without jitter. Canceling ctx stops the scheduler and cancels in-flight runs; the returned
+// func waits for them to finish.
+func (s *MCPServer) startScheduler(ctx context.Context, clk clock) func() {
+	r := s.schedules
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ctx, r.clk = ctx, clk
+	for _, scan := range r.scans {
+		s.startSchedule(scan)
+	}
+	return r.wg.Wait
+}
+
+// startSchedule starts the goroutine of one scheduled scan. The caller holds the registry's mu
+// and the scheduler has started.
+func (s *MCPServer) startSchedule(scan *scheduledScan) {
+	r := s.schedules
+	var ctx context.Context
+	ctx, scan.stop = context.WithCancel(r.ctx)
+	log.Printf("Scheduled scan %s with cron %q", scan.entry.Name, scan.entry.Cron)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		s.runSchedule(ctx, r.clk, scan, &r.wg)
+	}()
+}
+
+// addSchedule registers a tool-defined schedule, persists it and starts it if the scheduler
+// is running
+func (s *MCPServer) addSchedule(scan *scheduledScan) error {
+	r := s.schedules
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, taken := r.scans[scan.entry.Name]; taken {
+		return fmt.Errorf("a scheduled scan named %q already exists", scan.entry.Name)
+	}
+	r.scans[scan.entry.Name] = scan
+	if err := r.persist(); err != nil {
+		delete(r.scans, scan.entry.Name)
+		return err
+	}
+	if r.ctx != nil {
+		s.startSchedule(scan)
+	}
+	return nil
+}
+
+// removeSchedule stops and forgets a tool-defined schedule, canceling its in-flight run
+func (s *MCPServer) removeSchedule(name string) error {
+	r := s.schedules
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scan, ok := r.scans[name]
+	if !ok {
+		return fmt.Errorf("no scheduled scan named %q", name)
+	}
+	if scan.source != scheduleFromTool {
+		return fmt.Errorf("scheduled scan %q is defined in scheduled_scans; remove it from the configuration instead", name)
+	}
+	delete(r.scans, name)
+	if err := r.persist(); err != nil {
+		r.scans[name] = scan
+		return err
+	}
+	if scan.stop != nil {
+		scan.stop()
+	}
+	return nil
+}
+
+// runSchedule waits for each cron time of scan and starts a run unless the previous one is still going
+func (s *MCPServer) runSchedule(ctx context.Context, clk clock, scan *scheduledScan, wg *sync.WaitGroup) {
+	for {
+		now := clk.Now()
+		next := scan.schedule.Next(now)
+		if next.IsZero() {
+			log.Printf("Scheduled scan %s has no upcoming run, stopping it", scan.entry.Name)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-clk.After(next.Sub(now)):
+		}
+
+		if !scan.running.CompareAndSwap(false, true) {
+			log.Printf("Skipping scheduled scan %s at %s: previous run still in progress", scan.entry.Name, next.Format(time.RFC3339))
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer scan.running.Store(false)
+			s.runScheduledScan(ctx, scan, clk.Now())
+		}()
+	}
+}
+
+// runScheduledScan runs one scheduled scan and publishes its report. Failures are logged only.
+func (s *MCPServer) runScheduledScan(ctx context.Context, scan *scheduledScan, now time.Time) {
+	entry := scan.entry
+	options := entry.Options
+	if options.Namespace == "" {
+		options.Namespace = s.config().DefaultNamespace
+	}
+	if options.Strategy == "" {
+		options.Strategy = s.config().DefaultStrategy
+	}
+	options.Output = krr.OutputJSON
+	options.NoColor = true
+
+	ctx, cancel := context.WithTimeout(ctx, s.config().DefaultTimeout)
+	defer cancel()
+
+	// Scheduled runs show up in krr_list_running and can be stopped with krr_cancel
+	ctx, id, untrack := s.running.track(ctx, newRequestID(), "schedule:"+entry.Name, scanScope{}, options)
+	defer untrack()
+
+	log.Printf("Running scheduled scan %s (request %s)", entry.Name, id)
+	result, err := s.runScan(ctx, s.live().executor, options)
+	if err != nil {
+		scan.last.Store(&scheduleRun{At: now, Error: err.Error()})
+		log.Printf("Scheduled scan %s failed: %v", entry.Name, err)
+		return
+	}
+	scan.last.Store(&scheduleRun{At: now})
+	log.Printf("Scheduled scan %s finished: %d resources, %d with recommendations", entry.Name, result.Summary.TotalResources, result.Summary.ResourcesWithRecommendations)
+
+	if url := s.uploadReport(result, "", now); url != "" {
+		log.Printf("Scheduled scan %s report: %s", entry.Name, url)
+	}
+	// Unchanged recommendations would only repeat the previous notification
+	signature := krr.Signature(result)
+	previous := scan.signature.Swap(&signature)
+	if entry.NotifySlack && (previous == nil || *previous != signature) {
+		s.notifySlack(result, options.Namespace)
+	} else if entry.NotifySlack {
+		log.Printf("Scheduled scan %s: recommendations unchanged since the last run, skipping Slack notification", entry.Name)
+	}
+	s.pushMetrics(result, options.Namespace)
+	s.recordHistory(store.Entry{
+		ID:        id,
+		Timestamp: now,
+		Source:    "schedule:" + entry.Name,
+		Cluster:   historyCluster("", options),
+		Options:   options,
+		Summary:   result.Summary,
+	}, result)
+}
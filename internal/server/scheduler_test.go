@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+)
+
+// fakeClock is a clock that only moves when advanced
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// advance moves the clock forward by d, firing the timers that are due
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.ch <- c.now
+	}
+	c.timers = pending
+}
+
+// waitForTimer blocks until a goroutine waits on the clock, failing the test after a while
+func (c *fakeClock) waitForTimer(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		waiting := len(c.timers) > 0
+		c.mu.Unlock()
+		if waiting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("the scheduler never waited for its next run")
+}
+
+// waitFor polls condition until it holds, failing the test after a while
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// scheduleConfig is a configuration with one scheduled scan of the shop namespace every 5 minutes
+func scheduleConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.ScheduledScans = []config.ScheduleEntry{{
+		Name:    "shop",
+		Cron:    "*/5 * * * *",
+		Options: krr.ScanOptions{Namespace: "shop"},
+	}}
+	return cfg
+}
+
+func TestSchedulerRunsAtCronTimes(t *testing.T) {
+	s, fake := newTestServer(t, scheduleConfig())
+	clk := &fakeClock{now: time.Date(2024, 5, 1, 10, 2, 0, 0, time.UTC)}
+	ctx, cancel := context.WithCancel(context.Background())
+	wait := s.startScheduler(ctx, clk)
+	defer func() {
+		cancel()
+		wait()
+	}()
+	scan := s.schedules.scans["shop"]
+
+	// Nothing runs before the first cron time
+	clk.waitForTimer(t)
+	clk.advance(2 * time.Minute)
+	clk.waitForTimer(t)
+	if n := len(fake.options()); n != 0 {
+		t.Fatalf("KRR ran %d times before 10:05", n)
+	}
+
+	for i, want := range []time.Time{
+		time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC),
+		time.Date(2024, 5, 1, 10, 10, 0, 0, time.UTC),
+	} {
+		clk.advance(want.Sub(clk.Now()))
+		waitFor(t, "the scheduled run", func() bool {
+			last := scan.last.Load()
+			return last != nil && last.At.Equal(want)
+		})
+		clk.waitForTimer(t)
+		if n := len(fake.options()); n != i+1 {
+			t.Errorf("KRR ran %d times by %s, want %d", n, want.Format("15:04"), i+1)
+		}
+	}
+	if got := fake.options()[0].Namespace; got != "shop" {
+		t.Errorf("scheduled scan ran on namespace %q, want shop", got)
+	}
+}
+
+func TestSchedulerSkipsOverlappingRuns(t *testing.T) {
+	s, fake := newTestServer(t, scheduleConfig())
+	fake.block = make(chan struct{})
+	clk := &fakeClock{now: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	ctx, cancel := context.WithCancel(context.Background())
+	wait := s.startScheduler(ctx, clk)
+	defer func() {
+		cancel()
+		wait()
+	}()
+	scan := s.schedules.scans["shop"]
+
+	clk.waitForTimer(t)
+	clk.advance(5 * time.Minute)
+	waitFor(t, "the first run to start", func() bool { return len(fake.options()) == 1 })
+
+	// The 10:10 tick finds the 10:05 run still going
+	clk.waitForTimer(t)
+	clk.advance(5 * time.Minute)
+	clk.waitForTimer(t)
+	if n := len(fake.options()); n != 1 {
+		t.Fatalf("KRR ran %d times, want the overlapping run skipped", n)
+	}
+
+	close(fake.block)
+	waitFor(t, "the first run to finish", func() bool { return !scan.running.Load() })
+	clk.advance(5 * time.Minute)
+	waitFor(t, "the 10:15 run", func() bool { return len(fake.options()) == 2 })
+}
+
+func TestSchedulerStopCancelsRuns(t *testing.T) {
+	s, fake := newTestServer(t, scheduleConfig())
+	fake.block = make(chan struct{})
+	clk := &fakeClock{now: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	ctx, cancel := context.WithCancel(context.Background())
+	wait := s.startScheduler(ctx, clk)
+
+	clk.waitForTimer(t)
+	clk.advance(5 * time.Minute)
+	waitFor(t, "the run to start", func() bool { return len(fake.options()) == 1 })
+
+	// wait returns once the in-flight run has seen the cancellation
+	cancel()
+	wait()
+	if last := s.schedules.scans["shop"].last.Load(); last == nil || last.Error == "" {
+		t.Errorf("last run = %+v, want it canceled", last)
+	}
+}
+
+func TestScheduledScanSkipsUnchangedSlackNotifications(t *testing.T) {
+	cfg := scheduleConfig()
+	cfg.ScheduledScans[0].NotifySlack = true
+	s, fake := newTestServer(t, cfg)
+	slack := newFakeSlack()
+	withSlack(s, slack)
+	scan := s.schedules.scans["shop"]
+	resource := krr.Resource{Name: "web", Namespace: "shop", Kind: "Deployment", Container: "app", Severity: "WARNING",
+		Current: krr.ResourceRequirements{CPU: "500m"}, Recommended: krr.ResourceRequirements{CPU: "100m"}}
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	fake.result = &krr.ScanResult{Resources: []krr.Resource{resource}}
+	s.runScheduledScan(context.Background(), scan, now)
+	slack.wait(t)
+
+	// The same recommendations again are not worth a message
+	s.runScheduledScan(context.Background(), scan, now.Add(5*time.Minute))
+
+	resource.Recommended.CPU = "200m"
+	fake.result = &krr.ScanResult{Resources: []krr.Resource{resource}}
+	s.runScheduledScan(context.Background(), scan, now.Add(10*time.Minute))
+	slack.wait(t)
+
+	if n := slack.count(); n != 2 {
+		t.Errorf("posted %d Slack messages, want 2", n)
+	}
+}
+
+func TestScheduledScanSignatureIsRaceFree(t *testing.T) {
+	s, _ := newTestServer(t, scheduleConfig())
+	scan := s.schedules.scans["shop"]
+
+	// Runs of one schedule never overlap in production, but the signature must not depend on it
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runScheduledScan(context.Background(), scan, time.Unix(int64(i), 0))
+		}()
+	}
+	wg.Wait()
+	if scan.signature.Load() == nil {
+		t.Error("no signature recorded")
+	}
+}
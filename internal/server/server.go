@@ -2,18 +2,23 @@ package server
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"greenops-mcp/internal/artifact"
 	"greenops-mcp/internal/config"
 	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/kube"
+	"greenops-mcp/internal/notify"
+	"greenops-mcp/internal/oidc"
+	"greenops-mcp/internal/store"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -21,199 +26,313 @@ import (
 // MCPServer wraps the KRR functionality as an MCP server
 type MCPServer struct {
 	server     *mcp.Server
-	executor   krr.Executor
-	config     *config.Config
 	httpServer *http.Server
+
+	// state is what a configuration reload replaces; see live
+	state atomic.Pointer[runtimeState]
+
+	// loadConfig reloads the configuration on SIGHUP (nil when reloading is not set up)
+	loadConfig func() (*config.Config, error)
+
+	// scanSlots bounds the number of KRR scans running at once
+	scanSlots chan struct{}
+
+	// limits enforces client_rate_limit and client_max_concurrent_scans
+	limits clientLimiter
+
+	// running tracks in-flight scans for krr_list_running and krr_cancel
+	running scanRegistry
+
+	// recent keeps the outcomes of the last finished scans for krr_recent
+	recent recentScans
+
+	// outputs keeps the outputs of the last successful scans for the scan resources
+	outputs scanOutputs
+
+	// jobs holds the krr_scan_async jobs for krr_scan_status and krr_scan_result
+	jobs *jobStore
+
+	// schedules holds the scheduled scans, from scheduled_scans and krr_schedule_scan
+	schedules *schedules
+
+	// history persists every parsed scan for krr_scan_history (nil when history_dir is unset)
+	history *store.History
+
+	// metrics are the server's own metrics, served on /metrics
+	metrics serverMetrics
+
+	// draining is set once shutdown starts; /readyz reports 503 from then on
+	draining atomic.Bool
+
+	// readiness caches the KRR version check behind /readyz
+	readiness readinessCache
+
+	// prometheus is the metrics service the most recent scan reported discovering
+	prometheus atomic.Pointer[krr.PrometheusDiscovery]
+}
+
+// runtimeState is the configuration together with everything built from it. A reload swaps
+// it as a whole, so a request sees either the old or the new state, never a mix.
+type runtimeState struct {
+	config   *config.Config
+	executor krr.Executor
+	kube     *kube.Client
+	uploader artifact.Uploader
+	slack    notify.SlackClient
+	pushgw   notify.PushgatewayClient
+
+	// authTokens are the static API keys the MCP endpoint requires (none if empty)
+	authTokens []string
+
+	// oidc verifies bearer JWTs from the configured issuer (nil when OIDC is disabled)
+	oidc *oidc.Verifier
+
+	// tenants confine each API token to its scope (multi-tenant mode when non-empty)
+	tenants []*tenant
+
+	// clusters are the cluster registry, by name, for the cluster argument of krr_scan
+	clusters map[string]*cluster
 }
 
 // NewMCPServer creates a new MCP server instance
 func NewMCPServer(cfg *config.Config) (*MCPServer, error) {
-	// Create KRR executor
-	executor := krr.NewCLIExecutor(cfg.KRRPath, cfg.DefaultTimeout)
+	state, err := newRuntimeState(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create MCP server
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    cfg.ServerName,
 		Version: cfg.ServerVersion,
-	}, nil)
+	}, &mcp.ServerOptions{
+		SubscribeHandler:   subscribeScanResource,
+		UnsubscribeHandler: unsubscribeScanResource,
+	})
+
+	jobs, err := newJobStore(cfg.JobDir)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := newSchedules(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var history *store.History
+	if cfg.HistoryDir != "" {
+		codec := store.Codec{Compress: cfg.CompressStored, Debug: cfg.LogLevel == "debug"}
+		if history, err = store.OpenHistory(cfg.HistoryDir, codec, cfg.HistoryRetention); err != nil {
+			return nil, err
+		}
+	}
 
 	mcpServer := &MCPServer{
-		server:   server,
-		executor: executor,
-		config:   cfg,
+		server:    server,
+		scanSlots: make(chan struct{}, max(cfg.MaxConcurrentScans, 1)),
+		recent:    recentScans{capacity: cfg.RecentScans},
+		outputs:   scanOutputs{capacity: cfg.RecentScans},
+		jobs:      jobs,
+		schedules: schedules,
+		history:   history,
 	}
+	mcpServer.state.Store(state)
 
 	// Register tools
 	if err := mcpServer.registerTools(); err != nil {
 		return nil, fmt.Errorf("failed to register tools: %w", err)
 	}
+	mcpServer.registerResources()
+	mcpServer.registerPrompts()
 
 	return mcpServer, nil
 }
 
-// KRRScanArguments defines the arguments for the krr_scan tool
-type KRRScanArguments struct {
-	Namespace     *string `json:"namespace,omitempty" jsonschema:"Kubernetes namespace to scan (optional, scans all namespaces if not specified)"`
-	Context       *string `json:"context,omitempty" jsonschema:"Kubernetes context to use (optional, uses current context if not specified)"`
-	ClusterName   *string `json:"cluster_name,omitempty" jsonschema:"Name of the cluster for reporting purposes (optional)"`
-	Strategy      *string `json:"strategy,omitempty" jsonschema:"Recommendation strategy to use (e.g. 'simple' 'advanced')"`
-	CPUMin        *string `json:"cpu_min,omitempty" jsonschema:"Minimum CPU recommendation threshold (e.g. '100m')"`
-	CPUMax        *string `json:"cpu_max,omitempty" jsonschema:"Maximum CPU recommendation threshold (e.g. '2')"`
-	MemoryMin     *string `json:"memory_min,omitempty" jsonschema:"Minimum memory recommendation threshold (e.g. '128Mi')"`
-	MemoryMax     *string `json:"memory_max,omitempty" jsonschema:"Maximum memory recommendation threshold (e.g. '4Gi')"`
-	OutputFormat  *string `json:"output_format,omitempty" jsonschema:"Output format (fixed to 'table' - this parameter is ignored)"`
-	RecommendOnly *bool   `json:"recommend_only,omitempty" jsonschema:"Only show resources that have recommendations (default: false)"`
-	Verbose       *bool   `json:"verbose,omitempty" jsonschema:"Enable verbose output (default: false)"`
-	KRRPath       *string `json:"krr_path,omitempty" jsonschema:"Override the path to the KRR CLI executable (optional)"`
-}
-
-// KRRScanOutput defines the output structure for krr_scan tool
-type KRRScanOutput struct {
-	Result string `json:"result"`
-}
-
-// registerTools registers all KRR tools with the MCP server
-func (s *MCPServer) registerTools() error {
-	// Register krr_scan tool using AddTool with type-safe handler
-	mcp.AddTool(s.server, &mcp.Tool{
-		Name:        "krr_scan",
-		Description: "Execute a KRR (Kubernetes Resource Recommender) scan to analyze resource usage and get recommendations",
-	}, s.handleScanTyped)
-
-	return nil
-}
-
-// ExecuteScan is a public method for testing purposes
-func (s *MCPServer) ExecuteScan(arguments KRRScanArguments) (KRRScanOutput, error) {
-	req := &mcp.CallToolRequest{}
-	_, output, err := s.handleScanTyped(context.Background(), req, arguments)
-	return output, err
-}
-
-// handleScanTyped handles the krr_scan tool execution with type-safe API
-func (s *MCPServer) handleScanTyped(ctx context.Context, req *mcp.CallToolRequest, arguments KRRScanArguments) (*mcp.CallToolResult, KRRScanOutput, error) {
-	// Create context with timeout if not already set
-	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, s.config.DefaultTimeout)
-		defer cancel()
+// newRuntimeState builds the executor, clients and credentials of a configuration
+func newRuntimeState(cfg *config.Config) (*runtimeState, error) {
+	authTokens, err := cfg.AuthTokens()
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse arguments into ScanOptions
-	options := krr.ScanOptions{
-		Output: krr.OutputTable, // Force table format only
+	state := &runtimeState{
+		config:     cfg,
+		executor:   newExecutor(cfg, cfg.KRRPath),
+		kube:       newKubeClient(cfg),
+		tenants:    newTenants(cfg),
+		clusters:   newClusters(cfg),
+		authTokens: authTokens,
 	}
 
-	executor := s.executor
-	if arguments.KRRPath != nil && strings.TrimSpace(*arguments.KRRPath) != "" {
-		executor = krr.NewCLIExecutor(strings.TrimSpace(*arguments.KRRPath), s.config.DefaultTimeout)
+	if cfg.OIDCIssuerURL != "" {
+		state.oidc = oidc.NewVerifier(cfg.OIDCIssuerURL, cfg.OIDCAudience)
 	}
 
-	if arguments.Namespace != nil {
-		options.Namespace = *arguments.Namespace
-	} else if s.config.DefaultNamespace != "" {
-		options.Namespace = s.config.DefaultNamespace
+	// Create the optional report uploader
+	if cfg.S3Bucket != "" {
+		uploader, err := artifact.NewS3Uploader(artifact.S3Config{
+			Bucket:          cfg.S3Bucket,
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			UsePathStyle:    cfg.S3UsePathStyle,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 uploader: %w", err)
+		}
+		state.uploader = uploader
 	}
 
-	if arguments.Context != nil {
-		options.Context = *arguments.Context
+	if cfg.SlackWebhookURL != "" {
+		state.slack = notify.NewWebhookClient(cfg.SlackWebhookURL)
 	}
 
-	if arguments.ClusterName != nil {
-		options.ClusterName = *arguments.ClusterName
+	if cfg.PushgatewayURL != "" {
+		state.pushgw = notify.NewHTTPPushgateway(cfg.PushgatewayURL, cfg.PushgatewayJob)
 	}
 
-	if arguments.Strategy != nil {
-		options.Strategy = *arguments.Strategy
-	} else {
-		options.Strategy = s.config.DefaultStrategy
-	}
+	return state, nil
+}
 
-	if arguments.CPUMin != nil {
-		options.CPUMin = *arguments.CPUMin
-	}
+// live returns the current runtime state. Callers needing several fields to agree should load
+// it once rather than calling live repeatedly across a reload.
+func (s *MCPServer) live() *runtimeState {
+	return s.state.Load()
+}
 
-	if arguments.CPUMax != nil {
-		options.CPUMax = *arguments.CPUMax
-	}
+// config returns the current configuration
+func (s *MCPServer) config() *config.Config {
+	return s.live().config
+}
 
-	if arguments.MemoryMin != nil {
-		options.MemoryMin = *arguments.MemoryMin
+// newExecutor creates a KRR executor for the given KRR path using the server configuration;
+// extra options are applied last
+func newExecutor(cfg *config.Config, krrPath string, extra ...krr.ExecutorOption) krr.Executor {
+	opts := []krr.ExecutorOption{krr.WithSeverityThresholds(severityThresholds(cfg))}
+	if cfg.KubeconfigData != "" {
+		opts = append(opts, krr.WithKubeconfigData(cfg.KubeconfigData))
 	}
-
-	if arguments.MemoryMax != nil {
-		options.MemoryMax = *arguments.MemoryMax
+	if cfg.PythonPath != "" {
+		opts = append(opts, krr.WithPythonPath(cfg.PythonPath))
 	}
-
-	// OutputFormat is ignored - always use table format
-
-	if arguments.RecommendOnly != nil {
-		options.RecommendOnly = *arguments.RecommendOnly
+	if cfg.PrometheusCACertFile != "" {
+		opts = append(opts, krr.WithPrometheusCACertFile(cfg.PrometheusCACertFile))
 	}
-
-	options.NoColor = s.config.DefaultNoColor
-
-	// Execute the scan
-	result, err := executor.Scan(ctx, options)
-	if err != nil {
-		errorMsg := fmt.Sprintf("KRR scan failed: %v", err)
-		if strings.Contains(err.Error(), "executable file not found") {
-			errorMsg += "\n\nKRR CLI is not installed or not in PATH. Please install it with:\n  pip install krr\n\nThen verify installation with:\n  krr --version"
-		}
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: errorMsg},
-			},
-			IsError: true,
-		}, KRRScanOutput{}, nil
-	}
-
-	// Format the result based on output format
-	var outputText string
-	// For table and yaml formats, return raw output directly to save tokens
-	if options.Output == krr.OutputTable || options.Output == krr.OutputYAML {
-		outputText = fmt.Sprintf("KRR Scan Results:\n\n%s", result.RawOutput)
-	} else {
-		// For JSON format, return structured data
-		resultJSON, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Failed to format scan result: %v", err)},
-				},
-				IsError: true,
-			}, KRRScanOutput{}, nil
+	if len(cfg.ExtraArgs) > 0 {
+		opts = append(opts, krr.WithExtraArgs(cfg.ExtraArgs))
+		if cfg.LogLevel == "debug" {
+			log.Printf("Appending extra KRR arguments to every scan: %q", cfg.ExtraArgs)
 		}
-		outputText = fmt.Sprintf("KRR Scan Results:\n\n%s", string(resultJSON))
 	}
+	opts = append(opts, extra...)
+	executor := krr.NewCLIExecutor(krrPath, cfg.DefaultTimeout, opts...)
+	if cfg.RateLimitRetries > 0 {
+		executor = krr.NewRetryingExecutor(executor, cfg.RateLimitRetries, cfg.RateLimitBackoff)
+	}
+	return executor
+}
+
+// severityThresholds returns the severity classification thresholds from the configuration
+func severityThresholds(cfg *config.Config) krr.SeverityThresholds {
+	return krr.SeverityThresholds{
+		UnderCritical: cfg.SeverityUnderCriticalPercent,
+		UnderWarning:  cfg.SeverityUnderWarningPercent,
+		OverCritical:  cfg.SeverityOverCriticalPercent,
+		OverWarning:   cfg.SeverityOverWarningPercent,
+	}
+}
 
-	return nil, KRRScanOutput{Result: outputText}, nil
+// newKubeClient creates a kubectl client using the server configuration; extra options are
+// applied last
+func newKubeClient(cfg *config.Config, extra ...kube.ClientOption) *kube.Client {
+	var opts []kube.ClientOption
+	if cfg.KubeconfigData != "" {
+		opts = append(opts, kube.WithClientKubeconfigData(cfg.KubeconfigData))
+	}
+	opts = append(opts, extra...)
+	return kube.NewClient(cfg.KubectlPath, opts...)
 }
 
 // Run starts the MCP server
 func (s *MCPServer) Run() error {
-	log.Printf("Starting KRR MCP Server %s version %s", s.config.ServerName, s.config.ServerVersion)
-	log.Printf("Using KRR CLI at: %s", s.config.KRRPath)
+	log.Printf("Starting KRR MCP Server %s version %s", s.config().ServerName, s.config().ServerVersion)
+	log.Printf("Using KRR CLI at: %s", s.config().KRRPath)
+
+	// Start scheduled scans; they stop, and in-flight runs are canceled, when Run returns
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	waitScheduler := s.startScheduler(schedulerCtx, realClock{})
+	defer func() {
+		stopScheduler()
+		waitScheduler()
+	}()
+
+	// Reload the configuration on SIGHUP for as long as the server runs
+	s.watchReloads(schedulerCtx)
+
+	if s.config().Transport == config.TransportStdio {
+		return s.runStdio()
+	}
 
 	// Create streamable HTTP handler
 	handler := mcp.NewStreamableHTTPHandler(
 		func(*http.Request) *mcp.Server {
 			return s.server
 		},
-		&mcp.StreamableHTTPOptions{},
+		&mcp.StreamableHTTPOptions{Stateless: s.config().Stateless},
 	)
 
-	// Setup HTTP routes
+	// Setup HTTP routes, all under base_path. The prefix is kept in the request path, so the
+	// SSE transport announces message endpoints the client can reach.
 	mux := http.NewServeMux()
-	mux.HandleFunc("/mcp", handler.ServeHTTP)
+	mux.HandleFunc(s.config().RoutePath(s.config().MCPPath), s.withAccessLog(s.protect(s.withScanWriteDeadline(withoutWriteDeadline(handler)))))
+	if s.config().EnableSSE {
+		sseHandler := mcp.NewSSEHandler(func(*http.Request) *mcp.Server {
+			return s.server
+		}, nil)
+		mux.HandleFunc(s.config().RoutePath(s.config().SSEPath), s.withAccessLog(s.protect(withoutWriteDeadline(sseHandler))))
+	}
+	mux.HandleFunc(s.config().RoutePath("/healthz"), s.handleHealthz)
+	mux.HandleFunc(s.config().RoutePath("/readyz"), s.handleReadyz)
+	mux.HandleFunc(s.config().RoutePath("/capabilities"), s.handleCapabilities)
+	mux.HandleFunc(s.config().RoutePath("/metrics"), s.handleMetrics)
+
+	tlsConfig, err := newTLSConfig(s.config())
+	if err != nil {
+		return err
+	}
 
 	// Create HTTP server
 	s.httpServer = &http.Server{
-		Addr:    ":8080",
-		Handler: mux,
+		Addr:         s.config().ListenAddr,
+		Handler:      mux,
+		ReadTimeout:  s.config().ReadTimeout,
+		WriteTimeout: s.config().WriteTimeout,
+		IdleTimeout:  s.config().IdleTimeout,
+		TLSConfig:    tlsConfig,
 	}
 
-	log.Printf("Server ready to accept MCP requests on http://0.0.0.0:8080/mcp")
+	listener, err := s.listen()
+	if err != nil {
+		return err
+	}
+
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	if path, ok := s.config().UnixSocketPath(); ok {
+		log.Printf("Server ready to accept MCP requests on Unix socket %s (%s, path %s)", path, scheme, s.config().RoutePath(s.config().MCPPath))
+	} else {
+		log.Printf("Server ready to accept MCP requests on %s://%s%s", scheme, displayAddr(s.config().ListenAddr), s.config().RoutePath(s.config().MCPPath))
+	}
+	if s.config().Stateless {
+		log.Printf("Stateless mode: MCP sessions are not kept between requests")
+	}
+	if s.config().EnableSSE {
+		log.Printf("Legacy SSE transport enabled on %s", s.config().RoutePath(s.config().SSEPath))
+	}
 
 	// Setup signal handling
 	sigChan := make(chan os.Signal, 1)
@@ -222,7 +341,14 @@ func (s *MCPServer) Run() error {
 	// Start HTTP server in goroutine
 	errChan := make(chan error, 1)
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		// The certificate comes from TLSConfig.GetCertificate, so no files are passed here
+		var err error
+		if tlsConfig != nil {
+			err = s.httpServer.ServeTLS(listener, "", "")
+		} else {
+			err = s.httpServer.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			errChan <- fmt.Errorf("HTTP server error: %w", err)
 		}
 	}()
@@ -231,7 +357,9 @@ func (s *MCPServer) Run() error {
 	select {
 	case sig := <-sigChan:
 		log.Printf("Received signal: %v, shutting down gracefully", sig)
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		s.beginShutdown()
+		s.drain()
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer cancel()
 		return s.httpServer.Shutdown(ctx)
 	case err := <-errChan:
@@ -239,10 +367,112 @@ func (s *MCPServer) Run() error {
 	}
 }
 
-// Close gracefully shuts down the server
+// listen opens the HTTP server's listener: TCP, or a Unix socket for a "unix:///path"
+// listen_addr. A socket left behind by an earlier run is replaced; the socket is removed again
+// when the server closes the listener.
+func (s *MCPServer) listen() (net.Listener, error) {
+	path, ok := s.config().UnixSocketPath()
+	if !ok {
+		return net.Listen("tcp", s.config().ListenAddr)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("listen_addr %s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// Owner and group only, so co-located clients are authorized by group membership
+	if err := os.Chmod(path, 0660); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// displayAddr turns a listen address into one for logs, spelling out the wildcard host
+func displayAddr(addr string) string {
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		return net.JoinHostPort("0.0.0.0", port)
+	}
+	return addr
+}
+
+// runStdio serves MCP over stdin and stdout, for clients that launch the server as a subprocess.
+// It returns when the client closes the session, or on SIGINT/SIGTERM once in-flight scans
+// are drained.
+func (s *MCPServer) runStdio() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case sig := <-sigChan:
+			log.Printf("Received signal: %v, shutting down gracefully", sig)
+			s.beginShutdown()
+			s.drain()
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	log.Printf("Server ready to accept MCP requests on stdio")
+	if err := s.server.Run(ctx, &mcp.StdioTransport{}); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("stdio transport error: %w", err)
+	}
+	return nil
+}
+
+// protect wraps an MCP endpoint in the CORS policy and the authentication middleware. Each is a
+// no-op unless configured; CORS comes first since browsers send preflights without credentials.
+func (s *MCPServer) protect(next http.Handler) http.HandlerFunc {
+	return s.withCORS(s.withClientCert(s.withAuth(s.withOIDC(s.withTenantAuth(next)))))
+}
+
+// withoutWriteDeadline clears the server's write_timeout for GET requests, which open a
+// long-lived stream: the legacy SSE transport's carries every response of a session, and the
+// streamable transport's carries server notifications, for as long as the client stays connected
+func withoutWriteDeadline(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+				log.Printf("Failed to clear write deadline for %s: %v", r.URL.Path, err)
+			}
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// withScanWriteDeadline extends the server's write_timeout for MCP requests, whose responses
+// are only written once a scan finishes: the deadline becomes max_timeout plus write_timeout,
+// so a scan can use its whole timeout and still have write_timeout left to send the result
+func (s *MCPServer) withScanWriteDeadline(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.config().WriteTimeout > 0 {
+			deadline := time.Now().Add(s.config().MaxTimeout + s.config().WriteTimeout)
+			if err := http.NewResponseController(w).SetWriteDeadline(deadline); err != nil {
+				log.Printf("Failed to extend write deadline for %s: %v", r.URL.Path, err)
+			}
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// Close gracefully shuts down the server, draining in-flight scans first
 func (s *MCPServer) Close() error {
+	s.beginShutdown()
+	s.drain()
 	if s.httpServer != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer cancel()
 		return s.httpServer.Shutdown(ctx)
 	}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/notify"
+)
+
+// fakeExecutor records the options of every scan and returns result, or err when it is set.
+// When block is set, scans wait for it to be closed, or for their context to end, first.
+type fakeExecutor struct {
+	krr.Executor
+
+	mu      sync.Mutex
+	scans   []krr.ScanOptions
+	result  *krr.ScanResult
+	err     error
+	version string
+	block   chan struct{}
+}
+
+func (e *fakeExecutor) Scan(ctx context.Context, options krr.ScanOptions) (*krr.ScanResult, error) {
+	e.mu.Lock()
+	e.scans = append(e.scans, options)
+	block := e.block
+	e.mu.Unlock()
+
+	if block != nil {
+		select {
+		case <-block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err != nil {
+		return nil, e.err
+	}
+	if e.result != nil {
+		result := *e.result
+		return &result, nil
+	}
+	return &krr.ScanResult{Resources: []krr.Resource{}}, nil
+}
+
+func (e *fakeExecutor) GetVersion(ctx context.Context) (string, error) {
+	return e.version, nil
+}
+
+// options returns the options of every scan so far
+func (e *fakeExecutor) options() []krr.ScanOptions {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]krr.ScanOptions(nil), e.scans...)
+}
+
+// newTestServer creates a server from cfg (the default configuration when nil) whose scans,
+// including those of tenants and registry clusters, all run on the returned fake executor
+func newTestServer(t *testing.T, cfg *config.Config) (*MCPServer, *fakeExecutor) {
+	t.Helper()
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	s, err := NewMCPServer(cfg)
+	if err != nil {
+		t.Fatalf("NewMCPServer() error = %v", err)
+	}
+
+	executor := &fakeExecutor{}
+	state := *s.live()
+	state.executor = executor
+	for _, t := range state.tenants {
+		t.executor = executor
+	}
+	for _, c := range state.clusters {
+		c.executor = executor
+	}
+	s.state.Store(&state)
+	return s, executor
+}
+
+// fakeSlack records the messages posted to Slack, failing with err when it is set
+type fakeSlack struct {
+	mu       sync.Mutex
+	messages []notify.SlackMessage
+	err      error
+	posted   chan struct{}
+}
+
+func newFakeSlack() *fakeSlack {
+	return &fakeSlack{posted: make(chan struct{}, 16)}
+}
+
+func (f *fakeSlack) Post(ctx context.Context, message notify.SlackMessage) error {
+	f.mu.Lock()
+	f.messages = append(f.messages, message)
+	f.mu.Unlock()
+	f.posted <- struct{}{}
+	return f.err
+}
+
+// count returns the number of messages posted so far
+func (f *fakeSlack) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.messages)
+}
+
+// wait blocks until a message is posted, failing the test after a while
+func (f *fakeSlack) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-f.posted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no Slack message was posted")
+	}
+}
+
+// withSlack makes s post Slack messages to slack
+func withSlack(s *MCPServer, slack notify.SlackClient) {
+	state := *s.live()
+	state.slack = slack
+	s.state.Store(&state)
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/kube"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// tenant is a configured tenant with the executor and cluster client bound to its kubeconfig.
+// key is what the tenant is routed by: its bearer token, tenant header value or claim value.
+type tenant struct {
+	key      string
+	config   config.TenantConfig
+	executor krr.Executor
+	kube     *kube.Client
+}
+
+// tenantsClaim is the TokenInfo.Extra key holding the tenant claim values of an OIDC token
+const tenantsClaim = "tenants"
+
+// newTenants builds the tenants of a configuration
+func newTenants(cfg *config.Config) []*tenant {
+	tenants := make([]*tenant, 0, len(cfg.Tenants))
+	for key, tenantConfig := range cfg.Tenants {
+		scoped := *cfg
+		if tenantConfig.KubeconfigData != "" {
+			scoped.KubeconfigData = tenantConfig.KubeconfigData
+		}
+		tenants = append(tenants, &tenant{
+			key:      key,
+			config:   tenantConfig,
+			executor: newExecutor(&scoped, scoped.KRRPath),
+			kube:     newKubeClient(&scoped),
+		})
+	}
+	return tenants
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header, or ""
+func bearerToken(header http.Header) string {
+	scheme, token, ok := strings.Cut(header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// tenantByKey looks a routing key up in constant time per tenant, so response timing does not
+// reveal how much of a token matched
+func (s *MCPServer) tenantByKey(key string) *tenant {
+	var found *tenant
+	for _, t := range s.live().tenants {
+		if subtle.ConstantTimeCompare([]byte(t.key), []byte(key)) == 1 {
+			found = t
+		}
+	}
+	return found
+}
+
+// tenantFor resolves the tenant of a request from its headers and, in claim mode, the claims of
+// its OIDC token. A token granting several tenants needs the tenant header to pick one of them.
+// The second result explains a nil tenant.
+func (s *MCPServer) tenantFor(header http.Header, info *auth.TokenInfo) (*tenant, string) {
+	switch s.config().TenantRouting {
+	case config.TenantRoutingHeader:
+		name := strings.TrimSpace(header.Get(s.config().TenantHeader))
+		if name == "" {
+			return nil, fmt.Sprintf("missing %s header", s.config().TenantHeader)
+		}
+		if t := s.tenantByKey(name); t != nil {
+			return t, ""
+		}
+		return nil, fmt.Sprintf("%s header does not name a configured tenant", s.config().TenantHeader)
+
+	case config.TenantRoutingClaim:
+		var granted []string
+		if info != nil {
+			granted, _ = info.Extra[tenantsClaim].([]string)
+		}
+		requested := strings.TrimSpace(header.Get(s.config().TenantHeader))
+		var found *tenant
+		for _, key := range granted {
+			t := s.tenantByKey(key)
+			if t == nil || (requested != "" && key != requested) {
+				continue
+			}
+			if found != nil && found != t {
+				return nil, fmt.Sprintf("token grants several tenants; pick one with the %s header", s.config().TenantHeader)
+			}
+			found = t
+		}
+		if found == nil {
+			return nil, fmt.Sprintf("token does not grant a configured tenant through its %q claim", s.config().TenantClaim)
+		}
+		return found, ""
+
+	default:
+		if t := s.tenantByKey(bearerToken(header)); t != nil {
+			return t, ""
+		}
+		return nil, "token is not mapped to a tenant"
+	}
+}
+
+// requestTenant resolves the tenant of a tool call, see tenantFor
+func (s *MCPServer) requestTenant(req *mcp.CallToolRequest) *tenant {
+	if req == nil {
+		return nil
+	}
+	return s.extraTenant(req.Extra)
+}
+
+// extraTenant resolves the tenant of any MCP request from its transport extras, see tenantFor
+func (s *MCPServer) extraTenant(extra *mcp.RequestExtra) *tenant {
+	if extra == nil || extra.Header == nil {
+		return nil
+	}
+	t, _ := s.tenantFor(extra.Header, extra.TokenInfo)
+	return t
+}
+
+// withTenantAuth rejects MCP requests that do not resolve to a tenant: 401 without any bearer
+// token in token mode, 403 otherwise. It is a no-op when no tenants are configured.
+func (s *MCPServer) withTenantAuth(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(s.live().tenants) > 0 {
+			if s.config().TenantRouting == config.TenantRoutingToken && bearerToken(r.Header) == "" {
+				authFailure(w, r, http.StatusUnauthorized, "missing bearer token")
+				return
+			}
+			if _, reason := s.tenantFor(r.Header, auth.TokenInfoFromContext(r.Context())); reason != "" {
+				authFailure(w, r, http.StatusForbidden, reason)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// scanScope is where a tool call may scan: the server's own executor and cluster client, or a
+// tenant's. Tenant is empty outside multi-tenant mode, user when OIDC is disabled. A tenant
+// bound to one namespace has it in namespace; one limited to several has them in namespaces.
+type scanScope struct {
+	tenant     string
+	user       string
+	namespace  string
+	namespaces []string
+	executor   krr.Executor
+	kube       *kube.Client
+}
+
+// allows reports whether the scope may scan a namespace
+func (scope scanScope) allows(namespace string) bool {
+	if scope.namespace != "" {
+		return namespace == scope.namespace
+	}
+	return len(scope.namespaces) == 0 || slices.Contains(scope.namespaces, namespace)
+}
+
+// scopeFor resolves the scan scope of a tool call and forces the tenant's context, namespace
+// and Prometheus onto options, overriding whatever the client asked for. A tenant limited to
+// several namespaces scans all of them when the client names none, and rejects any other; a
+// tenant bound to a context rejects a cluster_name naming another one.
+func (s *MCPServer) scopeFor(req *mcp.CallToolRequest, options *krr.ScanOptions) (scanScope, error) {
+	if len(s.live().tenants) == 0 {
+		return scanScope{user: callerIdentity(req), executor: s.live().executor, kube: s.live().kube}, nil
+	}
+
+	t := s.requestTenant(req)
+	if t == nil {
+		return scanScope{}, fmt.Errorf("request is not authenticated as a tenant")
+	}
+	scope := scanScope{
+		tenant:     t.config.Name,
+		user:       callerIdentity(req),
+		namespace:  t.config.Namespace,
+		namespaces: t.config.Namespaces,
+		executor:   t.executor,
+		kube:       t.kube,
+	}
+
+	if options != nil {
+		if t.config.Context != "" {
+			// KRR receives cluster_name as a second --context, which would win over the forced one
+			if options.ClusterName != "" && options.ClusterName != t.config.Context {
+				return scanScope{}, fmt.Errorf("cluster_name %q is not allowed for tenant %s", options.ClusterName, t.config.Name)
+			}
+			options.Context = t.config.Context
+		}
+		if t.config.PrometheusURL != "" {
+			options.PrometheusURL = t.config.PrometheusURL
+		}
+		switch {
+		case t.config.Namespace != "":
+			options.Namespace = t.config.Namespace
+		case len(t.config.Namespaces) > 0 && options.Namespace == "":
+			options.Namespaces = t.config.Namespaces
+		case !scope.allows(options.Namespace):
+			return scanScope{}, fmt.Errorf("namespace %q is not allowed for tenant %s", options.Namespace, t.config.Name)
+		}
+	}
+	return scope, nil
+}
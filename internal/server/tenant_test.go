@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	alphaToken = "alpha-token-0123456789"
+	betaToken  = "beta-token-0123456789"
+)
+
+// tenantConfig is a configuration with the tenants alpha and beta, each bound to a namespace
+// of its own, and scan history enabled
+func tenantConfig(t *testing.T) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Transport = config.TransportHTTP
+	cfg.Tenants = map[string]config.TenantConfig{
+		alphaToken: {Name: "alpha", Namespace: "alpha-ns"},
+		betaToken:  {Name: "beta", Namespace: "beta-ns"},
+	}
+	cfg.HistoryDir = t.TempDir()
+	return cfg
+}
+
+// tenantRequest is a tool call authenticated with a tenant's bearer token
+func tenantRequest(token string) *mcp.CallToolRequest {
+	return &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{"Authorization": {"Bearer " + token}}}}
+}
+
+func TestTenantsOnlySeeTheirRunningScans(t *testing.T) {
+	s, fake := newTestServer(t, tenantConfig(t))
+	fake.block = make(chan struct{})
+	alpha, beta := tenantRequest(alphaToken), tenantRequest(betaToken)
+
+	done := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		result, _, _ := s.handleScanTyped(context.Background(), alpha, KRRScanArguments{})
+		done <- result
+	}()
+	var running []RunningScan
+	waitFor(t, "alpha's scan to start", func() bool {
+		_, out, _ := s.handleListRunning(context.Background(), alpha, KRRListRunningArguments{})
+		running = out.Scans
+		return len(running) == 1
+	})
+	if running[0].Tenant != "alpha" || running[0].Options.Namespace != "alpha-ns" {
+		t.Errorf("running scan = %+v, want alpha's scan of alpha-ns", running[0])
+	}
+
+	if _, out, _ := s.handleListRunning(context.Background(), beta, KRRListRunningArguments{}); len(out.Scans) != 0 {
+		t.Errorf("beta sees running scans %+v", out.Scans)
+	}
+	result, _, _ := s.handleCancel(context.Background(), beta, KRRCancelArguments{RequestID: running[0].RequestID})
+	if result == nil || !result.IsError {
+		t.Fatal("beta canceled alpha's scan")
+	}
+	if _, out, _ := s.handleListRunning(context.Background(), alpha, KRRListRunningArguments{}); len(out.Scans) != 1 {
+		t.Fatal("alpha's scan stopped after beta's cancel")
+	}
+
+	if result, _, _ := s.handleCancel(context.Background(), alpha, KRRCancelArguments{RequestID: running[0].RequestID}); result != nil {
+		t.Fatalf("alpha could not cancel its own scan: %s", resultText(result))
+	}
+	select {
+	case result := <-done:
+		if result == nil || !strings.Contains(resultText(result), "canceled") {
+			t.Errorf("canceled scan returned %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("canceled scan did not return")
+	}
+}
+
+func TestTenantsOnlySeeTheirJobs(t *testing.T) {
+	s, _ := newTestServer(t, tenantConfig(t))
+	alpha, beta := tenantRequest(alphaToken), tenantRequest(betaToken)
+
+	result, job, _ := s.handleScanAsync(context.Background(), alpha, KRRScanArguments{})
+	if result != nil {
+		t.Fatalf("handleScanAsync() = %s", resultText(result))
+	}
+	waitFor(t, "the job to finish", func() bool {
+		_, status, _ := s.handleScanStatus(context.Background(), alpha, KRRScanJobArguments{JobID: job.JobID})
+		return status.Status != jobRunning
+	})
+
+	if result, _, _ := s.handleScanStatus(context.Background(), beta, KRRScanJobArguments{JobID: job.JobID}); result == nil || !result.IsError {
+		t.Error("beta read the status of alpha's job")
+	}
+	if result, _, _ := s.handleScanResult(context.Background(), beta, KRRScanJobArguments{JobID: job.JobID}); result == nil || !result.IsError {
+		t.Error("beta read the result of alpha's job")
+	}
+	if result, _, _ := s.handleScanResult(context.Background(), alpha, KRRScanJobArguments{JobID: job.JobID}); result != nil {
+		t.Errorf("alpha could not read its own job: %s", resultText(result))
+	}
+}
+
+func TestTenantsOnlySeeTheirHistory(t *testing.T) {
+	s, fake := newTestServer(t, tenantConfig(t))
+	fake.result = &krr.ScanResult{Resources: []krr.Resource{{Name: "web", Namespace: "alpha-ns", Kind: "Deployment", Severity: krr.SeverityOK}}}
+	alpha, beta := tenantRequest(alphaToken), tenantRequest(betaToken)
+
+	format := outputModeJSON
+	if result, _, _ := s.handleScanTyped(context.Background(), alpha, KRRScanArguments{OutputFormat: &format}); result != nil {
+		t.Fatalf("handleScanTyped() = %s", resultText(result))
+	}
+
+	_, history, _ := s.handleScanHistory(context.Background(), alpha, KRRScanHistoryArguments{})
+	if len(history.Scans) != 1 {
+		t.Fatalf("alpha sees %d stored scans, want 1", len(history.Scans))
+	}
+	scan := history.Scans[0]
+
+	if _, out, _ := s.handleScanHistory(context.Background(), beta, KRRScanHistoryArguments{}); len(out.Scans) != 0 {
+		t.Errorf("beta sees stored scans %+v", out.Scans)
+	}
+	if result, _, _ := s.handleScanHistory(context.Background(), beta, KRRScanHistoryArguments{ScanID: &scan.ID}); result == nil || !result.IsError {
+		t.Error("beta read the recommendations of alpha's scan")
+	}
+	if _, out, _ := s.handleRecent(context.Background(), beta, KRRRecentArguments{}); len(out.Scans) != 0 {
+		t.Errorf("beta sees recent scans %+v", out.Scans)
+	}
+	if _, out, _ := s.handleRecent(context.Background(), alpha, KRRRecentArguments{}); len(out.Scans) != 1 {
+		t.Errorf("alpha sees %d recent scans, want 1", len(out.Scans))
+	}
+	if scan.Tenant != "alpha" {
+		t.Errorf("stored scan tenant = %q, want alpha", scan.Tenant)
+	}
+}
+
+func TestTenantScansAreForcedIntoTheirNamespace(t *testing.T) {
+	s, fake := newTestServer(t, tenantConfig(t))
+	other := "beta-ns"
+
+	if result, _, _ := s.handleScanTyped(context.Background(), tenantRequest(alphaToken), KRRScanArguments{Namespace: &other}); result != nil && result.IsError {
+		t.Fatalf("handleScanTyped() = %s", resultText(result))
+	}
+	if got := fake.options()[0].Namespace; got != "alpha-ns" {
+		t.Errorf("alpha scanned namespace %q, want alpha-ns", got)
+	}
+
+	result, _, _ := s.handleScanTyped(context.Background(), tenantRequest("not-a-tenant-token-000"), KRRScanArguments{})
+	if result == nil || !result.IsError {
+		t.Error("a request without a tenant token was scanned")
+	}
+}
+
+func TestTenantClusterNameCannotLeaveTheirContext(t *testing.T) {
+	cfg := tenantConfig(t)
+	cfg.Tenants[alphaToken] = config.TenantConfig{Name: "alpha", Namespace: "alpha-ns", Context: "alpha-ctx"}
+	s, fake := newTestServer(t, cfg)
+	other := "beta-ctx"
+
+	result, _, _ := s.handleScanTyped(context.Background(), tenantRequest(alphaToken), KRRScanArguments{ClusterName: &other})
+	if result == nil || !strings.Contains(resultText(result), `cluster_name "beta-ctx" is not allowed`) {
+		t.Fatalf("handleScanTyped() = %+v, want cluster_name rejected", result)
+	}
+	if n := len(fake.options()); n != 0 {
+		t.Errorf("KRR ran %d times", n)
+	}
+}
+
+func TestTenantScanArgvHoldsOnlyTheirContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake KRR binary is a shell script")
+	}
+	// The fake KRR prints one argument per line
+	krrPath := filepath.Join(t.TempDir(), "krr")
+	if err := os.WriteFile(krrPath, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.KRRPath = krrPath
+	cfg.Tenants = map[string]config.TenantConfig{alphaToken: {Name: "alpha", Namespace: "alpha-ns", Context: "alpha-ctx"}}
+	s, err := NewMCPServer(cfg)
+	if err != nil {
+		t.Fatalf("NewMCPServer() error = %v", err)
+	}
+	kubeContext, clusterName, format, raw := "beta-ctx", "alpha-ctx", outputModeTable, true
+
+	result, out, _ := s.handleScanTyped(context.Background(), tenantRequest(alphaToken), KRRScanArguments{
+		Context: &kubeContext, ClusterName: &clusterName, OutputFormat: &format, RawResult: &raw,
+	})
+	if result != nil {
+		t.Fatalf("handleScanTyped() = %s", resultText(result))
+	}
+	args := strings.Split(strings.TrimSpace(out.Result), "\n")
+	var contexts []string
+	for i, arg := range args {
+		if arg == "--context" && i+1 < len(args) {
+			contexts = append(contexts, args[i+1])
+		}
+	}
+	for _, kubeContext := range contexts {
+		if kubeContext != "alpha-ctx" {
+			t.Errorf("KRR ran with --context %s (args %q), want only alpha-ctx", kubeContext, args)
+		}
+	}
+	if len(contexts) == 0 {
+		t.Errorf("KRR ran without --context (args %q)", args)
+	}
+}
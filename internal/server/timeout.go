@@ -0,0 +1,81 @@
+package server
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// timeoutHeaders are the HTTP headers a client may use to announce how long it is willing to wait,
+// in order of precedence
+var timeoutHeaders = []string{"X-MCP-Timeout", "Request-Timeout"}
+
+// scanTimeout resolves the timeout for a scan. An explicit timeout_seconds argument wins over
+// a client deadline header, which wins over the configured default. The result is capped by
+// max_timeout (or default_timeout, if that is larger).
+func (s *MCPServer) scanTimeout(req *mcp.CallToolRequest, timeoutSeconds *int) time.Duration {
+	timeout := s.config().DefaultTimeout
+
+	if headerTimeout, ok := requestHeaderTimeout(req); ok {
+		timeout = headerTimeout
+	}
+
+	if timeoutSeconds != nil && *timeoutSeconds > 0 {
+		timeout = time.Duration(*timeoutSeconds) * time.Second
+	}
+
+	limit := s.config().MaxTimeout
+	if s.config().DefaultTimeout > limit {
+		limit = s.config().DefaultTimeout
+	}
+	if limit > 0 && timeout > limit {
+		timeout = limit
+	}
+
+	return timeout
+}
+
+// requestHeaderTimeout reads a client deadline from the HTTP request headers, if present.
+// Malformed values are logged and ignored.
+func requestHeaderTimeout(req *mcp.CallToolRequest) (time.Duration, bool) {
+	if req == nil || req.Extra == nil || req.Extra.Header == nil {
+		return 0, false
+	}
+
+	for _, name := range timeoutHeaders {
+		value := strings.TrimSpace(req.Extra.Header.Get(name))
+		if value == "" {
+			continue
+		}
+		timeout, err := parseTimeoutHeader(value)
+		if err != nil {
+			log.Printf("Warning: ignoring malformed %s header %q: %v", name, value, err)
+			continue
+		}
+		return timeout, true
+	}
+
+	return 0, false
+}
+
+// parseTimeoutHeader parses a timeout given either as seconds ("90", "1.5") or as a Go duration ("2m")
+func parseTimeoutHeader(value string) (time.Duration, error) {
+	var timeout time.Duration
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		timeout = time.Duration(seconds * float64(time.Second))
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, err
+		}
+		timeout = parsed
+	}
+
+	if timeout <= 0 {
+		return 0, strconv.ErrRange
+	}
+	return timeout, nil
+}
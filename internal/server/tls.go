@@ -0,0 +1,132 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"greenops-mcp/internal/config"
+)
+
+// certReloadInterval bounds how often the certificate files are checked for changes, so
+// handshakes don't stat them every time
+const certReloadInterval = 10 * time.Second
+
+// certReloader serves the TLS key pair from disk and, when reloading is enabled, reloads it once
+// either file changes, so rotated certificates (e.g. from cert-manager) are picked up without a
+// restart. A rotation that fails to load keeps the previous pair.
+type certReloader struct {
+	certFile, keyFile string
+	reload            bool
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTimes  [2]time.Time
+	checkedAt time.Time
+}
+
+// newCertReloader loads the initial key pair
+func newCertReloader(certFile, keyFile string, reload bool) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, reload: reload}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// load reads the key pair and remembers the files' modification times
+func (r *certReloader) load() error {
+	modTimes, err := r.modTimesNow()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+	r.cert = &cert
+	r.modTimes = modTimes
+	return nil
+}
+
+// modTimesNow returns the current modification times of the certificate and key files
+func (r *certReloader) modTimesNow() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+	for i, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return modTimes, fmt.Errorf("failed to stat TLS file: %w", err)
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.reload && time.Since(r.checkedAt) >= certReloadInterval {
+		r.checkedAt = time.Now()
+		if modTimes, err := r.modTimesNow(); err != nil {
+			log.Printf("Keeping current TLS certificate: %v", err)
+		} else if modTimes != r.modTimes {
+			if err := r.load(); err != nil {
+				log.Printf("Keeping current TLS certificate: %v", err)
+			} else {
+				log.Printf("Reloaded TLS certificate from %s", r.certFile)
+			}
+		}
+	}
+	return r.cert, nil
+}
+
+// newTLSConfig builds the HTTP server's TLS configuration, or returns nil when TLS is not
+// configured. Client certificates are verified against tls_client_ca_file when presented but
+// not required by the handshake, so probes can still reach the health checks; withClientCert
+// requires them on the MCP endpoint.
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if cfg.TLSCertFile == "" {
+		return nil, nil
+	}
+	reloader, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSAutoReload)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		data, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("client CA file %s contains no PEM certificates", cfg.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
+// withClientCert rejects MCP requests without a client certificate verified against
+// tls_client_ca_file (401). It is a no-op unless mutual TLS is configured.
+func (s *MCPServer) withClientCert(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.config().TLSClientCAFile != "" && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
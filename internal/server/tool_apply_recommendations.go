@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/kube"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Outcomes of one workload patch of krr_apply_recommendations
+const (
+	patchValidated = "validated" // the dry run passed
+	patchApplied   = "applied"
+	patchFailed    = "failed"
+)
+
+// KRRApplyRecommendationsArguments defines the arguments for the krr_apply_recommendations tool
+type KRRApplyRecommendationsArguments struct {
+	ScanID        *string            `json:"scan_id,omitempty" jsonschema:"Request ID of the scan whose recommendations to apply, or 'latest' (default 'latest')"`
+	Workloads     []WorkloadSelector `json:"workloads" jsonschema:"Workloads to patch; each selector matches by namespace, kind, name and container, and an empty field matches anything"`
+	IncludeLimits *bool              `json:"include_limits,omitempty" jsonschema:"Also set the recommended limits, not only the requests (default false)"`
+	DryRun        *bool              `json:"dry_run,omitempty" jsonschema:"Only validate the patches with a server-side dry run and return them with a confirm token (default true)"`
+	Confirm       *string            `json:"confirm,omitempty" jsonschema:"The confirm_token of the dry run previewing exactly these patches; required when dry_run is false"`
+}
+
+// AppliedPatch is the patch of one workload and its outcome
+type AppliedPatch struct {
+	krr.WorkloadPatch
+
+	// Patch is the strategic merge patch sent to the API server
+	Patch  map[string]any `json:"patch"`
+	Status string         `json:"status"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// KRRApplyRecommendationsOutput defines the output structure for the krr_apply_recommendations tool
+type KRRApplyRecommendationsOutput struct {
+	ScanID  string            `json:"scan_id"`
+	Context string            `json:"context,omitempty"`
+	DryRun  bool              `json:"dry_run"`
+	Patches []AppliedPatch    `json:"patches"`
+	Skipped []SkippedWorkload `json:"skipped"`
+
+	// ConfirmToken identifies the previewed patches; pass it as confirm to apply them
+	ConfirmToken string `json:"confirm_token,omitempty"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_apply_recommendations",
+		"Apply selected recommendations of a scan to the workloads in the cluster with strategic merge patches of their container requests (and optionally limits). Always run with dry_run first: it validates the exact patches server-side and returns a confirm token, which the real apply requires. Disabled unless the server sets enable_apply",
+		(*MCPServer).handleApplyRecommendations,
+	))
+}
+
+// handleApplyRecommendations previews or applies the patches of the selected workloads. An
+// apply recomputes the patches and only proceeds if they are the ones the dry run confirmed.
+func (s *MCPServer) handleApplyRecommendations(ctx context.Context, req *mcp.CallToolRequest, arguments KRRApplyRecommendationsArguments) (*mcp.CallToolResult, KRRApplyRecommendationsOutput, error) {
+	failed := KRRApplyRecommendationsOutput{Patches: []AppliedPatch{}, Skipped: []SkippedWorkload{}}
+	if !s.config().EnableApply {
+		return errorResult("Applying recommendations is disabled on this server; set enable_apply to allow krr_apply_recommendations to patch workloads"), failed, nil
+	}
+
+	var problems validationErrors
+	if len(arguments.Workloads) == 0 {
+		problems.add("workloads", arguments.Workloads, "must select at least one workload")
+	}
+	dryRun := arguments.DryRun == nil || *arguments.DryRun
+	confirm := ""
+	if arguments.Confirm != nil {
+		confirm = strings.TrimSpace(*arguments.Confirm)
+	}
+	if !dryRun && confirm == "" {
+		problems.add("confirm", nil, "is required when dry_run is false; run with dry_run first and pass its confirm_token")
+	}
+	if len(problems) > 0 {
+		return problems.result(), failed, nil
+	}
+
+	includeLimits := arguments.IncludeLimits != nil && *arguments.IncludeLimits
+	plan, failure := s.planWorkloads(req, arguments.ScanID, arguments.Workloads, includeLimits)
+	if failure != nil {
+		return failure, failed, nil
+	}
+	output := KRRApplyRecommendationsOutput{ScanID: plan.scan.ID, Context: plan.context, DryRun: dryRun, Patches: []AppliedPatch{}, Skipped: plan.skipped}
+	if len(plan.patches) == 0 {
+		return errorResult(fmt.Sprintf("The selected containers of scan %s already run with the recommended values; there is nothing to apply", plan.scan.ID)), output, nil
+	}
+
+	token, err := confirmToken(plan.scan.ID, plan.context, plan.patches)
+	if err != nil {
+		return errorResult(err.Error()), failed, nil
+	}
+	if !dryRun && confirm != token {
+		return errorResult("confirm does not match the patches this call would apply; the selection, options or scan differ from the dry run. Run the dry run again and review its patches"), failed, nil
+	}
+
+	for _, patch := range plan.patches {
+		output.Patches = append(output.Patches, s.applyPatch(ctx, plan.scope.kube, plan.context, patch, dryRun, plan.scope.user, plan.scan.ID))
+	}
+	if dryRun {
+		output.ConfirmToken = token
+	}
+	return nil, output, nil
+}
+
+// applyPatch sends one workload patch, or validates it with a server-side dry run
+func (s *MCPServer) applyPatch(ctx context.Context, client *kube.Client, kubeContext string, patch krr.WorkloadPatch, dryRun bool, user, scanID string) AppliedPatch {
+	applied := AppliedPatch{WorkloadPatch: patch, Patch: patch.StrategicMergePatch()}
+	document, err := json.Marshal(applied.Patch)
+	if err != nil {
+		applied.Status, applied.Error = patchFailed, err.Error()
+		return applied
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, kubeLookupTimeout)
+	defer cancel()
+	if err := client.Patch(ctx, kubeContext, patch.Namespace, patch.Kind, patch.Name, document, dryRun); err != nil {
+		applied.Status, applied.Error = patchFailed, err.Error()
+		return applied
+	}
+	if dryRun {
+		applied.Status = patchValidated
+		return applied
+	}
+	applied.Status = patchApplied
+	log.Printf("Applied recommendations of scan %s to %s %s/%s for %q: %s", scanID, patch.Kind, patch.Namespace, patch.Name, user, document)
+	return applied
+}
+
+// confirmToken hashes everything an apply would do, so an apply can check that it matches
+// the dry run the caller reviewed
+func confirmToken(scanID, kubeContext string, patches []krr.WorkloadPatch) (string, error) {
+	data, err := json.Marshal(struct {
+		ScanID  string              `json:"scan_id"`
+		Context string              `json:"context"`
+		Patches []krr.WorkloadPatch `json:"patches"`
+	}{scanID, kubeContext, patches})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode patches: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/kube"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRBatchScanArguments defines the arguments for the krr_batch_scan tool
+type KRRBatchScanArguments struct {
+	Namespaces        []string `json:"namespaces,omitempty" jsonschema:"Kubernetes namespaces to scan (required unless namespace_selector is set)"`
+	NamespaceSelector *string  `json:"namespace_selector,omitempty" jsonschema:"Scan every namespace matching this label selector, e.g. 'team=payments' (cannot be combined with namespaces)"`
+	Context           *string  `json:"context,omitempty" jsonschema:"Kubernetes context to use (optional, uses current context if not specified)"`
+	Strategy          *string  `json:"strategy,omitempty" jsonschema:"Recommendation strategy to use (e.g. 'simple' 'simple-limit')"`
+	TimeoutSeconds    *int     `json:"timeout_seconds,omitempty" jsonschema:"Timeout for the whole batch in seconds (optional, capped by the server's max_timeout)"`
+}
+
+// BatchNamespaceResult is the outcome of scanning one namespace in a batch
+type BatchNamespaceResult struct {
+	Namespace string       `json:"namespace"`
+	Summary   *krr.Summary `json:"summary,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// KRRBatchScanOutput defines the output structure for the krr_batch_scan tool
+type KRRBatchScanOutput struct {
+	Namespaces []BatchNamespaceResult `json:"namespaces"`
+	Totals     krr.Summary            `json:"totals"`
+	Failed     int                    `json:"failed"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_batch_scan",
+		"Scan a list of namespaces concurrently and return a per-namespace breakdown with grand totals; a failing namespace is reported without failing the batch",
+		(*MCPServer).handleBatchScan,
+	))
+}
+
+// handleBatchScan scans each namespace in parallel, bounded by the server's scan slots
+func (s *MCPServer) handleBatchScan(ctx context.Context, req *mcp.CallToolRequest, arguments KRRBatchScanArguments) (*mcp.CallToolResult, KRRBatchScanOutput, error) {
+	var namespaces []string
+	seen := make(map[string]bool)
+	for _, namespace := range arguments.Namespaces {
+		namespace = strings.TrimSpace(namespace)
+		if namespace != "" && !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	var problems validationErrors
+	var selector string
+	if arguments.NamespaceSelector != nil {
+		selector = strings.TrimSpace(*arguments.NamespaceSelector)
+		if err := kube.ValidateLabelSelector(selector); err != nil {
+			problems.add("namespace_selector", *arguments.NamespaceSelector, err.Error())
+		} else if len(namespaces) > 0 {
+			problems.add("namespace_selector", *arguments.NamespaceSelector, "cannot be combined with namespaces")
+		}
+	} else if len(namespaces) == 0 {
+		problems.add("namespaces", arguments.Namespaces, "must contain at least one namespace")
+	}
+	if arguments.Strategy != nil {
+		if err := krr.ValidateStrategy(*arguments.Strategy, false); err != nil {
+			problems.add("strategy", *arguments.Strategy, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return problems.result(), KRRBatchScanOutput{}, nil
+	}
+
+	// A tenant bound to a namespace only ever scans that namespace, and one limited to several
+	// only those of them it names or its selector matches
+	var kubeContext string
+	if arguments.Context != nil {
+		kubeContext = *arguments.Context
+	}
+	scopeOptions := krr.ScanOptions{Context: kubeContext}
+	scope, err := s.scopeFor(req, &scopeOptions)
+	if err != nil {
+		return errorResult(err.Error()), KRRBatchScanOutput{}, nil
+	}
+	kubeContext = scopeOptions.Context
+	if scope.namespace != "" {
+		namespaces, selector = []string{scope.namespace}, ""
+	}
+	for _, namespace := range namespaces {
+		if !scope.allows(namespace) {
+			return errorResult(fmt.Sprintf("namespace %q is not allowed for tenant %s", namespace, scope.tenant)), KRRBatchScanOutput{}, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.scanTimeout(req, arguments.TimeoutSeconds))
+	defer cancel()
+
+	// The selector is resolved once up front; the matched namespaces share the scan slots like a list
+	if selector != "" {
+		matched, err := scope.kube.NamespaceNames(ctx, kubeContext, selector)
+		if err != nil {
+			return errorResult(fmt.Sprintf("Failed to list namespaces for namespace_selector: %v", err)), KRRBatchScanOutput{}, nil
+		}
+		matched = slices.DeleteFunc(matched, func(namespace string) bool { return !scope.allows(namespace) })
+		if len(matched) == 0 {
+			return errorResult(fmt.Sprintf("No namespaces match namespace_selector %q", selector)), KRRBatchScanOutput{}, nil
+		}
+		namespaces = matched
+	}
+
+	base := krr.ScanOptions{
+		Output:   krr.OutputJSON,
+		Strategy: s.config().DefaultStrategy,
+		NoColor:  true,
+	}
+	if arguments.Strategy != nil {
+		base.Strategy = *arguments.Strategy
+	}
+	base.Context = kubeContext
+	base.PrometheusURL = scopeOptions.PrometheusURL
+
+	release, limited := s.acquireClientScan(req)
+	if limited != nil {
+		return limited, KRRBatchScanOutput{}, nil
+	}
+	defer release()
+
+	// The batch is tracked as one scan; canceling it stops every namespace still running
+	ctx, _, untrack := s.running.track(ctx, requestID(req), "krr_batch_scan", scope, base)
+	defer untrack()
+
+	results := make([]*krr.ScanResult, len(namespaces))
+	errs := make([]error, len(namespaces))
+	var wg sync.WaitGroup
+	for i, namespace := range namespaces {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			options := base
+			options.Namespace = namespace
+			results[i], errs[i] = s.runScan(ctx, scope.executor, options)
+		}()
+	}
+	wg.Wait()
+
+	// The namespaces share every policy-relevant option, so a rejection applies to the whole batch
+	for _, err := range errs {
+		var policyErr *policyError
+		if errors.As(err, &policyErr) {
+			return policyResult(policyErr.violations), KRRBatchScanOutput{}, nil
+		}
+	}
+
+	output := KRRBatchScanOutput{Namespaces: make([]BatchNamespaceResult, len(namespaces))}
+	var succeeded []*krr.ScanResult
+	for i, namespace := range namespaces {
+		output.Namespaces[i] = BatchNamespaceResult{Namespace: namespace}
+		if errs[i] != nil {
+			output.Namespaces[i].Error = fmt.Sprintf("KRR scan failed: %v", errs[i])
+			output.Failed++
+			continue
+		}
+		summary := results[i].Summary
+		output.Namespaces[i].Summary = &summary
+		succeeded = append(succeeded, results[i])
+	}
+	output.Totals = krr.MergeResults(succeeded...).Summary
+
+	if len(succeeded) == 0 {
+		return errorResult(fmt.Sprintf("KRR scan failed for all %d namespaces: %s", len(namespaces), output.Namespaces[0].Error)), KRRBatchScanOutput{}, nil
+	}
+
+	return nil, output, nil
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRCancelArguments defines the arguments for the krr_cancel tool
+type KRRCancelArguments struct {
+	RequestID string `json:"request_id" jsonschema:"Request ID of the running scan to cancel, as listed by krr_list_running"`
+}
+
+// KRRCancelOutput defines the output structure for the krr_cancel tool
+type KRRCancelOutput struct {
+	RequestID string `json:"request_id"`
+	Canceled  bool   `json:"canceled"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_cancel",
+		"Cancel a running KRR scan by request ID, killing its KRR process",
+		(*MCPServer).handleCancel,
+	))
+}
+
+// handleCancel cancels the context of an in-flight scan
+func (s *MCPServer) handleCancel(ctx context.Context, req *mcp.CallToolRequest, arguments KRRCancelArguments) (*mcp.CallToolResult, KRRCancelOutput, error) {
+	id := strings.TrimSpace(arguments.RequestID)
+	if id == "" {
+		var problems validationErrors
+		problems.add("request_id", arguments.RequestID, "is required")
+		return problems.result(), KRRCancelOutput{}, nil
+	}
+
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return errorResult(err.Error()), KRRCancelOutput{}, nil
+	}
+	if !s.running.cancel(id, scope.tenant) {
+		return errorResult(fmt.Sprintf("No running scan with request ID %q", id)), KRRCancelOutput{}, nil
+	}
+
+	return nil, KRRCancelOutput{RequestID: id, Canceled: true}, nil
+}
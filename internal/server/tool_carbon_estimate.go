@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"sort"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRCarbonEstimateArguments defines the arguments for the krr_carbon_estimate tool
+type KRRCarbonEstimateArguments struct {
+	ScanID        *string  `json:"scan_id,omitempty" jsonschema:"Request ID of the scan to estimate, or 'latest' (default 'latest')"`
+	WattsPerVCPU  *float64 `json:"watts_per_vcpu,omitempty" jsonschema:"Average power drawn per vCPU in watts (optional, default the server's carbon_watts_per_vcpu)"`
+	WattsPerGiB   *float64 `json:"watts_per_gib,omitempty" jsonschema:"Average power drawn per GiB of memory in watts (optional, default the server's carbon_watts_per_gib)"`
+	PUE           *float64 `json:"pue,omitempty" jsonschema:"Power usage effectiveness of the data center, at least 1 (optional, default the server's carbon_pue)"`
+	GridIntensity *float64 `json:"grid_intensity,omitempty" jsonschema:"Carbon intensity of the electricity in gCO2e/kWh (optional, default the grid_intensity of the scan's cluster, then the server's carbon_grid_intensity)"`
+}
+
+// CarbonFigures are the monthly energy and emissions of a group of containers
+type CarbonFigures struct {
+	ReclaimableCPUCores      float64 `json:"reclaimable_cpu_cores"`
+	ReclaimableMemoryGiB     float64 `json:"reclaimable_memory_gib"`
+	CurrentMonthlyKWh        float64 `json:"current_monthly_kwh"`
+	MonthlyKWhSavings        float64 `json:"monthly_kwh_savings"`
+	CurrentMonthlyCO2eKg     float64 `json:"current_monthly_co2e_kg"`
+	RecommendedMonthlyCO2eKg float64 `json:"recommended_monthly_co2e_kg"`
+	MonthlyCO2eSavingsKg     float64 `json:"monthly_co2e_savings_kg"`
+}
+
+// NamespaceCarbon is the monthly energy and emissions of one namespace
+type NamespaceCarbon struct {
+	Namespace string `json:"namespace"`
+	CarbonFigures
+}
+
+// KRRCarbonEstimateOutput defines the output structure for the krr_carbon_estimate tool
+type KRRCarbonEstimateOutput struct {
+	ScanID     string             `json:"scan_id"`
+	Cluster    string             `json:"cluster,omitempty"`
+	Model      krr.CarbonModel    `json:"model"`
+	Total      krr.CarbonEstimate `json:"total"`
+	Namespaces []NamespaceCarbon  `json:"namespaces"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_carbon_estimate",
+		"Estimate the energy (kWh) and emissions (kg CO2e) of a scan's requested resources and what its recommendations would save per month, for the cluster and each namespace, from watts per vCPU and per GiB, the data center's PUE and the grid's carbon intensity (the server's carbon model unless given in the call)",
+		(*MCPServer).handleCarbonEstimate,
+	))
+}
+
+// handleCarbonEstimate estimates the footprint of a stored scan. Tenants only see their own
+// scans and namespaces.
+func (s *MCPServer) handleCarbonEstimate(ctx context.Context, req *mcp.CallToolRequest, arguments KRRCarbonEstimateArguments) (*mcp.CallToolResult, KRRCarbonEstimateOutput, error) {
+	failed := KRRCarbonEstimateOutput{Namespaces: []NamespaceCarbon{}, Total: krr.CarbonEstimate{Assumptions: []string{}}}
+
+	var problems validationErrors
+	for _, argument := range []struct {
+		name  string
+		value *float64
+	}{{"watts_per_vcpu", arguments.WattsPerVCPU}, {"watts_per_gib", arguments.WattsPerGiB}, {"grid_intensity", arguments.GridIntensity}} {
+		if argument.value != nil && *argument.value < 0 {
+			problems.add(argument.name, *argument.value, "cannot be negative")
+		}
+	}
+	if arguments.PUE != nil && *arguments.PUE < 1 {
+		problems.add("pue", *arguments.PUE, "must be at least 1")
+	}
+	if len(problems) > 0 {
+		return problems.result(), failed, nil
+	}
+
+	scan, scope, err := s.loadScopedScan(req, arguments.ScanID)
+	if err != nil {
+		return errorResult(err.Error()), failed, nil
+	}
+	model := s.carbonModel(scan.Cluster, scope)
+	if arguments.WattsPerVCPU != nil {
+		model.WattsPerVCPU = *arguments.WattsPerVCPU
+	}
+	if arguments.WattsPerGiB != nil {
+		model.WattsPerGiB = *arguments.WattsPerGiB
+	}
+	if arguments.PUE != nil {
+		model.PUE = *arguments.PUE
+	}
+	if arguments.GridIntensity != nil {
+		model.GridIntensity = *arguments.GridIntensity
+	}
+
+	summary := krr.SummarizeCluster(&krr.ScanResult{Resources: scan.Resources})
+	output := KRRCarbonEstimateOutput{
+		ScanID:     scan.ID,
+		Cluster:    scan.Cluster,
+		Model:      model,
+		Total:      krr.EstimateCarbon(summary.Totals, model),
+		Namespaces: []NamespaceCarbon{},
+	}
+	for _, namespace := range summary.Namespaces {
+		output.Namespaces = append(output.Namespaces, NamespaceCarbon{Namespace: namespace.Namespace, CarbonFigures: carbonFigures(namespace.Summary, model)})
+	}
+	sort.SliceStable(output.Namespaces, func(i, j int) bool {
+		return output.Namespaces[i].MonthlyCO2eSavingsKg > output.Namespaces[j].MonthlyCO2eSavingsKg
+	})
+	return nil, output, nil
+}
+
+// carbonModel returns the server's carbon model, with the grid intensity of the named registry
+// cluster when that cluster sets one
+func (s *MCPServer) carbonModel(clusterName string, scope scanScope) krr.CarbonModel {
+	cfg := s.config()
+	model := krr.CarbonModel{
+		WattsPerVCPU:  cfg.CarbonWattsPerVCPU,
+		WattsPerGiB:   cfg.CarbonWattsPerGiB,
+		PUE:           cfg.CarbonPUE,
+		GridIntensity: cfg.CarbonGridIntensity,
+		HoursPerMonth: krr.HoursPerMonth,
+	}
+	if clusterName != "" && scope.tenant == "" {
+		if registered, err := s.registryCluster(clusterName); err == nil && registered.config.GridIntensity > 0 {
+			model.GridIntensity = registered.config.GridIntensity
+		}
+	}
+	return model
+}
+
+// carbonFigures estimates the footprint of a summary with the carbon model
+func carbonFigures(summary krr.Summary, model krr.CarbonModel) CarbonFigures {
+	estimate := krr.EstimateCarbon(summary, model)
+	return CarbonFigures{
+		ReclaimableCPUCores:      estimate.ReclaimableCPUCores,
+		ReclaimableMemoryGiB:     estimate.ReclaimableMemoryGiB,
+		CurrentMonthlyKWh:        estimate.CurrentMonthlyKWh,
+		MonthlyKWhSavings:        estimate.MonthlyKWhSavings,
+		CurrentMonthlyCO2eKg:     estimate.CurrentMonthlyCO2eKg,
+		RecommendedMonthlyCO2eKg: estimate.RecommendedMonthlyCO2eKg,
+		MonthlyCO2eSavingsKg:     estimate.MonthlyCO2eSavingsKg,
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRClusterSummaryArguments defines the arguments for the krr_cluster_summary tool
+type KRRClusterSummaryArguments struct {
+	Context        *string `json:"context,omitempty" jsonschema:"Kubernetes context to use (optional, uses current context if not specified)"`
+	Strategy       *string `json:"strategy,omitempty" jsonschema:"Recommendation strategy to use (e.g. 'simple' 'simple-limit')"`
+	TimeoutSeconds *int    `json:"timeout_seconds,omitempty" jsonschema:"Scan timeout in seconds (optional, capped by the server's max_timeout)"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_cluster_summary",
+		"Scan all namespaces and return cluster-level totals of requested vs recommended CPU and memory, estimated utilization and a per-namespace breakdown as compact JSON",
+		(*MCPServer).handleClusterSummary,
+	))
+}
+
+// handleClusterSummary runs an all-namespace scan and rolls the recommendations up per namespace
+func (s *MCPServer) handleClusterSummary(ctx context.Context, req *mcp.CallToolRequest, arguments KRRClusterSummaryArguments) (*mcp.CallToolResult, krr.ClusterSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.scanTimeout(req, arguments.TimeoutSeconds))
+	defer cancel()
+
+	options := krr.ScanOptions{
+		Output:   krr.OutputJSON,
+		Strategy: s.config().DefaultStrategy,
+		NoColor:  true,
+
+		PrometheusUserAgent: s.config().PrometheusUserAgent,
+	}
+	if arguments.Strategy != nil {
+		options.Strategy = *arguments.Strategy
+	}
+	if arguments.Context != nil {
+		options.Context = *arguments.Context
+	}
+
+	scope, err := s.scopeFor(req, &options)
+	if err != nil {
+		return errorResult(err.Error()), krr.ClusterSummary{}, nil
+	}
+
+	release, limited := s.acquireClientScan(req)
+	if limited != nil {
+		return limited, krr.ClusterSummary{}, nil
+	}
+	defer release()
+
+	ctx, id, untrack := s.running.track(ctx, requestID(req), "krr_cluster_summary", scope, options)
+	defer untrack()
+
+	result, err := s.runScan(ctx, scope.executor, options)
+	if err != nil {
+		return scanErrorResult(err, true, id), krr.ClusterSummary{}, nil
+	}
+
+	return nil, krr.SummarizeCluster(result), nil
+}
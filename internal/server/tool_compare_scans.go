@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Why a container entered or left the waste lists of krr_compare_scans
+const (
+	wasteAppeared   = "appeared"   // the container is new in the later scan
+	wasteRegressed  = "regressed"  // the container reclaimed nothing in the earlier scan
+	wasteRightsized = "rightsized" // the container reclaims nothing in the later scan
+	wasteRemoved    = "removed"    // the container is gone from the later scan
+)
+
+// KRRCompareScansArguments defines the arguments for the krr_compare_scans tool
+type KRRCompareScansArguments struct {
+	Before string  `json:"before" jsonschema:"Request ID of the earlier scan, as krr_recent reports it"`
+	After  *string `json:"after,omitempty" jsonschema:"Request ID of the later scan, or 'latest' (default 'latest')"`
+}
+
+// WasteItem is a container whose requests exceed its recommendation in one of the compared scans
+type WasteItem struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container,omitempty"`
+
+	// CPUCores and MemoryBytes are what adopting the recommendation would free, in the later
+	// scan for new waste and in the earlier scan for resolved waste
+	CPUCores    float64 `json:"cpu_cores"`
+	MemoryBytes float64 `json:"memory_bytes"`
+	Reason      string  `json:"reason"`
+}
+
+// ReclaimableTotals sums what every container of a scan would free
+type ReclaimableTotals struct {
+	CPUCores    float64 `json:"cpu_cores"`
+	MemoryBytes float64 `json:"memory_bytes"`
+}
+
+// KRRCompareScansOutput defines the output structure for the krr_compare_scans tool
+type KRRCompareScansOutput struct {
+	Before           string    `json:"before"`
+	After            string    `json:"after"`
+	BeforeFinishedAt time.Time `json:"before_finished_at"`
+	AfterFinishedAt  time.Time `json:"after_finished_at"`
+
+	// Changed are the containers whose recommendation or severity differs between the scans
+	Changed []krr.RecommendationChange `json:"changed"`
+
+	// NewWaste are the containers over-provisioned only in the later scan, and Resolved those
+	// over-provisioned only in the earlier one
+	NewWaste []WasteItem `json:"new_waste"`
+	Resolved []WasteItem `json:"resolved"`
+
+	ReclaimableBefore ReclaimableTotals `json:"reclaimable_before"`
+	ReclaimableAfter  ReclaimableTotals `json:"reclaimable_after"`
+
+	// Warning is set when the scans ran with a different scope, so the diff mixes scope changes
+	// with recommendation changes
+	Warning string `json:"warning,omitempty"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_compare_scans",
+		"Compare two recent krr_scan results by request ID (or an ID and 'latest'): containers whose recommendations changed, newly over-provisioned containers and resolved ones, with the reclaimable capacity of each scan; both scans must have used the json output mode",
+		(*MCPServer).handleCompareScans,
+	))
+}
+
+// handleCompareScans diffs two scans kept for the scan resources. Tenants can only compare
+// their own scans.
+func (s *MCPServer) handleCompareScans(ctx context.Context, req *mcp.CallToolRequest, arguments KRRCompareScansArguments) (*mcp.CallToolResult, KRRCompareScansOutput, error) {
+	failed := KRRCompareScansOutput{Changed: []krr.RecommendationChange{}, NewWaste: []WasteItem{}, Resolved: []WasteItem{}}
+
+	var problems validationErrors
+	beforeID := strings.TrimSpace(arguments.Before)
+	afterID := "latest"
+	if arguments.After != nil {
+		afterID = strings.TrimSpace(*arguments.After)
+	}
+	if beforeID == "" {
+		problems.add("before", arguments.Before, "is required")
+	}
+	if afterID == "" {
+		problems.add("after", *arguments.After, "must be a request ID or 'latest'")
+	}
+	if len(problems) > 0 {
+		return problems.result(), failed, nil
+	}
+
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return errorResult(err.Error()), failed, nil
+	}
+	before, failure := s.comparableScan(beforeID, scope.tenant)
+	if failure != nil {
+		return failure, failed, nil
+	}
+	after, failure := s.comparableScan(afterID, scope.tenant)
+	if failure != nil {
+		return failure, failed, nil
+	}
+	if before.RequestID == after.RequestID {
+		return errorResult(fmt.Sprintf("before and after are the same scan %s", before.RequestID)), failed, nil
+	}
+	// Compare in time order, whichever order the IDs were given in
+	if after.FinishedAt.Before(before.FinishedAt) {
+		before, after = after, before
+	}
+
+	output := compareScans(before, after)
+	if reason := scopeMismatch(before.Output.EffectiveOptions, after.Output.EffectiveOptions); reason != "" {
+		output.Warning = fmt.Sprintf("The scans ran with a different %s; containers outside the common scope show up as added or removed", reason)
+	}
+	return nil, output, nil
+}
+
+// comparableScan looks up a kept scan that carries parsed recommendations
+func (s *MCPServer) comparableScan(id, tenant string) (ScanResource, *mcp.CallToolResult) {
+	scan, ok := s.outputs.get(id, tenant)
+	if !ok {
+		if s.outputs.capacity <= 0 {
+			return ScanResource{}, errorResult("Scan outputs are not kept (recent_scans is 0), so there is nothing to compare")
+		}
+		return ScanResource{}, errorResult(fmt.Sprintf("Scan %s not found; only the last %d successful scans are kept", id, s.outputs.capacity))
+	}
+	if scan.Output.Summary == nil {
+		return ScanResource{}, errorResult(fmt.Sprintf("Scan %s did not use the json output mode, so its recommendations were not kept", scan.RequestID))
+	}
+	return scan, nil
+}
+
+// compareScans diffs the recommendations of two scans and sorts the containers into new and
+// resolved waste by what they would reclaim
+func compareScans(before, after ScanResource) KRRCompareScansOutput {
+	earlier := &krr.ScanResult{Resources: before.Output.Recommendations}
+	later := &krr.ScanResult{Resources: after.Output.Recommendations}
+	diff := krr.DiffResults(earlier, later)
+
+	output := KRRCompareScansOutput{
+		Before:           before.RequestID,
+		After:            after.RequestID,
+		BeforeFinishedAt: before.FinishedAt,
+		AfterFinishedAt:  after.FinishedAt,
+		Changed:          diff.Changed,
+		NewWaste:         []WasteItem{},
+		Resolved:         []WasteItem{},
+	}
+	if output.Changed == nil {
+		output.Changed = []krr.RecommendationChange{}
+	}
+
+	wasted := make(map[string]bool, len(earlier.Resources))
+	for _, resource := range earlier.Resources {
+		cpu, memory := krr.Reclaimable(resource)
+		output.ReclaimableBefore.CPUCores += cpu
+		output.ReclaimableBefore.MemoryBytes += memory
+		wasted[containerKey(resource)] = cpu > 0 || memory > 0
+	}
+
+	present := make(map[string]bool, len(later.Resources))
+	for _, resource := range later.Resources {
+		key := containerKey(resource)
+		present[key] = true
+		cpu, memory := krr.Reclaimable(resource)
+		output.ReclaimableAfter.CPUCores += cpu
+		output.ReclaimableAfter.MemoryBytes += memory
+
+		wasteBefore, seen := wasted[key]
+		switch {
+		case (cpu > 0 || memory > 0) && !wasteBefore:
+			reason := wasteRegressed
+			if !seen {
+				reason = wasteAppeared
+			}
+			output.NewWaste = append(output.NewWaste, newWasteItem(resource, cpu, memory, reason))
+		case cpu == 0 && memory == 0 && wasteBefore:
+			previous := findResource(earlier.Resources, key)
+			cpu, memory = krr.Reclaimable(previous)
+			output.Resolved = append(output.Resolved, newWasteItem(previous, cpu, memory, wasteRightsized))
+		}
+	}
+	for _, resource := range earlier.Resources {
+		if present[containerKey(resource)] {
+			continue
+		}
+		if cpu, memory := krr.Reclaimable(resource); cpu > 0 || memory > 0 {
+			output.Resolved = append(output.Resolved, newWasteItem(resource, cpu, memory, wasteRemoved))
+		}
+	}
+	return output
+}
+
+// containerKey identifies a container across scans
+func containerKey(resource krr.Resource) string {
+	return resource.Namespace + "/" + resource.Kind + "/" + resource.Name + "/" + resource.Container
+}
+
+// findResource returns the container of resources with the given key
+func findResource(resources []krr.Resource, key string) krr.Resource {
+	for _, resource := range resources {
+		if containerKey(resource) == key {
+			return resource
+		}
+	}
+	return krr.Resource{}
+}
+
+// newWasteItem describes a container with what it would reclaim
+func newWasteItem(resource krr.Resource, cpuCores, memoryBytes float64, reason string) WasteItem {
+	return WasteItem{
+		Namespace:   resource.Namespace,
+		Kind:        resource.Kind,
+		Name:        resource.Name,
+		Container:   resource.Container,
+		CPUCores:    cpuCores,
+		MemoryBytes: memoryBytes,
+		Reason:      reason,
+	}
+}
+
+// scopeMismatch names the first scope setting two scans differ in, or returns ""
+func scopeMismatch(before, after *EffectiveScanOptions) string {
+	if before == nil || after == nil {
+		return ""
+	}
+	switch {
+	case before.Context != after.Context:
+		return "context"
+	case before.Namespace != after.Namespace:
+		return "namespace"
+	case !slices.Equal(before.Namespaces, after.Namespaces):
+		return "namespace list"
+	case !slices.Equal(before.Resources, after.Resources):
+		return "resource kind filter"
+	}
+	return ""
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Limits of the top argument of krr_cost_estimate
+const (
+	defaultCostWorkloads = 20
+	maxCostWorkloads     = 1000
+)
+
+// KRRCostEstimateArguments defines the arguments for the krr_cost_estimate tool
+type KRRCostEstimateArguments struct {
+	ScanID               *string  `json:"scan_id,omitempty" jsonschema:"Request ID of the scan to price, or 'latest' (default 'latest')"`
+	Pricing              *string  `json:"pricing,omitempty" jsonschema:"Name of a price set of the server's pricing (optional; defaults to the price set of the scan's cluster, then the server's cost model)"`
+	CPUCostPerCoreHour   *float64 `json:"cpu_cost_per_core_hour,omitempty" jsonschema:"Price of one CPU core for one hour, overriding the selected prices (optional)"`
+	MemoryCostPerGiBHour *float64 `json:"memory_cost_per_gib_hour,omitempty" jsonschema:"Price of one GiB of memory for one hour, overriding the selected prices (optional)"`
+	Top                  *int     `json:"top,omitempty" jsonschema:"Number of workloads to return, the most wasteful first (default 20, maximum 1000)"`
+}
+
+// CostFigures are the monthly cost figures of a group of containers
+type CostFigures struct {
+	ReclaimableCPUCores     float64 `json:"reclaimable_cpu_cores"`
+	ReclaimableMemoryGiB    float64 `json:"reclaimable_memory_gib"`
+	CurrentMonthlyCost      float64 `json:"current_monthly_cost"`
+	RecommendedMonthlyCost  float64 `json:"recommended_monthly_cost"`
+	EstimatedMonthlySavings float64 `json:"estimated_monthly_savings"`
+}
+
+// NamespaceCost is the monthly cost of one namespace
+type NamespaceCost struct {
+	Namespace string `json:"namespace"`
+	CostFigures
+}
+
+// WorkloadCost is the monthly cost of one workload
+type WorkloadCost struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	CostFigures
+}
+
+// KRRCostEstimateOutput defines the output structure for the krr_cost_estimate tool
+type KRRCostEstimateOutput struct {
+	ScanID  string `json:"scan_id"`
+	Cluster string `json:"cluster,omitempty"`
+
+	// Pricing names the price set used, or "server" for the server's cost model, with
+	// "(overridden)" when an argument changed a price
+	Pricing  string `json:"pricing"`
+	Currency string `json:"currency,omitempty"`
+
+	Total      krr.CostEstimate `json:"total"`
+	Namespaces []NamespaceCost  `json:"namespaces"`
+
+	// Workloads are the most wasteful workloads, by estimated monthly savings
+	Workloads        []WorkloadCost `json:"workloads"`
+	WorkloadsOmitted int            `json:"workloads_omitted,omitempty"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_cost_estimate",
+		"Estimate how much money a scan's over-provisioned requests waste: monthly current cost, recommended cost and savings for the cluster, each namespace and the most wasteful workloads, from per-core-hour and per-GiB-hour prices (the server's cost model, a named price set, or prices given in the call)",
+		(*MCPServer).handleCostEstimate,
+	))
+}
+
+// handleCostEstimate prices a stored scan. Tenants only see their own scans and namespaces.
+func (s *MCPServer) handleCostEstimate(ctx context.Context, req *mcp.CallToolRequest, arguments KRRCostEstimateArguments) (*mcp.CallToolResult, KRRCostEstimateOutput, error) {
+	failed := KRRCostEstimateOutput{Namespaces: []NamespaceCost{}, Workloads: []WorkloadCost{}, Total: krr.CostEstimate{Assumptions: []string{}}}
+
+	var problems validationErrors
+	top := defaultCostWorkloads
+	if arguments.Top != nil {
+		top = *arguments.Top
+		if top < 1 || top > maxCostWorkloads {
+			problems.add("top", top, fmt.Sprintf("must be between 1 and %d", maxCostWorkloads))
+		}
+	}
+	if arguments.CPUCostPerCoreHour != nil && *arguments.CPUCostPerCoreHour < 0 {
+		problems.add("cpu_cost_per_core_hour", *arguments.CPUCostPerCoreHour, "cannot be negative")
+	}
+	if arguments.MemoryCostPerGiBHour != nil && *arguments.MemoryCostPerGiBHour < 0 {
+		problems.add("memory_cost_per_gib_hour", *arguments.MemoryCostPerGiBHour, "cannot be negative")
+	}
+	pricing := ""
+	if arguments.Pricing != nil {
+		pricing = strings.TrimSpace(*arguments.Pricing)
+		if _, ok := s.config().Pricing[pricing]; !ok {
+			problems.add("pricing", pricing, fmt.Sprintf("is not a price set of the server; defined: %s", strings.Join(slices.Sorted(maps.Keys(s.config().Pricing)), ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return problems.result(), failed, nil
+	}
+
+	scan, scope, err := s.loadScopedScan(req, arguments.ScanID)
+	if err != nil {
+		return errorResult(err.Error()), failed, nil
+	}
+
+	output := KRRCostEstimateOutput{ScanID: scan.ID, Cluster: scan.Cluster, Namespaces: []NamespaceCost{}, Workloads: []WorkloadCost{}}
+	model, name, currency := s.costModelFor(scan, scope, pricing)
+	output.Pricing, output.Currency = name, currency
+	if arguments.CPUCostPerCoreHour != nil || arguments.MemoryCostPerGiBHour != nil {
+		if arguments.CPUCostPerCoreHour != nil {
+			model.CPUCostPerCoreHour = *arguments.CPUCostPerCoreHour
+		}
+		if arguments.MemoryCostPerGiBHour != nil {
+			model.MemoryCostPerGiBHour = *arguments.MemoryCostPerGiBHour
+		}
+		output.Pricing += " (overridden)"
+	}
+	if !model.Enabled() {
+		return errorResult("No prices to estimate costs with; set cpu_cost_per_core_hour and memory_cost_per_gib_hour on the server, define a price set in pricing, or pass the prices in the call"), failed, nil
+	}
+
+	summary := krr.SummarizeCluster(&krr.ScanResult{Resources: scan.Resources})
+	output.Total = krr.EstimateCost(summary.Totals, model)
+	for _, namespace := range summary.Namespaces {
+		output.Namespaces = append(output.Namespaces, NamespaceCost{Namespace: namespace.Namespace, CostFigures: costFigures(namespace.Summary, model)})
+	}
+	sort.SliceStable(output.Namespaces, func(i, j int) bool {
+		return output.Namespaces[i].EstimatedMonthlySavings > output.Namespaces[j].EstimatedMonthlySavings
+	})
+
+	for _, workload := range krr.SummarizeWorkloads(scan.Resources) {
+		output.Workloads = append(output.Workloads, WorkloadCost{
+			Namespace:   workload.Namespace,
+			Kind:        workload.Kind,
+			Name:        workload.Name,
+			CostFigures: costFigures(workload.Summary, model),
+		})
+	}
+	sort.SliceStable(output.Workloads, func(i, j int) bool {
+		return output.Workloads[i].EstimatedMonthlySavings > output.Workloads[j].EstimatedMonthlySavings
+	})
+	if len(output.Workloads) > top {
+		output.WorkloadsOmitted = len(output.Workloads) - top
+		output.Workloads = output.Workloads[:top]
+	}
+	return nil, output, nil
+}
+
+// costModelFor returns the cost model of a stored scan, with the name of its prices and their
+// currency: the named price set, else the price set of the registry cluster the scan ran
+// against, else the server's cost model
+func (s *MCPServer) costModelFor(scan storedScan, scope scanScope, pricing string) (krr.CostModel, string, string) {
+	if pricing == "" && scan.Cluster != "" && scope.tenant == "" {
+		if registered, err := s.registryCluster(scan.Cluster); err == nil {
+			pricing = registered.config.Pricing
+		}
+	}
+	model := s.costModel()
+	prices, ok := s.config().Pricing[pricing]
+	if !ok {
+		return model, "server", ""
+	}
+	model.CPUCostPerCoreHour, model.MemoryCostPerGiBHour = prices.CPUCostPerCoreHour, prices.MemoryCostPerGiBHour
+	return model, pricing, prices.Currency
+}
+
+// costFigures prices a summary with the cost model
+func costFigures(summary krr.Summary, model krr.CostModel) CostFigures {
+	estimate := krr.EstimateCost(summary, model)
+	return CostFigures{
+		ReclaimableCPUCores:     estimate.ReclaimableCPUCores,
+		ReclaimableMemoryGiB:    estimate.ReclaimableMemoryGiB,
+		CurrentMonthlyCost:      estimate.CurrentMonthlyCost,
+		RecommendedMonthlyCost:  estimate.RecommendedMonthlyCost,
+		EstimatedMonthlySavings: estimate.EstimatedMonthlySavings,
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRDeleteScheduleArguments defines the arguments for the krr_delete_schedule tool
+type KRRDeleteScheduleArguments struct {
+	Name string `json:"name" jsonschema:"Name of the schedule to delete, as krr_list_schedules reports it"`
+}
+
+// KRRDeleteScheduleOutput defines the output structure for the krr_delete_schedule tool
+type KRRDeleteScheduleOutput struct {
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_delete_schedule",
+		"Delete a scheduled scan created with krr_schedule_scan, canceling its run in progress; schedules from the server configuration cannot be deleted",
+		(*MCPServer).handleDeleteSchedule,
+	))
+}
+
+// handleDeleteSchedule removes a tool-defined schedule
+func (s *MCPServer) handleDeleteSchedule(ctx context.Context, req *mcp.CallToolRequest, arguments KRRDeleteScheduleArguments) (*mcp.CallToolResult, KRRDeleteScheduleOutput, error) {
+	name := strings.TrimSpace(arguments.Name)
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return errorResult(err.Error()), KRRDeleteScheduleOutput{Name: name}, nil
+	}
+	if scope.tenant != "" {
+		return errorResult("Scheduled scans are not available to tenants"), KRRDeleteScheduleOutput{Name: name}, nil
+	}
+
+	if err := s.removeSchedule(name); err != nil {
+		return errorResult(err.Error()), KRRDeleteScheduleOutput{Name: name}, nil
+	}
+	return nil, KRRDeleteScheduleOutput{Name: name, Deleted: true}, nil
+}
@@ -0,0 +1,378 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Outcomes of a krr_doctor check
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// doctorCheckTimeout bounds each krr_doctor check
+const doctorCheckTimeout = 15 * time.Second
+
+// maxPrometheusResponse bounds how much of a Prometheus query response is read
+const maxPrometheusResponse = 16 << 20
+
+// KRRDoctorArguments defines the arguments for the krr_doctor tool
+type KRRDoctorArguments struct {
+	Context   *string `json:"context,omitempty" jsonschema:"Kubernetes context to check (optional, uses current context if not specified)"`
+	Cluster   *string `json:"cluster,omitempty" jsonschema:"Named cluster from the server's cluster registry to check (optional, cannot be combined with context)"`
+	Namespace *string `json:"namespace,omitempty" jsonschema:"Namespace whose container metrics to look for (optional, all namespaces if not specified)"`
+}
+
+// DoctorCheck is the outcome of one krr_doctor check, with a remediation hint when it did not pass
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// KRRDoctorOutput defines the output structure for the krr_doctor tool
+type KRRDoctorOutput struct {
+	// Healthy is true when no check failed; warnings and skipped checks do not count
+	Healthy bool          `json:"healthy"`
+	Checks  []DoctorCheck `json:"checks"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_doctor",
+		"Diagnose why scans fail: check the KRR installation and version, the kubeconfig context, API server reachability, Prometheus connectivity and whether the container metrics KRR needs exist, returning pass/fail per check with remediation hints",
+		(*MCPServer).handleDoctor,
+	))
+}
+
+// handleDoctor runs every check in order. A failed check does not stop the later ones, except
+// that the cluster check needs a kubeconfig context and the metrics check a reachable Prometheus.
+func (s *MCPServer) handleDoctor(ctx context.Context, req *mcp.CallToolRequest, arguments KRRDoctorArguments) (*mcp.CallToolResult, KRRDoctorOutput, error) {
+	output := KRRDoctorOutput{Checks: []DoctorCheck{}}
+
+	var options krr.ScanOptions
+	if arguments.Context != nil {
+		options.Context = strings.TrimSpace(*arguments.Context)
+	}
+	if arguments.Namespace != nil {
+		options.Namespace = strings.TrimSpace(*arguments.Namespace)
+	}
+	var registered *cluster
+	if arguments.Cluster != nil {
+		var problems validationErrors
+		var err error
+		if arguments.Context != nil {
+			problems.add("cluster", *arguments.Cluster, "cannot be combined with context")
+		} else if registered, err = s.registryCluster(*arguments.Cluster); err != nil {
+			problems.add("cluster", *arguments.Cluster, err.Error())
+		}
+		if len(problems) > 0 {
+			return problems.result(), output, nil
+		}
+		options.Context = registered.config.Context
+		options.PrometheusURL = registered.config.PrometheusURL
+	}
+
+	scope, err := s.scopeFor(req, &options)
+	if err != nil {
+		return errorResult(err.Error()), output, nil
+	}
+	if registered != nil {
+		if scope.tenant != "" {
+			return errorResult("cluster is not allowed for tenants"), output, nil
+		}
+		scope.executor, scope.kube = registered.executor, registered.kube
+	}
+
+	kubeconfig := s.checkKubeconfig(ctx, scope, options.Context)
+	clusterCheck := DoctorCheck{Name: "cluster", Status: checkSkip, Detail: "needs a usable kubeconfig context"}
+	if kubeconfig.Status != checkFail {
+		clusterCheck = s.checkCluster(ctx, scope, options.Context)
+	}
+	output.Checks = append(output.Checks, s.checkKRR(ctx, scope), kubeconfig, clusterCheck)
+	prometheus, client, prometheusURL := s.checkPrometheus(ctx, options.PrometheusURL)
+	output.Checks = append(output.Checks, prometheus, s.checkMetrics(ctx, client, prometheusURL, prometheus.Status, options.Namespace))
+
+	output.Healthy = true
+	for _, check := range output.Checks {
+		if check.Status == checkFail {
+			output.Healthy = false
+		}
+	}
+	return nil, output, nil
+}
+
+// checkKRR runs the KRR CLI for its version
+func (s *MCPServer) checkKRR(ctx context.Context, scope scanScope) DoctorCheck {
+	check := DoctorCheck{Name: "krr"}
+	ctx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+	defer cancel()
+
+	version, err := scope.executor.GetVersion(ctx)
+	switch {
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		check.Status, check.Detail = checkFail, fmt.Sprintf("KRR CLI not found at %q", s.config().KRRPath)
+		check.Hint = "Install KRR with `pip install krr`, or set krr_path (KRR_PATH) to the krr executable"
+	case err != nil:
+		check.Status, check.Detail = checkFail, err.Error()
+		check.Hint = fmt.Sprintf("Run `%s --version` on the server host to see the error; KRR needs a working Python environment with its dependencies", s.config().KRRPath)
+	default:
+		check.Status, check.Detail = checkPass, "KRR "+version
+	}
+	return check
+}
+
+// checkKubeconfig checks that the context scans use exists in the kubeconfig. A kubeconfig
+// without contexts means KRR relies on in-cluster credentials.
+func (s *MCPServer) checkKubeconfig(ctx context.Context, scope scanScope, kubeContext string) DoctorCheck {
+	check := DoctorCheck{Name: "kubeconfig"}
+	ctx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+	defer cancel()
+
+	contexts, err := scope.kube.Contexts(ctx)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		if errors.Is(err, exec.ErrNotFound) {
+			check.Hint = "Install kubectl, or set kubectl_path (KUBECTL_PATH) to the kubectl executable"
+		} else {
+			check.Hint = "Check that the kubeconfig (KUBECONFIG, ~/.kube/config or kubeconfig_data) is valid YAML"
+		}
+		return check
+	}
+	if len(contexts) == 0 {
+		check.Status, check.Detail = checkPass, "no kubeconfig contexts; KRR will use in-cluster credentials"
+		return check
+	}
+
+	names := make([]string, 0, len(contexts))
+	current := ""
+	for _, c := range contexts {
+		names = append(names, c.Name)
+		if c.Current {
+			current = c.Name
+		}
+		if kubeContext != "" && c.Name == kubeContext {
+			check.Status, check.Detail = checkPass, fmt.Sprintf("context %q found (cluster %s)", c.Name, c.Server)
+			return check
+		}
+	}
+	switch {
+	case kubeContext != "":
+		check.Status, check.Detail = checkFail, fmt.Sprintf("context %q is not in the kubeconfig", kubeContext)
+		check.Hint = fmt.Sprintf("Use one of the defined contexts (%s); krr_list_contexts lists them", strings.Join(names, ", "))
+	case current == "":
+		check.Status, check.Detail = checkFail, "the kubeconfig has no current context"
+		check.Hint = "Pass a context, or set one with `kubectl config use-context`"
+	default:
+		check.Status, check.Detail = checkPass, fmt.Sprintf("current context %q", current)
+	}
+	return check
+}
+
+// checkCluster checks that the API server answers with the kubeconfig's credentials
+func (s *MCPServer) checkCluster(ctx context.Context, scope scanScope, kubeContext string) DoctorCheck {
+	check := DoctorCheck{Name: "cluster"}
+	ctx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+	defer cancel()
+
+	version, err := scope.kube.ServerVersion(ctx, kubeContext)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		check.Hint = "Check network access from the server to the Kubernetes API server and that the credentials are valid and unexpired"
+		return check
+	}
+	check.Status, check.Detail = checkPass, "API server reachable, Kubernetes "+version
+	return check
+}
+
+// checkPrometheus runs a trivial query against the Prometheus scans use: the configured URL,
+// or the one the last scan discovered. It returns the client and URL for the metrics check.
+func (s *MCPServer) checkPrometheus(ctx context.Context, configured string) (DoctorCheck, *http.Client, string) {
+	check := DoctorCheck{Name: "prometheus"}
+	target, discovered := s.prometheusTarget(configured)
+	if target == "" {
+		check.Status, check.Detail = checkSkip, "no Prometheus URL is configured and no scan has discovered one yet; KRR discovers Prometheus in the cluster at scan time"
+		check.Hint = "Run krr_scan with verbose, or configure a prometheus_url for the tenant or cluster, and check again"
+		return check, nil, ""
+	}
+
+	client, err := s.prometheusClient()
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		check.Hint = "Fix prometheus_ca_cert_file"
+		return check, nil, ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+	defer cancel()
+	if _, err := s.queryPrometheus(ctx, client, target, "vector(1)"); err != nil {
+		check.Status, check.Detail = checkFail, fmt.Sprintf("%s: %v", target, err)
+		check.Hint = "Check that the server can reach Prometheus and, for HTTPS, that prometheus_ca_cert_file holds its CA"
+		if discovered {
+			// KRR may reach a discovered in-cluster service through the API server proxy
+			check.Status = checkWarn
+			check.Hint = "The URL was discovered by KRR inside the cluster and may only be reachable there; configure a prometheus_url the server can reach to check it directly"
+		}
+		return check, nil, ""
+	}
+	check.Status, check.Detail = checkPass, target+" answers queries"
+	return check, client, target
+}
+
+// prometheusTarget returns the configured Prometheus URL, else the one the last scan discovered,
+// and whether it was discovered. It is empty when there is neither.
+func (s *MCPServer) prometheusTarget(configured string) (string, bool) {
+	if configured != "" {
+		return configured, false
+	}
+	if last := s.prometheus.Load(); last != nil && last.URL != "" {
+		return last.URL, true
+	}
+	return "", false
+}
+
+// checkMetrics looks for the cAdvisor container metrics KRR bases its recommendations on
+func (s *MCPServer) checkMetrics(ctx context.Context, client *http.Client, target, prometheusStatus, namespace string) DoctorCheck {
+	check := DoctorCheck{Name: "metrics"}
+	if prometheusStatus != checkPass {
+		check.Status, check.Detail = checkSkip, "needs a reachable Prometheus"
+		return check
+	}
+
+	selector := ""
+	if namespace != "" {
+		selector = fmt.Sprintf(`{namespace=%q}`, namespace)
+	}
+	ctx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+	defer cancel()
+
+	var missing, found []string
+	for _, metric := range []string{"container_cpu_usage_seconds_total", "container_memory_working_set_bytes"} {
+		series, err := s.queryPrometheus(ctx, client, target, fmt.Sprintf("count(%s%s)", metric, selector))
+		if err != nil {
+			check.Status, check.Detail = checkFail, err.Error()
+			return check
+		}
+		if series == 0 {
+			missing = append(missing, metric)
+		} else {
+			found = append(found, fmt.Sprintf("%s (%.0f series)", metric, series))
+		}
+	}
+	if len(missing) > 0 {
+		check.Status, check.Detail = checkFail, "no series for "+strings.Join(missing, ", ")
+		check.Hint = "KRR needs cAdvisor container metrics; make sure Prometheus scrapes the kubelets' /metrics/cadvisor endpoint"
+		if namespace != "" {
+			check.Hint += fmt.Sprintf(", and that namespace %q runs pods", namespace)
+		}
+		return check
+	}
+	check.Status, check.Detail = checkPass, strings.Join(found, ", ")
+	return check
+}
+
+// prometheusClient creates an HTTP client trusting prometheus_ca_cert_file when it is set,
+// like KRR does
+func (s *MCPServer) prometheusClient() (*http.Client, error) {
+	if s.config().PrometheusCACertFile == "" {
+		return &http.Client{}, nil
+	}
+	data, err := os.ReadFile(s.config().PrometheusCACertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prometheus_ca_cert_file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("prometheus_ca_cert_file %s contains no PEM certificates", s.config().PrometheusCACertFile)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &http.Client{Transport: transport}, nil
+}
+
+// prometheusResponse is the subset of a Prometheus instant query response used here
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  [2]any            `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// queryPrometheus runs an instant query and returns the value of its first sample, or 0 when
+// the result is empty
+func (s *MCPServer) queryPrometheus(ctx context.Context, client *http.Client, target, query string) (float64, error) {
+	samples, err := s.queryPrometheusVector(ctx, client, target, query)
+	if err != nil || len(samples) == 0 {
+		return 0, err
+	}
+	return samples[0].value, nil
+}
+
+// prometheusSample is one sample of an instant vector: its labels and value
+type prometheusSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// queryPrometheusVector runs an instant query and returns every sample of its result
+func (s *MCPServer) queryPrometheusVector(ctx context.Context, client *http.Client, target, query string) ([]prometheusSample, error) {
+	endpoint := strings.TrimRight(target, "/") + "/api/v1/query?query=" + url.QueryEscape(query)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Prometheus URL: %w", err)
+	}
+	if s.config().PrometheusUserAgent != "" {
+		req.Header.Set("User-Agent", s.config().PrometheusUserAgent)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body prometheusResponse
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPrometheusResponse))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Prometheus response: %w", err)
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("Prometheus returned status %d and no query result", resp.StatusCode)
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("Prometheus returned status %d: %s", resp.StatusCode, body.Error)
+	}
+	samples := make([]prometheusSample, 0, len(body.Data.Result))
+	for _, result := range body.Data.Result {
+		value, _ := result.Value[1].(string)
+		var sample float64
+		if _, err := fmt.Sscan(value, &sample); err != nil {
+			return nil, fmt.Errorf("unexpected Prometheus sample %q", value)
+		}
+		samples = append(samples, prometheusSample{labels: result.Metric, value: sample})
+	}
+	return samples, nil
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRExplainArguments defines the arguments for the krr_explain tool
+type KRRExplainArguments struct {
+	Namespace string  `json:"namespace" jsonschema:"Namespace of the workload to explain"`
+	Name      string  `json:"name" jsonschema:"Name of the workload to explain (e.g. the Deployment name)"`
+	Kind      *string `json:"kind,omitempty" jsonschema:"Workload kind (e.g. 'Deployment' 'StatefulSet'); optional, matches any kind if not specified"`
+	Container *string `json:"container,omitempty" jsonschema:"Container to explain (optional, explains every container if not specified)"`
+	Context   *string `json:"context,omitempty" jsonschema:"Kubernetes context to use (optional, uses current context if not specified)"`
+	Strategy  *string `json:"strategy,omitempty" jsonschema:"Recommendation strategy to use (e.g. 'simple' 'simple-limit')"`
+}
+
+// KRRExplainOutput defines the output structure for the krr_explain tool
+type KRRExplainOutput struct {
+	Explanation string `json:"explanation"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_explain",
+		"Explain why KRR recommends a resource change for a single workload: current vs recommended, strategy, history window and what observed usage implies",
+		(*MCPServer).handleExplain,
+	))
+}
+
+// handleExplain runs a targeted scan for one workload and explains its recommendations
+func (s *MCPServer) handleExplain(ctx context.Context, req *mcp.CallToolRequest, arguments KRRExplainArguments) (*mcp.CallToolResult, KRRExplainOutput, error) {
+	namespace := strings.TrimSpace(arguments.Namespace)
+	name := strings.TrimSpace(arguments.Name)
+	var problems validationErrors
+	if namespace == "" {
+		problems.add("namespace", nil, "required to explain a recommendation")
+	}
+	if name == "" {
+		problems.add("name", nil, "required to explain a recommendation")
+	}
+	if arguments.Strategy != nil {
+		if err := krr.ValidateStrategy(*arguments.Strategy, false); err != nil {
+			problems.add("strategy", *arguments.Strategy, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return problems.result(), KRRExplainOutput{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.scanTimeout(req, nil))
+	defer cancel()
+
+	options := krr.ScanOptions{
+		Namespace: namespace,
+		Output:    krr.OutputJSON,
+		Strategy:  s.config().DefaultStrategy,
+		NoColor:   true,
+	}
+	if arguments.Strategy != nil {
+		options.Strategy = *arguments.Strategy
+	}
+	if arguments.Context != nil {
+		options.Context = *arguments.Context
+	}
+
+	var kind, container string
+	if arguments.Kind != nil {
+		kind = strings.TrimSpace(*arguments.Kind)
+		if kind != "" {
+			options.Resources = []string{kind}
+		}
+	}
+	if arguments.Container != nil {
+		container = strings.TrimSpace(*arguments.Container)
+	}
+
+	scope, err := s.scopeFor(req, &options)
+	if err != nil {
+		return errorResult(err.Error()), KRRExplainOutput{}, nil
+	}
+	if options.Namespace != namespace {
+		return errorResult(fmt.Sprintf("Namespace %s is outside this tenant's scope", namespace)), KRRExplainOutput{}, nil
+	}
+
+	release, limited := s.acquireClientScan(req)
+	if limited != nil {
+		return limited, KRRExplainOutput{}, nil
+	}
+	defer release()
+
+	// Tracked like other scans, so krr_cancel and shutdown draining cover it
+	ctx, id, untrack := s.running.track(ctx, requestID(req), "krr_explain", scope, options)
+	defer untrack()
+
+	result, err := s.runScan(ctx, scope.executor, options)
+	if err != nil {
+		return scanErrorResult(err, false, id), KRRExplainOutput{}, nil
+	}
+
+	matches := krr.FindResources(result.Resources, kind, name, namespace, container)
+	if len(matches) == 0 {
+		return errorResult(fmt.Sprintf("No recommendation found for workload %s/%s; check the name, kind and namespace", namespace, name)), KRRExplainOutput{}, nil
+	}
+
+	explanations := make([]string, 0, len(matches))
+	for _, resource := range matches {
+		explanations = append(explanations, krr.Explain(resource, result.Strategy))
+	}
+
+	return nil, KRRExplainOutput{Explanation: strings.Join(explanations, "\n---\n\n")}, nil
+}
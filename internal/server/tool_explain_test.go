@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestExplainRunsThroughRunScan(t *testing.T) {
+	cfg := policyConfig(t)
+	cfg.DefaultKRRWorkers = 3
+	cfg.PrometheusUserAgent = "greenops-test"
+	s, fake := newTestServer(t, cfg)
+	fake.result = &krr.ScanResult{Resources: []krr.Resource{
+		{Kind: "Deployment", Namespace: "shop", Name: "web", Container: "app"},
+	}}
+
+	result, out, _ := s.handleExplain(context.Background(), nil, KRRExplainArguments{Namespace: "shop", Name: "web"})
+	if result != nil {
+		t.Fatalf("handleExplain() = %+v", result)
+	}
+	if !strings.Contains(out.Explanation, "Deployment shop/web") {
+		t.Errorf("explanation = %q, want it to cover shop/web", out.Explanation)
+	}
+
+	options := fake.options()
+	if len(options) != 1 {
+		t.Fatalf("KRR ran %d times, want 1", len(options))
+	}
+	if got := options[0].HistoryDuration; got != 7*24*time.Hour {
+		t.Errorf("KRR ran with history %s, want it capped at 7 days", got)
+	}
+	if options[0].MaxWorkers != 3 || options[0].PrometheusUserAgent != "greenops-test" {
+		t.Errorf("KRR ran with workers %d and user agent %q, want the configured defaults", options[0].MaxWorkers, options[0].PrometheusUserAgent)
+	}
+	if recent := s.recent.list(""); len(recent) != 1 {
+		t.Errorf("recent scans = %d, want the explain scan recorded", len(recent))
+	}
+	if metrics := string(s.metrics.format(0, 0)); !strings.Contains(metrics, `greenops_mcp_scan_duration_seconds_count{tool="krr_explain"} 1`) {
+		t.Errorf("metrics lack the explain scan:\n%s", metrics)
+	}
+}
+
+func TestExplainScanFailure(t *testing.T) {
+	s, fake := newTestServer(t, config.DefaultConfig())
+	fake.err = &krr.ScanError{Kind: krr.ErrorKindNotInstalled, ExitCode: -1}
+
+	result, _, _ := s.handleExplain(context.Background(), nil, KRRExplainArguments{Namespace: "shop", Name: "web"})
+	if result == nil || !result.IsError {
+		t.Fatalf("handleExplain() = %+v, want an error result", result)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "pip install krr") {
+		t.Errorf("result = %q, want the install hint of scanErrorResult", text)
+	}
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/artifact"
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRExportResourcesArguments defines the arguments for the krr_export_resources tool
+type KRRExportResourcesArguments struct {
+	Namespace       *string `json:"namespace,omitempty" jsonschema:"Kubernetes namespace to export (optional, defaults to the server's default namespace or all namespaces)"`
+	Context         *string `json:"context,omitempty" jsonschema:"Kubernetes context to use (optional, uses current context if not specified)"`
+	Strategy        *string `json:"strategy,omitempty" jsonschema:"Recommendation strategy to use (e.g. 'simple' 'simple-limit')"`
+	HistoryDuration *string `json:"history_duration,omitempty" jsonschema:"How much Prometheus history KRR analyses, e.g. '36h' or '7d' (optional, capped by the server's max_history_duration)"`
+	SaveToPath      *string `json:"save_to_path,omitempty" jsonschema:"Directory for the export, relative to the server's artifact_dir (optional, defaults to artifact_dir itself)"`
+	TimeoutSeconds  *int    `json:"timeout_seconds,omitempty" jsonschema:"Scan timeout in seconds (optional, capped by the server's max_timeout)"`
+}
+
+// KRRExportResourcesOutput defines the output structure for the krr_export_resources tool
+type KRRExportResourcesOutput struct {
+	// ResourcesFile is relative to artifact_dir and can be passed to krr_scan's resources_file as is
+	ResourcesFile string `json:"resources_file"`
+	Path          string `json:"path"`
+	Resources     int    `json:"resources"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_export_resources",
+		"Scan the cluster and save KRR's JSON report under the server's artifact_dir, for later offline analysis with krr_scan's resources_file",
+		(*MCPServer).handleExportResources,
+	))
+}
+
+// handleExportResources runs a JSON scan and writes KRR's raw report to the artifact directory
+func (s *MCPServer) handleExportResources(ctx context.Context, req *mcp.CallToolRequest, arguments KRRExportResourcesArguments) (*mcp.CallToolResult, KRRExportResourcesOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.scanTimeout(req, arguments.TimeoutSeconds))
+	defer cancel()
+
+	options := krr.ScanOptions{
+		Namespace: s.config().DefaultNamespace,
+		Strategy:  s.config().DefaultStrategy,
+		Output:    krr.OutputJSON,
+		NoColor:   true,
+	}
+	var problems validationErrors
+
+	if arguments.Namespace != nil {
+		options.Namespace = *arguments.Namespace
+	}
+	if arguments.Context != nil {
+		options.Context = *arguments.Context
+	}
+	if arguments.Strategy != nil {
+		options.Strategy = *arguments.Strategy
+		if err := krr.ValidateStrategy(options.Strategy, false); err != nil {
+			problems.add("strategy", options.Strategy, err.Error())
+		}
+	}
+	if arguments.HistoryDuration != nil {
+		duration, err := krr.ParseHistoryDuration(*arguments.HistoryDuration)
+		if err != nil {
+			problems.add("history_duration", *arguments.HistoryDuration, err.Error())
+		}
+		options.HistoryDuration = duration
+	}
+	if arguments.TimeoutSeconds != nil && *arguments.TimeoutSeconds < 0 {
+		problems.add("timeout_seconds", *arguments.TimeoutSeconds, "cannot be negative")
+	}
+
+	relativeDir := "."
+	if arguments.SaveToPath != nil && strings.TrimSpace(*arguments.SaveToPath) != "" {
+		relativeDir = strings.TrimSpace(*arguments.SaveToPath)
+	}
+	var dir string
+	if s.config().ArtifactDir == "" {
+		problems.add("save_to_path", arguments.SaveToPath, "requires the server to be configured with an artifact_dir")
+	} else if resolved, err := artifact.ResolvePath(s.config().ArtifactDir, relativeDir); err != nil {
+		problems.add("save_to_path", relativeDir, err.Error())
+	} else {
+		dir = resolved
+	}
+
+	scope, err := s.scopeFor(req, &options)
+	if err != nil {
+		return errorResult(err.Error()), KRRExportResourcesOutput{}, nil
+	}
+
+	if len(problems) > 0 {
+		return problems.result(), KRRExportResourcesOutput{}, nil
+	}
+
+	release, limited := s.acquireClientScan(req)
+	if limited != nil {
+		return limited, KRRExportResourcesOutput{}, nil
+	}
+	defer release()
+
+	ctx, id, untrack := s.running.track(ctx, requestID(req), "krr_export_resources", scope, options)
+	defer untrack()
+
+	result, err := s.runScan(ctx, scope.executor, options)
+	if err != nil {
+		return scanErrorResult(err, true, id), KRRExportResourcesOutput{}, nil
+	}
+
+	// KRR's own JSON is exported, not the parsed result, so the file keeps everything KRR reported
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errorResult(fmt.Sprintf("Failed to create export directory: %v", err)), KRRExportResourcesOutput{}, nil
+	}
+	name := artifact.FileStem("krr-export", time.Now()) + ".raw.json"
+	path := filepath.Join(dir, name)
+	if err := artifact.WriteFile(path, []byte(result.RawOutput)); err != nil {
+		return errorResult(fmt.Sprintf("Failed to write export: %v", err)), KRRExportResourcesOutput{}, nil
+	}
+
+	return nil, KRRExportResourcesOutput{
+		ResourcesFile: filepath.ToSlash(filepath.Join(relativeDir, name)),
+		Path:          path,
+		Resources:     len(result.Resources),
+	}, nil
+}
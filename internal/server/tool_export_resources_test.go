@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+)
+
+func TestExportResourcesWritesOwnerOnlyFile(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ArtifactDir = t.TempDir()
+	s, fake := newTestServer(t, cfg)
+	fake.result = &krr.ScanResult{RawOutput: `{"scans": []}`}
+	namespace, dir := "shop", "exports"
+
+	var files []string
+	for range 2 {
+		result, out, _ := s.handleExportResources(context.Background(), nil, KRRExportResourcesArguments{Namespace: &namespace, SaveToPath: &dir})
+		if result != nil {
+			t.Fatalf("handleExportResources() = %+v", result)
+		}
+		info, err := os.Stat(out.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("export mode = %o, want 600", mode)
+		}
+		if _, err := s.resolveResourcesFile(out.ResourcesFile); err != nil {
+			t.Errorf("resources_file %q does not resolve: %v", out.ResourcesFile, err)
+		}
+		files = append(files, out.Path)
+	}
+	if files[0] == files[1] {
+		t.Errorf("two exports were written to %s", files[0])
+	}
+}
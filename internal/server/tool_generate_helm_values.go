@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/manifest"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRGenerateHelmValuesArguments defines the arguments for the krr_generate_helm_values tool
+type KRRGenerateHelmValuesArguments struct {
+	ScanID        *string            `json:"scan_id,omitempty" jsonschema:"Request ID of the scan whose recommendations to use, or 'latest' (default 'latest')"`
+	Workloads     []WorkloadSelector `json:"workloads,omitempty" jsonschema:"Workloads to generate values for; each selector matches by namespace, kind, name and container, and an empty field matches anything (optional, every workload of the scan if not specified)"`
+	IncludeLimits *bool              `json:"include_limits,omitempty" jsonschema:"Also set the recommended limits, not only the requests (default false)"`
+	ValuePaths    map[string]string  `json:"value_paths,omitempty" jsonschema:"Where each chart takes container resources in its values, as dot-separated paths by chart name ('*' for any other chart), e.g. {'my-app': '{component}.resources'}; paths may use {container}, {workload}, {component} and {release}. Overrides the server's helm_value_paths (default 'resources')"`
+}
+
+// HelmValues is the values override of one Helm release
+type HelmValues struct {
+	Release   string `json:"release"`
+	Namespace string `json:"namespace"`
+	Chart     string `json:"chart,omitempty"`
+
+	// Values is the values.yaml snippet to pass with helm upgrade --reuse-values -f
+	Values    string               `json:"values"`
+	Workloads []string             `json:"workloads"`
+	Changes   []krr.ContainerDelta `json:"changes"`
+}
+
+// KRRGenerateHelmValuesOutput defines the output structure for the krr_generate_helm_values tool
+type KRRGenerateHelmValuesOutput struct {
+	ScanID   string            `json:"scan_id"`
+	Releases []HelmValues      `json:"releases"`
+	Skipped  []SkippedWorkload `json:"skipped"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_generate_helm_values",
+		"Map the recommendations of a scan onto Helm values overrides, so Helm-managed workloads keep them on their next release instead of reverting a patch. Reads each workload from the cluster to find its release and chart, and returns a values.yaml snippet per release; where a chart takes container resources is configurable per chart with value_paths",
+		(*MCPServer).handleGenerateHelmValues,
+	))
+}
+
+// helmRelease collects the values of one release while workloads are mapped onto it
+type helmRelease struct {
+	output HelmValues
+	values map[string]any
+
+	// paths are the value paths taken so far, with the container that took each
+	paths map[string]string
+}
+
+// handleGenerateHelmValues groups the patches of the selected workloads by Helm release and
+// writes their resources at the chart's value path
+func (s *MCPServer) handleGenerateHelmValues(ctx context.Context, req *mcp.CallToolRequest, arguments KRRGenerateHelmValuesArguments) (*mcp.CallToolResult, KRRGenerateHelmValuesOutput, error) {
+	failed := KRRGenerateHelmValuesOutput{Releases: []HelmValues{}, Skipped: []SkippedWorkload{}}
+
+	paths := make(map[string]string, len(s.config().HelmValuePaths)+len(arguments.ValuePaths))
+	for chart, path := range s.config().HelmValuePaths {
+		paths[chart] = path
+	}
+	var problems validationErrors
+	for chart, path := range arguments.ValuePaths {
+		if err := manifest.ValidateValuePath(path); err != nil {
+			problems.add("value_paths."+chart, path, err.Error())
+		}
+		paths[chart] = path
+	}
+	if len(problems) > 0 {
+		return problems.result(), failed, nil
+	}
+
+	includeLimits := arguments.IncludeLimits != nil && *arguments.IncludeLimits
+	plan, failure := s.planWorkloads(req, arguments.ScanID, arguments.Workloads, includeLimits)
+	if failure != nil {
+		return failure, failed, nil
+	}
+	output := KRRGenerateHelmValuesOutput{ScanID: plan.scan.ID, Releases: []HelmValues{}, Skipped: plan.skipped}
+	if len(plan.patches) == 0 {
+		return errorResult(fmt.Sprintf("The selected containers of scan %s already run with the recommended values; there is nothing to override", plan.scan.ID)), output, nil
+	}
+
+	releases := map[string]*helmRelease{}
+	for _, patch := range plan.patches {
+		workload := patch.Namespace + "/" + patch.Kind + "/" + patch.Name
+		if err := s.mapHelmValues(ctx, plan, patch, paths, releases); err != nil {
+			output.Skipped = append(output.Skipped, SkippedWorkload{Workload: workload, Reason: err.Error()})
+		}
+	}
+	if len(releases) == 0 {
+		return errorResult(fmt.Sprintf("No Helm values could be generated: %s", output.Skipped[len(output.Skipped)-1].Reason)), output, nil
+	}
+
+	for _, release := range releases {
+		content, err := manifest.MarshalYAML(release.values)
+		if err != nil {
+			return errorResult(err.Error()), failed, nil
+		}
+		release.output.Values = string(content)
+		output.Releases = append(output.Releases, release.output)
+	}
+	sort.Slice(output.Releases, func(i, j int) bool {
+		a, b := output.Releases[i], output.Releases[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Release < b.Release
+	})
+	return nil, output, nil
+}
+
+// mapHelmValues adds the resources of one workload to the values of its release. A workload
+// whose containers would take a value path another container already took is left out whole.
+func (s *MCPServer) mapHelmValues(ctx context.Context, plan *workloadPlan, patch krr.WorkloadPatch, paths map[string]string, releases map[string]*helmRelease) error {
+	object, err := s.fetchWorkload(ctx, plan, patch)
+	if err != nil {
+		return err
+	}
+	owner, ok := manifest.ReleaseOf(object)
+	if !ok {
+		return fmt.Errorf("not managed by Helm (no meta.helm.sh/release-name annotation)")
+	}
+
+	template, ok := paths[owner.Chart]
+	if !ok || owner.Chart == "" {
+		if template, ok = paths["*"]; !ok {
+			template = manifest.DefaultValuePath
+		}
+	}
+	key := owner.Namespace + "/" + owner.Name
+	release := releases[key]
+	if release == nil {
+		release = &helmRelease{
+			output: HelmValues{Release: owner.Name, Namespace: owner.Namespace, Chart: owner.Chart, Workloads: []string{}, Changes: []krr.ContainerDelta{}},
+			values: map[string]any{},
+			paths:  map[string]string{},
+		}
+	}
+
+	workload := patch.Kind + "/" + patch.Name
+	locations := make([][]string, len(patch.Containers))
+	for i, container := range patch.Containers {
+		keys, err := manifest.ExpandValuePath(template, map[string]string{
+			"container": container.Name,
+			"workload":  patch.Name,
+			"component": owner.Component,
+			"release":   owner.Name,
+		})
+		if err != nil {
+			return err
+		}
+		path := strings.Join(keys, ".")
+		for taken, by := range release.paths {
+			if path == taken || strings.HasPrefix(path, taken+".") || strings.HasPrefix(taken, path+".") {
+				return fmt.Errorf("%s/%s and %s both map to %q in the values of release %s; set a value path for chart %q, e.g. \"{component}.resources\" or \"{container}.resources\"", workload, container.Name, by, path, owner.Name, owner.Chart)
+			}
+		}
+		for _, other := range locations[:i] {
+			if path == strings.Join(other, ".") {
+				return fmt.Errorf("the containers of %s all map to %q in the values of release %s; set a value path using {container} for chart %q", workload, path, owner.Name, owner.Chart)
+			}
+		}
+		locations[i] = keys
+	}
+
+	for i, container := range patch.Containers {
+		resources := map[string]any{}
+		if len(container.Requests) > 0 {
+			resources["requests"] = container.Requests
+		}
+		if len(container.Limits) > 0 {
+			resources["limits"] = container.Limits
+		}
+		setValue(release.values, locations[i], resources)
+		release.paths[strings.Join(locations[i], ".")] = workload + "/" + container.Name
+	}
+	release.output.Workloads = append(release.output.Workloads, workload)
+	release.output.Changes = append(release.output.Changes, patch.Changes...)
+	releases[key] = release
+	return nil
+}
+
+// setValue sets a nested value, creating the maps along its path
+func setValue(values map[string]any, keys []string, value any) {
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := values[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			values[key] = next
+		}
+		values = next
+	}
+	values[keys[len(keys)-1]] = value
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/manifest"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRGenerateKustomizePatchArguments defines the arguments for the krr_generate_kustomize_patch tool
+type KRRGenerateKustomizePatchArguments struct {
+	ScanID        *string            `json:"scan_id,omitempty" jsonschema:"Request ID of the scan whose recommendations to use, or 'latest' (default 'latest')"`
+	Workloads     []WorkloadSelector `json:"workloads,omitempty" jsonschema:"Workloads to generate patches for; each selector matches by namespace, kind, name and container, and an empty field matches anything (optional, every workload of the scan if not specified)"`
+	IncludeLimits *bool              `json:"include_limits,omitempty" jsonschema:"Also set the recommended limits, not only the requests (default false)"`
+	Format        *string            `json:"format,omitempty" jsonschema:"'strategic_merge' (default; needs no cluster access) or 'json_patch' (JSON 6902 operations, which address containers by position and so read each workload from the cluster)"`
+}
+
+// KustomizeFile is one generated file, with its path relative to the overlay
+type KustomizeFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// KRRGenerateKustomizePatchOutput defines the output structure for the krr_generate_kustomize_patch tool
+type KRRGenerateKustomizePatchOutput struct {
+	ScanID string `json:"scan_id"`
+	Format string `json:"format"`
+
+	// Files hold one directory per namespace, with a patch file per workload and a
+	// kustomization.yaml component listing them
+	Files   []KustomizeFile   `json:"files"`
+	Skipped []SkippedWorkload `json:"skipped"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_generate_kustomize_patch",
+		"Generate Kustomize patch files that set the recommended container requests (and optionally limits) of a scan's workloads, as strategic merge or JSON 6902 patches. Files are organized in one directory per namespace, each with a kustomization.yaml Kustomize component that overlays include with components:",
+		(*MCPServer).handleGenerateKustomizePatch,
+	))
+}
+
+// handleGenerateKustomizePatch renders a patch file per workload and a component per namespace
+func (s *MCPServer) handleGenerateKustomizePatch(ctx context.Context, req *mcp.CallToolRequest, arguments KRRGenerateKustomizePatchArguments) (*mcp.CallToolResult, KRRGenerateKustomizePatchOutput, error) {
+	failed := KRRGenerateKustomizePatchOutput{Files: []KustomizeFile{}, Skipped: []SkippedWorkload{}}
+
+	format := manifestFormatStrategic
+	if arguments.Format != nil {
+		format = strings.TrimSpace(*arguments.Format)
+	}
+	if format != manifestFormatStrategic && format != manifestFormatJSONPatch {
+		var problems validationErrors
+		problems.add("format", format, "must be 'strategic_merge' or 'json_patch'")
+		return problems.result(), failed, nil
+	}
+
+	includeLimits := arguments.IncludeLimits != nil && *arguments.IncludeLimits
+	plan, failure := s.planWorkloads(req, arguments.ScanID, arguments.Workloads, includeLimits)
+	if failure != nil {
+		return failure, failed, nil
+	}
+	output := KRRGenerateKustomizePatchOutput{ScanID: plan.scan.ID, Format: format, Files: []KustomizeFile{}, Skipped: plan.skipped}
+	if len(plan.patches) == 0 {
+		return errorResult(fmt.Sprintf("The selected containers of scan %s already run with the recommended values; there is nothing to patch", plan.scan.ID)), output, nil
+	}
+
+	components := map[string][]manifest.KustomizePatch{}
+	for _, patch := range plan.patches {
+		name := strings.ToLower(patch.Kind) + "-" + patch.Name + ".yaml"
+		entry, content, err := s.kustomizePatch(ctx, plan, patch, format, name)
+		if err != nil {
+			output.Skipped = append(output.Skipped, SkippedWorkload{Workload: patch.Namespace + "/" + patch.Kind + "/" + patch.Name, Reason: err.Error()})
+			continue
+		}
+		output.Files = append(output.Files, KustomizeFile{Path: patch.Namespace + "/" + name, Content: content})
+		components[patch.Namespace] = append(components[patch.Namespace], entry)
+	}
+	if len(components) == 0 {
+		return errorResult(fmt.Sprintf("No patch could be generated: %s", output.Skipped[len(output.Skipped)-1].Reason)), output, nil
+	}
+
+	namespaces := make([]string, 0, len(components))
+	for namespace := range components {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		content, err := manifest.MarshalYAML(manifest.NewComponent(components[namespace]))
+		if err != nil {
+			return errorResult(err.Error()), failed, nil
+		}
+		output.Files = append(output.Files, KustomizeFile{Path: namespace + "/kustomization.yaml", Content: string(content)})
+	}
+	// Keep each kustomization.yaml next to its patches
+	sort.SliceStable(output.Files, func(i, j int) bool {
+		return pathDir(output.Files[i].Path) < pathDir(output.Files[j].Path)
+	})
+	return nil, output, nil
+}
+
+// kustomizePatch renders the patch file of one workload, and its entry in the component
+func (s *MCPServer) kustomizePatch(ctx context.Context, plan *workloadPlan, patch krr.WorkloadPatch, format, name string) (manifest.KustomizePatch, string, error) {
+	apiVersion, ok := manifest.WorkloadAPIVersion(patch.Kind)
+	if !ok {
+		return manifest.KustomizePatch{}, "", fmt.Errorf("unknown API version of %s workloads", patch.Kind)
+	}
+	if format == manifestFormatStrategic {
+		content, err := manifest.MarshalYAML(manifest.StrategicMergeFile(patch, apiVersion))
+		return manifest.KustomizePatch{Path: name}, string(content), err
+	}
+
+	object, err := s.fetchWorkload(ctx, plan, patch)
+	if err != nil {
+		return manifest.KustomizePatch{}, "", err
+	}
+	operations, err := manifest.JSONPatch(object, patch)
+	if err != nil {
+		return manifest.KustomizePatch{}, "", err
+	}
+	content, err := manifest.MarshalYAML(operations)
+	entry := manifest.KustomizePatch{Path: name, Target: manifest.NewTarget(apiVersion, patch.Kind, patch.Name)}
+	return entry, string(content), err
+}
+
+// pathDir returns the directory of a slash-separated path
+func pathDir(path string) string {
+	dir, _, _ := strings.Cut(path, "/")
+	return dir
+}
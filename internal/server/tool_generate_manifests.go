@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/manifest"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Formats of krr_generate_manifests
+const (
+	manifestFormatManifest  = "manifest"        // the live object with the recommendations, as YAML
+	manifestFormatJSONPatch = "json_patch"      // RFC 6902 operations, as JSON
+	manifestFormatStrategic = "strategic_merge" // a strategic merge patch, as YAML
+)
+
+// KRRGenerateManifestsArguments defines the arguments for the krr_generate_manifests tool
+type KRRGenerateManifestsArguments struct {
+	ScanID        *string            `json:"scan_id,omitempty" jsonschema:"Request ID of the scan whose recommendations to use, or 'latest' (default 'latest')"`
+	Workloads     []WorkloadSelector `json:"workloads,omitempty" jsonschema:"Workloads to generate changes for; each selector matches by namespace, kind, name and container, and an empty field matches anything (optional, every workload of the scan if not specified)"`
+	IncludeLimits *bool              `json:"include_limits,omitempty" jsonschema:"Also set the recommended limits, not only the requests (default false)"`
+	Format        *string            `json:"format,omitempty" jsonschema:"'manifest' (the full live manifest with the new resources, as YAML; default), 'json_patch' (RFC 6902 operations, as JSON) or 'strategic_merge' (a strategic merge patch, as YAML; needs no cluster access)"`
+}
+
+// GeneratedManifest is the generated change of one workload
+type GeneratedManifest struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+
+	// Content is the manifest or patch, in the requested format
+	Content string               `json:"content"`
+	Changes []krr.ContainerDelta `json:"changes"`
+}
+
+// KRRGenerateManifestsOutput defines the output structure for the krr_generate_manifests tool
+type KRRGenerateManifestsOutput struct {
+	ScanID    string              `json:"scan_id"`
+	Format    string              `json:"format"`
+	Manifests []GeneratedManifest `json:"manifests"`
+	Skipped   []SkippedWorkload   `json:"skipped"`
+
+	// Document joins every YAML manifest or patch into one multi-document stream
+	Document string `json:"document,omitempty"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_generate_manifests",
+		"Generate the changes a scan recommends as reviewable files instead of applying them: updated workload manifests read from the cluster, RFC 6902 JSON patches, or strategic merge patches, with the recommended container requests (and optionally limits); the server never writes to the cluster",
+		(*MCPServer).handleGenerateManifests,
+	))
+}
+
+// handleGenerateManifests renders the patches of the selected workloads. The manifest and JSON
+// patch formats read each workload from the cluster, since they need its current definition.
+func (s *MCPServer) handleGenerateManifests(ctx context.Context, req *mcp.CallToolRequest, arguments KRRGenerateManifestsArguments) (*mcp.CallToolResult, KRRGenerateManifestsOutput, error) {
+	failed := KRRGenerateManifestsOutput{Manifests: []GeneratedManifest{}, Skipped: []SkippedWorkload{}}
+
+	format := manifestFormatManifest
+	if arguments.Format != nil {
+		format = strings.TrimSpace(*arguments.Format)
+	}
+	if format != manifestFormatManifest && format != manifestFormatJSONPatch && format != manifestFormatStrategic {
+		var problems validationErrors
+		problems.add("format", format, "must be 'manifest', 'json_patch' or 'strategic_merge'")
+		return problems.result(), failed, nil
+	}
+
+	includeLimits := arguments.IncludeLimits != nil && *arguments.IncludeLimits
+	plan, failure := s.planWorkloads(req, arguments.ScanID, arguments.Workloads, includeLimits)
+	if failure != nil {
+		return failure, failed, nil
+	}
+	output := KRRGenerateManifestsOutput{ScanID: plan.scan.ID, Format: format, Manifests: []GeneratedManifest{}, Skipped: plan.skipped}
+	if len(plan.patches) == 0 {
+		return errorResult(fmt.Sprintf("The selected containers of scan %s already run with the recommended values; there is nothing to change", plan.scan.ID)), output, nil
+	}
+
+	var documents []string
+	for _, patch := range plan.patches {
+		content, err := s.renderWorkloadChange(ctx, plan, patch, format)
+		if err != nil {
+			output.Skipped = append(output.Skipped, SkippedWorkload{Workload: patch.Namespace + "/" + patch.Kind + "/" + patch.Name, Reason: err.Error()})
+			continue
+		}
+		output.Manifests = append(output.Manifests, GeneratedManifest{
+			Namespace: patch.Namespace,
+			Kind:      patch.Kind,
+			Name:      patch.Name,
+			Content:   content,
+			Changes:   patch.Changes,
+		})
+		if format != manifestFormatJSONPatch {
+			documents = append(documents, fmt.Sprintf("# %s %s/%s\n%s", patch.Kind, patch.Namespace, patch.Name, content))
+		}
+	}
+	if len(output.Manifests) == 0 {
+		return errorResult(fmt.Sprintf("No manifest could be generated: %s", output.Skipped[len(output.Skipped)-1].Reason)), output, nil
+	}
+	if len(documents) > 0 {
+		output.Document = strings.Join(documents, "---\n")
+	}
+	return nil, output, nil
+}
+
+// renderWorkloadChange renders the change of one workload in the given format
+func (s *MCPServer) renderWorkloadChange(ctx context.Context, plan *workloadPlan, patch krr.WorkloadPatch, format string) (string, error) {
+	if format == manifestFormatStrategic {
+		content, err := manifest.MarshalYAML(patch.StrategicMergePatch())
+		return string(content), err
+	}
+
+	object, err := s.fetchWorkload(ctx, plan, patch)
+	if err != nil {
+		return "", err
+	}
+
+	if format == manifestFormatJSONPatch {
+		operations, err := manifest.JSONPatch(object, patch)
+		if err != nil {
+			return "", err
+		}
+		content, err := json.MarshalIndent(operations, "", "  ")
+		return string(content), err
+	}
+
+	manifest.Clean(object)
+	if err := manifest.SetResources(object, patch); err != nil {
+		return "", err
+	}
+	content, err := manifest.MarshalYAML(object)
+	return string(content), err
+}
+
+// fetchWorkload reads the live object of a patched workload from the cluster of the plan
+func (s *MCPServer) fetchWorkload(ctx context.Context, plan *workloadPlan, patch krr.WorkloadPatch) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(ctx, kubeLookupTimeout)
+	defer cancel()
+	data, err := plan.scope.kube.Get(ctx, plan.context, patch.Namespace, patch.Kind, patch.Name)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("failed to parse %s %s/%s: %w", patch.Kind, patch.Namespace, patch.Name, err)
+	}
+	return object, nil
+}
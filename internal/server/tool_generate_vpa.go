@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/manifest"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultVPABoundMargin is how far, in percent, a generated VPA may move away from the
+// recommendation of the scan
+const defaultVPABoundMargin = 50
+
+// KRRGenerateVPAArguments defines the arguments for the krr_generate_vpa tool
+type KRRGenerateVPAArguments struct {
+	ScanID        *string            `json:"scan_id,omitempty" jsonschema:"Request ID of the scan whose recommendations to use, or 'latest' (default 'latest')"`
+	Workloads     []WorkloadSelector `json:"workloads,omitempty" jsonschema:"Workloads to generate a VerticalPodAutoscaler for; each selector matches by namespace, kind, name and container, and an empty field matches anything (optional, every workload of the scan if not specified)"`
+	UpdateMode    *string            `json:"update_mode,omitempty" jsonschema:"VPA update mode: 'Off' (recommend only), 'Initial' (set resources when pods are created) or 'Auto' (also evict pods to resize them) (default 'Off')"`
+	BoundMargin   *int               `json:"bound_margin,omitempty" jsonschema:"Percentage around the scan's recommendation that bounds the VPA with minAllowed and maxAllowed; 0 pins the VPA to the recommendation (default 50, maximum 1000)"`
+	IncludeLimits *bool              `json:"include_limits,omitempty" jsonschema:"Let the VPA scale limits in proportion to requests, not only the requests (default false)"`
+}
+
+// VPAManifest is the generated VerticalPodAutoscaler of one workload
+type VPAManifest struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+
+	// Containers are the containers the VPA controls; other containers are left alone
+	Containers []string `json:"containers"`
+	Content    string   `json:"content"`
+}
+
+// KRRGenerateVPAOutput defines the output structure for the krr_generate_vpa tool
+type KRRGenerateVPAOutput struct {
+	ScanID     string            `json:"scan_id"`
+	UpdateMode string            `json:"update_mode"`
+	Manifests  []VPAManifest     `json:"manifests"`
+	Skipped    []SkippedWorkload `json:"skipped"`
+
+	// Document joins every manifest into one multi-document YAML stream
+	Document string `json:"document,omitempty"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_generate_vpa",
+		"Generate VerticalPodAutoscaler manifests for the workloads of a scan, bounded around its recommendations, as a gradual alternative to patching requests directly: start with update_mode 'Off' to compare VPA's recommendations with KRR's, then move to 'Initial' or 'Auto'. Needs the VPA components in the cluster; the server does not create the objects",
+		(*MCPServer).handleGenerateVPA,
+	))
+}
+
+// handleGenerateVPA renders one VerticalPodAutoscaler per selected workload. Unlike the patch
+// tools it covers containers already at their recommendation, since the VPA keeps tracking them.
+func (s *MCPServer) handleGenerateVPA(ctx context.Context, req *mcp.CallToolRequest, arguments KRRGenerateVPAArguments) (*mcp.CallToolResult, KRRGenerateVPAOutput, error) {
+	failed := KRRGenerateVPAOutput{Manifests: []VPAManifest{}, Skipped: []SkippedWorkload{}}
+
+	var problems validationErrors
+	updateMode := manifest.UpdateModeOff
+	if arguments.UpdateMode != nil {
+		updateMode = strings.TrimSpace(*arguments.UpdateMode)
+		for _, mode := range []string{manifest.UpdateModeOff, manifest.UpdateModeInitial, manifest.UpdateModeAuto} {
+			if strings.EqualFold(updateMode, mode) {
+				updateMode = mode
+			}
+		}
+		if updateMode != manifest.UpdateModeOff && updateMode != manifest.UpdateModeInitial && updateMode != manifest.UpdateModeAuto {
+			problems.add("update_mode", updateMode, "must be 'Off', 'Initial' or 'Auto'")
+		}
+	}
+	margin := defaultVPABoundMargin
+	if arguments.BoundMargin != nil {
+		margin = *arguments.BoundMargin
+		if margin < 0 || margin > 1000 {
+			problems.add("bound_margin", margin, "must be between 0 and 1000")
+		}
+	}
+	if len(problems) > 0 {
+		return problems.result(), failed, nil
+	}
+
+	includeLimits := arguments.IncludeLimits != nil && *arguments.IncludeLimits
+	plan, failure := s.planWorkloads(req, arguments.ScanID, arguments.Workloads, includeLimits)
+	if failure != nil {
+		return failure, failed, nil
+	}
+	// Patch limitations do not apply to a VPA, only the tenant's scope does
+	output := KRRGenerateVPAOutput{ScanID: plan.scan.ID, UpdateMode: updateMode, Manifests: []VPAManifest{}, Skipped: plan.excluded}
+
+	controlledValues := "RequestsOnly"
+	if includeLimits {
+		controlledValues = "RequestsAndLimits"
+	}
+	var documents []string
+	for _, workload := range groupWorkloads(plan.resources) {
+		key := workload[0].Namespace + "/" + workload[0].Kind + "/" + workload[0].Name
+		apiVersion, ok := manifest.WorkloadAPIVersion(workload[0].Kind)
+		if !ok {
+			output.Skipped = append(output.Skipped, SkippedWorkload{Workload: key, Reason: fmt.Sprintf("a VerticalPodAutoscaler cannot target %s workloads", workload[0].Kind)})
+			continue
+		}
+
+		vpa := VPAManifest{Namespace: workload[0].Namespace, Kind: workload[0].Kind, Name: workload[0].Name, Containers: []string{}}
+		var policies []manifest.VPAContainerPolicy
+		for _, resource := range workload {
+			if resource.Container == "" {
+				continue
+			}
+			policy := manifest.VPAContainerPolicy{
+				ContainerName:       resource.Container,
+				ControlledResources: []string{"cpu", "memory"},
+				ControlledValues:    controlledValues,
+			}
+			policy.MinAllowed, policy.MaxAllowed = vpaBounds(resource.Recommended, margin)
+			policies = append(policies, policy)
+			vpa.Containers = append(vpa.Containers, resource.Container)
+		}
+		if len(policies) == 0 {
+			output.Skipped = append(output.Skipped, SkippedWorkload{Workload: key, Reason: "KRR did not report the container names"})
+			continue
+		}
+		// Containers of the workload that were not selected keep their own resources
+		if leavesContainers(plan.scan.Resources, workload) {
+			policies = append(policies, manifest.VPAContainerPolicy{ContainerName: "*", Mode: "Off"})
+		}
+
+		content, err := manifest.MarshalYAML(manifest.NewVPA(vpa.Namespace, vpa.Kind, vpa.Name, apiVersion, updateMode, policies))
+		if err != nil {
+			return errorResult(err.Error()), failed, nil
+		}
+		vpa.Content = string(content)
+		output.Manifests = append(output.Manifests, vpa)
+		documents = append(documents, fmt.Sprintf("# %s %s/%s\n%s", vpa.Kind, vpa.Namespace, vpa.Name, vpa.Content))
+	}
+	if len(output.Manifests) == 0 {
+		return errorResult(fmt.Sprintf("No VerticalPodAutoscaler could be generated from scan %s: %s", plan.scan.ID, output.Skipped[len(output.Skipped)-1].Reason)), output, nil
+	}
+	output.Document = strings.Join(documents, "---\n")
+	return nil, output, nil
+}
+
+// groupWorkloads groups containers by workload, in the order workloads first appear
+func groupWorkloads(resources []krr.Resource) [][]krr.Resource {
+	var groups [][]krr.Resource
+	index := map[string]int{}
+	for _, resource := range resources {
+		key := resource.Namespace + "/" + resource.Kind + "/" + resource.Name
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], resource)
+	}
+	return groups
+}
+
+// leavesContainers reports whether the scan has containers of the workload that are not among
+// the selected ones
+func leavesContainers(resources, selected []krr.Resource) bool {
+	var names []string
+	for _, resource := range selected {
+		names = append(names, resource.Container)
+	}
+	for _, resource := range resources {
+		if resource.Namespace == selected[0].Namespace && resource.Kind == selected[0].Kind && resource.Name == selected[0].Name && !slices.Contains(names, resource.Container) {
+			return true
+		}
+	}
+	return false
+}
+
+// vpaBounds returns the minAllowed and maxAllowed of a container, margin percent below and above
+// its recommendation. Resources without a usable recommendation are left unbounded.
+func vpaBounds(recommended krr.ResourceRequirements, margin int) (minAllowed, maxAllowed map[string]string) {
+	factor := float64(margin) / 100
+	bound := func(resource, quantity string, parse func(string) (float64, error), format func(float64) string) {
+		value, err := parse(quantity)
+		if err != nil || value == 0 {
+			return
+		}
+		if minAllowed == nil {
+			minAllowed, maxAllowed = map[string]string{}, map[string]string{}
+		}
+		if factor < 1 {
+			minAllowed[resource] = format(value * (1 - factor))
+		}
+		maxAllowed[resource] = format(value * (1 + factor))
+	}
+	bound("cpu", recommended.CPU, krr.ParseCPU, krr.FormatCPU)
+	bound("memory", recommended.Memory, krr.ParseMemory, krr.FormatMemory)
+	if len(minAllowed) == 0 {
+		minAllowed = nil
+	}
+	return minAllowed, maxAllowed
+}
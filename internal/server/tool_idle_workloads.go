@@ -0,0 +1,416 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Defaults and limits of krr_idle_workloads
+const (
+	defaultIdleWindow           = "7d"
+	minIdleWindow               = time.Hour
+	maxIdleWindow               = 90 * 24 * time.Hour
+	defaultIdleCPUThreshold     = 0.005
+	defaultIdleNetworkThreshold = 100
+	idleQueryTimeout            = 2 * time.Minute
+)
+
+// Suggestions of krr_idle_workloads
+const (
+	idleScaleToZero = "scale_to_zero"
+	idleDelete      = "delete"
+)
+
+// KRRIdleWorkloadsArguments defines the arguments for the krr_idle_workloads tool
+type KRRIdleWorkloadsArguments struct {
+	Context          *string  `json:"context,omitempty" jsonschema:"Kubernetes context whose Prometheus to query (optional, uses current context if not specified)"`
+	Cluster          *string  `json:"cluster,omitempty" jsonschema:"Named cluster from the server's cluster registry whose Prometheus to query (optional, cannot be combined with context)"`
+	Namespace        *string  `json:"namespace,omitempty" jsonschema:"Namespace to look for idle workloads in (optional, all namespaces if not specified)"`
+	Window           *string  `json:"window,omitempty" jsonschema:"How far back to look, e.g. '7d' or '36h', between 1h and 90d (default '7d'); workloads younger than the window are not reported"`
+	CPUThreshold     *float64 `json:"cpu_threshold,omitempty" jsonschema:"Average CPU usage in cores over the window below which a workload counts as idle (default 0.005)"`
+	NetworkThreshold *float64 `json:"network_threshold,omitempty" jsonschema:"Average received network traffic in bytes per second over the window below which a workload counts as idle (default 100, enough for probes and metric scrapes)"`
+}
+
+// IdleWorkload is a workload that used next to no CPU and received next to no traffic over the window
+type IdleWorkload struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Pods      int    `json:"pods"`
+
+	// Averages over the window; NetworkBytesPerSecond is nil when the pods have no network metrics
+	CPUCores              float64  `json:"cpu_cores"`
+	NetworkBytesPerSecond *float64 `json:"network_bytes_per_second,omitempty"`
+
+	// Resources the running pods request, and their monthly footprint with the server's carbon model
+	RequestedCPUCores  float64 `json:"requested_cpu_cores"`
+	RequestedMemoryGiB float64 `json:"requested_memory_gib"`
+	MonthlyKWh         float64 `json:"monthly_kwh"`
+	MonthlyCO2eKg      float64 `json:"monthly_co2e_kg"`
+
+	// Suggestion is scale_to_zero or delete
+	Suggestion string `json:"suggestion"`
+	Reason     string `json:"reason"`
+}
+
+// IdleTotals are what the idle workloads request and emit per month
+type IdleTotals struct {
+	RequestedCPUCores  float64 `json:"requested_cpu_cores"`
+	RequestedMemoryGiB float64 `json:"requested_memory_gib"`
+	MonthlyKWh         float64 `json:"monthly_kwh"`
+	MonthlyCO2eKg      float64 `json:"monthly_co2e_kg"`
+}
+
+// KRRIdleWorkloadsOutput defines the output structure for the krr_idle_workloads tool
+type KRRIdleWorkloadsOutput struct {
+	Prometheus       string  `json:"prometheus"`
+	Window           string  `json:"window"`
+	CPUThreshold     float64 `json:"cpu_threshold"`
+	NetworkThreshold float64 `json:"network_threshold"`
+
+	// WorkloadsChecked counts the running workloads observed for the whole window, and
+	// WorkloadsTooRecent those that were not
+	WorkloadsChecked   int            `json:"workloads_checked"`
+	WorkloadsTooRecent int            `json:"workloads_too_recent,omitempty"`
+	Idle               []IdleWorkload `json:"idle"`
+	Total              IdleTotals     `json:"total"`
+	Notes              []string       `json:"notes,omitempty"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_idle_workloads",
+		"Find workloads that are not used at all, rather than oversized: running workloads whose average CPU usage and received network traffic over a window (default 7 days) stayed below thresholds, from Prometheus, as candidates to scale to zero or delete, with the resources they request and their monthly energy and emissions",
+		(*MCPServer).handleIdleWorkloads,
+	))
+}
+
+// idleWorkload accumulates the pods of one workload
+type idleWorkload struct {
+	IdleWorkload
+	firstSeen  time.Time
+	running    bool
+	hasNetwork bool
+	network    float64
+	memory     float64
+}
+
+// handleIdleWorkloads queries the Prometheus scans use, like krr_doctor does, and groups pod
+// usage into workloads through kube-state-metrics owner series
+func (s *MCPServer) handleIdleWorkloads(ctx context.Context, req *mcp.CallToolRequest, arguments KRRIdleWorkloadsArguments) (*mcp.CallToolResult, KRRIdleWorkloadsOutput, error) {
+	failed := KRRIdleWorkloadsOutput{Idle: []IdleWorkload{}}
+
+	var problems validationErrors
+	windowText := defaultIdleWindow
+	if arguments.Window != nil {
+		windowText = strings.TrimSpace(*arguments.Window)
+	}
+	window, err := krr.ParseHistoryDuration(windowText)
+	if err != nil || window < minIdleWindow || window > maxIdleWindow {
+		problems.add("window", windowText, "must be a duration between 1h and 90d such as '7d' or '36h'")
+	}
+	cpuThreshold, networkThreshold := defaultIdleCPUThreshold, float64(defaultIdleNetworkThreshold)
+	if arguments.CPUThreshold != nil {
+		if cpuThreshold = *arguments.CPUThreshold; cpuThreshold < 0 {
+			problems.add("cpu_threshold", cpuThreshold, "cannot be negative")
+		}
+	}
+	if arguments.NetworkThreshold != nil {
+		if networkThreshold = *arguments.NetworkThreshold; networkThreshold < 0 {
+			problems.add("network_threshold", networkThreshold, "cannot be negative")
+		}
+	}
+
+	var options krr.ScanOptions
+	if arguments.Context != nil {
+		options.Context = strings.TrimSpace(*arguments.Context)
+	}
+	if arguments.Namespace != nil {
+		options.Namespace = strings.TrimSpace(*arguments.Namespace)
+	}
+	var registered *cluster
+	clusterName := ""
+	if arguments.Cluster != nil {
+		clusterName = strings.TrimSpace(*arguments.Cluster)
+		if arguments.Context != nil {
+			problems.add("cluster", *arguments.Cluster, "cannot be combined with context")
+		} else if registered, err = s.registryCluster(clusterName); err != nil {
+			problems.add("cluster", *arguments.Cluster, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return problems.result(), failed, nil
+	}
+	if registered != nil {
+		options.Context = registered.config.Context
+		options.PrometheusURL = registered.config.PrometheusURL
+	}
+
+	scope, err := s.scopeFor(req, &options)
+	if err != nil {
+		return errorResult(err.Error()), failed, nil
+	}
+	if registered != nil && scope.tenant != "" {
+		return errorResult("cluster is not allowed for tenants"), failed, nil
+	}
+
+	target, discovered := s.prometheusTarget(options.PrometheusURL)
+	if target == "" {
+		return errorResult("No Prometheus to query: configure a prometheus_url for the tenant or cluster, or run a scan so KRR discovers one"), failed, nil
+	}
+	client, err := s.prometheusClient()
+	if err != nil {
+		return errorResult(err.Error()), failed, nil
+	}
+
+	selector := idleNamespaceSelector(options)
+	rangeText := fmt.Sprintf("%ds", int64(window.Seconds()))
+	step := max(window/24, time.Minute)
+	queries := []struct {
+		name, query string
+	}{
+		{"running", fmt.Sprintf(`count by (namespace, pod) (container_cpu_usage_seconds_total{container!=""%s})`, selector)},
+		{"cpu", fmt.Sprintf(`sum by (namespace, pod) (rate(container_cpu_usage_seconds_total{container!=""%s}[%s]))`, selector, rangeText)},
+		{"first_seen", fmt.Sprintf(`min by (namespace, pod) (min_over_time(timestamp(container_cpu_usage_seconds_total{container!=""%s})[%s:%ds]))`, selector, rangeText, int64(step.Seconds()))},
+		{"network", fmt.Sprintf(`sum by (namespace, pod) (rate(container_network_receive_bytes_total{pod!=""%s}[%s]))`, selector, rangeText)},
+		{"requests", fmt.Sprintf(`sum by (namespace, pod, resource) (kube_pod_container_resource_requests{resource=~"cpu|memory"%s})`, selector)},
+		{"pod_owner", fmt.Sprintf(`max by (namespace, pod, owner_kind, owner_name) (max_over_time(kube_pod_owner{pod!=""%s}[%s]))`, selector, rangeText)},
+		{"replicaset_owner", fmt.Sprintf(`max by (namespace, replicaset, owner_kind, owner_name) (max_over_time(kube_replicaset_owner{replicaset!=""%s}[%s]))`, selector, rangeText)},
+	}
+	ctx, cancel := context.WithTimeout(ctx, idleQueryTimeout)
+	defer cancel()
+	results := map[string][]prometheusSample{}
+	for _, query := range queries {
+		samples, err := s.queryPrometheusVector(ctx, client, target, query.query)
+		if err != nil {
+			message := fmt.Sprintf("Prometheus query failed at %s: %v", target, err)
+			if discovered {
+				message += "; the URL was discovered by KRR inside the cluster and may only be reachable there, so configure a prometheus_url the server can reach"
+			}
+			return errorResult(message), failed, nil
+		}
+		results[query.name] = samples
+	}
+	if len(results["running"]) == 0 {
+		return errorResult("Prometheus has no container_cpu_usage_seconds_total series for running pods in scope; run krr_doctor to check the metrics"), failed, nil
+	}
+
+	output := KRRIdleWorkloadsOutput{
+		Prometheus:       target,
+		Window:           windowText,
+		CPUThreshold:     cpuThreshold,
+		NetworkThreshold: networkThreshold,
+		Idle:             []IdleWorkload{},
+	}
+	owners := newPodOwners(results["pod_owner"], results["replicaset_owner"])
+	if len(results["pod_owner"]) == 0 {
+		output.Notes = append(output.Notes, "Prometheus has no kube_pod_owner series (kube-state-metrics), so workloads were guessed from pod names")
+	}
+	if len(results["requests"]) == 0 {
+		output.Notes = append(output.Notes, "Prometheus has no kube_pod_container_resource_requests series (kube-state-metrics), so requests and emissions are 0")
+	}
+
+	workloads := map[string]*idleWorkload{}
+	each := func(name string, apply func(*idleWorkload, prometheusSample)) {
+		for _, sample := range results[name] {
+			namespace, pod := sample.labels["namespace"], sample.labels["pod"]
+			if namespace == "" || pod == "" || !scope.allows(namespace) {
+				continue
+			}
+			kind, workloadName := owners.workload(namespace, pod)
+			if kind == "" {
+				continue
+			}
+			key := namespace + "/" + kind + "/" + workloadName
+			workload, ok := workloads[key]
+			if !ok {
+				workload = &idleWorkload{IdleWorkload: IdleWorkload{Namespace: namespace, Kind: kind, Name: workloadName}}
+				workloads[key] = workload
+			}
+			apply(workload, sample)
+		}
+	}
+	each("running", func(w *idleWorkload, _ prometheusSample) { w.running = true; w.Pods++ })
+	each("cpu", func(w *idleWorkload, sample prometheusSample) { w.CPUCores += sample.value })
+	each("network", func(w *idleWorkload, sample prometheusSample) { w.hasNetwork = true; w.network += sample.value })
+	each("first_seen", func(w *idleWorkload, sample prometheusSample) {
+		seen := time.Unix(int64(sample.value), 0)
+		if w.firstSeen.IsZero() || seen.Before(w.firstSeen) {
+			w.firstSeen = seen
+		}
+	})
+	each("requests", func(w *idleWorkload, sample prometheusSample) {
+		switch sample.labels["resource"] {
+		case "cpu":
+			w.RequestedCPUCores += sample.value
+		case "memory":
+			w.memory += sample.value
+		}
+	})
+
+	model := s.carbonModel(clusterName, scope)
+	// A workload first seen after the window started, give or take the subquery step, is too recent
+	observedSince := time.Now().Add(-window + 2*step)
+	var total krr.Summary
+	for _, workload := range workloads {
+		if !workload.running {
+			continue
+		}
+		if workload.firstSeen.IsZero() || workload.firstSeen.After(observedSince) {
+			output.WorkloadsTooRecent++
+			continue
+		}
+		output.WorkloadsChecked++
+		if workload.CPUCores >= cpuThreshold || (workload.hasNetwork && workload.network >= networkThreshold) {
+			continue
+		}
+
+		idle := workload.IdleWorkload
+		idle.CPUCores = math.Round(idle.CPUCores*1e4) / 1e4
+		if workload.hasNetwork {
+			network := math.Round(workload.network*100) / 100
+			idle.NetworkBytesPerSecond = &network
+		}
+		requested := krr.Summary{
+			CurrentCPUCores:        workload.RequestedCPUCores,
+			CurrentMemoryBytes:     workload.memory,
+			ReclaimableCPUCores:    workload.RequestedCPUCores,
+			ReclaimableMemoryBytes: workload.memory,
+		}
+		footprint := krr.EstimateCarbon(requested, model)
+		idle.RequestedCPUCores, idle.RequestedMemoryGiB = footprint.ReclaimableCPUCores, footprint.ReclaimableMemoryGiB
+		idle.MonthlyKWh, idle.MonthlyCO2eKg = footprint.MonthlyKWhSavings, footprint.MonthlyCO2eSavingsKg
+		idle.Suggestion, idle.Reason = idleSuggestion(workload, windowText)
+
+		output.Idle = append(output.Idle, idle)
+		total.CurrentCPUCores += requested.CurrentCPUCores
+		total.CurrentMemoryBytes += requested.CurrentMemoryBytes
+	}
+	total.ReclaimableCPUCores, total.ReclaimableMemoryBytes = total.CurrentCPUCores, total.CurrentMemoryBytes
+	footprint := krr.EstimateCarbon(total, model)
+	output.Total = IdleTotals{
+		RequestedCPUCores:  footprint.ReclaimableCPUCores,
+		RequestedMemoryGiB: footprint.ReclaimableMemoryGiB,
+		MonthlyKWh:         footprint.MonthlyKWhSavings,
+		MonthlyCO2eKg:      footprint.MonthlyCO2eSavingsKg,
+	}
+
+	sort.Slice(output.Idle, func(i, j int) bool {
+		a, b := output.Idle[i], output.Idle[j]
+		if a.MonthlyCO2eKg != b.MonthlyCO2eKg {
+			return a.MonthlyCO2eKg > b.MonthlyCO2eKg
+		}
+		if a.RequestedCPUCores != b.RequestedCPUCores {
+			return a.RequestedCPUCores > b.RequestedCPUCores
+		}
+		return a.Namespace+"/"+a.Kind+"/"+a.Name < b.Namespace+"/"+b.Kind+"/"+b.Name
+	})
+	if output.WorkloadsTooRecent > 0 {
+		output.Notes = append(output.Notes, fmt.Sprintf("%d workload(s) were first seen less than %s ago and were not checked", output.WorkloadsTooRecent, windowText))
+	}
+	if len(output.Idle) > 0 {
+		output.Notes = append(output.Notes, "Jobs and CronJobs are never reported, since they are idle between runs; check with the owners before removing anything, as usage outside the window or over other protocols is not seen")
+	}
+	return nil, output, nil
+}
+
+// idleSuggestion proposes what to do with an idle workload. A workload that received no
+// traffic at all, or that cannot be scaled, is a candidate for deletion; one with a trickle
+// of traffic can scale to zero and be woken on demand, e.g. with KEDA or Knative.
+func idleSuggestion(workload *idleWorkload, window string) (string, string) {
+	usage := fmt.Sprintf("averaged %.4f cores", workload.CPUCores)
+	switch {
+	case !workload.hasNetwork:
+		usage += " with no network metrics"
+	case workload.network == 0:
+		usage += " and received no traffic"
+	default:
+		usage += fmt.Sprintf(" and received %.2f bytes/s", workload.network)
+	}
+	usage += " over the last " + window
+
+	switch {
+	case workload.Kind == "DaemonSet" || workload.Kind == "Pod":
+		return idleDelete, usage + fmt.Sprintf("; a %s cannot be scaled to zero, so delete it if nothing depends on it", workload.Kind)
+	case workload.hasNetwork && workload.network == 0:
+		return idleDelete, usage + "; nothing seems to use it, so delete it, or scale it to zero first to be safe"
+	default:
+		return idleScaleToZero, usage + "; scale it to zero, or to zero outside working hours, and wake it on demand"
+	}
+}
+
+// idleNamespaceSelector returns the label matcher, with a leading comma, limiting queries to the
+// namespaces of the scan options, or nothing for all namespaces
+func idleNamespaceSelector(options krr.ScanOptions) string {
+	if options.Namespace != "" {
+		return fmt.Sprintf(`,namespace=%q`, options.Namespace)
+	}
+	if len(options.Namespaces) > 0 {
+		quoted := make([]string, len(options.Namespaces))
+		for i, namespace := range options.Namespaces {
+			quoted[i] = regexp.QuoteMeta(namespace)
+		}
+		return fmt.Sprintf(`,namespace=~%q`, strings.Join(quoted, "|"))
+	}
+	return ""
+}
+
+// podOwners maps pods to their workloads from the kube_pod_owner and kube_replicaset_owner series
+type podOwners struct {
+	pods        map[string][2]string
+	replicaSets map[string][2]string
+}
+
+// newPodOwners indexes the owner series by namespace and name
+func newPodOwners(pods, replicaSets []prometheusSample) podOwners {
+	owners := podOwners{pods: map[string][2]string{}, replicaSets: map[string][2]string{}}
+	for _, sample := range pods {
+		owners.pods[sample.labels["namespace"]+"/"+sample.labels["pod"]] = [2]string{sample.labels["owner_kind"], sample.labels["owner_name"]}
+	}
+	for _, sample := range replicaSets {
+		owners.replicaSets[sample.labels["namespace"]+"/"+sample.labels["replicaset"]] = [2]string{sample.labels["owner_kind"], sample.labels["owner_name"]}
+	}
+	return owners
+}
+
+// Pod names of ReplicaSets (<deployment>-<template hash>-<suffix>) and StatefulSets (<name>-<ordinal>)
+var (
+	replicaSetPodName  = regexp.MustCompile(`^(.+)-[a-z0-9]{6,10}-[a-z0-9]{5}$`)
+	statefulSetPodName = regexp.MustCompile(`^(.+)-[0-9]+$`)
+)
+
+// workload returns the kind and name of the workload a pod belongs to, or an empty kind for
+// Jobs, which are idle between runs by design. Without owner series it is guessed from the name.
+func (o podOwners) workload(namespace, pod string) (string, string) {
+	if len(o.pods) == 0 {
+		if match := replicaSetPodName.FindStringSubmatch(pod); match != nil {
+			return "Deployment", match[1]
+		}
+		if match := statefulSetPodName.FindStringSubmatch(pod); match != nil {
+			return "StatefulSet", match[1]
+		}
+		return "Pod", pod
+	}
+
+	owner, ok := o.pods[namespace+"/"+pod]
+	switch {
+	case !ok || owner[0] == "" || owner[0] == "<none>":
+		return "Pod", pod
+	case owner[0] == "Job":
+		return "", ""
+	case owner[0] == "ReplicaSet":
+		if parent, ok := o.replicaSets[namespace+"/"+owner[1]]; ok && parent[0] != "" && parent[0] != "<none>" {
+			return parent[0], parent[1]
+		}
+	}
+	return owner[0], owner[1]
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRListClustersArguments defines the (empty) arguments for the krr_list_clusters tool
+type KRRListClustersArguments struct{}
+
+// RegisteredCluster describes a cluster registry entry to clients. The kubeconfig path stays
+// on the server.
+type RegisteredCluster struct {
+	Name          string            `json:"name"`
+	Context       string            `json:"context,omitempty"`
+	PrometheusURL string            `json:"prometheus_url,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Pricing       string            `json:"pricing,omitempty"`
+}
+
+// KRRListClustersOutput defines the output structure for the krr_list_clusters tool
+type KRRListClustersOutput struct {
+	Clusters []RegisteredCluster `json:"clusters"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_list_clusters",
+		"List the named clusters of the server's cluster registry with their context, Prometheus URL and labels (e.g. env=prod), to find valid values for krr_scan's cluster argument",
+		(*MCPServer).handleListClusters,
+	))
+}
+
+// handleListClusters lists the cluster registry by name. Tenants are confined to their own
+// cluster, so they see no registry.
+func (s *MCPServer) handleListClusters(ctx context.Context, req *mcp.CallToolRequest, arguments KRRListClustersArguments) (*mcp.CallToolResult, KRRListClustersOutput, error) {
+	output := KRRListClustersOutput{Clusters: []RegisteredCluster{}}
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return errorResult(err.Error()), output, nil
+	}
+	if scope.tenant != "" {
+		return nil, output, nil
+	}
+
+	clusters := s.live().clusters
+	for _, name := range slices.Sorted(maps.Keys(clusters)) {
+		c := clusters[name]
+		output.Clusters = append(output.Clusters, RegisteredCluster{
+			Name:          name,
+			Context:       c.config.Context,
+			PrometheusURL: c.config.PrometheusURL,
+			Labels:        c.config.Labels,
+			Pricing:       c.config.Pricing,
+		})
+	}
+	return nil, output, nil
+}
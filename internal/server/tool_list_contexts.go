@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/kube"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRListContextsArguments defines the (empty) arguments for the krr_list_contexts tool
+type KRRListContextsArguments struct{}
+
+// KRRListContextsOutput defines the output structure for the krr_list_contexts tool
+type KRRListContextsOutput struct {
+	Contexts []kube.Context `json:"contexts"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_list_contexts",
+		"List the contexts of the server's kubeconfig with their cluster endpoint and default namespace, to find valid values for the context argument of the scan tools",
+		(*MCPServer).handleListContexts,
+	))
+}
+
+// handleListContexts lists kubeconfig contexts through kubectl. A tenant sees the contexts of
+// its own kubeconfig, or only its context when it is bound to one.
+func (s *MCPServer) handleListContexts(ctx context.Context, req *mcp.CallToolRequest, arguments KRRListContextsArguments) (*mcp.CallToolResult, KRRListContextsOutput, error) {
+	// Error results still have their output validated, and the schema wants an array
+	failed := KRRListContextsOutput{Contexts: []kube.Context{}}
+
+	var scopeOptions krr.ScanOptions
+	scope, err := s.scopeFor(req, &scopeOptions)
+	if err != nil {
+		return errorResult(err.Error()), failed, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, kubeLookupTimeout)
+	defer cancel()
+	contexts, err := scope.kube.Contexts(ctx)
+	if err != nil {
+		return errorResult(fmt.Sprintf("Failed to list kubeconfig contexts: %v", err)), failed, nil
+	}
+	if scopeOptions.Context != "" {
+		contexts = slices.DeleteFunc(contexts, func(kubeContext kube.Context) bool { return kubeContext.Name != scopeOptions.Context })
+	}
+	return nil, KRRListContextsOutput{Contexts: contexts}, nil
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/kube"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// kubeLookupTimeout bounds the kubectl calls of the cluster discovery tools
+const kubeLookupTimeout = 30 * time.Second
+
+// KRRListNamespacesArguments defines the arguments for the krr_list_namespaces tool
+type KRRListNamespacesArguments struct {
+	Context       *string `json:"context,omitempty" jsonschema:"Kubernetes context to use (optional, uses current context if not specified)"`
+	LabelSelector *string `json:"label_selector,omitempty" jsonschema:"Only list namespaces matching this label selector, e.g. 'team=payments' (optional)"`
+}
+
+// KRRListNamespacesOutput defines the output structure for the krr_list_namespaces tool
+type KRRListNamespacesOutput struct {
+	Namespaces []kube.Namespace `json:"namespaces"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_list_namespaces",
+		"List the namespaces of the target cluster, optionally filtered by label selector, to find valid values for the namespace argument of the scan tools",
+		(*MCPServer).handleListNamespaces,
+	))
+}
+
+// handleListNamespaces lists namespaces through kubectl; tenants only see the namespaces they
+// may scan
+func (s *MCPServer) handleListNamespaces(ctx context.Context, req *mcp.CallToolRequest, arguments KRRListNamespacesArguments) (*mcp.CallToolResult, KRRListNamespacesOutput, error) {
+	// Error results still have their output validated, and the schema wants an array
+	failed := KRRListNamespacesOutput{Namespaces: []kube.Namespace{}}
+
+	var selector string
+	if arguments.LabelSelector != nil {
+		selector = strings.TrimSpace(*arguments.LabelSelector)
+		if err := kube.ValidateLabelSelector(selector); err != nil {
+			var problems validationErrors
+			problems.add("label_selector", *arguments.LabelSelector, err.Error())
+			return problems.result(), failed, nil
+		}
+	}
+
+	var scopeOptions krr.ScanOptions
+	if arguments.Context != nil {
+		scopeOptions.Context = *arguments.Context
+	}
+	scope, err := s.scopeFor(req, &scopeOptions)
+	if err != nil {
+		return errorResult(err.Error()), failed, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, kubeLookupTimeout)
+	defer cancel()
+	namespaces, err := scope.kube.Namespaces(ctx, scopeOptions.Context, selector)
+	if err != nil {
+		return errorResult(fmt.Sprintf("Failed to list namespaces: %v", err)), failed, nil
+	}
+	namespaces = slices.DeleteFunc(namespaces, func(namespace kube.Namespace) bool { return !scope.allows(namespace.Name) })
+	return nil, KRRListNamespacesOutput{Namespaces: namespaces}, nil
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRListRunningArguments defines the (empty) arguments for the krr_list_running tool
+type KRRListRunningArguments struct{}
+
+// KRRListRunningOutput defines the output structure for the krr_list_running tool
+type KRRListRunningOutput struct {
+	Scans []RunningScan `json:"scans"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_list_running",
+		"List the KRR scans currently in flight with their request ID, options and start time",
+		(*MCPServer).handleListRunning,
+	))
+}
+
+// handleListRunning returns the scans currently tracked in the running-scan registry; tenants
+// only see their own
+func (s *MCPServer) handleListRunning(ctx context.Context, req *mcp.CallToolRequest, arguments KRRListRunningArguments) (*mcp.CallToolResult, KRRListRunningOutput, error) {
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return errorResult(err.Error()), KRRListRunningOutput{}, nil
+	}
+	return nil, KRRListRunningOutput{Scans: s.running.list(scope.tenant)}, nil
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRListSchedulesArguments defines the (empty) arguments for the krr_list_schedules tool
+type KRRListSchedulesArguments struct{}
+
+// KRRListSchedulesOutput defines the output structure for the krr_list_schedules tool
+type KRRListSchedulesOutput struct {
+	Schedules []ScheduleInfo `json:"schedules"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_list_schedules",
+		"List the scheduled scans, from the server configuration and from krr_schedule_scan, with their cron expression, scan options, next run and the outcome of the last run",
+		(*MCPServer).handleListSchedules,
+	))
+}
+
+// handleListSchedules lists every scheduled scan; tenants have none
+func (s *MCPServer) handleListSchedules(ctx context.Context, req *mcp.CallToolRequest, arguments KRRListSchedulesArguments) (*mcp.CallToolResult, KRRListSchedulesOutput, error) {
+	output := KRRListSchedulesOutput{Schedules: []ScheduleInfo{}}
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return errorResult(err.Error()), output, nil
+	}
+	if scope.tenant != "" {
+		return nil, output, nil
+	}
+
+	now := time.Now()
+	for _, scan := range s.schedules.list() {
+		output.Schedules = append(output.Schedules, scheduleInfo(scan, now))
+	}
+	return nil, output, nil
+}
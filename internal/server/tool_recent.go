@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRRecentArguments defines the (empty) arguments for the krr_recent tool
+type KRRRecentArguments struct{}
+
+// KRRRecentOutput defines the output structure for the krr_recent tool
+type KRRRecentOutput struct {
+	// Scans are the most recently finished scans, newest first
+	Scans []RecentScan `json:"scans"`
+
+	// LastError is the most recent failed scan among them, if any
+	LastError *RecentScan `json:"last_error,omitempty"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_recent",
+		"List the most recently finished KRR scans, newest first, with their request ID, scope, duration and outcome (error kind and message for failures), plus the last failure",
+		(*MCPServer).handleRecent,
+	))
+}
+
+// handleRecent returns the recent-scans buffer; tenants only see their own scans
+func (s *MCPServer) handleRecent(ctx context.Context, req *mcp.CallToolRequest, arguments KRRRecentArguments) (*mcp.CallToolResult, KRRRecentOutput, error) {
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return errorResult(err.Error()), KRRRecentOutput{}, nil
+	}
+
+	output := KRRRecentOutput{Scans: s.recent.list(scope.tenant)}
+	for i := range output.Scans {
+		if !output.Scans[i].Success {
+			output.LastError = &output.Scans[i]
+			break
+		}
+	}
+	return nil, output, nil
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/store"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Limits of krr_savings_report
+const (
+	maxReportScans     = 20
+	reportOffenders    = 10
+	defaultTrendPeriod = "7d"
+)
+
+// KRRSavingsReportArguments defines the arguments for the krr_savings_report tool
+type KRRSavingsReportArguments struct {
+	ScanIDs []string `json:"scan_ids,omitempty" jsonschema:"Request IDs of the scans to report on, e.g. one per cluster, or 'latest' (default ['latest'], at most 20)"`
+	Period  *string  `json:"period,omitempty" jsonschema:"How far back the trend looks: each scan is compared with the newest stored scan of the same scope at least this much older, e.g. '7d' or '30d' (default '7d')"`
+}
+
+// KRRSavingsReportOutput defines the output structure for the krr_savings_report tool
+type KRRSavingsReportOutput struct {
+	krr.SavingsReport
+
+	// Markdown is the report rendered for humans
+	Markdown string `json:"markdown"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_savings_report",
+		"Produce an executive summary of what the recommendations of one or more scans would save per month: CPU cores, memory GiB, money and kg CO2e, the top 10 offending workloads and the trend versus the previous period (from the scan history), as structured data plus a Markdown rendering to share",
+		(*MCPServer).handleSavingsReport,
+	))
+}
+
+// handleSavingsReport combines the cost and carbon estimates of stored scans. Each scan is
+// priced with the prices and grid intensity of its own cluster.
+func (s *MCPServer) handleSavingsReport(ctx context.Context, req *mcp.CallToolRequest, arguments KRRSavingsReportArguments) (*mcp.CallToolResult, KRRSavingsReportOutput, error) {
+	failed := KRRSavingsReportOutput{SavingsReport: krr.SavingsReport{Scans: []string{}, Offenders: []krr.SavingsOffender{}}}
+
+	var problems validationErrors
+	ids := arguments.ScanIDs
+	if len(ids) == 0 {
+		ids = []string{"latest"}
+	}
+	if len(ids) > maxReportScans {
+		problems.add("scan_ids", len(ids), fmt.Sprintf("at most %d scans can be reported on at once", maxReportScans))
+	}
+	periodText := defaultTrendPeriod
+	if arguments.Period != nil {
+		periodText = strings.TrimSpace(*arguments.Period)
+	}
+	period, err := krr.ParseHistoryDuration(periodText)
+	if err != nil || period <= 0 {
+		problems.add("period", periodText, "must be a positive duration such as '7d' or '36h'")
+	}
+	if len(problems) > 0 {
+		return problems.result(), failed, nil
+	}
+
+	report := krr.SavingsReport{GeneratedAt: time.Now(), Scans: []string{}, Offenders: []krr.SavingsOffender{}}
+	trend := &krr.SavingsTrend{Period: periodText, PreviousScans: []string{}}
+	currencies := map[string]bool{}
+	var unpriced, untracked []string
+	for _, id := range ids {
+		scan, scope, err := s.loadScopedScan(req, &id)
+		if err != nil {
+			return errorResult(err.Error()), failed, nil
+		}
+		if slices.Contains(report.Scans, scan.ID) {
+			continue
+		}
+		report.Scans = append(report.Scans, scan.ID)
+		cluster := historyCluster(scan.Cluster, scan.Options)
+		if cluster != "" && !slices.Contains(report.Clusters, cluster) {
+			report.Clusters = append(report.Clusters, cluster)
+		}
+
+		cost, _, currency := s.costModelFor(scan, scope, "")
+		if cost.Enabled() {
+			currencies[currency] = true
+		} else {
+			unpriced = append(unpriced, scan.ID)
+		}
+		carbon := s.carbonModel(scan.Cluster, scope)
+
+		summary := krr.SummarizeCluster(&krr.ScanResult{Resources: scan.Resources})
+		current := krr.Savings(summary.Totals, cost, carbon)
+		report.Total.Add(current)
+		for _, workload := range krr.SummarizeWorkloads(scan.Resources) {
+			savings := krr.Savings(workload.Summary, cost, carbon)
+			if savings.ReclaimableCPUCores > 0 || savings.ReclaimableMemoryGiB > 0 {
+				report.Offenders = append(report.Offenders, krr.SavingsOffender{Cluster: cluster, Namespace: workload.Namespace, Kind: workload.Kind, Name: workload.Name, SavingsTotals: savings})
+			}
+		}
+
+		previous, ok := s.previousScan(scan, scope, period)
+		if !ok {
+			untracked = append(untracked, scan.ID)
+			continue
+		}
+		trend.PreviousScans = append(trend.PreviousScans, previous.ID)
+		trend.Previous.Add(krr.Savings(previous.Summary, cost, carbon))
+		trend.Current.Add(current)
+	}
+
+	// Money ranks offenders when every scan is priced, emissions otherwise
+	sort.SliceStable(report.Offenders, func(i, j int) bool {
+		a, b := report.Offenders[i], report.Offenders[j]
+		if len(unpriced) == 0 && a.MonthlySavings != b.MonthlySavings {
+			return a.MonthlySavings > b.MonthlySavings
+		}
+		return a.MonthlyCO2eSavingsKg > b.MonthlyCO2eSavingsKg
+	})
+	if len(report.Offenders) > reportOffenders {
+		report.Offenders = report.Offenders[:reportOffenders]
+	}
+
+	if len(trend.PreviousScans) > 0 {
+		trend.Change = trend.Current.Sub(trend.Previous)
+		report.Trend = trend
+	}
+	if len(currencies) == 1 {
+		for currency := range currencies {
+			report.Currency = currency
+		}
+	} else if len(currencies) > 1 {
+		report.Notes = append(report.Notes, "The scans are priced in different currencies, so the money totals mix them")
+	}
+	if len(unpriced) > 0 {
+		report.Notes = append(report.Notes, fmt.Sprintf("No prices are configured for scan(s) %s, so their money savings are not counted; set cpu_cost_per_core_hour and memory_cost_per_gib_hour or pricing", strings.Join(unpriced, ", ")))
+	}
+	switch {
+	case s.history == nil:
+		report.Notes = append(report.Notes, "There is no trend since the scan history is disabled; set history_dir")
+	case len(untracked) > 0:
+		report.Notes = append(report.Notes, fmt.Sprintf("Scan(s) %s have no stored scan of the same scope at least %s older, so they are not part of the trend", strings.Join(untracked, ", "), periodText))
+	}
+
+	return nil, KRRSavingsReportOutput{SavingsReport: report, Markdown: krr.RenderSavingsMarkdown(report)}, nil
+}
+
+// previousScan finds the newest stored scan of the same cluster and namespaces as scan that is
+// at least period older
+func (s *MCPServer) previousScan(scan storedScan, scope scanScope, period time.Duration) (store.Entry, bool) {
+	if s.history == nil {
+		return store.Entry{}, false
+	}
+	cluster := historyCluster(scan.Cluster, scan.Options)
+	for _, entry := range s.history.Query(store.Filter{Tenant: scope.tenant, Until: scan.FinishedAt.Add(-period)}) {
+		if entry.Cluster == cluster && entry.Options.Namespace == scan.Options.Namespace &&
+			slices.Equal(entry.Options.Namespaces, scan.Options.Namespaces) {
+			return entry, true
+		}
+	}
+	return store.Entry{}, false
+}
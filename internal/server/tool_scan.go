@@ -0,0 +1,708 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/artifact"
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/kube"
+	"greenops-mcp/internal/store"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRScanArguments defines the arguments for the krr_scan tool
+type KRRScanArguments struct {
+	Profile           *string           `json:"profile,omitempty" jsonschema:"Named scan profile from the server configuration to use as the base options; explicit arguments override it (optional)"`
+	Namespace         *string           `json:"namespace,omitempty" jsonschema:"Kubernetes namespace to scan (optional, scans all namespaces if not specified)"`
+	ExcludeNamespaces []string          `json:"exclude_namespaces,omitempty" jsonschema:"Scan all namespaces except these (optional, cannot be combined with namespace); applied after the scan"`
+	Context           *string           `json:"context,omitempty" jsonschema:"Kubernetes context to use (optional, uses current context if not specified)"`
+	ClusterName       *string           `json:"cluster_name,omitempty" jsonschema:"Name of the cluster for reporting purposes (optional)"`
+	Cluster           *string           `json:"cluster,omitempty" jsonschema:"Named cluster from the server's cluster registry (see krr_list_clusters); selects its kubeconfig, context and Prometheus URL (optional, cannot be combined with context)"`
+	Strategy          *string           `json:"strategy,omitempty" jsonschema:"Recommendation strategy to use (e.g. 'simple' 'simple-limit', or the name registered by strategy_path)"`
+	StrategyPath      *string           `json:"strategy_path,omitempty" jsonschema:"Custom KRR strategy Python file, relative to the server's strategy_dir (optional)"`
+	CPUMin            *string           `json:"cpu_min,omitempty" jsonschema:"Minimum CPU recommendation threshold (e.g. '100m')"`
+	CPUMax            *string           `json:"cpu_max,omitempty" jsonschema:"Maximum CPU recommendation threshold (e.g. '2')"`
+	MemoryMin         *string           `json:"memory_min,omitempty" jsonschema:"Minimum memory recommendation threshold (e.g. '128Mi')"`
+	MemoryMax         *string           `json:"memory_max,omitempty" jsonschema:"Maximum memory recommendation threshold (e.g. '4Gi')"`
+	HistoryDuration   *string           `json:"history_duration,omitempty" jsonschema:"How much Prometheus history KRR analyses, e.g. '36h', '7d' or a number of hours (optional, KRR defaults to 14 days; capped by the server's max_history_duration)"`
+	PrometheusLabel   *string           `json:"prometheus_label,omitempty" jsonschema:"Metric label that distinguishes clusters in a centralized Prometheus/Thanos, e.g. 'cluster' (optional)"`
+	ClusterLabelValue *string           `json:"cluster_label_value,omitempty" jsonschema:"Value of prometheus_label selecting this cluster's metrics (optional, requires prometheus_label)"`
+	ExtraFlags        map[string]string `json:"extra_flags,omitempty" jsonschema:"Additional KRR flags by name, e.g. {\"--use_oomkill_data\": \"\"} (empty value for switches); only flags in the server's allowed_extra_flags are accepted (optional)"`
+	KRRWorkers        *int              `json:"krr_workers,omitempty" jsonschema:"Number of concurrent requests KRR makes to Prometheus and Kubernetes (optional, defaults to the server setting; capped by max_krr_workers); raise it for large clusters if Prometheus can take the load"`
+	OutputFormat      *string           `json:"output_format,omitempty" jsonschema:"Output format: 'table' (default unless the server sets default_output_format), 'json' (typed recommendations and summary in the structured output), 'yaml' (KRR's YAML report, unfiltered), 'cost' (estimated monthly savings from the configured cost model) 'markdown' (shareable rightsizing report) or 'delta' (per container, only the fields whose recommendation differs from the current value, with the direction of change)"`
+	RecommendOnly     *bool             `json:"recommend_only,omitempty" jsonschema:"Only show resources whose recommendation differs from their current values (default: false); same as view 'recommend-only'"`
+	View              *string           `json:"view,omitempty" jsonschema:"Which resources to report: 'all' (default), 'recommend-only' or 'problems' (recommend-only plus WARNING/CRITICAL and containers without a recommendation); takes precedence over recommend_only"`
+	Verbose           *bool             `json:"verbose,omitempty" jsonschema:"Enable verbose KRR logging; logs are returned in a separate Verbose Output section (default: false)"`
+	NoColor           *bool             `json:"no_color,omitempty" jsonschema:"Disable ANSI colors in KRR output (optional, defaults to the server's default_no_color); set false only if the client renders ANSI"`
+	KRRPath           *string           `json:"krr_path,omitempty" jsonschema:"Override the path to the KRR CLI executable (optional)"`
+	TimeoutSeconds    *int              `json:"timeout_seconds,omitempty" jsonschema:"Scan timeout in seconds (optional, capped by the server's max_timeout)"`
+	MinSeverity       *string           `json:"min_severity,omitempty" jsonschema:"Only report containers at or above this severity: 'CRITICAL', 'WARNING' or 'OK' (optional)"`
+	NodeSelector      *string           `json:"node_selector,omitempty" jsonschema:"Only report workloads with pods on nodes matching this label selector (e.g. 'pool=spot'); approximate, applied after the scan from current pod placement"`
+	NotifySlack       *bool             `json:"notify_slack,omitempty" jsonschema:"Post a savings summary to the configured Slack channel after a successful scan (optional, defaults to the server setting)"`
+	MaxOutputRows     *int              `json:"max_output_rows,omitempty" jsonschema:"Limit table output to this many rows, keeping the header (optional, defaults to the server setting; 0 disables)"`
+	RawResult         *bool             `json:"raw_result,omitempty" jsonschema:"Return the output without the 'KRR Scan Results:' prefix; in cost and delta modes the result is just the JSON document (default: false)"`
+	SummaryOnly       *bool             `json:"summary_only,omitempty" jsonschema:"Return only the savings summary as JSON, without the per-container recommendations or KRR's raw output (default: false); cannot be combined with a non-table output_format"`
+	ResourcesFile     *string           `json:"resources_file,omitempty" jsonschema:"Analyse a saved KRR JSON report (relative to the server's artifact_dir, e.g. from save_to_path or krr_export_resources) instead of scanning; no cluster or Prometheus access (optional)"`
+	SaveToPath        *string           `json:"save_to_path,omitempty" jsonschema:"Save the report to timestamped files in this directory, relative to the server's artifact_dir (optional)"`
+}
+
+// KRRScanOutput defines the output structure for krr_scan tool
+type KRRScanOutput struct {
+	Result        string   `json:"result"`
+	ArtifactPaths []string `json:"artifact_paths,omitempty"`
+	ReportURL     string   `json:"report_url,omitempty"`
+
+	// Signature hashes the reported recommendations, ignoring order and timestamps; equal
+	// signatures mean nothing changed between two scans of the same scope
+	Signature string `json:"signature,omitempty"`
+
+	// EffectiveOptions are the settings the scan actually ran with
+	EffectiveOptions *EffectiveScanOptions `json:"effective_options,omitempty"`
+
+	// Recommendations and Summary are the parsed scan in json mode, where result only carries
+	// a one-line overview so the report is not returned twice
+	Recommendations []krr.Resource `json:"recommendations,omitempty"`
+	Summary         *krr.Summary   `json:"summary,omitempty"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_scan",
+		"Execute a KRR (Kubernetes Resource Recommender) scan to analyze resource usage and get recommendations",
+		(*MCPServer).handleScanTyped,
+	))
+}
+
+// ExecuteScan is a public method for testing purposes
+func (s *MCPServer) ExecuteScan(arguments KRRScanArguments) (KRRScanOutput, error) {
+	req := &mcp.CallToolRequest{}
+	_, output, err := s.handleScanTyped(context.Background(), req, arguments)
+	return output, err
+}
+
+// scanPlan is a validated krr_scan call: the resolved KRR options plus everything applied
+// around the scan
+type scanPlan struct {
+	options       krr.ScanOptions
+	mode          string
+	view          krr.View
+	timeout       time.Duration
+	scope         scanScope
+	cluster       string
+	executor      krr.Executor
+	excluded      map[string]bool
+	nodeSelector  string
+	minSeverity   string
+	maxRows       int
+	artifactDir   string
+	resourcesFile string
+	notifySlack   bool
+	renderTable   bool
+	summaryOnly   bool
+}
+
+// effectiveOptions describes the options the planned scan runs with
+func (p *scanPlan) effectiveOptions() *EffectiveScanOptions {
+	effective := newEffectiveScanOptions(p.options, p.mode, p.view, p.timeout)
+	effective.SummaryOnly = p.summaryOnly
+	return effective
+}
+
+// structured reports whether the planned scan returns machine-readable JSON
+func (p *scanPlan) structured() bool {
+	return isStructuredMode(p.mode) || p.summaryOnly
+}
+
+// planScan validates krr_scan arguments and resolves them against profiles, server defaults,
+// the caller's tenant scope and the scan policy. It never runs KRR; on invalid arguments or a
+// policy violation it returns the error result to send instead.
+func (s *MCPServer) planScan(req *mcp.CallToolRequest, arguments KRRScanArguments) (*scanPlan, *mcp.CallToolResult) {
+	// Parse arguments into ScanOptions, collecting every invalid argument before failing
+	options := krr.ScanOptions{}
+	var problems validationErrors
+
+	// A profile provides the base options; server defaults only fill what it leaves unset
+	var profile krr.ScanOptions
+	if arguments.Profile != nil {
+		var err error
+		if profile, err = s.scanProfile(*arguments.Profile); err != nil {
+			problems.add("profile", *arguments.Profile, err.Error())
+		}
+		options = profile
+	}
+
+	excluded := make(map[string]bool)
+	for _, namespace := range arguments.ExcludeNamespaces {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			excluded[namespace] = true
+		}
+	}
+
+	if arguments.Namespace != nil {
+		options.Namespace = *arguments.Namespace
+		if len(excluded) > 0 {
+			problems.add("exclude_namespaces", arguments.ExcludeNamespaces, "cannot be combined with namespace")
+		}
+	} else if len(excluded) > 0 {
+		options.Namespace = ""
+	} else if options.Namespace == "" {
+		options.Namespace = s.config().DefaultNamespace
+	}
+
+	if arguments.Context != nil {
+		options.Context = *arguments.Context
+	}
+
+	if arguments.ClusterName != nil {
+		options.ClusterName = *arguments.ClusterName
+	}
+
+	if arguments.Strategy != nil {
+		options.Strategy = *arguments.Strategy
+	} else if options.Strategy == "" {
+		options.Strategy = s.config().DefaultStrategy
+	}
+
+	// A profile's strategy_path is relative to strategy_dir, just like the argument
+	requestedStrategyPath := arguments.StrategyPath
+	if requestedStrategyPath == nil && options.StrategyPath != "" {
+		requestedStrategyPath = &options.StrategyPath
+	}
+	if requestedStrategyPath != nil {
+		requested := *requestedStrategyPath
+		options.StrategyPath = ""
+		if s.config().StrategyDir == "" {
+			problems.add("strategy_path", requested, "requires the server to be configured with a strategy_dir")
+		} else if strategyPath, err := artifact.ResolvePath(s.config().StrategyDir, requested); err != nil {
+			problems.add("strategy_path", requested, err.Error())
+		} else if info, err := os.Stat(strategyPath); err != nil || info.IsDir() {
+			problems.add("strategy_path", requested, "not a file in the strategy directory")
+		} else {
+			options.StrategyPath = strategyPath
+		}
+	}
+
+	if options.Strategy != "" {
+		if err := krr.ValidateStrategy(options.Strategy, requestedStrategyPath != nil); err != nil {
+			problems.add("strategy", options.Strategy, err.Error())
+		}
+	}
+
+	quantities := []struct {
+		field string
+		value *string
+		parse func(string) (float64, error)
+		dest  *string
+	}{
+		{"cpu_min", arguments.CPUMin, krr.ParseCPU, &options.CPUMin},
+		{"cpu_max", arguments.CPUMax, krr.ParseCPU, &options.CPUMax},
+		{"memory_min", arguments.MemoryMin, krr.ParseMemory, &options.MemoryMin},
+		{"memory_max", arguments.MemoryMax, krr.ParseMemory, &options.MemoryMax},
+	}
+	for _, quantity := range quantities {
+		if quantity.value == nil {
+			continue
+		}
+		if _, err := quantity.parse(*quantity.value); err != nil {
+			problems.add(quantity.field, *quantity.value, "not a valid Kubernetes quantity")
+			continue
+		}
+		*quantity.dest = *quantity.value
+	}
+
+	if arguments.HistoryDuration != nil {
+		duration, err := krr.ParseHistoryDuration(*arguments.HistoryDuration)
+		if err != nil {
+			problems.add("history_duration", *arguments.HistoryDuration, err.Error())
+		}
+		options.HistoryDuration = duration
+	}
+
+	if arguments.PrometheusLabel != nil {
+		options.PrometheusLabel = strings.TrimSpace(*arguments.PrometheusLabel)
+	}
+	if arguments.ClusterLabelValue != nil {
+		options.ClusterLabelValue = strings.TrimSpace(*arguments.ClusterLabelValue)
+	}
+	if options.ClusterLabelValue != "" && options.PrometheusLabel == "" {
+		problems.add("cluster_label_value", options.ClusterLabelValue, "requires prometheus_label")
+	}
+
+	// Extra flags from a profile or the call must be allow-listed; names are normalized to --name
+	if arguments.ExtraFlags != nil {
+		options.ExtraFlags = arguments.ExtraFlags
+	}
+	if len(options.ExtraFlags) > 0 {
+		flags := make(map[string]string, len(options.ExtraFlags))
+		for name, value := range options.ExtraFlags {
+			flag, err := krr.NormalizeFlagName(name)
+			switch {
+			case err != nil:
+				problems.add("extra_flags", name, err.Error())
+			case !s.extraFlagAllowed(flag):
+				problems.add("extra_flags", name, "not in the server's allowed_extra_flags")
+			case strings.ContainsAny(value, "\x00\n"):
+				problems.add("extra_flags", name, "value cannot contain NUL or newline characters")
+			default:
+				flags[flag] = value
+			}
+		}
+		options.ExtraFlags = flags
+	}
+
+	// A profile's worker count is held to the same cap as the argument
+	if arguments.KRRWorkers != nil {
+		options.MaxWorkers = *arguments.KRRWorkers
+		if options.MaxWorkers < 1 {
+			problems.add("krr_workers", options.MaxWorkers, "must be positive")
+		}
+	} else if options.MaxWorkers == 0 {
+		options.MaxWorkers = s.config().DefaultKRRWorkers
+	}
+	if options.MaxWorkers > s.config().MaxKRRWorkers {
+		problems.add("krr_workers", options.MaxWorkers, fmt.Sprintf("cannot exceed the server's max_krr_workers (%d)", s.config().MaxKRRWorkers))
+	}
+
+	// The summary replaces the table, so it only combines with an explicit table format and
+	// ignores the server's default format
+	summaryOnly := arguments.SummaryOnly != nil && *arguments.SummaryOnly
+	defaultFormat := s.config().DefaultOutputFormat
+	if summaryOnly {
+		defaultFormat = outputModeTable
+	}
+	mode, err := resolveOutputMode(arguments.OutputFormat, defaultFormat)
+	if err != nil {
+		problems.add("output_format", *arguments.OutputFormat, err.Error())
+	} else if mode == outputModeCost && !s.costModel().Enabled() {
+		problems.add("output_format", mode, "requires cpu_cost_per_core_hour and/or memory_cost_per_gib_hour to be configured")
+	}
+	options.Output = outputModes[mode]
+
+	if summaryOnly && mode != outputModeTable {
+		problems.add("summary_only", summaryOnly, "cannot be combined with output_format "+mode)
+	}
+
+	if arguments.TimeoutSeconds != nil && *arguments.TimeoutSeconds < 0 {
+		problems.add("timeout_seconds", *arguments.TimeoutSeconds, "cannot be negative")
+	}
+
+	if arguments.RecommendOnly != nil {
+		options.RecommendOnly = *arguments.RecommendOnly
+	}
+
+	// view picks the filter in one place; an explicit view overrides recommend_only
+	view := krr.ViewAll
+	if options.RecommendOnly {
+		view = krr.ViewRecommendOnly
+	}
+	if arguments.View != nil {
+		parsed, err := krr.ParseView(*arguments.View)
+		if err != nil {
+			problems.add("view", *arguments.View, err.Error())
+		}
+		view = parsed
+		options.RecommendOnly = view == krr.ViewRecommendOnly
+	}
+
+	if arguments.Verbose != nil {
+		options.Verbose = *arguments.Verbose
+	}
+
+	// Profile booleans can only switch behaviour on, since false is indistinguishable from unset
+	options.NoColor = options.NoColor || s.config().DefaultNoColor
+	if arguments.NoColor != nil {
+		options.NoColor = *arguments.NoColor
+	}
+
+	notifySlack := s.config().DefaultNotifySlack
+	if arguments.NotifySlack != nil {
+		notifySlack = *arguments.NotifySlack
+	}
+	if notifySlack && s.live().slack == nil {
+		problems.add("notify_slack", notifySlack, "requires the server to be configured with a slack_webhook_url")
+	}
+
+	var nodeSelector string
+	if arguments.NodeSelector != nil {
+		nodeSelector = strings.TrimSpace(*arguments.NodeSelector)
+		if err := kube.ValidateLabelSelector(nodeSelector); err != nil {
+			problems.add("node_selector", *arguments.NodeSelector, err.Error())
+		}
+	}
+
+	var minSeverity string
+	if arguments.MinSeverity != nil {
+		minSeverity = strings.ToUpper(strings.TrimSpace(*arguments.MinSeverity))
+		if !krr.ValidSeverity(minSeverity) {
+			problems.add("min_severity", *arguments.MinSeverity, "must be one of "+strings.Join(krr.MinimumSeverities, ", "))
+		}
+	}
+
+	maxRows := s.config().MaxOutputRows
+	if arguments.MaxOutputRows != nil {
+		if *arguments.MaxOutputRows < 0 {
+			problems.add("max_output_rows", *arguments.MaxOutputRows, "cannot be negative")
+		}
+		maxRows = *arguments.MaxOutputRows
+	}
+
+	// A resources file replaces the live scan, so options that only shape KRR's cluster and
+	// Prometheus access are rejected rather than silently ignored. They are checked on the merged
+	// options, so a profile cannot slip them in; the strategy and worker count are filled from
+	// server defaults, so for those only an argument or the profile counts.
+	var resourcesFile string
+	if arguments.ResourcesFile != nil {
+		if resourcesFile, err = s.resolveResourcesFile(*arguments.ResourcesFile); err != nil {
+			problems.add("resources_file", *arguments.ResourcesFile, err.Error())
+		}
+		liveOnly := []struct {
+			field string
+			set   bool
+		}{
+			{"context", options.Context != ""},
+			{"cluster_name", options.ClusterName != ""},
+			{"cluster", arguments.Cluster != nil},
+			{"krr_path", arguments.KRRPath != nil},
+			{"strategy", arguments.Strategy != nil || profile.Strategy != ""},
+			{"strategy_path", options.StrategyPath != ""},
+			{"history_duration", options.HistoryDuration != 0},
+			{"prometheus_url", options.PrometheusURL != ""},
+			{"prometheus_label", options.PrometheusLabel != ""},
+			{"cluster_label_value", options.ClusterLabelValue != ""},
+			{"cpu_min", options.CPUMin != ""},
+			{"cpu_max", options.CPUMax != ""},
+			{"memory_min", options.MemoryMin != ""},
+			{"memory_max", options.MemoryMax != ""},
+			{"node_selector", nodeSelector != ""},
+			{"krr_workers", arguments.KRRWorkers != nil || profile.MaxWorkers != 0},
+			{"extra_flags", len(options.ExtraFlags) > 0},
+		}
+		for _, option := range liveOnly {
+			if option.set {
+				problems.add(option.field, nil, "cannot be combined with resources_file")
+			}
+		}
+	}
+
+	// A registry cluster supplies the context and Prometheus URL, and below its kubeconfig
+	var registered *cluster
+	clusterName := ""
+	if arguments.Cluster != nil {
+		if arguments.Context != nil {
+			problems.add("cluster", *arguments.Cluster, "cannot be combined with context")
+		}
+		if registered, err = s.registryCluster(*arguments.Cluster); err != nil {
+			problems.add("cluster", *arguments.Cluster, err.Error())
+		} else {
+			clusterName = registered.name
+			options.Context = registered.config.Context
+			options.PrometheusURL = registered.config.PrometheusURL
+		}
+	}
+
+	// Tenants scan with their own kubeconfig, context and namespace; a custom KRR binary, a
+	// registry cluster or a server-side file would step outside that scope
+	scope, err := s.scopeFor(req, &options)
+	if err != nil {
+		return nil, errorResult(err.Error())
+	}
+	if registered != nil && scope.tenant == "" {
+		scope.executor, scope.kube = registered.executor, registered.kube
+	}
+
+	// Tenants save into their own subdirectory of the artifact directory
+	var artifactDir string
+	if arguments.SaveToPath != nil {
+		if s.config().ArtifactDir == "" {
+			problems.add("save_to_path", *arguments.SaveToPath, "requires the server to be configured with an artifact_dir")
+		} else if artifactDir, err = artifact.ResolvePath(tenantDir(s.config().ArtifactDir, scope.tenant), *arguments.SaveToPath); err != nil {
+			problems.add("save_to_path", *arguments.SaveToPath, err.Error())
+		}
+	}
+	if scope.tenant != "" {
+		if arguments.KRRPath != nil {
+			problems.add("krr_path", *arguments.KRRPath, "not allowed for tenants")
+		}
+		if arguments.ResourcesFile != nil {
+			problems.add("resources_file", *arguments.ResourcesFile, "not allowed for tenants")
+		}
+		if arguments.Cluster != nil {
+			problems.add("cluster", *arguments.Cluster, "not allowed for tenants")
+		}
+	}
+
+	// YAML is KRR's own report, passed through unparsed, so nothing can filter it
+	if mode == outputModeYAML {
+		unparsed := []struct {
+			field string
+			value any
+			set   bool
+		}{
+			{"view", view, view != krr.ViewAll},
+			{"min_severity", minSeverity, minSeverity != ""},
+			{"node_selector", nodeSelector, nodeSelector != ""},
+			{"exclude_namespaces", arguments.ExcludeNamespaces, len(excluded) > 0},
+			{"resources_file", resourcesFile, resourcesFile != ""},
+			{"notify_slack", notifySlack, notifySlack},
+		}
+		for _, option := range unparsed {
+			if option.set {
+				problems.add(option.field, option.value, "cannot be combined with output_format yaml")
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, problems.result()
+	}
+
+	executor := scope.executor
+	if arguments.KRRPath != nil && strings.TrimSpace(*arguments.KRRPath) != "" {
+		if registered != nil {
+			executor = clusterExecutor(s.config(), registered.config, strings.TrimSpace(*arguments.KRRPath))
+		} else {
+			executor = newExecutor(s.config(), strings.TrimSpace(*arguments.KRRPath))
+		}
+	}
+
+	// Operator scope limits are checked on the resolved options, after defaults are applied, so
+	// krr_validate_args and the effective options reflect them; runScan enforces them again.
+	// They protect Prometheus, which a resources file never touches.
+	if resourcesFile == "" {
+		if violations := s.applyScanPolicy(&options); len(violations) > 0 {
+			return nil, policyResult(violations)
+		}
+	}
+
+	// Slack summaries, pushed metrics, the scan history and post-filters need parsed
+	// recommendations, so table mode renders its table from KRR's JSON. The summary is computed
+	// from parsed recommendations too, and replaces the table entirely.
+	renderTable := false
+	if summaryOnly {
+		options.Output = krr.OutputJSON
+	} else if (resourcesFile != "" || notifySlack || s.live().pushgw != nil || s.history != nil || nodeSelector != "" || minSeverity != "" || len(excluded) > 0 || view != krr.ViewAll) && mode == outputModeTable {
+		options.Output = krr.OutputJSON
+		renderTable = true
+	}
+
+	return &scanPlan{
+		options:       options,
+		mode:          mode,
+		view:          view,
+		timeout:       s.scanTimeout(req, arguments.TimeoutSeconds),
+		scope:         scope,
+		cluster:       clusterName,
+		executor:      executor,
+		excluded:      excluded,
+		nodeSelector:  nodeSelector,
+		minSeverity:   minSeverity,
+		maxRows:       maxRows,
+		artifactDir:   artifactDir,
+		resourcesFile: resourcesFile,
+		notifySlack:   notifySlack,
+		renderTable:   renderTable,
+		summaryOnly:   summaryOnly,
+	}, nil
+}
+
+// extraFlagAllowed reports whether allowed_extra_flags lists a normalized flag name
+func (s *MCPServer) extraFlagAllowed(flag string) bool {
+	for _, allowed := range s.config().AllowedExtraFlags {
+		if normalized, err := krr.NormalizeFlagName(allowed); err == nil && normalized == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// handleScanTyped handles the krr_scan tool execution with type-safe API
+func (s *MCPServer) handleScanTyped(ctx context.Context, req *mcp.CallToolRequest, arguments KRRScanArguments) (*mcp.CallToolResult, KRRScanOutput, error) {
+	plan, invalid := s.planScan(req, arguments)
+	if invalid != nil {
+		return invalid, KRRScanOutput{}, nil
+	}
+
+	release, limited := s.acquireClientScan(req)
+	if limited != nil {
+		return limited, KRRScanOutput{}, nil
+	}
+	defer release()
+
+	// Bound the scan by the resolved timeout; an earlier deadline already on ctx still applies
+	ctx, cancel := context.WithTimeout(ctx, plan.timeout)
+	defer cancel()
+
+	// Track the scan so krr_cancel can stop it, then execute it once a scan slot is free
+	ctx, id, untrack := s.running.track(ctx, requestID(req), "krr_scan", plan.scope, plan.options)
+	defer untrack()
+
+	// Interactive clients that passed a progress token see the scan's stages as it runs
+	progress := newScanProgress(req, id)
+	ctx, stopProgress := progress.start(ctx)
+	defer stopProgress()
+
+	// Report the resolved options rather than the raw arguments
+	effective := plan.effectiveOptions()
+	logEffectiveOptions(id, effective)
+
+	var result *krr.ScanResult
+	var err error
+	if plan.resourcesFile != "" {
+		if result, err = s.loadResourcesFile(plan.resourcesFile, plan.options); err != nil {
+			return errorResult(fmt.Sprintf("Failed to load resources_file: %v", err)), KRRScanOutput{}, nil
+		}
+	} else if result, err = s.runScan(ctx, plan.executor, plan.options); err != nil {
+		return scanErrorResult(err, plan.structured(), id), KRRScanOutput{}, nil
+	}
+
+	progress.report(ctx, "formatting results")
+
+	// A KRR release the parser does not understand yet still yields its raw report, rather than
+	// a failed scan; filters, cost and delta modes need parsed recommendations and are skipped
+	if result.ParseError != "" {
+		version, err := s.krrVersion(ctx)
+		if err != nil {
+			version = "unknown"
+		}
+		log.Printf("Scan %s: failed to parse KRR output (KRR version %s): %s", id, version, result.ParseError)
+		outputText := fmt.Sprintf("Warning: couldn't parse recommendations; returning raw KRR output — parser may be out of date with KRR version %s\n\n%s", version, result.RawOutput)
+		return nil, KRRScanOutput{Result: outputText, EffectiveOptions: effective}, nil
+	}
+
+	// KRR has no node filter, so node selection is applied to the parsed results
+	if plan.nodeSelector != "" {
+		pods, err := plan.scope.kube.PodsOnNodes(ctx, plan.options.Context, plan.nodeSelector)
+		if err != nil {
+			return errorResult(fmt.Sprintf("Failed to resolve pods for node_selector: %v", err)), KRRScanOutput{}, nil
+		}
+		result = krr.FilterResources(result, func(resource krr.Resource) bool {
+			for _, pod := range resource.Pods {
+				if pods[resource.Namespace+"/"+pod] {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	// Exclusions are a post-filter over an all-namespace scan; KRR still scans every namespace
+	if len(plan.excluded) > 0 {
+		result = krr.FilterResources(result, func(resource krr.Resource) bool {
+			return !plan.excluded[resource.Namespace]
+		})
+	}
+
+	if plan.view != krr.ViewAll {
+		result = krr.FilterResources(result, plan.view.Keep)
+	}
+
+	if plan.minSeverity != "" {
+		result = krr.FilterResources(result, func(resource krr.Resource) bool {
+			return krr.MeetsSeverity(resource.Severity, plan.minSeverity)
+		})
+	}
+
+	// Format the result based on output format; raw_result drops the human-oriented prefix
+	rawResult := arguments.RawResult != nil && *arguments.RawResult
+	resultPrefix := "KRR Scan Results:\n\n"
+	if rawResult {
+		resultPrefix = ""
+	}
+	var outputText string
+	if plan.summaryOnly {
+		if outputText, err = encodeIndented(resultPrefix, result.Summary); err != nil {
+			return errorResult(fmt.Sprintf("Failed to format summary: %v", err)), KRRScanOutput{}, nil
+		}
+	} else if plan.mode == outputModeCost {
+		// Cost mode returns the summary together with the priced savings and their assumptions
+		report := costReport{
+			Summary: result.Summary,
+			Cost:    krr.EstimateCost(result.Summary, s.costModel()),
+		}
+		if outputText, err = encodeIndented(resultPrefix, report); err != nil {
+			return errorResult(fmt.Sprintf("Failed to format cost estimate: %v", err)), KRRScanOutput{}, nil
+		}
+	} else if plan.mode == outputModeDelta {
+		if outputText, err = encodeIndented(resultPrefix, krr.ComputeDelta(result)); err != nil {
+			return errorResult(fmt.Sprintf("Failed to format delta: %v", err)), KRRScanOutput{}, nil
+		}
+	} else if plan.mode == outputModeMarkdown {
+		outputText = krr.RenderMarkdown(result)
+	} else if plan.renderTable {
+		outputText = resultPrefix + truncateRows(krr.RenderTable(result.Resources), plan.maxRows)
+	} else if plan.options.Output == krr.OutputTable {
+		// For table format, return raw output directly to save tokens
+		outputText = resultPrefix + truncateRows(result.RawOutput, plan.maxRows)
+	} else if plan.options.Output == krr.OutputYAML {
+		outputText = resultPrefix + result.RawOutput
+	} else {
+		// JSON mode returns the typed recommendations as structured output; KRR's raw JSON
+		// carries the same data and is dropped
+		outputText = fmt.Sprintf("%s%d resources scanned, %d with recommendations", resultPrefix, result.Summary.TotalResources, result.Summary.ResourcesWithRecommendations)
+	}
+
+	// Raw structured output is just the JSON document, without appended sections
+	appendSections := !rawResult || !plan.structured()
+
+	// Empty recommendations are usually missing metrics, so flag a failed Prometheus discovery
+	if appendSections && result.Prometheus != nil && !result.Prometheus.Found {
+		outputText += "\n\nWarning: KRR could not auto-discover Prometheus; recommendations may be missing or empty. Check that Prometheus is reachable from the cluster or configure its URL for KRR."
+	}
+
+	// KRR's verbose logs are only returned when explicitly requested, in their own section
+	if appendSections && plan.options.Verbose && result.VerboseOutput != "" {
+		outputText += fmt.Sprintf("\n\nVerbose Output:\n\n%s", result.VerboseOutput)
+	}
+
+	now := time.Now()
+	output := KRRScanOutput{Result: outputText, EffectiveOptions: effective, Signature: krr.Signature(result)}
+	if plan.mode == outputModeJSON {
+		output.Recommendations = result.Resources
+		output.Summary = &result.Summary
+	}
+	if plan.artifactDir != "" {
+		paths, err := artifact.WriteScan(plan.artifactDir, result, plan.options.Output, now)
+		if err != nil {
+			return errorResult(fmt.Sprintf("Scan succeeded but saving the report failed: %v", err)), KRRScanOutput{}, nil
+		}
+		output.ArtifactPaths = paths
+	}
+	output.ReportURL = s.uploadReport(result, plan.scope.tenant, now)
+
+	// A YAML report is never parsed, so there is nothing to publish
+	if plan.options.Output == krr.OutputJSON {
+		if plan.notifySlack {
+			s.notifySlack(result, plan.options.Namespace)
+		}
+		s.pushMetrics(result, plan.options.Namespace)
+		if plan.resourcesFile == "" {
+			s.recordHistory(store.Entry{
+				ID:        id,
+				Timestamp: now,
+				Tenant:    plan.scope.tenant,
+				User:      plan.scope.user,
+				Source:    "krr_scan",
+				Cluster:   historyCluster(plan.cluster, plan.options),
+				Options:   plan.options,
+				Summary:   result.Summary,
+			}, result)
+		}
+	}
+
+	// Clients can read the output again later through the scan resources
+	s.publishScan(ctx, ScanResource{
+		RequestID:  id,
+		Tenant:     plan.scope.tenant,
+		User:       plan.scope.user,
+		FinishedAt: now,
+		Output:     output,
+	})
+
+	return nil, output, nil
+}
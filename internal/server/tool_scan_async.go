@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRScanAsyncOutput defines the output structure for the krr_scan_async tool
+type KRRScanAsyncOutput struct {
+	JobID  string `json:"job_id"`
+	Status string `json:"status"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_scan_async",
+		"Start a krr_scan in the background and return a job ID immediately; poll krr_scan_status and fetch the output with krr_scan_result, so long scans do not hit client timeouts",
+		(*MCPServer).handleScanAsync,
+	))
+}
+
+// handleScanAsync validates the arguments like krr_scan, then runs the scan detached from the
+// request. The job ID doubles as the scan's request ID, so krr_list_running shows the job and
+// krr_cancel stops it.
+func (s *MCPServer) handleScanAsync(ctx context.Context, req *mcp.CallToolRequest, arguments KRRScanArguments) (*mcp.CallToolResult, KRRScanAsyncOutput, error) {
+	plan, invalid := s.planScan(req, arguments)
+	if invalid != nil {
+		return invalid, KRRScanAsyncOutput{}, nil
+	}
+
+	s.jobs.prune(s.config().JobRetention, time.Now())
+	job := ScanJob{
+		JobID:       newRequestID(),
+		Status:      jobRunning,
+		Tenant:      plan.scope.tenant,
+		User:        plan.scope.user,
+		SubmittedAt: time.Now(),
+	}
+	s.jobs.add(job)
+	log.Printf("Scan job %s submitted", job.JobID)
+
+	// The request's context ends with this call, so the scan gets its own; its timeout still
+	// comes from the arguments, and drain and krr_cancel still reach it through the registry
+	background := detachedRequest(req, job.JobID)
+	go func() {
+		result, output, err := s.handleScanTyped(context.Background(), background, arguments)
+		switch {
+		case err != nil:
+			s.jobs.finish(job.JobID, nil, err.Error())
+		case result != nil && result.IsError:
+			s.jobs.finish(job.JobID, nil, resultText(result))
+		default:
+			s.jobs.finish(job.JobID, &output, "")
+		}
+		log.Printf("Scan job %s finished", job.JobID)
+	}()
+
+	return nil, KRRScanAsyncOutput{JobID: job.JobID, Status: job.Status}, nil
+}
+
+// detachedRequest copies a tool request for use after the call returns, with id as its
+// request ID. The headers are cloned, since the transport may reuse the originals, and the
+// progress token is dropped, since the call it belongs to is over.
+func detachedRequest(req *mcp.CallToolRequest, id string) *mcp.CallToolRequest {
+	detached := *req
+	if req.Params != nil {
+		params := *req.Params
+		params.Meta = nil
+		detached.Params = &params
+	}
+	extra := mcp.RequestExtra{Header: http.Header{}}
+	if req.Extra != nil {
+		extra = *req.Extra
+		extra.Header = req.Extra.Header.Clone()
+		if extra.Header == nil {
+			extra.Header = http.Header{}
+		}
+	}
+	extra.Header.Set(requestIDHeader, id)
+	detached.Extra = &extra
+	return &detached
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/store"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Number of history entries krr_scan_history returns by default, and at most
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 1000
+)
+
+// KRRScanHistoryArguments defines the arguments for the krr_scan_history tool
+type KRRScanHistoryArguments struct {
+	Cluster   *string `json:"cluster,omitempty" jsonschema:"Only scans of this registry cluster, cluster name or context (optional)"`
+	Namespace *string `json:"namespace,omitempty" jsonschema:"Only scans of this namespace (optional; scans of all namespaces do not match)"`
+	Since     *string `json:"since,omitempty" jsonschema:"Only scans from this time on: an RFC 3339 timestamp or a duration back from now such as '7d' or '36h' (optional)"`
+	Until     *string `json:"until,omitempty" jsonschema:"Only scans up to this time: an RFC 3339 timestamp or a duration back from now (optional)"`
+	Limit     *int    `json:"limit,omitempty" jsonschema:"Maximum number of scans to return, newest first (default 50, at most 1000)"`
+	ScanID    *string `json:"scan_id,omitempty" jsonschema:"Return the stored recommendations of this scan instead of listing scans (optional, cannot be combined with the filters)"`
+}
+
+// HistoryTrend is how the totals changed from the oldest to the newest returned scan
+type HistoryTrend struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	ReclaimableCPUCoresChange    float64 `json:"reclaimable_cpu_cores_change"`
+	ReclaimableMemoryBytesChange float64 `json:"reclaimable_memory_bytes_change"`
+	CriticalChange               int     `json:"critical_change"`
+	WithRecommendationsChange    int     `json:"with_recommendations_change"`
+}
+
+// KRRScanHistoryOutput defines the output structure for the krr_scan_history tool
+type KRRScanHistoryOutput struct {
+	// Scans are the matching stored scans with their options and totals, newest first
+	Scans []store.Entry `json:"scans"`
+
+	// Trend is only set when at least two scans of the same cluster and namespace are returned
+	Trend *HistoryTrend `json:"trend,omitempty"`
+
+	// Recommendations are the stored results of scan_id
+	Recommendations []krr.Resource `json:"recommendations,omitempty"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_scan_history",
+		"List persisted scans with their options and totals (reclaimable CPU and memory, severity counts), filtered by cluster, namespace and time range, with the trend between the oldest and newest; or return the stored recommendations of one scan by scan_id",
+		(*MCPServer).handleScanHistory,
+	))
+}
+
+// handleScanHistory queries the scan history. Tenants only see their own scans.
+func (s *MCPServer) handleScanHistory(ctx context.Context, req *mcp.CallToolRequest, arguments KRRScanHistoryArguments) (*mcp.CallToolResult, KRRScanHistoryOutput, error) {
+	failed := KRRScanHistoryOutput{Scans: []store.Entry{}}
+	if s.history == nil {
+		return errorResult("Scan history is disabled; set history_dir to persist scans"), failed, nil
+	}
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return errorResult(err.Error()), failed, nil
+	}
+
+	var problems validationErrors
+	filter := store.Filter{Tenant: scope.tenant, Limit: defaultHistoryLimit}
+	if arguments.Cluster != nil {
+		filter.Cluster = strings.TrimSpace(*arguments.Cluster)
+	}
+	if arguments.Namespace != nil {
+		filter.Namespace = strings.TrimSpace(*arguments.Namespace)
+	}
+	now := time.Now()
+	if arguments.Since != nil {
+		if filter.Since, err = parseHistoryTime(*arguments.Since, now); err != nil {
+			problems.add("since", *arguments.Since, err.Error())
+		}
+	}
+	if arguments.Until != nil {
+		if filter.Until, err = parseHistoryTime(*arguments.Until, now); err != nil {
+			problems.add("until", *arguments.Until, err.Error())
+		}
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && filter.Until.Before(filter.Since) {
+		problems.add("until", *arguments.Until, "must not be before since")
+	}
+	if arguments.Limit != nil {
+		if *arguments.Limit < 1 || *arguments.Limit > maxHistoryLimit {
+			problems.add("limit", *arguments.Limit, fmt.Sprintf("must be between 1 and %d", maxHistoryLimit))
+		}
+		filter.Limit = *arguments.Limit
+	}
+	if arguments.ScanID != nil {
+		filtered := arguments.Cluster != nil || arguments.Namespace != nil || arguments.Since != nil || arguments.Until != nil || arguments.Limit != nil
+		if filtered {
+			problems.add("scan_id", *arguments.ScanID, "cannot be combined with cluster, namespace, since, until or limit")
+		}
+	}
+	if len(problems) > 0 {
+		return problems.result(), failed, nil
+	}
+
+	if arguments.ScanID != nil {
+		entry, result, err := s.history.Result(strings.TrimSpace(*arguments.ScanID), scope.tenant)
+		if err != nil {
+			return errorResult(fmt.Sprintf("Failed to load scan: %v", err)), failed, nil
+		}
+		return nil, KRRScanHistoryOutput{Scans: []store.Entry{entry}, Recommendations: result.Resources}, nil
+	}
+
+	output := KRRScanHistoryOutput{Scans: s.history.Query(filter)}
+	output.Trend = historyTrend(output.Scans)
+	return nil, output, nil
+}
+
+// parseHistoryTime parses an RFC 3339 timestamp, or a duration back from now in the forms
+// history_duration accepts
+func parseHistoryTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	duration, err := krr.ParseHistoryDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp or a duration such as '7d' or '36h'")
+	}
+	return now.Add(-duration), nil
+}
+
+// historyTrend compares the oldest and newest of scans, given newest first. Scans of different
+// scopes are not comparable, so there is no trend for them.
+func historyTrend(scans []store.Entry) *HistoryTrend {
+	if len(scans) < 2 {
+		return nil
+	}
+	newest, oldest := scans[0], scans[len(scans)-1]
+	for _, scan := range scans[1:] {
+		if scan.Cluster != newest.Cluster || scan.Options.Namespace != newest.Options.Namespace ||
+			!slices.Equal(scan.Options.Namespaces, newest.Options.Namespaces) {
+			return nil
+		}
+	}
+	return &HistoryTrend{
+		From:                         oldest.Timestamp,
+		To:                           newest.Timestamp,
+		ReclaimableCPUCoresChange:    newest.Summary.ReclaimableCPUCores - oldest.Summary.ReclaimableCPUCores,
+		ReclaimableMemoryBytesChange: newest.Summary.ReclaimableMemoryBytes - oldest.Summary.ReclaimableMemoryBytes,
+		CriticalChange:               newest.Summary.CriticalSeverity - oldest.Summary.CriticalSeverity,
+		WithRecommendationsChange:    newest.Summary.ResourcesWithRecommendations - oldest.Summary.ResourcesWithRecommendations,
+	}
+}
+
+// recordHistory persists a parsed scan for krr_scan_history, if the history is enabled.
+// Failures are logged only, so a full disk never fails a scan.
+func (s *MCPServer) recordHistory(entry store.Entry, result *krr.ScanResult) {
+	if s.history == nil {
+		return
+	}
+	if err := s.history.Add(entry, result); err != nil {
+		log.Printf("Scan %s: failed to record scan history: %v", entry.ID, err)
+	}
+}
+
+// historyCluster names the cluster of a stored scan: the registry cluster it was run against,
+// else its reported cluster name, else its context
+func historyCluster(registry string, options krr.ScanOptions) string {
+	switch {
+	case registry != "":
+		return registry
+	case options.ClusterName != "":
+		return options.ClusterName
+	}
+	return options.Context
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func init() {
+	registerTool(newTool(
+		"krr_scan_result",
+		"Return the output of a finished krr_scan_async job, exactly as krr_scan would have returned it",
+		(*MCPServer).handleScanResult,
+	))
+}
+
+// handleScanResult returns a succeeded job's krr_scan output, or its failure as an error result
+func (s *MCPServer) handleScanResult(ctx context.Context, req *mcp.CallToolRequest, arguments KRRScanJobArguments) (*mcp.CallToolResult, KRRScanOutput, error) {
+	record, failure := s.lookupJob(req, arguments.JobID)
+	if failure != nil {
+		return failure, KRRScanOutput{}, nil
+	}
+
+	switch record.Status {
+	case jobRunning:
+		return errorResult(fmt.Sprintf("Scan job %s is still running; poll krr_scan_status until it finishes", record.JobID)), KRRScanOutput{}, nil
+	case jobFailed:
+		return errorResult(record.Error), KRRScanOutput{}, nil
+	}
+	return nil, *record.Output, nil
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRScanJobArguments defines the arguments for the krr_scan_status and krr_scan_result tools
+type KRRScanJobArguments struct {
+	JobID string `json:"job_id" jsonschema:"Job ID returned by krr_scan_async"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_scan_status",
+		"Report the status of a krr_scan_async job: running, succeeded or failed",
+		(*MCPServer).handleScanStatus,
+	))
+}
+
+// handleScanStatus reports a job's status; tenants only see their own jobs
+func (s *MCPServer) handleScanStatus(ctx context.Context, req *mcp.CallToolRequest, arguments KRRScanJobArguments) (*mcp.CallToolResult, ScanJob, error) {
+	record, failure := s.lookupJob(req, arguments.JobID)
+	if failure != nil {
+		return failure, ScanJob{}, nil
+	}
+	return nil, record.ScanJob, nil
+}
+
+// lookupJob resolves the job_id argument of a job tool to a job visible to the caller
+func (s *MCPServer) lookupJob(req *mcp.CallToolRequest, jobID string) (jobRecord, *mcp.CallToolResult) {
+	id := strings.TrimSpace(jobID)
+	if id == "" {
+		var problems validationErrors
+		problems.add("job_id", jobID, "is required")
+		return jobRecord{}, problems.result()
+	}
+
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return jobRecord{}, errorResult(err.Error())
+	}
+	s.jobs.prune(s.config().JobRetention, time.Now())
+	record, ok := s.jobs.get(id, scope.tenant)
+	if !ok {
+		return jobRecord{}, errorResult(fmt.Sprintf("No scan job with ID %q (finished jobs are kept for %s)", id, s.config().JobRetention))
+	}
+	return record, nil
+}
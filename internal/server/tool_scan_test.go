@@ -0,0 +1,363 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"testing"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+)
+
+func TestScanMinSeverity(t *testing.T) {
+	resources := []krr.Resource{
+		{Name: "critical", Namespace: "shop", Kind: "Deployment", Severity: krr.SeverityCritical},
+		{Name: "warning", Namespace: "shop", Kind: "Deployment", Severity: krr.SeverityWarning},
+		{Name: "ok", Namespace: "shop", Kind: "Deployment", Severity: krr.SeverityOK},
+		{Name: "unknown", Namespace: "shop", Kind: "Deployment", Severity: "UNKNOWN"},
+	}
+	tests := []struct {
+		minimum   string
+		wantNames string
+		wantErr   bool
+	}{
+		{minimum: "CRITICAL", wantNames: "critical"},
+		{minimum: "warning", wantNames: "critical,warning"},
+		{minimum: "OK", wantNames: "critical,warning,ok"},
+		{minimum: "GOOD", wantErr: true},
+		{minimum: "unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.minimum, func(t *testing.T) {
+			s, fake := newTestServer(t, nil)
+			fake.result = &krr.ScanResult{Resources: resources}
+			minimum, format := tt.minimum, outputModeJSON
+
+			result, out, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{MinSeverity: &minimum, OutputFormat: &format})
+			if tt.wantErr {
+				if result == nil {
+					t.Fatal("handleScanTyped() succeeded, want a validation error")
+				}
+				if text := resultText(result); !strings.Contains(text, "must be one of CRITICAL, WARNING, OK") {
+					t.Errorf("result = %s, want the accepted severities listed", text)
+				}
+				return
+			}
+			if result != nil {
+				t.Fatalf("handleScanTyped() = %s", resultText(result))
+			}
+			var names []string
+			for _, resource := range out.Recommendations {
+				names = append(names, resource.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.wantNames {
+				t.Errorf("recommendations = %s, want %s", got, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestScanExtraFlagsAllowList(t *testing.T) {
+	tests := []struct {
+		name      string
+		flags     map[string]string
+		wantFlags map[string]string
+		wantErr   string
+	}{
+		{
+			name:      "allowed flag without dashes",
+			flags:     map[string]string{"use_oomkill_data": ""},
+			wantFlags: map[string]string{"--use_oomkill_data": ""},
+		},
+		{
+			name:      "allowed flag with a value",
+			flags:     map[string]string{"--cpu_percentile": "95"},
+			wantFlags: map[string]string{"--cpu_percentile": "95"},
+		},
+		{
+			name:      "value that looks like a flag",
+			flags:     map[string]string{"--cpu_percentile": "95 --kubeconfig=/etc/shadow"},
+			wantFlags: map[string]string{"--cpu_percentile": "95 --kubeconfig=/etc/shadow"},
+		},
+		{name: "denied flag", flags: map[string]string{"--kubeconfig": "/tmp/other"}, wantErr: "not in the server's allowed_extra_flags"},
+		{name: "allowed flag with an inline value", flags: map[string]string{"--cpu_percentile=95": ""}, wantErr: "invalid flag name"},
+		{name: "denied flag smuggled as a value", flags: map[string]string{"--cpu_percentile=95 --kubeconfig": "x"}, wantErr: "invalid flag name"},
+		{name: "short flag", flags: map[string]string{"-v": ""}, wantErr: "invalid flag name"},
+		{name: "newline in a value", flags: map[string]string{"--cpu_percentile": "95\n--kubeconfig=x"}, wantErr: "cannot contain NUL or newline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.AllowedExtraFlags = []string{"--use_oomkill_data", "cpu_percentile"}
+			s, fake := newTestServer(t, cfg)
+
+			result, _, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{ExtraFlags: tt.flags})
+			if tt.wantErr != "" {
+				if result == nil || !result.IsError {
+					t.Fatalf("handleScanTyped() succeeded, want an error containing %q", tt.wantErr)
+				}
+				if text := resultText(result); !strings.Contains(text, tt.wantErr) || !strings.Contains(text, `"field": "extra_flags"`) {
+					t.Errorf("result = %s, want an extra_flags error containing %q", text, tt.wantErr)
+				}
+				if n := len(fake.options()); n != 0 {
+					t.Errorf("KRR ran %d times with a rejected flag", n)
+				}
+				return
+			}
+			if result != nil && result.IsError {
+				t.Fatalf("handleScanTyped() = %s", resultText(result))
+			}
+			if got := fake.options()[0].ExtraFlags; !maps.Equal(got, tt.wantFlags) {
+				t.Errorf("ExtraFlags = %v, want %v", got, tt.wantFlags)
+			}
+		})
+	}
+}
+
+func TestScanVerboseSection(t *testing.T) {
+	for _, verbose := range []bool{true, false} {
+		t.Run(fmt.Sprintf("verbose=%v", verbose), func(t *testing.T) {
+			s, fake := newTestServer(t, nil)
+			fake.result = &krr.ScanResult{RawOutput: "table", VerboseOutput: "debug: querying prometheus"}
+			format := outputModeTable
+
+			result, out, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{Verbose: &verbose, OutputFormat: &format})
+			if result != nil {
+				t.Fatalf("handleScanTyped() = %s", resultText(result))
+			}
+			if got := fake.options()[0].Verbose; got != verbose {
+				t.Errorf("options.Verbose = %v, want %v", got, verbose)
+			}
+			if got := strings.Contains(out.Result, "Verbose Output:"); got != verbose {
+				t.Errorf("verbose section present = %v, want %v", got, verbose)
+			}
+		})
+	}
+}
+
+func TestScanNoColorPrecedence(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name      string
+		defaultOn bool
+		argument  *bool
+		want      bool
+	}{
+		{"server default on", true, nil, true},
+		{"server default off", false, nil, false},
+		{"argument overrides default on", true, &off, false},
+		{"argument overrides default off", false, &on, true},
+		{"argument agrees with default", true, &on, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.DefaultNoColor = tt.defaultOn
+			s, fake := newTestServer(t, cfg)
+
+			if result, _, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{NoColor: tt.argument}); result != nil {
+				t.Fatalf("handleScanTyped() = %s", resultText(result))
+			}
+			if got := fake.options()[0].NoColor; got != tt.want {
+				t.Errorf("options.NoColor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanClusterLabelValueRequiresLabel(t *testing.T) {
+	label, value := "cluster", "prod-eu"
+	tests := []struct {
+		name      string
+		arguments KRRScanArguments
+		wantValue string
+		wantErr   bool
+	}{
+		{name: "label and value", arguments: KRRScanArguments{PrometheusLabel: &label, ClusterLabelValue: &value}, wantValue: value},
+		{name: "label only", arguments: KRRScanArguments{PrometheusLabel: &label}},
+		{name: "value only", arguments: KRRScanArguments{ClusterLabelValue: &value}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake := newTestServer(t, nil)
+			result, _, _ := s.handleScanTyped(context.Background(), nil, tt.arguments)
+			if tt.wantErr {
+				if result == nil || !strings.Contains(resultText(result), "requires prometheus_label") {
+					t.Errorf("handleScanTyped() = %+v, want a cluster_label_value error", result)
+				}
+				return
+			}
+			if result != nil {
+				t.Fatalf("handleScanTyped() = %s", resultText(result))
+			}
+			got := fake.options()[0]
+			if got.PrometheusLabel != label || got.ClusterLabelValue != tt.wantValue {
+				t.Errorf("options label = %q value = %q, want %q %q", got.PrometheusLabel, got.ClusterLabelValue, label, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestScanViews(t *testing.T) {
+	resources := []krr.Resource{
+		{Name: "changed", Namespace: "shop", Kind: "Deployment", Severity: krr.SeverityOK,
+			Current: krr.ResourceRequirements{CPU: "500m"}, Recommended: krr.ResourceRequirements{CPU: "400m"}},
+		{Name: "steady", Namespace: "shop", Kind: "Deployment", Severity: krr.SeverityOK,
+			Current: krr.ResourceRequirements{CPU: "500m"}, Recommended: krr.ResourceRequirements{CPU: "500m"}},
+		{Name: "warning", Namespace: "shop", Kind: "Deployment", Severity: krr.SeverityWarning,
+			Current: krr.ResourceRequirements{CPU: "500m"}, Recommended: krr.ResourceRequirements{CPU: "500m"}},
+		{Name: "no-history", Namespace: "shop", Kind: "Deployment",
+			Current: krr.ResourceRequirements{CPU: "500m"}},
+	}
+	on, off := true, false
+	tests := []struct {
+		name          string
+		view          string
+		recommendOnly *bool
+		wantNames     string
+		wantErr       bool
+	}{
+		{name: "default", wantNames: "changed,steady,warning,no-history"},
+		{name: "all", view: "all", wantNames: "changed,steady,warning,no-history"},
+		{name: "recommend-only", view: "recommend-only", wantNames: "changed"},
+		{name: "problems", view: "problems", wantNames: "changed,warning,no-history"},
+		{name: "recommend_only flag", recommendOnly: &on, wantNames: "changed"},
+		{name: "view overrides recommend_only", view: "all", recommendOnly: &on, wantNames: "changed,steady,warning,no-history"},
+		{name: "view with recommend_only off", view: "problems", recommendOnly: &off, wantNames: "changed,warning,no-history"},
+		{name: "unknown view", view: "everything", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake := newTestServer(t, nil)
+			fake.result = &krr.ScanResult{Resources: resources}
+			arguments := KRRScanArguments{RecommendOnly: tt.recommendOnly}
+			if tt.view != "" {
+				arguments.View = &tt.view
+			}
+			format := outputModeJSON
+			arguments.OutputFormat = &format
+
+			result, out, _ := s.handleScanTyped(context.Background(), nil, arguments)
+			if tt.wantErr {
+				if result == nil || !strings.Contains(resultText(result), `"field": "view"`) {
+					t.Errorf("handleScanTyped() = %+v, want a view validation error", result)
+				}
+				return
+			}
+			if result != nil {
+				t.Fatalf("handleScanTyped() = %s", resultText(result))
+			}
+			var names []string
+			for _, resource := range out.Recommendations {
+				names = append(names, resource.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.wantNames {
+				t.Errorf("recommendations = %s, want %s", got, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestScanParseFailureFallsBackToRawOutput(t *testing.T) {
+	s, fake := newTestServer(t, nil)
+	fake.version = "1.9.0"
+	fake.result = &krr.ScanResult{RawOutput: `{"scans": [{"object": `, ParseError: "unexpected end of JSON input"}
+	format := outputModeJSON
+
+	result, out, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{OutputFormat: &format})
+	if result != nil {
+		t.Fatalf("handleScanTyped() = %s, want the raw output", resultText(result))
+	}
+	if !strings.Contains(out.Result, "couldn't parse recommendations") || !strings.Contains(out.Result, "KRR version 1.9.0") {
+		t.Errorf("result = %q, want a warning naming the KRR version", out.Result)
+	}
+	if !strings.HasSuffix(out.Result, `{"scans": [{"object": `) {
+		t.Errorf("result = %q, want KRR's raw output after the warning", out.Result)
+	}
+	if out.Recommendations != nil || out.Summary != nil {
+		t.Errorf("output = %+v, want no parsed recommendations", out)
+	}
+}
+
+func TestScanSummaryOnly(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RecentScans = 4
+	s, fake := newTestServer(t, cfg)
+	fake.result = &krr.ScanResult{
+		RawOutput: "│ 1 │ shop │ web-frontend │ raw KRR table │",
+		Resources: []krr.Resource{{Name: "web-frontend", Namespace: "shop", Kind: "Deployment"}},
+		Summary:   krr.Summary{TotalResources: 1, ReclaimableCPUCores: 0.4},
+	}
+	summaryOnly, raw := true, true
+
+	result, out, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{SummaryOnly: &summaryOnly, RawResult: &raw})
+	if result != nil {
+		t.Fatalf("handleScanTyped() = %s", resultText(result))
+	}
+	var summary krr.Summary
+	if err := json.Unmarshal([]byte(out.Result), &summary); err != nil {
+		t.Fatalf("result is not a summary: %v\n%s", err, out.Result)
+	}
+	if summary.TotalResources != 1 || summary.ReclaimableCPUCores != 0.4 {
+		t.Errorf("summary = %+v, want the scan's summary", summary)
+	}
+	if out.Recommendations != nil {
+		t.Errorf("recommendations = %+v, want none", out.Recommendations)
+	}
+
+	// Neither the response nor the stored scan output carries the table
+	stored, ok := s.outputs.get("latest", "")
+	if !ok {
+		t.Fatal("the scan output was not stored")
+	}
+	for name, text := range map[string]string{"response": out.Result, "stored output": stored.Output.Result} {
+		if strings.Contains(text, "raw KRR table") || strings.Contains(text, "web-frontend") {
+			t.Errorf("%s = %q, want no table rows", name, text)
+		}
+	}
+}
+
+func TestScanKRRWorkers(t *testing.T) {
+	tests := []struct {
+		name        string
+		workers     *int
+		wantWorkers int
+		wantErr     string
+	}{
+		{name: "server default", wantWorkers: 4},
+		{name: "argument", workers: intPointer(16), wantWorkers: 16},
+		{name: "at the cap", workers: intPointer(20), wantWorkers: 20},
+		{name: "over the cap", workers: intPointer(21), wantErr: "cannot exceed the server's max_krr_workers (20)"},
+		{name: "zero", workers: intPointer(0), wantErr: "must be positive"},
+		{name: "negative", workers: intPointer(-2), wantErr: "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.DefaultKRRWorkers = 4
+			cfg.MaxKRRWorkers = 20
+			s, fake := newTestServer(t, cfg)
+
+			result, _, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{KRRWorkers: tt.workers})
+			if tt.wantErr != "" {
+				if result == nil || !strings.Contains(resultText(result), tt.wantErr) {
+					t.Errorf("handleScanTyped() = %+v, want a krr_workers error containing %q", result, tt.wantErr)
+				}
+				return
+			}
+			if result != nil {
+				t.Fatalf("handleScanTyped() = %s", resultText(result))
+			}
+			if got := fake.options()[0].MaxWorkers; got != tt.wantWorkers {
+				t.Errorf("options.MaxWorkers = %d, want %d", got, tt.wantWorkers)
+			}
+		})
+	}
+}
+
+func intPointer(n int) *int {
+	return &n
+}
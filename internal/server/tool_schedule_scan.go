@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"greenops-mcp/internal/config"
+	"greenops-mcp/internal/krr"
+	"greenops-mcp/internal/schedule"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// minScheduleInterval is the shortest gap between two runs a krr_schedule_scan cron may have,
+// so a client cannot turn the server into a constant load on Prometheus
+const minScheduleInterval = 15 * time.Minute
+
+// scheduleNamePattern matches the names krr_schedule_scan accepts
+var scheduleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// KRRScheduleScanArguments defines the arguments for the krr_schedule_scan tool
+type KRRScheduleScanArguments struct {
+	Name            string  `json:"name" jsonschema:"Name of the schedule: lowercase letters, digits, '-' and '_'"`
+	Cron            string  `json:"cron" jsonschema:"Five-field cron expression in the server's local time (e.g. '0 6 * * 1-5') or a shorthand such as '@daily'; runs must be at least 15 minutes apart"`
+	Namespace       *string `json:"namespace,omitempty" jsonschema:"Kubernetes namespace to scan (optional, the server's default namespace if not specified)"`
+	Context         *string `json:"context,omitempty" jsonschema:"Kubernetes context to use (optional, uses current context if not specified)"`
+	Strategy        *string `json:"strategy,omitempty" jsonschema:"Recommendation strategy to use (optional, the server's default strategy if not specified)"`
+	HistoryDuration *string `json:"history_duration,omitempty" jsonschema:"How much Prometheus history KRR analyses, e.g. '36h' or '7d' (optional)"`
+	NotifySlack     *bool   `json:"notify_slack,omitempty" jsonschema:"Post a summary to the server's Slack webhook when the recommendations change (optional)"`
+}
+
+// ScheduleInfo describes a scheduled scan
+type ScheduleInfo struct {
+	Name        string          `json:"name"`
+	Cron        string          `json:"cron"`
+	Options     krr.ScanOptions `json:"options"`
+	NotifySlack bool            `json:"notify_slack,omitempty"`
+
+	// Source is "config" for scheduled_scans entries and "tool" for krr_schedule_scan ones
+	Source    string     `json:"source"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	NextRun   *time.Time `json:"next_run,omitempty"`
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	Running   bool       `json:"running"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_schedule_scan",
+		"Schedule a KRR scan to run periodically on a cron expression; results are stored in the scan history, reported to the configured report bucket and metrics, and optionally summarized to Slack when recommendations change",
+		(*MCPServer).handleScheduleScan,
+	))
+}
+
+// handleScheduleScan validates and registers a tool-defined schedule. Scheduled scans run with
+// the server's own credentials, so tenants cannot create them.
+func (s *MCPServer) handleScheduleScan(ctx context.Context, req *mcp.CallToolRequest, arguments KRRScheduleScanArguments) (*mcp.CallToolResult, ScheduleInfo, error) {
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return errorResult(err.Error()), ScheduleInfo{}, nil
+	}
+	if scope.tenant != "" {
+		return errorResult("Scheduled scans run with the server's credentials and are not available to tenants"), ScheduleInfo{}, nil
+	}
+	if s.config().MaxSchedules == 0 {
+		return errorResult("krr_schedule_scan is disabled on this server (max_schedules is 0)"), ScheduleInfo{}, nil
+	}
+
+	var problems validationErrors
+	name := strings.TrimSpace(arguments.Name)
+	if !scheduleNamePattern.MatchString(name) {
+		problems.add("name", arguments.Name, "must be 1 to 63 lowercase letters, digits, '-' or '_', starting with a letter or digit")
+	}
+	cron := strings.TrimSpace(arguments.Cron)
+	sched, err := schedule.Parse(cron)
+	if err != nil {
+		problems.add("cron", arguments.Cron, err.Error())
+	} else if gap := shortestGap(sched, time.Now()); gap > 0 && gap < minScheduleInterval {
+		problems.add("cron", arguments.Cron, fmt.Sprintf("runs %s apart; scheduled scans must be at least %s apart", gap, minScheduleInterval))
+	}
+
+	options := krr.ScanOptions{Namespace: s.config().DefaultNamespace, Strategy: s.config().DefaultStrategy}
+	if arguments.Namespace != nil {
+		options.Namespace = strings.TrimSpace(*arguments.Namespace)
+	}
+	if arguments.Context != nil {
+		options.Context = strings.TrimSpace(*arguments.Context)
+	}
+	if arguments.Strategy != nil {
+		options.Strategy = strings.TrimSpace(*arguments.Strategy)
+		if err := krr.ValidateStrategy(options.Strategy, false); err != nil {
+			problems.add("strategy", options.Strategy, err.Error())
+		}
+	}
+	if arguments.HistoryDuration != nil {
+		if options.HistoryDuration, err = krr.ParseHistoryDuration(*arguments.HistoryDuration); err != nil {
+			problems.add("history_duration", *arguments.HistoryDuration, err.Error())
+		}
+	}
+	notifySlack := arguments.NotifySlack != nil && *arguments.NotifySlack
+	if notifySlack && s.live().slack == nil {
+		problems.add("notify_slack", true, "requires the server to be configured with a slack_webhook_url")
+	}
+	if len(problems) > 0 {
+		return problems.result(), ScheduleInfo{}, nil
+	}
+
+	// The schedule runs unattended, so the operator's scope limits are checked now rather than
+	// only failing every run
+	if violations := s.applyScanPolicy(&options); len(violations) > 0 {
+		return policyResult(violations), ScheduleInfo{}, nil
+	}
+	if s.schedules.count(scheduleFromTool) >= s.config().MaxSchedules {
+		return errorResult(fmt.Sprintf("The server already has the maximum of %d scheduled scans created with krr_schedule_scan; delete one with krr_delete_schedule first", s.config().MaxSchedules)), ScheduleInfo{}, nil
+	}
+
+	scan := &scheduledScan{
+		entry:     config.ScheduleEntry{Name: name, Cron: cron, Options: options, NotifySlack: notifySlack},
+		schedule:  sched,
+		source:    scheduleFromTool,
+		createdBy: scope.user,
+		createdAt: time.Now(),
+	}
+	if err := s.addSchedule(scan); err != nil {
+		return errorResult(err.Error()), ScheduleInfo{}, nil
+	}
+	return nil, scheduleInfo(scan, time.Now()), nil
+}
+
+// shortestGap returns the shortest time between consecutive runs among the next few runs of
+// sched, or 0 when it runs fewer than twice
+func shortestGap(sched *schedule.Schedule, now time.Time) time.Duration {
+	var shortest time.Duration
+	previous := sched.Next(now)
+	for range 24 {
+		if previous.IsZero() {
+			break
+		}
+		next := sched.Next(previous)
+		if next.IsZero() {
+			break
+		}
+		if gap := next.Sub(previous); shortest == 0 || gap < shortest {
+			shortest = gap
+		}
+		previous = next
+	}
+	return shortest
+}
+
+// scheduleInfo describes a scheduled scan as of now
+func scheduleInfo(scan *scheduledScan, now time.Time) ScheduleInfo {
+	info := ScheduleInfo{
+		Name:        scan.entry.Name,
+		Cron:        scan.entry.Cron,
+		Options:     scan.entry.Options,
+		NotifySlack: scan.entry.NotifySlack,
+		Source:      scan.source,
+		CreatedBy:   scan.createdBy,
+		Running:     scan.running.Load(),
+	}
+	if !scan.createdAt.IsZero() {
+		info.CreatedAt = &scan.createdAt
+	}
+	if next := scan.schedule.Next(now); !next.IsZero() {
+		info.NextRun = &next
+	}
+	if last := scan.last.Load(); last != nil {
+		info.LastRun, info.LastError = &last.At, last.Error
+	}
+	return info
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// strategyLookupTimeout bounds krr_strategies, which starts KRR once per strategy
+const strategyLookupTimeout = time.Minute
+
+// KRRStrategiesArguments defines the (empty) arguments for the krr_strategies tool
+type KRRStrategiesArguments struct{}
+
+// KRRStrategiesOutput defines the output structure for the krr_strategies tool
+type KRRStrategiesOutput struct {
+	Strategies []krr.Strategy `json:"strategies"`
+
+	// DefaultStrategy is the strategy scans use when they name none
+	DefaultStrategy string `json:"default_strategy"`
+
+	// CustomStrategies reports whether strategy_path can load strategies beyond these
+	CustomStrategies bool `json:"custom_strategies"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_strategies",
+		"List the recommendation strategies of the installed KRR with their tunable settings (flag, type, default), to find valid values for the strategy argument before scanning",
+		(*MCPServer).handleStrategies,
+	))
+}
+
+// handleStrategies introspects the installed KRR's help output, so the list reflects the
+// installed version rather than what the server expects
+func (s *MCPServer) handleStrategies(ctx context.Context, req *mcp.CallToolRequest, arguments KRRStrategiesArguments) (*mcp.CallToolResult, KRRStrategiesOutput, error) {
+	output := KRRStrategiesOutput{
+		Strategies:       []krr.Strategy{},
+		DefaultStrategy:  s.config().DefaultStrategy,
+		CustomStrategies: s.config().StrategyDir != "",
+	}
+	scope, err := s.scopeFor(req, nil)
+	if err != nil {
+		return errorResult(err.Error()), output, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, strategyLookupTimeout)
+	defer cancel()
+	strategies, err := scope.executor.DescribeStrategies(ctx)
+	if err != nil {
+		return errorResult(fmt.Sprintf("Failed to list KRR strategies: %v", err)), output, nil
+	}
+	// Only strategies krr_scan accepts are listed; others need a strategy_path file
+	for _, strategy := range strategies {
+		if krr.ValidateStrategy(strategy.Name, false) == nil {
+			output.Strategies = append(output.Strategies, strategy)
+		}
+	}
+	return nil, output, nil
+}
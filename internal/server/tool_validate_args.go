@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KRRValidateArgsOutput defines the output structure for the krr_validate_args tool
+type KRRValidateArgsOutput struct {
+	Valid bool `json:"valid"`
+
+	// EffectiveOptions are the settings krr_scan would run with
+	EffectiveOptions *EffectiveScanOptions `json:"effective_options,omitempty"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_validate_args",
+		"Check krr_scan arguments without running a scan: returns the effective options the scan would use, or the same structured validation and policy errors krr_scan would return",
+		(*MCPServer).handleValidateArgs,
+	))
+}
+
+// handleValidateArgs runs krr_scan's validation and option resolution without executing KRR
+func (s *MCPServer) handleValidateArgs(ctx context.Context, req *mcp.CallToolRequest, arguments KRRScanArguments) (*mcp.CallToolResult, KRRValidateArgsOutput, error) {
+	plan, invalid := s.planScan(req, arguments)
+	if invalid != nil {
+		return invalid, KRRValidateArgsOutput{}, nil
+	}
+	return nil, KRRValidateArgsOutput{Valid: true, EffectiveOptions: plan.effectiveOptions()}, nil
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"greenops-mcp/internal/krr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Bounds of a krr_watch call, so a single client cannot keep re-scanning indefinitely
+const (
+	minWatchInterval     = 30 * time.Second
+	maxWatchInterval     = time.Hour
+	defaultWatchInterval = 5 * time.Minute
+	defaultWatchDuration = 30 * time.Minute
+)
+
+// KRRWatchArguments defines the arguments for the krr_watch tool
+type KRRWatchArguments struct {
+	Namespace       *string `json:"namespace,omitempty" jsonschema:"Kubernetes namespace to watch (optional, watches all namespaces if not specified)"`
+	Context         *string `json:"context,omitempty" jsonschema:"Kubernetes context to use (optional, uses current context if not specified)"`
+	Strategy        *string `json:"strategy,omitempty" jsonschema:"Recommendation strategy to use (e.g. 'simple' 'simple-limit')"`
+	IntervalSeconds *int    `json:"interval_seconds,omitempty" jsonschema:"Seconds between scans, from 30 to 3600 (default 300)"`
+	DurationSeconds *int    `json:"duration_seconds,omitempty" jsonschema:"How long to keep watching in seconds (default 1800, capped by the server's max_timeout)"`
+}
+
+// WatchIteration is one krr_watch scan that observed a change from the previous one
+type WatchIteration struct {
+	Iteration int            `json:"iteration"`
+	Timestamp string         `json:"timestamp"`
+	Error     string         `json:"error,omitempty"`
+	Diff      krr.ResultDiff `json:"diff,omitzero"`
+}
+
+// KRRWatchOutput defines the output structure for the krr_watch tool
+type KRRWatchOutput struct {
+	Scans  int `json:"scans"`
+	Failed int `json:"failed"`
+
+	// Changes holds the iterations whose recommendations differed from the previous scan, and
+	// ChangedContainers every container that changed at least once
+	Changes           []WatchIteration `json:"changes"`
+	ChangedContainers []string         `json:"changed_containers"`
+
+	// Summary is the summary of the last successful scan
+	Summary *krr.Summary `json:"summary,omitempty"`
+
+	// Stopped is why the watch ended: "duration" or "canceled"
+	Stopped string `json:"stopped"`
+}
+
+func init() {
+	registerTool(newTool(
+		"krr_watch",
+		"Re-scan a namespace at an interval for a bounded duration, sending a progress notification with only the recommendation changes since the previous scan, and return a summary of every change observed",
+		(*MCPServer).handleWatch,
+	))
+}
+
+// handleWatch re-scans one scope until its duration elapses or it is canceled
+func (s *MCPServer) handleWatch(ctx context.Context, req *mcp.CallToolRequest, arguments KRRWatchArguments) (*mcp.CallToolResult, KRRWatchOutput, error) {
+	var problems validationErrors
+
+	interval := defaultWatchInterval
+	if arguments.IntervalSeconds != nil {
+		interval = time.Duration(*arguments.IntervalSeconds) * time.Second
+		if interval < minWatchInterval || interval > maxWatchInterval {
+			problems.add("interval_seconds", *arguments.IntervalSeconds, fmt.Sprintf("must be between %d and %d", int(minWatchInterval.Seconds()), int(maxWatchInterval.Seconds())))
+		}
+	}
+
+	duration := min(defaultWatchDuration, s.config().MaxTimeout)
+	if arguments.DurationSeconds != nil {
+		duration = time.Duration(*arguments.DurationSeconds) * time.Second
+		if duration < interval {
+			problems.add("duration_seconds", *arguments.DurationSeconds, "must be at least interval_seconds")
+		} else if duration > s.config().MaxTimeout {
+			problems.add("duration_seconds", *arguments.DurationSeconds, fmt.Sprintf("cannot exceed the server's max_timeout (%s)", s.config().MaxTimeout))
+		}
+	}
+
+	options := krr.ScanOptions{
+		Namespace: s.config().DefaultNamespace,
+		Output:    krr.OutputJSON,
+		Strategy:  s.config().DefaultStrategy,
+		NoColor:   true,
+	}
+	if arguments.Namespace != nil {
+		options.Namespace = *arguments.Namespace
+	}
+	if arguments.Context != nil {
+		options.Context = *arguments.Context
+	}
+	if arguments.Strategy != nil {
+		options.Strategy = *arguments.Strategy
+		if err := krr.ValidateStrategy(options.Strategy, false); err != nil {
+			problems.add("strategy", options.Strategy, err.Error())
+		}
+	}
+
+	scope, err := s.scopeFor(req, &options)
+	if err != nil {
+		return errorResult(err.Error()), KRRWatchOutput{}, nil
+	}
+	if len(problems) > 0 {
+		return problems.result(), KRRWatchOutput{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	release, limited := s.acquireClientScan(req)
+	if limited != nil {
+		return limited, KRRWatchOutput{}, nil
+	}
+	defer release()
+
+	// The watch is tracked as one scan; krr_cancel stops it and returns what was observed so far
+	ctx, id, untrack := s.running.track(ctx, requestID(req), "krr_watch", scope, options)
+	defer untrack()
+
+	progress := newWatchProgress(req, int(duration/interval)+1)
+	output := KRRWatchOutput{Changes: []WatchIteration{}, ChangedContainers: []string{}}
+	changed := make(map[string]bool)
+	var previous *krr.ScanResult
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+watch:
+	for iteration := 1; ; iteration++ {
+		scanCtx, cancelScan := context.WithTimeout(ctx, s.config().DefaultTimeout)
+		result, err := s.runScan(scanCtx, scope.executor, options)
+		cancelScan()
+
+		// A scan cut short by the end of the watch is not a failure, just the end
+		if err != nil && ctx.Err() != nil {
+			break watch
+		}
+		// The policy rejects every iteration alike, so the watch ends on the first
+		var policyErr *policyError
+		if errors.As(err, &policyErr) {
+			return policyResult(policyErr.violations), KRRWatchOutput{}, nil
+		}
+		output.Scans++
+
+		current := WatchIteration{Iteration: iteration, Timestamp: time.Now().Format(time.RFC3339)}
+		switch {
+		case err != nil:
+			output.Failed++
+			current.Error = fmt.Sprintf("KRR scan failed: %v", err)
+			output.Changes = append(output.Changes, current)
+		case previous != nil:
+			current.Diff = krr.DiffResults(previous, result)
+			if !current.Diff.Empty() {
+				output.Changes = append(output.Changes, current)
+				for _, container := range current.Diff.Containers() {
+					if !changed[container] {
+						changed[container] = true
+						output.ChangedContainers = append(output.ChangedContainers, container)
+					}
+				}
+			}
+		}
+		if err == nil {
+			previous = result
+			summary := result.Summary
+			output.Summary = &summary
+		}
+		progress.notify(ctx, id, current, iteration == 1 && err == nil)
+
+		select {
+		case <-ctx.Done():
+			break watch
+		case <-ticker.C:
+		}
+	}
+
+	output.Stopped = "duration"
+	if errors.Is(ctx.Err(), context.Canceled) {
+		output.Stopped = "canceled"
+	}
+	return nil, output, nil
+}
+
+// watchProgress sends krr_watch's per-scan progress notifications, if the client asked for them
+type watchProgress struct {
+	req   *mcp.CallToolRequest
+	token any
+	total int
+}
+
+// newWatchProgress prepares progress notifications for a watch expected to run total scans
+func newWatchProgress(req *mcp.CallToolRequest, total int) *watchProgress {
+	progress := &watchProgress{req: req, total: total}
+	if req != nil && req.Params != nil && req.Session != nil {
+		progress.token = req.Params.GetProgressToken()
+	}
+	return progress
+}
+
+// notify reports one scan. The message carries only that scan's diff (or its error); the first
+// scan is the baseline and is reported without one.
+func (p *watchProgress) notify(ctx context.Context, requestID string, iteration WatchIteration, baseline bool) {
+	if p.token == nil {
+		return
+	}
+	message := "baseline scan complete"
+	if !baseline {
+		data, err := json.Marshal(iteration)
+		if err != nil {
+			log.Printf("Watch %s: failed to marshal progress: %v", requestID, err)
+			return
+		}
+		message = string(data)
+	}
+	params := &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Message:       message,
+		Progress:      float64(iteration.Iteration),
+		Total:         float64(max(p.total, iteration.Iteration)),
+	}
+	if err := p.req.Session.NotifyProgress(ctx, params); err != nil {
+		log.Printf("Watch %s: failed to send progress notification: %v", requestID, err)
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolHandler is a type-safe tool handler bound to an MCPServer at registration time
+type toolHandler[In, Out any] func(s *MCPServer, ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error)
+
+// toolDefinition describes a single MCP tool exposed by the server
+type toolDefinition interface {
+	// Name returns the tool name as advertised to MCP clients
+	Name() string
+
+	// Description returns the human-readable tool description
+	Description() string
+
+	// InputSchema returns the JSON schema of the tool's arguments, as advertised to MCP clients
+	InputSchema() (*jsonschema.Schema, error)
+
+	// Register adds the tool to the MCP server
+	Register(s *MCPServer)
+}
+
+// typedTool is a toolDefinition backed by a type-safe handler
+type typedTool[In, Out any] struct {
+	name        string
+	description string
+	handler     toolHandler[In, Out]
+}
+
+// newTool creates a tool definition from a name, description and handler
+func newTool[In, Out any](name, description string, handler toolHandler[In, Out]) toolDefinition {
+	return &typedTool[In, Out]{
+		name:        name,
+		description: description,
+		handler:     handler,
+	}
+}
+
+// Name returns the tool name
+func (t *typedTool[In, Out]) Name() string {
+	return t.name
+}
+
+// Description returns the tool description
+func (t *typedTool[In, Out]) Description() string {
+	return t.description
+}
+
+// InputSchema infers the argument schema from the input type, the same way AddTool does
+func (t *typedTool[In, Out]) InputSchema() (*jsonschema.Schema, error) {
+	return jsonschema.For[In](&jsonschema.ForOptions{})
+}
+
+// Register adds the tool to the MCP server using AddTool with a type-safe handler
+func (t *typedTool[In, Out]) Register(s *MCPServer) {
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        t.name,
+		Description: t.description,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		if s.draining.Load() {
+			s.metrics.recordToolCall(t.name, true)
+			var zero Out
+			return nil, zero, errShuttingDown
+		}
+		if err := s.checkRateLimit(req); err != nil {
+			s.metrics.recordToolCall(t.name, true)
+			var zero Out
+			return nil, zero, err
+		}
+		result, output, err := t.handler(s, ctx, req, input)
+		s.metrics.recordToolCall(t.name, err != nil || (result != nil && result.IsError))
+		return result, output, err
+	})
+}
+
+// toolRegistry holds every tool known to the server, in registration order
+var toolRegistry []toolDefinition
+
+// registerTool adds a tool definition to the registry. Tools call it from init().
+func registerTool(tool toolDefinition) {
+	toolRegistry = append(toolRegistry, tool)
+}
+
+// registerTools registers all KRR tools with the MCP server
+func (s *MCPServer) registerTools() error {
+	seen := make(map[string]bool, len(toolRegistry))
+	for _, tool := range toolRegistry {
+		if seen[tool.Name()] {
+			return fmt.Errorf("duplicate tool name: %s", tool.Name())
+		}
+		seen[tool.Name()] = true
+		tool.Register(s)
+	}
+
+	return nil
+}
+
+// errorResult builds a tool result that reports an error to the client
+func errorResult(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: message},
+		},
+		IsError: true,
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ValidationError describes a single invalid tool argument
+type ValidationError struct {
+	Field  string `json:"field"`
+	Value  any    `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Error implements the error interface
+func (e ValidationError) Error() string {
+	if e.Value != nil {
+		return fmt.Sprintf("%s=%v: %s", e.Field, e.Value, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+// validationErrors collects every invalid argument of a tool call so they can be reported together
+type validationErrors []ValidationError
+
+// add records an invalid argument
+func (v *validationErrors) add(field string, value any, reason string) {
+	*v = append(*v, ValidationError{Field: field, Value: value, Reason: reason})
+}
+
+// validationReport is the JSON document returned for invalid arguments and policy violations
+type validationReport struct {
+	Error   string            `json:"error"`
+	Message string            `json:"message"`
+	Errors  []ValidationError `json:"errors"`
+}
+
+// result builds a tool error result carrying the validation errors as JSON
+func (v validationErrors) result() *mcp.CallToolResult {
+	return v.resultWithCode("invalid_arguments", "Invalid arguments")
+}
+
+// resultWithCode builds a tool error result with the given error code and message prefix
+func (v validationErrors) resultWithCode(code, prefix string) *mcp.CallToolResult {
+	messages := make([]string, len(v))
+	for i, err := range v {
+		messages[i] = err.Error()
+	}
+	report := validationReport{
+		Error:   code,
+		Message: prefix + ": " + strings.Join(messages, "; "),
+		Errors:  v,
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errorResult(report.Message)
+	}
+	return errorResult(string(data))
+}
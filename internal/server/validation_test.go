@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestScanReportsAllValidationErrorsTogether(t *testing.T) {
+	s, fake := newTestServer(t, nil)
+	cpuMin, history, format, workers, label := "lots", "forever", "pdf", -1, "prod"
+
+	result, _, _ := s.handleScanTyped(context.Background(), nil, KRRScanArguments{
+		CPUMin:            &cpuMin,
+		HistoryDuration:   &history,
+		OutputFormat:      &format,
+		KRRWorkers:        &workers,
+		ClusterLabelValue: &label,
+	})
+	if result == nil || !result.IsError {
+		t.Fatal("handleScanTyped() succeeded, want validation errors")
+	}
+	if n := len(fake.options()); n != 0 {
+		t.Errorf("KRR ran %d times with invalid arguments", n)
+	}
+
+	var report validationReport
+	if err := json.Unmarshal([]byte(resultText(result)), &report); err != nil {
+		t.Fatalf("result is not a validation report: %v\n%s", err, resultText(result))
+	}
+	if report.Error != "invalid_arguments" {
+		t.Errorf("error = %q, want invalid_arguments", report.Error)
+	}
+	fields := make(map[string]ValidationError)
+	for _, err := range report.Errors {
+		fields[err.Field] = err
+	}
+	for _, field := range []string{"cpu_min", "history_duration", "output_format", "krr_workers", "cluster_label_value"} {
+		err, ok := fields[field]
+		if !ok {
+			t.Errorf("no error reported for %s (errors %+v)", field, report.Errors)
+			continue
+		}
+		if err.Reason == "" || err.Value == nil {
+			t.Errorf("%s error = %+v, want the offending value and a reason", field, err)
+		}
+	}
+}
+
+func TestValidationErrorString(t *testing.T) {
+	tests := []struct {
+		err  ValidationError
+		want string
+	}{
+		{ValidationError{Field: "cpu_min", Value: "lots", Reason: "invalid quantity"}, "cpu_min=lots: invalid quantity"},
+		{ValidationError{Field: "namespace", Reason: "is required"}, "namespace: is required"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+
+	"greenops-mcp/internal/krr"
+)
+
+// gzipMagic starts every gzip stream. JSON never starts with it, so Decode can tell the two
+// payload forms apart without a header of its own.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Codec converts scan results to and from stored payloads: JSON, gzip-compressed when Compress
+// is set. Decode detects the form from the payload, so entries written before compression was
+// toggled stay readable and callers only ever see plain ScanResults.
+type Codec struct {
+	Compress bool
+	Debug    bool // log the compression ratio of every encoded payload
+}
+
+// Encode serializes a scan result for storage
+func (c Codec) Encode(result *krr.ScanResult) ([]byte, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode scan result: %w", err)
+	}
+	if !c.Compress {
+		return data, nil
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress scan result: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress scan result: %w", err)
+	}
+	if c.Debug {
+		log.Printf("Compressed stored scan result from %d to %d bytes (ratio %.1f)",
+			len(data), compressed.Len(), float64(len(data))/float64(compressed.Len()))
+	}
+	return compressed.Bytes(), nil
+}
+
+// Decode restores a scan result written by Encode, compressed or not
+func (c Codec) Decode(data []byte) (*krr.ScanResult, error) {
+	if bytes.HasPrefix(data, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress scan result: %w", err)
+		}
+		defer reader.Close()
+		if data, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed to decompress scan result: %w", err)
+		}
+	}
+
+	var result krr.ScanResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode scan result: %w", err)
+	}
+	return &result, nil
+}
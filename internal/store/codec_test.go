@@ -0,0 +1,80 @@
+package store
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"greenops-mcp/internal/krr"
+)
+
+func testScanResult() *krr.ScanResult {
+	return &krr.ScanResult{
+		Timestamp: "2024-05-01T10:00:00Z",
+		Cluster:   "prod",
+		Resources: []krr.Resource{
+			{
+				Name:        "web",
+				Namespace:   "shop",
+				Kind:        "Deployment",
+				Container:   "app",
+				Current:     krr.ResourceRequirements{CPU: "500m", Memory: "512Mi"},
+				Recommended: krr.ResourceRequirements{CPU: "100m", Memory: "256Mi"},
+				Severity:    "CRITICAL",
+			},
+		},
+		SchemaVersion: "v2",
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		codec := Codec{Compress: compress}
+		data, err := codec.Encode(testScanResult())
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		if got := bytes.HasPrefix(data, gzipMagic); got != compress {
+			t.Errorf("Compress=%v: payload gzipped = %v", compress, got)
+		}
+		result, err := codec.Decode(data)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if !reflect.DeepEqual(result, testScanResult()) {
+			t.Errorf("Compress=%v: Decode() = %+v, want %+v", compress, result, testScanResult())
+		}
+	}
+}
+
+func TestCodecDecodesEitherForm(t *testing.T) {
+	plain, err := Codec{}.Encode(testScanResult())
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	compressed, err := Codec{Compress: true}.Encode(testScanResult())
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	// Toggling compress_stored must keep entries written in the other form readable
+	for _, codec := range []Codec{{}, {Compress: true}} {
+		for _, data := range [][]byte{plain, compressed} {
+			result, err := codec.Decode(data)
+			if err != nil {
+				t.Fatalf("Compress=%v: Decode() error = %v", codec.Compress, err)
+			}
+			if !reflect.DeepEqual(result, testScanResult()) {
+				t.Errorf("Compress=%v: Decode() = %+v", codec.Compress, result)
+			}
+		}
+	}
+}
+
+func TestCodecRejectsCorruptPayloads(t *testing.T) {
+	for _, data := range [][]byte{[]byte("{not json"), append(append([]byte(nil), gzipMagic...), "truncated"...)} {
+		if _, err := (Codec{}).Decode(data); err == nil {
+			t.Errorf("Decode(%q) succeeded, want an error", data)
+		}
+	}
+}
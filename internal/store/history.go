@@ -0,0 +1,240 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"greenops-mcp/internal/krr"
+)
+
+// historyIndex is the file listing the stored scans, one JSON Entry per line, oldest first
+const historyIndex = "index.jsonl"
+
+// Entry describes one stored scan. The index holds entries only; the parsed results are kept
+// in a payload file of their own, so querying the history never decodes them.
+type Entry struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Tenant    string    `json:"tenant,omitempty"`
+	User      string    `json:"user,omitempty"`
+
+	// Source is what ran the scan: "krr_scan" or "schedule:<name>"
+	Source string `json:"source"`
+
+	// Cluster is the registry cluster, cluster name or context the scan targeted
+	Cluster string          `json:"cluster,omitempty"`
+	Options krr.ScanOptions `json:"options"`
+	Summary krr.Summary     `json:"summary"`
+}
+
+// Filter selects history entries. Zero fields match everything; Tenant only matches its own
+// entries, while an empty Tenant sees every entry.
+type Filter struct {
+	Tenant    string
+	Cluster   string
+	Namespace string
+	Since     time.Time
+	Until     time.Time
+
+	// Limit keeps the newest entries only (0 keeps all)
+	Limit int
+}
+
+// matches reports whether the filter selects entry. A namespace matches the scans of that
+// namespace, or of a namespace list including it, not scans of every namespace.
+func (f Filter) matches(entry Entry) bool {
+	switch {
+	case f.Tenant != "" && entry.Tenant != f.Tenant:
+		return false
+	case f.Cluster != "" && entry.Cluster != f.Cluster && entry.Options.Context != f.Cluster:
+		return false
+	case f.Namespace != "" && entry.Options.Namespace != f.Namespace &&
+		(entry.Options.Namespace != "" || !slices.Contains(entry.Options.Namespaces, f.Namespace)):
+		return false
+	case !f.Since.IsZero() && entry.Timestamp.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && entry.Timestamp.After(f.Until):
+		return false
+	}
+	return true
+}
+
+// History persists every scan in a directory: an append-only index of entries and one payload
+// per scan, written with the codec. Entries older than the retention are dropped as new scans
+// are added.
+type History struct {
+	mu        sync.Mutex
+	dir       string
+	codec     Codec
+	retention time.Duration
+	entries   []Entry
+}
+
+// OpenHistory opens the history in dir, creating the directory if needed. A zero retention
+// keeps scans forever.
+func OpenHistory(dir string, codec Codec, retention time.Duration) (*History, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	history := &History{dir: dir, codec: codec, retention: retention}
+
+	file, err := os.Open(filepath.Join(dir, historyIndex))
+	if errors.Is(err, fs.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history index: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.ID == "" {
+			log.Printf("Skipping line %d of history index: not a scan entry", line)
+			continue
+		}
+		history.entries = append(history.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history index: %w", err)
+	}
+	return history, nil
+}
+
+// Add stores a scan and its parsed results
+func (h *History) Add(entry Entry, result *krr.ScanResult) error {
+	data, err := h.codec.Encode(result)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// The payload goes first, so an indexed scan always has its results
+	if err := os.WriteFile(h.payloadPath(entry.ID), data, 0600); err != nil {
+		return fmt.Errorf("failed to write scan %s: %w", entry.ID, err)
+	}
+	index, err := os.OpenFile(filepath.Join(h.dir, historyIndex), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history index: %w", err)
+	}
+	_, err = index.Write(append(line, '\n'))
+	if closeErr := index.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to append to history index: %w", err)
+	}
+	h.entries = append(h.entries, entry)
+
+	if h.retention > 0 {
+		h.prune(entry.Timestamp.Add(-h.retention))
+	}
+	return nil
+}
+
+// prune drops the entries older than cutoff, rewriting the index. Failures are logged only;
+// the expired entries are tried again on the next scan.
+func (h *History) prune(cutoff time.Time) {
+	expired := 0
+	for expired < len(h.entries) && h.entries[expired].Timestamp.Before(cutoff) {
+		expired++
+	}
+	if expired == 0 {
+		return
+	}
+
+	kept := h.entries[expired:]
+	var data []byte
+	for _, entry := range kept {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			log.Printf("Failed to prune scan history: %v", err)
+			return
+		}
+		data = append(append(data, line...), '\n')
+	}
+	// Replace the index atomically, so a crash never leaves it half written
+	tmp := filepath.Join(h.dir, historyIndex+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		log.Printf("Failed to prune scan history: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, filepath.Join(h.dir, historyIndex)); err != nil {
+		log.Printf("Failed to prune scan history: %v", err)
+		return
+	}
+
+	for _, entry := range h.entries[:expired] {
+		if err := os.Remove(h.payloadPath(entry.ID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Failed to remove expired scan %s: %v", entry.ID, err)
+		}
+	}
+	h.entries = append([]Entry(nil), kept...)
+}
+
+// Query returns the entries the filter selects, newest first
+func (h *History) Query(filter Filter) []Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := []Entry{}
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+		if filter.matches(h.entries[i]) {
+			entries = append(entries, h.entries[i])
+		}
+	}
+	return entries
+}
+
+// Result loads the parsed results of a stored scan visible to tenant; an empty tenant sees
+// every scan
+func (h *History) Result(id, tenant string) (Entry, *krr.ScanResult, error) {
+	h.mu.Lock()
+	var entry Entry
+	found := false
+	for _, candidate := range h.entries {
+		if candidate.ID == id && (tenant == "" || candidate.Tenant == tenant) {
+			entry, found = candidate, true
+			break
+		}
+	}
+	h.mu.Unlock()
+	if !found {
+		return Entry{}, nil, fmt.Errorf("scan %s is not in the history", id)
+	}
+
+	data, err := os.ReadFile(h.payloadPath(id))
+	if err != nil {
+		return Entry{}, nil, fmt.Errorf("failed to read scan %s: %w", id, err)
+	}
+	result, err := h.codec.Decode(data)
+	if err != nil {
+		return Entry{}, nil, err
+	}
+	return entry, result, nil
+}
+
+// payloadPath is the file holding the results of a scan
+func (h *History) payloadPath(id string) string {
+	return filepath.Join(h.dir, id+".scan")
+}
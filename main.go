@@ -24,10 +24,13 @@ func main() {
 		krrPath    = flag.String("krr-path", "", "Path to KRR CLI executable (overrides config)")
 		timeout    = flag.Duration("timeout", 0, "Default timeout for KRR operations (overrides config)")
 		logLevel   = flag.String("log-level", "", "Log level: debug, info, warn, error (overrides config)")
+		transport  = flag.String("transport", "", "MCP transport: http or stdio (overrides config)")
+		listenAddr = flag.String("listen-addr", "", "HTTP listen address, e.g. ':8080', '127.0.0.1:9000' or 'unix:///path.sock' (overrides config)")
 		validate   = flag.Bool("validate", false, "Validate KRR installation and exit")
 		version    = flag.Bool("version", false, "Show version and exit")
 		help       = flag.Bool("help", false, "Show help message")
 	)
+	flag.StringVar(listenAddr, "listen", "", "Alias for -listen-addr, e.g. 'unix:///var/run/greenops-mcp.sock'")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
@@ -36,15 +39,23 @@ func main() {
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nEnvironment Variables:\n")
 		fmt.Fprintf(os.Stderr, "  KRR_TIMEOUT        Default timeout for KRR operations (e.g., '5m')\n")
+		fmt.Fprintf(os.Stderr, "  KRR_MAX_TIMEOUT    Upper bound for per-call scan timeouts (e.g., '30m')\n")
 		fmt.Fprintf(os.Stderr, "  KRR_STRATEGY       Default recommendation strategy\n")
 		fmt.Fprintf(os.Stderr, "  KRR_NAMESPACE      Default namespace to scan\n")
 		fmt.Fprintf(os.Stderr, "  KRR_OUTPUT_FORMAT  Default output format (json or yaml)\n")
+		fmt.Fprintf(os.Stderr, "  KRR_KUBECONFIG_DATA Inline kubeconfig YAML (raw or base64) used instead of a kubeconfig file\n")
+		fmt.Fprintf(os.Stderr, "  KRR_TRANSPORT      MCP transport (http or stdio)\n")
+		fmt.Fprintf(os.Stderr, "  KRR_LISTEN_ADDR    HTTP listen address (e.g., '127.0.0.1:9000' or 'unix:///var/run/greenops-mcp.sock')\n")
+		fmt.Fprintf(os.Stderr, "  KRR_HTTP_READ_TIMEOUT, KRR_HTTP_WRITE_TIMEOUT, KRR_HTTP_IDLE_TIMEOUT\n")
+		fmt.Fprintf(os.Stderr, "                     HTTP server timeouts (e.g., '30s'; '0' disables)\n")
+		fmt.Fprintf(os.Stderr, "  KRR_DRAIN_TIMEOUT  How long shutdown waits for in-flight scans (e.g., '2m')\n")
 		fmt.Fprintf(os.Stderr, "  KRR_LOG_LEVEL      Log level (debug, info, warn, error)\n")
 		fmt.Fprintf(os.Stderr, "  KRR_LOG_FILE       Log file path\n")
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  %s                                    # Start server with default config\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -config /path/to/config.json      # Start server with custom config\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -timeout 10m                      # Override default timeout\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -transport stdio                  # Serve MCP over stdin/stdout\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -validate                         # Validate KRR installation\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -version                          # Show version information\n", os.Args[0])
 	}
@@ -62,29 +73,39 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Load configuration
-	cfg, err := config.LoadConfig(*configPath)
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
+	// Load configuration: the file, then environment variables, then command line flags. SIGHUP
+	// repeats this to reload the configuration.
+	loadConfig := func() (*config.Config, error) {
+		cfg, err := config.LoadConfig(*configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+		cfg.LoadFromEnvironment()
 
-	// Load configuration from environment variables
-	cfg.LoadFromEnvironment()
+		if *krrPath != "" {
+			cfg.KRRPath = *krrPath
+		}
+		if *timeout > 0 {
+			cfg.DefaultTimeout = *timeout
+		}
+		if *logLevel != "" {
+			cfg.LogLevel = *logLevel
+		}
+		if *transport != "" {
+			cfg.Transport = *transport
+		}
+		if *listenAddr != "" {
+			cfg.ListenAddr = *listenAddr
+		}
 
-	// Override configuration with command line flags
-	if *krrPath != "" {
-		cfg.KRRPath = *krrPath
-	}
-	if *timeout > 0 {
-		cfg.DefaultTimeout = *timeout
-	}
-	if *logLevel != "" {
-		cfg.LogLevel = *logLevel
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+		return cfg, nil
 	}
-
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Invalid configuration: %v", err)
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	// Setup logging
@@ -100,7 +121,7 @@ func main() {
 	}
 
 	// Create and start MCP server
-	if err := runServer(cfg); err != nil {
+	if err := runServer(cfg, loadConfig); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
@@ -130,7 +151,11 @@ func validateKRRInstallation(cfg *config.Config) error {
 	defer cancel()
 
 	// Create a temporary KRR executor for validation
-	executor := krr.NewCLIExecutor(cfg.KRRPath, cfg.DefaultTimeout)
+	var opts []krr.ExecutorOption
+	if cfg.KubeconfigData != "" {
+		opts = append(opts, krr.WithKubeconfigData(cfg.KubeconfigData))
+	}
+	executor := krr.NewCLIExecutor(cfg.KRRPath, cfg.DefaultTimeout, opts...)
 
 	// Validate installation
 	if err := executor.ValidateInstallation(ctx); err != nil {
@@ -150,14 +175,19 @@ func validateKRRInstallation(cfg *config.Config) error {
 }
 
 // runServer creates and runs the MCP server
-func runServer(cfg *config.Config) error {
+func runServer(cfg *config.Config, loadConfig func() (*config.Config, error)) error {
 	// Create MCP server
 	mcpServer, err := server.NewMCPServer(cfg)
 
 	if err != nil {
 		return fmt.Errorf("failed to create MCP server: %w", err)
 	}
-	executeScan(mcpServer)
+	mcpServer.SetConfigLoader(loadConfig)
+
+	// In stdio mode stdout carries the MCP protocol, so the startup scan's output must stay off it
+	if cfg.Transport != config.TransportStdio {
+		executeScan(mcpServer)
+	}
 
 	// Start server in a goroutine
 	err = mcpServer.Run()